@@ -15,6 +15,7 @@ package pd
 
 import (
 	"context"
+	"net/http"
 	"reflect"
 	"sort"
 	"sync"
@@ -45,6 +46,8 @@ type baseClient struct {
 	cancel context.CancelFunc
 
 	security SecurityOption
+	// token is the bearer token sent with the gRPC and the HTTP requests.
+	token string
 
 	gRPCDialOptions []grpc.DialOption
 }
@@ -66,6 +69,37 @@ func WithGRPCDialOptions(opts ...grpc.DialOption) ClientOption {
 	}
 }
 
+// WithToken configures the client with the bearer token to authenticate
+// itself, which is sent with both the gRPC and the HTTP requests.
+func WithToken(token string) ClientOption {
+	return func(c *baseClient) {
+		c.token = token
+		c.gRPCDialOptions = append(c.gRPCDialOptions, grpc.WithPerRPCCredentials(tokenCredentials(token)))
+	}
+}
+
+// tokenCredentials puts the bearer token into the metadata of the gRPC
+// requests.
+type tokenCredentials string
+
+func (t tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// RequireTransportSecurity returns false, since PD may be deployed without
+// TLS.
+func (t tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// setToken puts the bearer token into the header of the HTTP request if the
+// client has one.
+func (c *baseClient) setToken(req *http.Request) {
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+}
+
 // newBaseClient returns a new baseClient.
 func newBaseClient(ctx context.Context, urls []string, security SecurityOption, opts ...ClientOption) (*baseClient, error) {
 	ctx1, cancel := context.WithCancel(ctx)
@@ -62,13 +62,32 @@ type Client interface {
 	// The store may expire later. Caller is responsible for caching and taking care
 	// of store change.
 	GetAllStores(ctx context.Context, opts ...GetStoreOption) ([]*metapb.Store, error)
+	// WatchStores watches the changes of stores since the revision, including
+	// store additions, updates such as becoming tombstone, and deletions.
+	// The current stores are sent first if the revision is not positive.
+	WatchStores(ctx context.Context, revision int64) (<-chan []*StoreEvent, error)
+	// GetRegionLabels gets the labels assigned to the region by the region
+	// label rules.
+	GetRegionLabels(ctx context.Context, regionID uint64) ([]*RegionLabel, error)
+	// GetKeyLabels gets the labels assigned to the key by the region label
+	// rules.
+	GetKeyLabels(ctx context.Context, key []byte) ([]*RegionLabel, error)
 	// Update GC safe point. TiKV will check it and do GC themselves if necessary.
 	// If the given safePoint is less than the current one, it will not be updated.
 	// Returns the new safePoint after updating.
 	UpdateGCSafePoint(ctx context.Context, safePoint uint64) (uint64, error)
+	// UpdateServiceGCSafePoint updates the safepoint of the service, which
+	// expires after ttl seconds. A non-positive ttl removes the safepoint of
+	// the service. The GC safepoint cannot exceed the safepoints of alive
+	// services. It returns the minimum safepoint among all alive services,
+	// or 0 if there is none.
+	UpdateServiceGCSafePoint(ctx context.Context, serviceID string, ttl int64, safePoint uint64) (uint64, error)
 	// ScatterRegion scatters the specified region. Should use it for a batch of regions,
 	// and the distribution of these regions will be dispersed.
-	ScatterRegion(ctx context.Context, regionID uint64) error
+	ScatterRegion(ctx context.Context, regionID uint64, opts ...ScatterRegionOption) error
+	// ScatterRegions scatters a batch of regions, and returns the reasons of
+	// the regions which fail to scatter by their IDs.
+	ScatterRegions(ctx context.Context, regionIDs []uint64, opts ...ScatterRegionOption) (map[uint64]string, error)
 	// GetOperator gets the status of operator of the specified region.
 	GetOperator(ctx context.Context, regionID uint64) (*pdpb.GetOperatorResponse, error)
 	// ConfigClient gets the configuration client.
@@ -604,7 +623,23 @@ func (c *client) UpdateGCSafePoint(ctx context.Context, safePoint uint64) (uint6
 	return resp.GetNewSafePoint(), nil
 }
 
-func (c *client) ScatterRegion(ctx context.Context, regionID uint64) error {
+func (c *client) ScatterRegion(ctx context.Context, regionID uint64, opts ...ScatterRegionOption) error {
+	op := &ScatterRegionOp{}
+	for _, opt := range opts {
+		opt(op)
+	}
+	// The gRPC request has no group, so the region in a group is scattered by
+	// the HTTP API.
+	if op.group != "" {
+		failures, err := c.ScatterRegions(ctx, []uint64{regionID}, opts...)
+		if err != nil {
+			return err
+		}
+		if msg, ok := failures[regionID]; ok {
+			return errors.Errorf("scatter region %d failed: %s", regionID, msg)
+		}
+		return nil
+	}
 	if span := opentracing.SpanFromContext(ctx); span != nil {
 		span = opentracing.StartSpan("pdclient.ScatterRegion", opentracing.ChildOf(span.Context()))
 		defer span.Finish()
@@ -0,0 +1,53 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+// The gRPC API has no service safepoints yet, so they are updated by the HTTP
+// API.
+const serviceGCSafePointPath = "/pd/api/v1/gc/safepoint"
+
+func (c *client) UpdateServiceGCSafePoint(ctx context.Context, serviceID string, ttl int64, safePoint uint64) (uint64, error) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("pdclient.UpdateServiceGCSafePoint", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	start := time.Now()
+	defer func() { cmdDurationUpdateServiceGCSafePoint.Observe(time.Since(start).Seconds()) }()
+
+	input := map[string]interface{}{
+		"service_id": serviceID,
+		"ttl":        ttl,
+		"safe_point": safePoint,
+	}
+	// The minimum safepoint is null if no service is alive.
+	var min *struct {
+		SafePoint uint64 `json:"safe_point"`
+	}
+	if err := c.postJSON(ctx, serviceGCSafePointPath, input, &min); err != nil {
+		cmdFailedDurationUpdateServiceGCSafePoint.Observe(time.Since(start).Seconds())
+		return 0, err
+	}
+	if min == nil {
+		return 0, nil
+	}
+	return min.SafePoint, nil
+}
@@ -84,6 +84,7 @@ var (
 	cmdDurationGetAllStores      = cmdDuration.WithLabelValues("get_all_stores")
 	cmdDurationUpdateGCSafePoint = cmdDuration.WithLabelValues("update_gc_safe_point")
 	cmdDurationScatterRegion     = cmdDuration.WithLabelValues("scatter_region")
+	cmdDurationScatterRegions    = cmdDuration.WithLabelValues("scatter_regions")
 	cmdDurationGetOperator       = cmdDuration.WithLabelValues("get_operator")
 
 	cmdFailDurationGetRegion           = cmdFailedDuration.WithLabelValues("get_region")
@@ -96,6 +97,10 @@ var (
 	cmdFailedDurationUpdateGCSafePoint = cmdFailedDuration.WithLabelValues("update_gc_safe_point")
 	requestDurationTSO                 = requestDuration.WithLabelValues("tso")
 
+	// service gc safepoint
+	cmdDurationUpdateServiceGCSafePoint       = cmdDuration.WithLabelValues("update_service_gc_safe_point")
+	cmdFailedDurationUpdateServiceGCSafePoint = cmdFailedDuration.WithLabelValues("update_service_gc_safe_point")
+
 	// config
 	configCmdDurationCreate = configCmdDuration.WithLabelValues("create")
 	configCmdDurationGetAll = configCmdDuration.WithLabelValues("get_all")
@@ -0,0 +1,106 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pingcap/pd/v4/pkg/grpcutil"
+	"github.com/pkg/errors"
+)
+
+const regionLabelPrefix = "/pd/api/v1/config/region-label/"
+
+// RegionLabel is a label of the regions assigned by the region label rules.
+type RegionLabel struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// GetRegionLabels gets the labels of the region by id.
+func (c *client) GetRegionLabels(ctx context.Context, regionID uint64) ([]*RegionLabel, error) {
+	var labels []*RegionLabel
+	if err := c.getJSON(ctx, fmt.Sprintf("%sregion/%d", regionLabelPrefix, regionID), &labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// GetKeyLabels gets the labels of the key.
+func (c *client) GetKeyLabels(ctx context.Context, key []byte) ([]*RegionLabel, error) {
+	var labels []*RegionLabel
+	if err := c.getJSON(ctx, regionLabelPrefix+"key/"+hex.EncodeToString(key), &labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// getJSON sends a GET request to the HTTP API of the leader and decodes the
+// response into v.
+func (c *client) getJSON(ctx context.Context, path string, v interface{}) error {
+	return c.doJSON(ctx, http.MethodGet, path, nil, v)
+}
+
+// postJSON sends a POST request with the input encoded in JSON to the HTTP
+// API of the leader and decodes the response into v.
+func (c *client) postJSON(ctx context.Context, path string, input, v interface{}) error {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return c.doJSON(ctx, http.MethodPost, path, data, v)
+}
+
+func (c *client) doJSON(ctx context.Context, method, path string, body []byte, v interface{}) error {
+	tlsCfg, err := grpcutil.SecurityConfig{
+		CAPath:   c.security.CAPath,
+		CertPath: c.security.CertPath,
+		KeyPath:  c.security.KeyPath,
+	}.ToTLSConfig()
+	if err != nil {
+		return err
+	}
+	cli := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsCfg}}
+	defer cli.CloseIdleConnections()
+
+	ctx, cancel := context.WithTimeout(ctx, pdTimeout)
+	defer cancel()
+	req, err := http.NewRequest(method, c.GetLeaderAddr()+path, bytes.NewReader(body))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.setToken(req)
+	resp, err := cli.Do(req.WithContext(ctx))
+	if err != nil {
+		c.ScheduleCheckLeader()
+		return errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return errors.Errorf("[pd] failed to %s %s, status: %s, message: %s", strings.ToLower(method), path, resp.Status, bytes.TrimSpace(msg))
+	}
+	return errors.WithStack(json.NewDecoder(resp.Body).Decode(v))
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+const scatterRegionsPath = "/pd/api/v1/regions/scatter"
+
+// ScatterRegionOp represents available options when scattering regions.
+type ScatterRegionOp struct {
+	group string
+}
+
+// ScatterRegionOption configures ScatterRegionOp.
+type ScatterRegionOption func(*ScatterRegionOp)
+
+// WithGroup scatters the regions in the group. The peers and the leaders of
+// the regions in the same group are balanced among the stores. The group is
+// saved by PD, so it is kept when the leader changes, and it is dropped after
+// it is idle for an hour.
+func WithGroup(group string) ScatterRegionOption {
+	return func(op *ScatterRegionOp) { op.group = group }
+}
+
+// ScatterRegions scatters the regions by the HTTP API, since the gRPC request
+// can only carry one region.
+func (c *client) ScatterRegions(ctx context.Context, regionIDs []uint64, opts ...ScatterRegionOption) (map[uint64]string, error) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("pdclient.ScatterRegions", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	start := time.Now()
+	defer func() { cmdDurationScatterRegions.Observe(time.Since(start).Seconds()) }()
+
+	op := &ScatterRegionOp{}
+	for _, opt := range opts {
+		opt(op)
+	}
+	input := map[string]interface{}{
+		"region_ids": regionIDs,
+		"group":      op.group,
+	}
+	var result struct {
+		FailedRegions map[uint64]string `json:"failed_regions"`
+	}
+	if err := c.postJSON(ctx, scatterRegionsPath, input, &result); err != nil {
+		return nil, err
+	}
+	return result.FailedRegions, nil
+}
@@ -0,0 +1,172 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/grpcutil"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// StoreEventType is the type of a store event.
+type StoreEventType string
+
+// Store event types.
+const (
+	StoreEventPut    StoreEventType = "put"
+	StoreEventDelete StoreEventType = "delete"
+)
+
+// StoreEvent is a change of a store.
+type StoreEvent struct {
+	Type StoreEventType
+	// Revision is used to resume the watch, see WatchStores.
+	Revision int64
+	// Store is the latest meta of the store. Only the ID is set for
+	// StoreEventDelete.
+	Store *metapb.Store
+}
+
+const (
+	watchPrefix = "/pd/api/v1/watch/"
+	// allowFollowerHandle lets the server serve the watch by itself instead of
+	// redirecting it to the leader, which does not support streaming.
+	allowFollowerHandle = "PD-Allow-follower-handle"
+	watchRetryInterval  = time.Second
+	watchChanSize       = 16
+)
+
+// watchEvent is the event sent by the watch API of PD.
+type watchEvent struct {
+	Type     string          `json:"type"`
+	Revision int64           `json:"revision"`
+	Key      string          `json:"key"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// WatchStores watches the changes of stores since the revision. If the
+// revision is not positive, the current stores are sent as put events first.
+// The watch is resumed automatically if it is broken. If the revision has
+// been compacted, it starts over with the current stores. The channel is
+// closed when the context is done or the client is closed.
+func (c *client) WatchStores(ctx context.Context, revision int64) (<-chan []*StoreEvent, error) {
+	tlsCfg, err := grpcutil.SecurityConfig{
+		CAPath:   c.security.CAPath,
+		CertPath: c.security.CertPath,
+		KeyPath:  c.security.KeyPath,
+	}.ToTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	// Watches are long-lived, no need to reuse connections.
+	cli := &http.Client{Transport: &http.Transport{
+		DisableKeepAlives: true,
+		TLSClientConfig:   tlsCfg,
+	}}
+
+	ctx, cancel := context.WithCancel(ctx)
+	ch := make(chan []*StoreEvent, watchChanSize)
+	go func() {
+		defer close(ch)
+		defer cancel()
+		go func() {
+			select {
+			case <-c.ctx.Done():
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		for {
+			var err error
+			revision, err = c.watchStores(ctx, cli, revision, ch)
+			if ctx.Err() != nil {
+				return
+			}
+			log.Warn("[pd] store watch is broken, retry later", zap.Int64("revision", revision), zap.Error(err))
+			// The server may be stopped or no longer serve the watch, retry
+			// with the latest leader.
+			c.ScheduleCheckLeader()
+			select {
+			case <-time.After(watchRetryInterval):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// watchStores watches the stores until the stream is broken, and returns the
+// revision to resume from.
+func (c *client) watchStores(ctx context.Context, cli *http.Client, revision int64, ch chan<- []*StoreEvent) (int64, error) {
+	url := fmt.Sprintf("%s%sstores?revision=%d", c.GetLeaderAddr(), watchPrefix, revision)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return revision, errors.WithStack(err)
+	}
+	req.Header.Set(allowFollowerHandle, "true")
+	c.setToken(req)
+	resp, err := cli.Do(req.WithContext(ctx))
+	if err != nil {
+		return revision, errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return revision, errors.Errorf("failed to watch stores, status: %s", resp.Status)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var events []*watchEvent
+		if err := decoder.Decode(&events); err != nil {
+			return revision, errors.WithStack(err)
+		}
+		storeEvents := make([]*StoreEvent, 0, len(events))
+		for _, e := range events {
+			switch e.Type {
+			case string(StoreEventPut):
+				store := &metapb.Store{}
+				if err := json.Unmarshal(e.Value, store); err != nil {
+					return revision, errors.WithStack(err)
+				}
+				storeEvents = append(storeEvents, &StoreEvent{Type: StoreEventPut, Revision: e.Revision, Store: store})
+			case string(StoreEventDelete):
+				id, err := strconv.ParseUint(e.Key, 10, 64)
+				if err != nil {
+					return revision, errors.WithStack(err)
+				}
+				storeEvents = append(storeEvents, &StoreEvent{Type: StoreEventDelete, Revision: e.Revision, Store: &metapb.Store{Id: id}})
+			default:
+				// The revision is compacted, start over with a snapshot.
+				return 0, errors.Errorf("revision %d is compacted", revision)
+			}
+			revision = e.Revision + 1
+		}
+		select {
+		case ch <- storeEvents:
+		case <-ctx.Done():
+			return revision, ctx.Err()
+		}
+	}
+}
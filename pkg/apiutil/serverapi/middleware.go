@@ -14,16 +14,22 @@
 package serverapi
 
 import (
+	"bytes"
 	"crypto/tls"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/typeutil"
 	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/audit"
+	"github.com/pingcap/pd/v4/server/auth"
 	"github.com/pingcap/pd/v4/server/config"
+	"github.com/pkg/errors"
 	"github.com/urfave/negroni"
 	"go.uber.org/zap"
 )
@@ -33,6 +39,12 @@ const (
 	RedirectorHeader    = "PD-Redirector"
 	AllowFollowerHandle = "PD-Allow-follower-handle"
 	FollowerHandle      = "PD-Follwer-handle"
+
+	// The caller of the request redirected to the leader, which is
+	// authenticated by the member redirecting it.
+	ForwardedUserHeader   = "PD-Forwarded-User"
+	ForwardedRoleHeader   = "PD-Forwarded-Role"
+	ForwardedMethodHeader = "PD-Forwarded-Method"
 )
 
 const (
@@ -89,6 +101,136 @@ func IsServiceAllowed(s *server.Server, group server.ServiceGroup) bool {
 	return false
 }
 
+type auditor struct {
+	s       *server.Server
+	routeOf func(*http.Request) string
+	roleOf  func(*http.Request) auth.Role
+}
+
+// NewAuditor records the requests which change the cluster in the audit log,
+// routeOf returns the path template of the request and roleOf returns the
+// role required by it. The requests which only read are not recorded. A
+// request redirected to the leader is recorded by both the member it is sent
+// to and the leader, the record of the leader has the name of the member in
+// redirected-from. It does nothing if the audit log is disabled.
+func NewAuditor(s *server.Server, routeOf func(*http.Request) string, roleOf func(*http.Request) auth.Role) negroni.Handler {
+	return &auditor{s: s, routeOf: routeOf, roleOf: roleOf}
+}
+
+func (h *auditor) ServeHTTP(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	l := h.s.GetAuditLogger()
+	if l == nil || isSafeMethod(r.Method) || h.roleOf(r) <= auth.RoleReadOnly {
+		next(w, r)
+		return
+	}
+	start := time.Now()
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	// The request is changed by the redirector, so the record is filled
+	// before serving it.
+	record := &audit.Record{
+		Time:           start,
+		Address:        r.RemoteAddr,
+		RedirectedFrom: r.Header.Get(RedirectorHeader),
+		Protocol:       audit.ProtocolHTTP,
+		Method:         r.Method,
+		Route:          h.routeOf(r),
+		Path:           r.URL.RequestURI(),
+	}
+	rw := negroni.NewResponseWriter(w)
+	next(rw, r)
+
+	record.Status = rw.Status()
+	if !rw.Written() {
+		// Nothing is written, which is answered with 200 OK.
+		record.Status = http.StatusOK
+	}
+	record.Duration = typeutil.NewDuration(time.Since(start))
+	if a := h.s.GetAuthenticator(); a != nil {
+		if id, err := authenticate(a, r); err == nil {
+			record.SetIdentity(id)
+		}
+	}
+	record.SetBody(body)
+	l.Log(record)
+}
+
+// isSafeMethod returns true if the HTTP method only reads, such as reading
+// the audit records by GET.
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+type authChecker struct {
+	s      *server.Server
+	roleOf func(*http.Request) auth.Role
+}
+
+// NewAuthChecker checks if the caller is allowed to do the request, roleOf
+// returns the role required by the request. The caller is put into the
+// context of the request. The request redirected by a PD member is checked
+// with the caller forwarded by the member, which has been checked by the
+// member as well. It does nothing if the authentication is disabled.
+func NewAuthChecker(s *server.Server, roleOf func(*http.Request) auth.Role) negroni.Handler {
+	return &authChecker{s: s, roleOf: roleOf}
+}
+
+func (h *authChecker) ServeHTTP(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	a := h.s.GetAuthenticator()
+	role := h.roleOf(r)
+	if a == nil || role == auth.RoleNone {
+		next(w, r)
+		return
+	}
+	id, err := authenticate(a, r)
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err := auth.Check(id, role); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	next(w, r.WithContext(auth.WithIdentity(r.Context(), id)))
+}
+
+// authenticate returns the caller of the request. The caller of the request
+// redirected by a PD member is the one authenticated by the member, instead of
+// the member itself.
+func authenticate(a *auth.Authenticator, r *http.Request) (*auth.Identity, error) {
+	if r.Header.Get(RedirectorHeader) == "" || r.Header.Get(ForwardedRoleHeader) == "" || !a.IsMember(r) {
+		return a.AuthenticateHTTP(r)
+	}
+	role, err := auth.ParseRole(r.Header.Get(ForwardedRoleHeader))
+	if err != nil {
+		return nil, errors.Wrap(auth.ErrUnauthenticated, err.Error())
+	}
+	return &auth.Identity{
+		Name:   r.Header.Get(ForwardedUserHeader),
+		Role:   role,
+		Method: r.Header.Get(ForwardedMethodHeader),
+	}, nil
+}
+
+// setForwardedIdentity puts the caller of the request into the headers, so
+// the leader can check it after the request is redirected.
+func setForwardedIdentity(r *http.Request) {
+	r.Header.Del(ForwardedUserHeader)
+	r.Header.Del(ForwardedRoleHeader)
+	r.Header.Del(ForwardedMethodHeader)
+	if id := auth.IdentityFrom(r.Context()); id != nil {
+		r.Header.Set(ForwardedUserHeader, id.Name)
+		r.Header.Set(ForwardedRoleHeader, id.Role.String())
+		r.Header.Set(ForwardedMethodHeader, id.Method)
+	}
+}
+
 type redirector struct {
 	s *server.Server
 }
@@ -116,6 +258,7 @@ func (h *redirector) ServeHTTP(w http.ResponseWriter, r *http.Request, next http
 	}
 
 	r.Header.Set(RedirectorHeader, h.s.Name())
+	setForwardedIdentity(r)
 
 	leader := h.s.GetMember().GetLeader()
 	if leader == nil {
@@ -25,6 +25,7 @@ import (
 	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/kv"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/server/schedule/placement"
 	"github.com/pingcap/pd/v4/server/statistics"
 	"go.uber.org/zap"
@@ -38,13 +39,15 @@ type Cluster struct {
 	*placement.RuleManager
 	*statistics.HotCache
 	*statistics.StoresStats
-	ID uint64
+	ID            uint64
+	regionLabeler *labeler.RegionLabeler
 }
 
 // NewCluster creates a new Cluster
 func NewCluster(opt *mockoption.ScheduleOptions) *Cluster {
 	ruleManager := placement.NewRuleManager(core.NewStorage(kv.NewMemoryKV()))
 	ruleManager.Initialize(opt.MaxReplicas, opt.GetLocationLabels())
+	regionLabeler, _ := labeler.NewRegionLabeler(core.NewStorage(kv.NewMemoryKV()))
 	return &Cluster{
 		BasicCluster:    core.NewBasicCluster(),
 		IDAllocator:     mockid.NewIDAllocator(),
@@ -52,6 +55,7 @@ func NewCluster(opt *mockoption.ScheduleOptions) *Cluster {
 		RuleManager:     ruleManager,
 		HotCache:        statistics.NewHotCache(),
 		StoresStats:     statistics.NewStoresStats(),
+		regionLabeler:   regionLabeler,
 	}
 }
 
@@ -135,6 +139,11 @@ func (mc *Cluster) GetRuleManager() *placement.RuleManager {
 	return mc.RuleManager
 }
 
+// GetRegionLabeler returns the region labeler of the cluster.
+func (mc *Cluster) GetRegionLabeler() *labeler.RegionLabeler {
+	return mc.regionLabeler
+}
+
 // SetStoreUp sets store state to be up.
 func (mc *Cluster) SetStoreUp(storeID uint64) {
 	store := mc.GetStore(storeID)
@@ -477,6 +486,27 @@ func (mc *Cluster) UpdateStorageReadKeys(storeID uint64, keysRead uint64) {
 	mc.PutStore(newStore)
 }
 
+// UpdateStorageCPUUsage updates store cpu usage.
+func (mc *Cluster) UpdateStorageCPUUsage(storeID uint64, cpuUsage uint64) {
+	store := mc.GetStore(storeID)
+	newStats := proto.Clone(store.GetStoreStats()).(*pdpb.StoreStats)
+	newStats.CpuUsages = []*pdpb.RecordPair{{Key: "cop", Value: cpuUsage}}
+	newStore := store.Clone(core.SetStoreStats(newStats))
+	mc.Set(storeID, newStats)
+	mc.PutStore(newStore)
+}
+
+// UpdateStorageDiskRate updates store disk read and write rates.
+func (mc *Cluster) UpdateStorageDiskRate(storeID uint64, readRate, writeRate uint64) {
+	store := mc.GetStore(storeID)
+	newStats := proto.Clone(store.GetStoreStats()).(*pdpb.StoreStats)
+	newStats.ReadIoRates = []*pdpb.RecordPair{{Key: "read", Value: readRate}}
+	newStats.WriteIoRates = []*pdpb.RecordPair{{Key: "write", Value: writeRate}}
+	newStore := store.Clone(core.SetStoreStats(newStats))
+	mc.Set(storeID, newStats)
+	mc.PutStore(newStore)
+}
+
 // UpdateStoreStatus updates store status.
 func (mc *Cluster) UpdateStoreStatus(id uint64) {
 	leaderCount := mc.Regions.GetStoreLeaderCount(id)
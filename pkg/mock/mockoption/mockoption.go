@@ -37,6 +37,8 @@ const (
 	defaultTolerantSizeRatio           = 2.5
 	defaultLowSpaceRatio               = 0.8
 	defaultHighSpaceRatio              = 0.6
+	defaultDiskAlmostFullRatio         = 0.9
+	defaultDiskFullRatio               = 0.95
 	defaultSchedulerMaxWaitingOperator = 3
 	defaultHotRegionCacheHitsThreshold = 3
 	defaultStrictlyMatchLabel          = true
@@ -59,6 +61,8 @@ type ScheduleOptions struct {
 	MaxMergeRegionSize           uint64
 	MaxMergeRegionKeys           uint64
 	SchedulerMaxWaitingOperator  uint64
+	SchedulerWeights             map[string]float64
+	SchedulerInflightQuotas      map[string]uint64
 	SplitMergeInterval           time.Duration
 	EnableOneWayMerge            bool
 	EnableCrossTableMerge        bool
@@ -71,6 +75,8 @@ type ScheduleOptions struct {
 	TolerantSizeRatio            float64
 	LowSpaceRatio                float64
 	HighSpaceRatio               float64
+	DiskAlmostFullRatio          float64
+	DiskFullRatio                float64
 	EnableRemoveDownReplica      bool
 	EnableReplaceOfflineReplica  bool
 	EnableMakeUpReplica          bool
@@ -110,6 +116,8 @@ func NewScheduleOptions() *ScheduleOptions {
 	mso.TolerantSizeRatio = defaultTolerantSizeRatio
 	mso.LowSpaceRatio = defaultLowSpaceRatio
 	mso.HighSpaceRatio = defaultHighSpaceRatio
+	mso.DiskAlmostFullRatio = defaultDiskAlmostFullRatio
+	mso.DiskFullRatio = defaultDiskFullRatio
 	mso.EnableRemoveDownReplica = true
 	mso.EnableReplaceOfflineReplica = true
 	mso.EnableMakeUpReplica = true
@@ -230,11 +238,34 @@ func (mso *ScheduleOptions) GetHighSpaceRatio() float64 {
 	return mso.HighSpaceRatio
 }
 
+// GetDiskAlmostFullRatio mocks method
+func (mso *ScheduleOptions) GetDiskAlmostFullRatio() float64 {
+	return mso.DiskAlmostFullRatio
+}
+
+// GetDiskFullRatio mocks method
+func (mso *ScheduleOptions) GetDiskFullRatio() float64 {
+	return mso.DiskFullRatio
+}
+
 // GetSchedulerMaxWaitingOperator mocks method.
 func (mso *ScheduleOptions) GetSchedulerMaxWaitingOperator() uint64 {
 	return mso.SchedulerMaxWaitingOperator
 }
 
+// GetSchedulerWeight mocks method.
+func (mso *ScheduleOptions) GetSchedulerWeight(name string) float64 {
+	if weight, ok := mso.SchedulerWeights[name]; ok {
+		return weight
+	}
+	return 1
+}
+
+// GetSchedulerInflightQuota mocks method.
+func (mso *ScheduleOptions) GetSchedulerInflightQuota(name string) uint64 {
+	return mso.SchedulerInflightQuotas[name]
+}
+
 // SetMaxReplicas mocks method
 func (mso *ScheduleOptions) SetMaxReplicas(replicas int) {
 	mso.MaxReplicas = replicas
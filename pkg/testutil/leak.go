@@ -13,7 +13,13 @@
 
 package testutil
 
-import "go.uber.org/goleak"
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"go.uber.org/goleak"
+)
 
 // LeakOptions is used to filter the goroutines.
 var LeakOptions = []goleak.Option{
@@ -27,9 +33,39 @@ var LeakOptions = []goleak.Option{
 	goleak.IgnoreTopFunction("google.golang.org/grpc.(*addrConn).createTransport"),
 	goleak.IgnoreTopFunction("google.golang.org/grpc.(*addrConn).resetTransport"),
 	goleak.IgnoreTopFunction("go.etcd.io/etcd/pkg/logutil.(*MergeLogger).outputLoop"),
+	// lumberjack never stops the goroutine to compress and remove the backups.
+	goleak.IgnoreTopFunction("gopkg.in/natefinch/lumberjack%2ev2.(*Logger).millRun"),
 	// TODO: remove the below options once we fixed the http connection leak problems
 	goleak.IgnoreTopFunction("internal/poll.runtime_pollWait"),
 	goleak.IgnoreTopFunction("net/http.(*persistConn).writeLoop"),
 	goleak.IgnoreTopFunction("net/http.(*persistConn).readLoop"),
 	goleak.IgnoreTopFunction("runtime.goparkunlock"),
 }
+
+// etcdConnReadTimeout is the read timeout of the connections between etcd
+// peers.
+const etcdConnReadTimeout = 5 * time.Second
+
+// VerifyTestMainWithEtcd is the same as goleak.VerifyTestMain, except that it
+// waits for the connections between etcd peers to be closed. The read
+// deadline of such a connection is reset before every read, so an HTTP
+// connection of etcd may not be closed until the read timeout after the
+// servers are stopped.
+func VerifyTestMainWithEtcd(m interface{ Run() int }) {
+	exitCode := m.Run()
+	if exitCode == 0 {
+		deadline := time.Now().Add(etcdConnReadTimeout)
+		for {
+			err := goleak.FindLeaks(LeakOptions...)
+			if err == nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				fmt.Fprintf(os.Stderr, "goleak: Errors on successful test run: %v\n", err)
+				exitCode = 1
+				break
+			}
+		}
+	}
+	os.Exit(exitCode)
+}
@@ -14,15 +14,19 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
 	"github.com/pingcap/pd/v4/pkg/apiutil"
 	"github.com/pingcap/pd/v4/server"
+	"github.com/pkg/errors"
 	"github.com/unrolled/render"
 )
 
+var errAuditDisabled = errors.New("audit log is disabled")
+
 type adminHandler struct {
 	svr *server.Server
 	rd  *render.Render
@@ -92,3 +96,28 @@ func (h *adminHandler) ResetTS(w http.ResponseWriter, r *http.Request) {
 	}
 	h.rd.JSON(w, http.StatusOK, "success")
 }
+
+// @Tags admin
+// @Summary List the recent audit records of the requests received by this PD, from the newest to the oldest.
+// @Param limit query integer false "The max number of records"
+// @Produce json
+// @Success 200 {array} audit.Record
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 412 {string} string "The audit log is disabled."
+// @Router /admin/audit [get]
+func (h *adminHandler) GetAuditRecords(w http.ResponseWriter, r *http.Request) {
+	l := h.svr.GetAuditLogger()
+	if l == nil {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errAuditDisabled.Error())
+		return
+	}
+	var limit int
+	if s := r.URL.Query().Get("limit"); s != "" {
+		var err error
+		if limit, err = strconv.Atoi(s); err != nil || limit <= 0 {
+			h.rd.JSON(w, http.StatusBadRequest, fmt.Sprintf("invalid limit: %s", s))
+			return
+		}
+	}
+	h.rd.JSON(w, http.StatusOK, l.Recent(limit))
+}
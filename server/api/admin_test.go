@@ -14,16 +14,28 @@
 package api
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"path/filepath"
 	"strings"
 	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/v4/pkg/testutil"
 	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/audit"
+	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/server/core"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 )
 
 var _ = Suite(&testAdminSuite{})
@@ -158,3 +170,90 @@ func (s *testTSOSuite) TestResetTS(c *C) {
 	c.Assert(err, NotNil)
 	c.Assert(err.Error(), Equals, "\"invalid tso value\"\n")
 }
+
+var _ = Suite(&testAuditSuite{})
+
+type testAuditSuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+	auditFile string
+}
+
+func (s *testAuditSuite) SetUpSuite(c *C) {
+	dir := c.MkDir()
+	tokenFile := filepath.Join(dir, "tokens.json")
+	c.Assert(ioutil.WriteFile(tokenFile, []byte(`[
+		{"token": "admin-token", "user": "alice", "role": "admin"},
+		{"token": "operator-token", "user": "bob", "role": "operator"}
+	]`), 0600), IsNil)
+	s.auditFile = filepath.Join(dir, "audit.log")
+	s.svr, s.cleanup = mustNewServer(c, func(cfg *config.Config) {
+		cfg.Auth.Enable = true
+		cfg.Auth.TokenFile = tokenFile
+		cfg.Audit.Enable = true
+		cfg.Audit.File.Filename = s.auditFile
+	})
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	addr := s.svr.GetAddr()
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1", addr, apiPrefix)
+}
+
+func (s *testAuditSuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testAuditSuite) request(c *C, method, url, token, body string) []byte {
+	req, err := http.NewRequest(method, url, bytes.NewBufferString(body))
+	c.Assert(err, IsNil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := dialClient.Do(req)
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, IsNil)
+	return data
+}
+
+func (s *testAuditSuite) TestAudit(c *C) {
+	grpcPDClient := testutil.MustNewGrpcClient(c, s.svr.GetAddr())
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer operator-token")
+	_, err := grpcPDClient.Bootstrap(ctx, &pdpb.BootstrapRequest{
+		Header: testutil.NewRequestHeader(s.svr.ClusterID()),
+		Store:  store,
+		Region: region,
+	})
+	c.Assert(err, IsNil)
+	s.request(c, http.MethodPost, s.urlPrefix+"/admin/log", "operator-token", `"info"`)
+	s.request(c, http.MethodPost, s.urlPrefix+"/admin/log", "admin-token", `"info"`)
+	// The requests which only read are not recorded.
+	s.request(c, http.MethodGet, s.urlPrefix+"/version", "operator-token", "")
+
+	var records []*audit.Record
+	c.Assert(json.Unmarshal(s.request(c, http.MethodGet, s.urlPrefix+"/admin/audit", "admin-token", ""), &records), IsNil)
+	c.Assert(records, HasLen, 3)
+	c.Assert(records[0].Route, Equals, "/pd/api/v1/admin/log")
+	c.Assert(records[0].Method, Equals, http.MethodPost)
+	c.Assert(records[0].User, Equals, "alice")
+	c.Assert(records[0].Status, Equals, http.StatusOK)
+	digest := sha256.Sum256([]byte(`"info"`))
+	c.Assert(records[0].BodyDigest, Equals, hex.EncodeToString(digest[:]))
+	c.Assert(records[1].User, Equals, "bob")
+	c.Assert(records[1].Status, Equals, http.StatusForbidden)
+	c.Assert(records[2].Protocol, Equals, audit.ProtocolGRPC)
+	c.Assert(records[2].Route, Equals, "/pdpb.PD/Bootstrap")
+	c.Assert(records[2].User, Equals, "bob")
+	c.Assert(records[2].Status, Equals, int(codes.OK))
+	c.Assert(records[2].Address, Not(Equals), "")
+
+	c.Assert(json.Unmarshal(s.request(c, http.MethodGet, s.urlPrefix+"/admin/audit?limit=1", "admin-token", ""), &records), IsNil)
+	c.Assert(records, HasLen, 1)
+	c.Assert(string(s.request(c, http.MethodGet, s.urlPrefix+"/admin/audit?limit=0", "admin-token", "")), Matches, ".*invalid limit.*\n")
+	c.Assert(string(s.request(c, http.MethodGet, s.urlPrefix+"/admin/audit", "operator-token", "")), Matches, "(?s).*permission denied.*")
+
+	// The records are in the audit log file.
+	data, err := ioutil.ReadFile(s.auditFile)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Count(string(data), "\n"), Equals, 3)
+}
@@ -0,0 +1,128 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pingcap/pd/v4/server/auth"
+)
+
+// routeRoles are the roles required by the routes, which are keyed by the
+// method and the path template. Every route which writes is listed, the routes
+// not listed require the read-only role to read and the operator role to
+// write.
+var routeRoles = map[string]auth.Role{
+	// The probes.
+	"GET /pd/ping":          auth.RoleNone,
+	"GET /pd/health":        auth.RoleNone,
+	"GET /pd/api/v1/ping":   auth.RoleNone,
+	"GET /pd/api/v1/health": auth.RoleNone,
+
+	// The requests which only read.
+	"POST /pd/api/v1/metric/query":        auth.RoleReadOnly,
+	"POST /pd/api/v1/metric/query_range":  auth.RoleReadOnly,
+	"POST /pd/api/v1/schedulers/simulate": auth.RoleReadOnly,
+
+	// The requests which schedule the cluster.
+	"POST /pd/api/v1/operators":                 auth.RoleOperator,
+	"DELETE /pd/api/v1/operators/{region_id}":   auth.RoleOperator,
+	"POST /pd/api/v1/schedulers":                auth.RoleOperator,
+	"POST /pd/api/v1/schedulers/{name}":         auth.RoleOperator,
+	"DELETE /pd/api/v1/schedulers/{name}":       auth.RoleOperator,
+	"POST /pd/api/v1/schedulers/{name}/explain": auth.RoleOperator,
+	"POST /pd/api/v1/scheduler-config/{name}":   auth.RoleOperator,
+	"POST /pd/api/v1/regions/scatter":           auth.RoleOperator,
+	"POST /pd/api/v1/regions/merge-jobs":        auth.RoleOperator,
+	"DELETE /pd/api/v1/regions/merge-jobs/{id}": auth.RoleOperator,
+	// The services such as CDC and BR hold their safepoints like the gRPC
+	// UpdateGCSafePoint.
+	"POST /pd/api/v1/gc/safepoint":                auth.RoleOperator,
+	"DELETE /pd/api/v1/gc/safepoint/{service_id}": auth.RoleOperator,
+
+	// The requests which change the config and the members of the cluster.
+	"POST /pd/api/v1/config":                                   auth.RoleAdmin,
+	"POST /pd/api/v1/config/schedule":                          auth.RoleAdmin,
+	"POST /pd/api/v1/config/replicate":                         auth.RoleAdmin,
+	"POST /pd/api/v1/config/label-property":                    auth.RoleAdmin,
+	"POST /pd/api/v1/config/cluster-version":                   auth.RoleAdmin,
+	"POST /pd/api/v1/config/rule":                              auth.RoleAdmin,
+	"DELETE /pd/api/v1/config/rule/{group}/{id}":               auth.RoleAdmin,
+	"POST /pd/api/v1/config/rules/batch":                       auth.RoleAdmin,
+	"POST /pd/api/v1/config/rule_group":                        auth.RoleAdmin,
+	"DELETE /pd/api/v1/config/rule_group/{id}":                 auth.RoleAdmin,
+	"POST /pd/api/v1/config/placement-rule":                    auth.RoleAdmin,
+	"POST /pd/api/v1/config/placement-rule/{group}":            auth.RoleAdmin,
+	"DELETE /pd/api/v1/config/placement-rule/{group}":          auth.RoleAdmin,
+	"POST /pd/api/v1/config/region-label/rule":                 auth.RoleAdmin,
+	"DELETE /pd/api/v1/config/region-label/rule/{id}":          auth.RoleAdmin,
+	"POST /pd/api/v1/config/schedule-profile/profile":          auth.RoleAdmin,
+	"DELETE /pd/api/v1/config/schedule-profile/profile/{name}": auth.RoleAdmin,
+	"POST /pd/api/v1/config/schedule-profile/calendar":         auth.RoleAdmin,
+	"POST /pd/api/v1/component":                                auth.RoleAdmin,
+	"DELETE /pd/api/v1/component/{component_id}":               auth.RoleAdmin,
+	"DELETE /pd/api/v1/store/{id}":                             auth.RoleAdmin,
+	"POST /pd/api/v1/store/{id}/state":                         auth.RoleAdmin,
+	"POST /pd/api/v1/store/{id}/label":                         auth.RoleAdmin,
+	"POST /pd/api/v1/store/{id}/weight":                        auth.RoleAdmin,
+	"POST /pd/api/v1/store/{id}/limit":                         auth.RoleAdmin,
+	"POST /pd/api/v1/stores/limit":                             auth.RoleAdmin,
+	"POST /pd/api/v1/stores/limit/scene":                       auth.RoleAdmin,
+	"DELETE /pd/api/v1/stores/remove-tombstone":                auth.RoleAdmin,
+	"DELETE /pd/api/v1/members/name/{name}":                    auth.RoleAdmin,
+	"DELETE /pd/api/v1/members/id/{id}":                        auth.RoleAdmin,
+	"POST /pd/api/v1/members/name/{name}":                      auth.RoleAdmin,
+	"POST /pd/api/v1/leader/resign":                            auth.RoleAdmin,
+	"POST /pd/api/v1/leader/transfer/{next_leader}":            auth.RoleAdmin,
+	"DELETE /pd/api/v1/admin/cache/region/{id}":                auth.RoleAdmin,
+	"POST /pd/api/v1/admin/reset-ts":                           auth.RoleAdmin,
+	"POST /pd/api/v1/admin/log":                                auth.RoleAdmin,
+	"POST /pd/api/v1/plugin":                                   auth.RoleAdmin,
+	"DELETE /pd/api/v1/plugin":                                 auth.RoleAdmin,
+	"GET /pd/api/v1/admin/audit":                               auth.RoleAdmin,
+}
+
+// newRouteTemplate returns the function which returns the path template of
+// the route matching the request to the router, it is empty if there is no
+// such route.
+func newRouteTemplate(router *mux.Router) func(*http.Request) string {
+	return func(r *http.Request) string {
+		var match mux.RouteMatch
+		if router.Match(r, &match) && match.Route != nil {
+			if tpl, err := match.Route.GetPathTemplate(); err == nil {
+				return tpl
+			}
+		}
+		return ""
+	}
+}
+
+// newRouteRole returns the function which returns the role required by the
+// request to the router.
+func newRouteRole(router *mux.Router) func(*http.Request) auth.Role {
+	routeOf := newRouteTemplate(router)
+	return func(r *http.Request) auth.Role {
+		if role, ok := routeRoles[r.Method+" "+routeOf(r)]; ok {
+			return role
+		}
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return auth.RoleReadOnly
+		default:
+			return auth.RoleOperator
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/v4/pkg/testutil"
+	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/config"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+var _ = Suite(&testAuthSuite{})
+
+type testAuthSuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func (s *testAuthSuite) SetUpSuite(c *C) {
+	tokenFile := filepath.Join(c.MkDir(), "tokens.json")
+	c.Assert(ioutil.WriteFile(tokenFile, []byte(`[
+		{"token": "admin-token", "user": "alice", "role": "admin"},
+		{"token": "operator-token", "user": "bob", "role": "operator"},
+		{"token": "reader-token", "user": "carol", "role": "read-only"}
+	]`), 0600), IsNil)
+	s.svr, s.cleanup = mustNewServer(c, func(cfg *config.Config) {
+		cfg.Auth.Enable = true
+		cfg.Auth.TokenFile = tokenFile
+	})
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	addr := s.svr.GetAddr()
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1", addr, apiPrefix)
+
+	grpcPDClient := testutil.MustNewGrpcClient(c, addr)
+	req := &pdpb.BootstrapRequest{
+		Header: testutil.NewRequestHeader(s.svr.ClusterID()),
+		Store:  store,
+		Region: region,
+	}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer operator-token")
+	resp, err := grpcPDClient.Bootstrap(ctx, req)
+	c.Assert(err, IsNil)
+	c.Assert(resp.GetHeader().GetError().GetType(), Equals, pdpb.ErrorType_OK)
+}
+
+func (s *testAuthSuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testAuthSuite) request(c *C, method, url, token, body string) *http.Response {
+	req, err := http.NewRequest(method, url, bytes.NewBufferString(body))
+	c.Assert(err, IsNil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := dialClient.Do(req)
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	return resp
+}
+
+func (s *testAuthSuite) TestGRPC(c *C) {
+	grpcPDClient := testutil.MustNewGrpcClient(c, s.svr.GetAddr())
+	req := &pdpb.AllocIDRequest{Header: testutil.NewRequestHeader(s.svr.ClusterID())}
+	_, err := grpcPDClient.AllocID(context.Background(), req)
+	c.Assert(grpcstatus.Code(err), Equals, codes.Unauthenticated)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer reader-token")
+	_, err = grpcPDClient.AllocID(ctx, req)
+	c.Assert(grpcstatus.Code(err), Equals, codes.PermissionDenied)
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer operator-token")
+	_, err = grpcPDClient.AllocID(ctx, req)
+	c.Assert(err, IsNil)
+	// The services hold their safepoints with the operator role.
+	_, err = grpcPDClient.UpdateGCSafePoint(ctx, &pdpb.UpdateGCSafePointRequest{
+		Header:    testutil.NewRequestHeader(s.svr.ClusterID()),
+		SafePoint: 1,
+	})
+	c.Assert(err, IsNil)
+
+	// The requests which only read are open to everyone.
+	_, err = grpcPDClient.GetMembers(context.Background(), &pdpb.GetMembersRequest{})
+	c.Assert(err, IsNil)
+	_, err = grpcPDClient.PutClusterConfig(ctx, &pdpb.PutClusterConfigRequest{
+		Header:  testutil.NewRequestHeader(s.svr.ClusterID()),
+		Cluster: &metapb.Cluster{Id: s.svr.ClusterID(), MaxPeerCount: 3},
+	})
+	c.Assert(grpcstatus.Code(err), Equals, codes.PermissionDenied)
+}
+
+func (s *testAuthSuite) TestHTTP(c *C) {
+	testCases := []struct {
+		method, path, token, body string
+		code                      int
+	}{
+		{http.MethodGet, "/ping", "", "", http.StatusOK},
+		{http.MethodGet, "/health", "", "", http.StatusOK},
+		{http.MethodGet, "/version", "", "", http.StatusUnauthorized},
+		{http.MethodGet, "/version", "unknown", "", http.StatusUnauthorized},
+		{http.MethodGet, "/version", "reader-token", "", http.StatusOK},
+		{http.MethodPost, "/admin/log", "reader-token", `"info"`, http.StatusForbidden},
+		{http.MethodPost, "/admin/log", "operator-token", `"info"`, http.StatusForbidden},
+		{http.MethodPost, "/admin/log", "admin-token", `"info"`, http.StatusOK},
+		{http.MethodPost, "/config/schedule-profile/profile", "reader-token", `{"name": "day"}`, http.StatusForbidden},
+		{http.MethodPost, "/config/schedule-profile/profile", "operator-token", `{"name": "day"}`, http.StatusForbidden},
+		{http.MethodPost, "/config/schedule-profile/profile", "admin-token", `{"name": "day"}`, http.StatusOK},
+		{http.MethodDelete, "/config/schedule-profile/profile/day", "operator-token", "", http.StatusForbidden},
+		{http.MethodDelete, "/config/schedule-profile/profile/day", "admin-token", "", http.StatusOK},
+		{http.MethodPost, "/config/rule_group", "operator-token", `{"id": "pd"}`, http.StatusForbidden},
+		{http.MethodPost, "/regions/merge-jobs", "reader-token", `{}`, http.StatusForbidden},
+		{http.MethodPost, "/gc/safepoint", "reader-token", `{"service_id": "cdc", "ttl": 3600, "safe_point": 1}`, http.StatusForbidden},
+		{http.MethodPost, "/gc/safepoint", "operator-token", `{"service_id": "cdc", "ttl": 3600, "safe_point": 1}`, http.StatusOK},
+		{http.MethodDelete, "/gc/safepoint/cdc", "operator-token", "", http.StatusOK},
+	}
+	for _, t := range testCases {
+		resp := s.request(c, t.method, s.urlPrefix+t.path, t.token, t.body)
+		c.Assert(resp.StatusCode, Equals, t.code, Commentf("%s %s %s", t.method, t.path, t.token))
+		if t.code == http.StatusUnauthorized {
+			c.Assert(resp.Header.Get("WWW-Authenticate"), Equals, "Bearer")
+		}
+	}
+	resp := s.request(c, http.MethodGet, s.svr.GetAddr()+"/pd/ping", "", "")
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+}
+
+func (s *testAuthSuite) TestRouteRoles(c *C) {
+	// All the routes listed exist.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r, _ := createRouter(ctx, apiPrefix, s.svr)
+	// The component routes are added lazily if the dynamic config is enabled.
+	lazyComponentRouter(ctx, s.svr, r.PathPrefix("/api/v1").Subrouter())
+	routes := make(map[string]struct{})
+	varRegexp := regexp.MustCompile(`{[^}]*}`)
+	err := r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		tpl, err := route.GetPathTemplate()
+		// Skip the routes of the subrouters, which do not handle requests.
+		if err != nil || route.GetHandler() == nil {
+			return nil
+		}
+		if _, err := route.GetMethods(); err != nil {
+			return nil
+		}
+		// The methods of a route in a subrouter include the ones of the
+		// subrouter, so the methods are checked by matching the requests.
+		path := varRegexp.ReplaceAllString(tpl, "1")
+		for _, m := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+			var match mux.RouteMatch
+			if r.Match(httptest.NewRequest(m, path, nil), &match) && match.Route == route {
+				routes[m+" "+tpl] = struct{}{}
+			}
+		}
+		return nil
+	})
+	c.Assert(err, IsNil)
+	// All the routes which write are listed.
+	for route := range routes {
+		if isSafeRoute(route) {
+			continue
+		}
+		_, ok := routeRoles[route]
+		c.Assert(ok, IsTrue, Commentf("%s", route))
+	}
+	for route := range routeRoles {
+		_, ok := routes[route]
+		c.Assert(ok, IsTrue, Commentf("%s", route))
+		c.Assert(strings.Count(route, " "), Equals, 1)
+	}
+}
+
+func isSafeRoute(route string) bool {
+	for _, m := range []string{http.MethodGet, http.MethodHead, http.MethodOptions} {
+		if strings.HasPrefix(route, m+" ") {
+			return true
+		}
+	}
+	return false
+}
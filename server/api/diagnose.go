@@ -21,6 +21,7 @@ import (
 
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pkg/errors"
 	"github.com/unrolled/render"
 )
@@ -58,6 +59,8 @@ const (
 	tikvCap90
 	tikvLostPeers
 	tikvLostPeersLongTime
+	tikvDiskAlmostFull
+	tikvDiskFull
 )
 
 var (
@@ -72,6 +75,8 @@ var (
 		tikvCap90:                   {modTiKV, levelMajor, "some TiKV storage used more than 90%.", "please add TiKV node."},
 		tikvLostPeers:               {modTiKV, levelWarning, "some TiKV lost connect.", "please check network."},
 		tikvLostPeersLongTime:       {modTiKV, levelMajor, "some TiKV lost connect more than 1h.", "please check network."},
+		tikvDiskAlmostFull:          {modTiKV, levelMajor, "some TiKV disk is almost full, leaders are moved away.", "please add TiKV node or expand the disk."},
+		tikvDiskFull:                {modTiKV, levelCritical, "some TiKV disk is full, leaders and regions are moved away.", "please add TiKV node or expand the disk."},
 	}
 )
 
@@ -156,6 +161,31 @@ func (d *diagnoseHandler) membersDiagnose(rdd *[]*Recommendation) error {
 	return nil
 }
 
+func (d *diagnoseHandler) storesDiagnose(rdd *[]*Recommendation) {
+	rc := d.svr.GetRaftCluster()
+	if rc == nil {
+		return
+	}
+	var almostFull, full string
+	for _, store := range rc.GetStores() {
+		if store.IsTombstone() {
+			continue
+		}
+		switch store.DiskState(rc.GetDiskAlmostFullRatio(), rc.GetDiskFullRatio()) {
+		case core.DiskStateAlmostFull:
+			almostFull = fmt.Sprintf("%s %d,", almostFull, store.GetID())
+		case core.DiskStateFull:
+			full = fmt.Sprintf("%s %d,", full, store.GetID())
+		}
+	}
+	if almostFull != "" {
+		*rdd = append(*rdd, diagnosePD(tikvDiskAlmostFull, "store ID"+almostFull, ""))
+	}
+	if full != "" {
+		*rdd = append(*rdd, diagnosePD(tikvDiskFull, "store ID"+full, ""))
+	}
+}
+
 // @Tags diagnose
 // @Summary Diagnostic information of the cluster.
 // @Produce json
@@ -168,5 +198,6 @@ func (d *diagnoseHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		d.rd.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	d.storesDiagnose(&rdd)
 	d.rd.JSON(w, http.StatusOK, rdd)
 }
@@ -15,8 +15,13 @@ package api
 
 import (
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/statistics"
+	"github.com/pkg/errors"
 	"github.com/unrolled/render"
 )
 
@@ -31,6 +36,9 @@ type HotStoreStats struct {
 	BytesReadStats  map[uint64]float64 `json:"bytes-read-rate,omitempty"`
 	KeysWriteStats  map[uint64]float64 `json:"keys-write-rate,omitempty"`
 	KeysReadStats   map[uint64]float64 `json:"keys-read-rate,omitempty"`
+	CPUUsageStats   map[uint64]float64 `json:"cpu-usage,omitempty"`
+	DiskWriteStats  map[uint64]float64 `json:"disk-write-rate,omitempty"`
+	DiskReadStats   map[uint64]float64 `json:"disk-read-rate,omitempty"`
 }
 
 func newHotStatusHandler(handler *server.Handler, rd *render.Render) *hotStatusHandler {
@@ -58,6 +66,69 @@ func (h *hotStatusHandler) GetHotReadRegions(w http.ResponseWriter, r *http.Requ
 	h.rd.JSON(w, http.StatusOK, h.Handler.GetHotReadRegions())
 }
 
+// @Tags hotspot
+// @Summary List the hot peers in the snapshots of the hot regions, the latest first.
+// @Param region_id query integer false "Only the hot peers of the region"
+// @Param store_id query integer false "Only the hot peers on the store"
+// @Param hot_type query string false "Only the hot peers of the type, read or write"
+// @Param start query integer false "Only the snapshots at or after the unix time"
+// @Param end query integer false "Only the snapshots before the unix time"
+// @Param limit query integer false "The max number of the hot peers"
+// @Produce json
+// @Success 200 {array} statistics.HotRegionHistoryRecord
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /hotspot/regions/history [get]
+func (h *hotStatusHandler) GetHotRegionHistory(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseHotRegionHistoryFilter(r.URL.Query())
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	records, err := h.Handler.GetHotRegionHistory(filter)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, records)
+}
+
+func parseHotRegionHistoryFilter(query url.Values) (*statistics.HotRegionHistoryFilter, error) {
+	filter := &statistics.HotRegionHistoryFilter{}
+	var err error
+	if s := query.Get("region_id"); s != "" {
+		if filter.RegionID, err = strconv.ParseUint(s, 10, 64); err != nil {
+			return nil, errors.Errorf("invalid region_id: %s", s)
+		}
+	}
+	if s := query.Get("store_id"); s != "" {
+		if filter.StoreID, err = strconv.ParseUint(s, 10, 64); err != nil {
+			return nil, errors.Errorf("invalid store_id: %s", s)
+		}
+	}
+	if s := query.Get("hot_type"); s != "" {
+		if s != statistics.ReadFlow.String() && s != statistics.WriteFlow.String() {
+			return nil, errors.Errorf("invalid hot_type: %s", s)
+		}
+		filter.HotType = s
+	}
+	for name, t := range map[string]*time.Time{"start": &filter.Start, "end": &filter.End} {
+		if s := query.Get(name); s != "" {
+			sec, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return nil, errors.Errorf("invalid %s: %s", name, s)
+			}
+			*t = time.Unix(sec, 0)
+		}
+	}
+	if s := query.Get("limit"); s != "" {
+		if filter.Limit, err = strconv.Atoi(s); err != nil || filter.Limit <= 0 {
+			return nil, errors.Errorf("invalid limit: %s", s)
+		}
+	}
+	return filter, nil
+}
+
 // @Tags hotspot
 // @Summary List the hot stores.
 // @Produce json
@@ -68,12 +139,18 @@ func (h *hotStatusHandler) GetHotStores(w http.ResponseWriter, r *http.Request)
 	bytesReadStats := h.GetHotBytesReadStores()
 	keysWriteStats := h.GetHotKeysWriteStores()
 	keysReadStats := h.GetHotKeysReadStores()
+	cpuUsageStats := h.GetHotCPUUsageStores()
+	diskWriteStats := h.GetHotDiskWriteStores()
+	diskReadStats := h.GetHotDiskReadStores()
 
 	stats := HotStoreStats{
 		BytesWriteStats: bytesWriteStats,
 		BytesReadStats:  bytesReadStats,
 		KeysWriteStats:  keysWriteStats,
 		KeysReadStats:   keysReadStats,
+		CPUUsageStats:   cpuUsageStats,
+		DiskWriteStats:  diskWriteStats,
+		DiskReadStats:   diskReadStats,
 	}
 	h.rd.JSON(w, http.StatusOK, stats)
 }
@@ -19,6 +19,7 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/pd/v4/server"
 	_ "github.com/pingcap/pd/v4/server/schedulers"
+	"github.com/pingcap/pd/v4/server/statistics"
 )
 
 var _ = Suite(&testHotStatusSuite{})
@@ -48,3 +49,15 @@ func (s testHotStatusSuite) TestGetHotStore(c *C) {
 	err := readJSON(s.urlPrefix+"/stores", &stat)
 	c.Assert(err, IsNil)
 }
+
+func (s testHotStatusSuite) TestGetHotRegionHistory(c *C) {
+	var records []*statistics.HotRegionHistoryRecord
+	err := readJSON(s.urlPrefix+"/regions/history?hot_type=write&limit=10", &records)
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 0)
+
+	for _, query := range []string{"hot_type=all", "region_id=a", "store_id=-1", "start=now", "limit=0"} {
+		err = readJSON(s.urlPrefix+"/regions/history?"+query, &records)
+		c.Assert(err, ErrorMatches, ".*400.*", Commentf(query))
+	}
+}
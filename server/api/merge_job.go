@@ -0,0 +1,141 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/hex"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/pingcap/pd/v4/pkg/apiutil"
+	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pkg/errors"
+	"github.com/unrolled/render"
+)
+
+type mergeJobHandler struct {
+	svr *server.Server
+	rd  *render.Render
+}
+
+func newMergeJobHandler(svr *server.Server, rd *render.Render) *mergeJobHandler {
+	return &mergeJobHandler{
+		svr: svr,
+		rd:  rd,
+	}
+}
+
+type mergeJobInput struct {
+	StartKey    string `json:"start_key"`
+	EndKey      string `json:"end_key"`
+	Concurrency int    `json:"concurrency"`
+}
+
+// @Tags region
+// @Summary Create a job to merge the empty or small regions in a key range in bulk.
+// @Accept json
+// @Param body body mergeJobInput true "The key range in hex format and the number of merges running at the same time"
+// @Produce json
+// @Success 200 {object} schedule.MergeJobStatus
+// @Failure 400 {string} string "The input is invalid."
+// @Router /regions/merge-jobs [post]
+func (h *mergeJobHandler) Add(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	var input mergeJobInput
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	startKey, err := hex.DecodeString(input.StartKey)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, "start key should be in hex format")
+		return
+	}
+	endKey, err := hex.DecodeString(input.EndKey)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, "end key should be in hex format")
+		return
+	}
+	job, err := cluster.GetMergeJobController().AddJob(startKey, endKey, input.Concurrency)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, job)
+}
+
+// @Tags region
+// @Summary List the merge jobs.
+// @Produce json
+// @Success 200 {array} schedule.MergeJobStatus
+// @Router /regions/merge-jobs [get]
+func (h *mergeJobHandler) List(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	h.rd.JSON(w, http.StatusOK, cluster.GetMergeJobController().GetJobs())
+}
+
+// @Tags region
+// @Summary Get the progress of a merge job.
+// @Param id path integer true "Job Id"
+// @Produce json
+// @Success 200 {object} schedule.MergeJobStatus
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 404 {string} string "The job does not exist."
+// @Router /regions/merge-jobs/{id} [get]
+func (h *mergeJobHandler) Get(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, "invalid job id")
+		return
+	}
+	job, err := cluster.GetMergeJobController().GetJob(id)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, job)
+}
+
+// @Tags region
+// @Summary Cancel a merge job, the running merges are not canceled.
+// @Param id path integer true "Job Id"
+// @Produce json
+// @Success 200 {string} string "The job is canceled."
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 404 {string} string "The job does not exist."
+// @Router /regions/merge-jobs/{id} [delete]
+func (h *mergeJobHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, "invalid job id")
+		return
+	}
+	if err := cluster.GetMergeJobController().CancelJob(id); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, "The job is canceled.")
+}
+
+func (h *mergeJobHandler) respondError(w http.ResponseWriter, err error) {
+	if errors.Cause(err) == schedule.ErrMergeJobNotFound {
+		h.rd.JSON(w, http.StatusNotFound, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+}
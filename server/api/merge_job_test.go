@@ -0,0 +1,95 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/config"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule"
+)
+
+var _ = Suite(&testMergeJobSuite{})
+
+type testMergeJobSuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func (s *testMergeJobSuite) SetUpSuite(c *C) {
+	s.svr, s.cleanup = mustNewServer(c, func(cfg *config.Config) { cfg.Replication.MaxReplicas = 1 })
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	addr := s.svr.GetAddr()
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1/regions/merge-jobs", addr, apiPrefix)
+
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testMergeJobSuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testMergeJobSuite) TestMergeJob(c *C) {
+	mustPutStore(c, s.svr, 1, metapb.StoreState_Up, nil)
+	opts := []core.RegionCreateOption{
+		core.SetApproximateSize(1), core.SetApproximateKeys(1),
+		core.SetWrittenBytes(0), core.SetWrittenKeys(0), core.SetReadBytes(0), core.SetReadKeys(0),
+	}
+	mustRegionHeartbeat(c, s.svr, newTestRegionInfo(10, 1, []byte("a"), []byte("b"), opts...))
+	mustRegionHeartbeat(c, s.svr, newTestRegionInfo(11, 1, []byte("b"), []byte("c"), opts...))
+	mustRegionHeartbeat(c, s.svr, newTestRegionInfo(12, 1, []byte("c"), []byte("d"), opts...))
+
+	input := map[string]interface{}{"start_key": "61", "end_key": "64", "concurrency": 2}
+	data, err := json.Marshal(input)
+	c.Assert(err, IsNil)
+	var job schedule.MergeJobStatus
+	c.Assert(postJSON(s.urlPrefix, data, func(res []byte, _ int) {
+		c.Assert(json.Unmarshal(res, &job), IsNil)
+	}), IsNil)
+	c.Assert(job.State, Equals, schedule.MergeJobRunning)
+	c.Assert(job.Total, Equals, 2)
+	c.Assert(job.Concurrency, Equals, 2)
+
+	// The range overlaps with the running job.
+	c.Assert(postJSON(s.urlPrefix, []byte(`{"start_key": "63"}`)), ErrorMatches, "(?s).*overlaps.*")
+	c.Assert(postJSON(s.urlPrefix, []byte(`{"start_key": "zz"}`)), ErrorMatches, "(?s).*hex format.*")
+	c.Assert(postJSON(s.urlPrefix, []byte(`{"start_key": "64", "concurrency": -1}`)), ErrorMatches, "(?s).*concurrency.*")
+
+	var jobs []*schedule.MergeJobStatus
+	c.Assert(readJSON(s.urlPrefix, &jobs), IsNil)
+	c.Assert(jobs, HasLen, 1)
+	c.Assert(jobs[0].ID, Equals, job.ID)
+
+	url := fmt.Sprintf("%s/%d", s.urlPrefix, job.ID)
+	res, err := doDelete(url)
+	c.Assert(err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	c.Assert(readJSON(url, &job), IsNil)
+	c.Assert(job.State, Equals, schedule.MergeJobCanceled)
+
+	url = fmt.Sprintf("%s/%d", s.urlPrefix, job.ID+1)
+	c.Assert(readJSON(url, &job), ErrorMatches, ".*404.*")
+	res, err = doDelete(url)
+	c.Assert(err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusNotFound)
+}
@@ -15,12 +15,16 @@ package api
 
 import (
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/pingcap/pd/v4/pkg/apiutil"
 	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/schedule"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pkg/errors"
 	"github.com/unrolled/render"
 )
 
@@ -106,6 +110,84 @@ func (h *operatorHandler) List(w http.ResponseWriter, r *http.Request) {
 	h.r.JSON(w, http.StatusOK, results)
 }
 
+// @Tags operator
+// @Summary List the waiting queues of the schedulers with their depth and wait time.
+// @Produce json
+// @Success 200 {array} schedule.WaitingQueueStatus
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /operators/waiting-queues [get]
+func (h *operatorHandler) ListWaitingQueues(w http.ResponseWriter, r *http.Request) {
+	queues, err := h.GetWaitingQueues()
+	if err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, queues)
+}
+
+// @Tags operator
+// @Summary List the finished operators in the operator history, the latest first.
+// @Param region_id query integer false "Only the operators of the region"
+// @Param store_id query integer false "Only the operators involving the store"
+// @Param kind query string false "Only the operators of the kind, e.g. leader"
+// @Param scheduler query string false "Only the operators created by the scheduler"
+// @Param start query integer false "Only the operators ending at or after the unix time"
+// @Param end query integer false "Only the operators ending before the unix time"
+// @Param limit query integer false "The max number of the operators"
+// @Produce json
+// @Success 200 {array} schedule.OperatorHistoryRecord
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /operators/history [get]
+func (h *operatorHandler) ListHistory(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseOperatorHistoryFilter(r.URL.Query())
+	if err != nil {
+		h.r.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	records, err := h.GetOperatorHistory(filter)
+	if err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, records)
+}
+
+func parseOperatorHistoryFilter(query url.Values) (*schedule.OperatorHistoryFilter, error) {
+	filter := &schedule.OperatorHistoryFilter{Scheduler: query.Get("scheduler")}
+	var err error
+	if s := query.Get("region_id"); s != "" {
+		if filter.RegionID, err = strconv.ParseUint(s, 10, 64); err != nil {
+			return nil, errors.Errorf("invalid region_id: %s", s)
+		}
+	}
+	if s := query.Get("store_id"); s != "" {
+		if filter.StoreID, err = strconv.ParseUint(s, 10, 64); err != nil {
+			return nil, errors.Errorf("invalid store_id: %s", s)
+		}
+	}
+	if s := query.Get("kind"); s != "" {
+		if filter.Kind, err = operator.ParseOperatorKind(s); err != nil {
+			return nil, errors.Errorf("invalid kind: %s", s)
+		}
+	}
+	for name, t := range map[string]*time.Time{"start": &filter.Start, "end": &filter.End} {
+		if s := query.Get(name); s != "" {
+			sec, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return nil, errors.Errorf("invalid %s: %s", name, s)
+			}
+			*t = time.Unix(sec, 0)
+		}
+	}
+	if s := query.Get("limit"); s != "" {
+		if filter.Limit, err = strconv.Atoi(s); err != nil || filter.Limit <= 0 {
+			return nil, errors.Errorf("invalid limit: %s", s)
+		}
+	}
+	return filter, nil
+}
+
 // FIXME: details of input json body params
 // @Tags operator
 // @Summary Create an operator.
@@ -275,7 +357,8 @@ func (h *operatorHandler) Post(w http.ResponseWriter, r *http.Request) {
 			h.r.JSON(w, http.StatusBadRequest, "missing region id")
 			return
 		}
-		if err := h.AddScatterRegionOperator(uint64(regionID)); err != nil {
+		group, _ := input["group"].(string)
+		if err := h.AddScatterRegionOperator(uint64(regionID), group); err != nil {
 			h.r.JSON(w, http.StatusInternalServerError, err.Error())
 			return
 		}
@@ -18,15 +18,18 @@ import (
 	"fmt"
 	"io/ioutil"
 	"strings"
+	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/v4/pkg/testutil"
 	"github.com/pingcap/pd/v4/server"
 	"github.com/pingcap/pd/v4/server/cluster"
 	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule"
 )
 
 var _ = Suite(&testOperatorSuite{})
@@ -135,6 +138,45 @@ func (s *testOperatorSuite) TestMergeRegionOperator(c *C) {
 	c.Assert(err, NotNil)
 }
 
+func (s *testOperatorSuite) TestOperatorHistory(c *C) {
+	mustPutStore(c, s.svr, 1, metapb.StoreState_Up, nil)
+	mustPutStore(c, s.svr, 5, metapb.StoreState_Up, nil)
+	mustPutStore(c, s.svr, 6, metapb.StoreState_Up, nil)
+	peer := &metapb.Peer{Id: 41, StoreId: 5}
+	region := &metapb.Region{
+		Id:          40,
+		Peers:       []*metapb.Peer{peer},
+		StartKey:    []byte("x"),
+		EndKey:      []byte("y"),
+		RegionEpoch: &metapb.RegionEpoch{ConfVer: 10, Version: 10},
+	}
+	mustRegionHeartbeat(c, s.svr, core.NewRegionInfo(region, peer))
+
+	err := postJSON(fmt.Sprintf("%s/operators", s.urlPrefix), []byte(`{"name":"add-peer", "region_id": 40, "store_id": 6}`))
+	c.Assert(err, IsNil)
+	_, err = doDelete(fmt.Sprintf("%s/operators/40", s.urlPrefix))
+	c.Assert(err, IsNil)
+
+	var records []*schedule.OperatorHistoryRecord
+	// The history is saved asynchronously.
+	testutil.WaitUntil(c, func(c *C) bool {
+		c.Assert(readJSON(fmt.Sprintf("%s/operators/history?region_id=40", s.urlPrefix), &records), IsNil)
+		return len(records) == 1
+	})
+	c.Assert(records[0].Desc, Equals, "admin-add-peer")
+	c.Assert(records[0].Stores, DeepEquals, []uint64{6})
+	c.Assert(records[0].Status, Equals, "Canceled")
+	c.Assert(readJSON(fmt.Sprintf("%s/operators/history?store_id=6&kind=admin&limit=1", s.urlPrefix), &records), IsNil)
+	c.Assert(records, HasLen, 1)
+	c.Assert(readJSON(fmt.Sprintf("%s/operators/history?region_id=40&start=%d", s.urlPrefix, time.Now().Add(time.Minute).Unix()), &records), IsNil)
+	c.Assert(records, HasLen, 0)
+
+	for _, query := range []string{"region_id=a", "kind=foo", "start=now", "limit=0"} {
+		err = readJSON(fmt.Sprintf("%s/operators/history?%s", s.urlPrefix, query), &records)
+		c.Assert(err, ErrorMatches, ".*return code 400", Commentf(query))
+	}
+}
+
 func mustPutStore(c *C, svr *server.Server, id uint64, state metapb.StoreState, labels []*metapb.StoreLabel) {
 	_, err := svr.PutStore(context.Background(), &pdpb.PutStoreRequest{
 		Header: &pdpb.RequestHeader{ClusterId: svr.ClusterID()},
@@ -168,3 +210,26 @@ func mustReadURL(c *C, url string) string {
 	c.Assert(err, IsNil)
 	return string(data)
 }
+
+func (s *testOperatorSuite) TestWaitingQueues(c *C) {
+	addr := fmt.Sprintf("%s/config", s.urlPrefix)
+	err := postJSON(addr, []byte(`{"scheduler-weights":{"balance-hot-region-scheduler":4},"scheduler-inflight-quotas":{"shuffle-region-scheduler":2}}`))
+	c.Assert(err, IsNil)
+	err = postJSON(addr, []byte(`{"scheduler-weights":{"evict-leader-scheduler":2}}`))
+	c.Assert(err, IsNil)
+	sc := &config.ScheduleConfig{}
+	c.Assert(readJSON(addr+"/schedule", sc), IsNil)
+	c.Assert(sc.SchedulerWeights, DeepEquals, map[string]float64{"balance-hot-region-scheduler": 4, "evict-leader-scheduler": 2})
+	c.Assert(sc.SchedulerInflightQuotas, DeepEquals, map[string]uint64{"shuffle-region-scheduler": 2})
+	c.Assert(s.svr.GetRaftCluster().GetSchedulerWeight("balance-hot-region-scheduler"), Equals, 4.0)
+	c.Assert(s.svr.GetRaftCluster().GetSchedulerWeight("balance-region-scheduler"), Equals, 1.0)
+
+	// The weight must be positive.
+	err = postJSON(addr, []byte(`{"scheduler-weights":{"balance-region-scheduler":0}}`))
+	c.Assert(err, ErrorMatches, "(?s).*should be positive.*")
+	c.Assert(s.svr.GetRaftCluster().GetSchedulerWeight("balance-region-scheduler"), Equals, 1.0)
+
+	var queues []*schedule.WaitingQueueStatus
+	c.Assert(readJSON(fmt.Sprintf("%s/operators/waiting-queues", s.urlPrefix), &queues), IsNil)
+	c.Assert(queues, HasLen, 0)
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/v4/pkg/apiutil"
 	"github.com/pingcap/pd/v4/server"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/unrolled/render"
@@ -550,6 +551,48 @@ func (h *regionsHandler) GetTopNRegions(w http.ResponseWriter, r *http.Request,
 	h.rd.JSON(w, http.StatusOK, regionsInfo)
 }
 
+type scatterRegionsInput struct {
+	RegionIDs []uint64 `json:"region_ids"`
+	Group     string   `json:"group"`
+}
+
+// ScatterRegionsResult is the result of scattering a batch of regions.
+type ScatterRegionsResult struct {
+	// FailedRegions maps the IDs of the regions which fail to scatter to the
+	// reasons.
+	FailedRegions map[uint64]string `json:"failed_regions"`
+}
+
+// @Tags region
+// @Summary Scatter a batch of regions. The peers and the leaders of the regions in the same group are balanced among the stores.
+// @Accept json
+// @Param body body scatterRegionsInput true "The IDs of the regions and the name of the group"
+// @Produce json
+// @Success 200 {object} ScatterRegionsResult
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /regions/scatter [post]
+func (h *regionsHandler) ScatterRegions(w http.ResponseWriter, r *http.Request) {
+	var input scatterRegionsInput
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	if len(input.RegionIDs) == 0 {
+		h.rd.JSON(w, http.StatusBadRequest, "missing region ids")
+		return
+	}
+	failures, err := h.svr.GetHandler().ScatterRegions(input.RegionIDs, input.Group)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	result := &ScatterRegionsResult{FailedRegions: make(map[uint64]string, len(failures))}
+	for id, err := range failures {
+		result.FailedRegions[id] = err.Error()
+	}
+	h.rd.JSON(w, http.StatusOK, result)
+}
+
 // RegionHeap implements heap.Interface, used for selecting top n regions.
 type RegionHeap struct {
 	regions []*core.RegionInfo
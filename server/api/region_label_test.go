@@ -0,0 +1,103 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
+)
+
+var _ = Suite(&testRegionLabelSuite{})
+
+type testRegionLabelSuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func (s *testRegionLabelSuite) SetUpSuite(c *C) {
+	s.svr, s.cleanup = mustNewServer(c)
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	addr := s.svr.GetAddr()
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1/config/region-label", addr, apiPrefix)
+
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testRegionLabelSuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testRegionLabelSuite) TestLabelRules(c *C) {
+	mustPutStore(c, s.svr, 1, metapb.StoreState_Up, nil)
+	mustRegionHeartbeat(c, s.svr, newTestRegionInfo(10, 1, []byte("a"), []byte("c")))
+
+	rules := []*labeler.LabelRule{
+		{ID: "rule1", Labels: []*labeler.RegionLabel{{Key: labeler.MergeKey, Value: labeler.DenyValue}}, StartKeyHex: hex.EncodeToString([]byte("a")), EndKeyHex: hex.EncodeToString([]byte("b"))},
+		{ID: "rule2", Labels: []*labeler.RegionLabel{{Key: "tag", Value: "foo"}}, StartKeyHex: hex.EncodeToString([]byte("b")), EndKeyHex: hex.EncodeToString([]byte("d"))},
+	}
+	for _, rule := range rules {
+		data, err := json.Marshal(rule)
+		c.Assert(err, IsNil)
+		c.Assert(postJSON(s.urlPrefix+"/rule", data), IsNil)
+	}
+
+	var resp []*labeler.LabelRule
+	c.Assert(readJSON(s.urlPrefix+"/rules", &resp), IsNil)
+	c.Assert(resp, HasLen, 2)
+	c.Assert(resp[0].ID, Equals, "rule1")
+	c.Assert(resp[1].ID, Equals, "rule2")
+
+	var rule labeler.LabelRule
+	c.Assert(readJSON(s.urlPrefix+"/rule/rule2", &rule), IsNil)
+	c.Assert(rule.Labels, DeepEquals, rules[1].Labels)
+	c.Assert(readJSON(s.urlPrefix+"/rule/rule3", &rule), ErrorMatches, ".*404.*")
+
+	// The region [a, c) overlaps with both rules.
+	var labels []*labeler.RegionLabel
+	c.Assert(readJSON(s.urlPrefix+"/region/10", &labels), IsNil)
+	c.Assert(labels, DeepEquals, []*labeler.RegionLabel{{Key: labeler.MergeKey, Value: labeler.DenyValue}, {Key: "tag", Value: "foo"}})
+	c.Assert(readJSON(s.urlPrefix+"/region/11", &labels), ErrorMatches, ".*404.*")
+	c.Assert(readJSON(s.urlPrefix+"/key/"+hex.EncodeToString([]byte("c")), &labels), IsNil)
+	c.Assert(labels, DeepEquals, []*labeler.RegionLabel{{Key: "tag", Value: "foo"}})
+	c.Assert(readJSON(s.urlPrefix+"/key/zz", &labels), ErrorMatches, ".*400.*")
+
+	// Invalid rules.
+	for _, data := range []string{
+		`{"id": "", "labels": [{"key": "tag", "value": "foo"}]}`,
+		`{"id": "rule3", "labels": []}`,
+		`{"id": "rule3", "labels": [{"key": "schedule", "value": "foo"}]}`,
+		`{"id": "rule3", "labels": [{"key": "leader-store-label", "value": "foo"}]}`,
+		`{"id": "rule3", "labels": [{"key": "tag", "value": "foo"}], "start_key": "zz"}`,
+		`{"id": "rule3", "labels": [{"key": "tag", "value": "foo"}], "start_key": "62", "end_key": "61"}`,
+		`{"id": "rule3", "labels": [{"key": "tag", "value": "foo"}], "ttl": "-1h"}`,
+	} {
+		c.Assert(postJSON(s.urlPrefix+"/rule", []byte(data)), ErrorMatches, "(?s).*invalid label rule.*")
+	}
+
+	res, err := doDelete(s.urlPrefix + "/rule/rule1")
+	c.Assert(err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	c.Assert(readJSON(s.urlPrefix+"/region/10", &labels), IsNil)
+	c.Assert(labels, DeepEquals, []*labeler.RegionLabel{{Key: "tag", Value: "foo"}})
+}
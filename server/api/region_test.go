@@ -15,6 +15,7 @@ package api
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"math/rand"
 	"net/url"
@@ -243,6 +244,22 @@ func (s *testRegionSuite) TestTopFlow(c *C) {
 	s.checkTopRegions(c, fmt.Sprintf("%s/regions/version?limit=2", s.urlPrefix), []uint64{2, 3})
 }
 
+func (s *testRegionSuite) TestScatterRegions(c *C) {
+	data, err := json.Marshal(map[string]interface{}{"region_ids": []uint64{30, 31}, "group": "test"})
+	c.Assert(err, IsNil)
+	var result ScatterRegionsResult
+	err = postJSON(s.urlPrefix+"/regions/scatter", data, func(res []byte, _ int) {
+		c.Assert(json.Unmarshal(res, &result), IsNil)
+	})
+	c.Assert(err, IsNil)
+	c.Assert(result.FailedRegions, HasLen, 2)
+	c.Assert(result.FailedRegions[30], Matches, ".*not found.*")
+	c.Assert(result.FailedRegions[31], Matches, ".*not found.*")
+
+	err = postJSON(s.urlPrefix+"/regions/scatter", []byte(`{"group": "test"}`))
+	c.Assert(err, ErrorMatches, "(?s).*missing region ids.*")
+}
+
 func (s *testRegionSuite) TestTopSize(c *C) {
 	baseOpt := []core.RegionCreateOption{core.SetRegionConfVer(3), core.SetRegionVersion(3)}
 	opt := core.SetApproximateSize(1000)
@@ -0,0 +1,44 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/pingcap/pd/v4/server"
+	"github.com/unrolled/render"
+)
+
+type replicateModeHandler struct {
+	svr *server.Server
+	rd  *render.Render
+}
+
+func newReplicateModeHandler(svr *server.Server, rd *render.Render) *replicateModeHandler {
+	return &replicateModeHandler{
+		svr: svr,
+		rd:  rd,
+	}
+}
+
+// @Tags replicate_mode
+// @Summary Get status of replicate mode
+// @Produce json
+// @Success 200 {object} replicate.HTTPReplicateStatus
+// @Router /replicate_mode/status [get]
+func (h *replicateModeHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	rc := getCluster(r.Context())
+	h.rd.JSON(w, http.StatusOK, rc.GetReplicateMode().GetReplicateStatusHTTP())
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/config"
+	"github.com/pingcap/pd/v4/server/replicate"
+)
+
+var _ = Suite(&testReplicateModeSuite{})
+
+type testReplicateModeSuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func (s *testReplicateModeSuite) SetUpSuite(c *C) {
+	s.svr, s.cleanup = mustNewServer(c, func(cfg *config.Config) {
+		cfg.ReplicateMode.ReplicateMode = "dr_autosync"
+		cfg.ReplicateMode.DRAutoSync.LabelKey = "zone"
+	})
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	addr := s.svr.GetAddr()
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1", addr, apiPrefix)
+
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testReplicateModeSuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testReplicateModeSuite) TestStatus(c *C) {
+	var status replicate.HTTPReplicateStatus
+	err := readJSON(s.urlPrefix+"/replicate_mode/status", &status)
+	c.Assert(err, IsNil)
+	c.Assert(status.Mode, Equals, "dr_autosync")
+	c.Assert(status.DrAutosync, NotNil)
+	c.Assert(status.DrAutosync.LabelKey, Equals, "zone")
+	c.Assert(status.DrAutosync.State, Equals, "sync")
+}
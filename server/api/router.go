@@ -69,14 +69,21 @@ func createRouter(ctx context.Context, prefix string, svr *server.Server) (*mux.
 	operatorHandler := newOperatorHandler(handler, rd)
 	apiRouter.HandleFunc("/operators", operatorHandler.List).Methods("GET")
 	apiRouter.HandleFunc("/operators", operatorHandler.Post).Methods("POST")
+	apiRouter.HandleFunc("/operators/waiting-queues", operatorHandler.ListWaitingQueues).Methods("GET")
+	apiRouter.HandleFunc("/operators/history", operatorHandler.ListHistory).Methods("GET")
 	apiRouter.HandleFunc("/operators/{region_id}", operatorHandler.Get).Methods("GET")
 	apiRouter.HandleFunc("/operators/{region_id}", operatorHandler.Delete).Methods("DELETE")
 
 	schedulerHandler := newSchedulerHandler(handler, rd)
 	apiRouter.HandleFunc("/schedulers", schedulerHandler.List).Methods("GET")
 	apiRouter.HandleFunc("/schedulers", schedulerHandler.Post).Methods("POST")
+	apiRouter.HandleFunc("/schedulers/simulate", schedulerHandler.Simulate).Methods("POST")
+	apiRouter.HandleFunc("/schedulers/{name}/explain", schedulerHandler.GetExplain).Methods("GET")
+	apiRouter.HandleFunc("/schedulers/{name}/explain", schedulerHandler.SetExplain).Methods("POST")
 	apiRouter.HandleFunc("/schedulers/{name}", schedulerHandler.Delete).Methods("DELETE")
 	apiRouter.HandleFunc("/schedulers/{name}", schedulerHandler.PauseOrResume).Methods("POST")
+	apiRouter.HandleFunc("/scheduler-config/{name}", schedulerHandler.GetConfig).Methods("GET")
+	apiRouter.HandleFunc("/scheduler-config/{name}", schedulerHandler.UpdateConfig).Methods("POST")
 	schedulerConfigHandler := newSchedulerConfigHandler(svr, rd)
 	rootRouter.PathPrefix(server.SchedulerConfigHandlerPath).Handler(schedulerConfigHandler)
 
@@ -102,9 +109,38 @@ func createRouter(ctx context.Context, prefix string, svr *server.Server) (*mux.
 	clusterRouter.HandleFunc("/config/rules/group/{group}", rulesHandler.GetAllByGroup).Methods("GET")
 	clusterRouter.HandleFunc("/config/rules/region/{region}", rulesHandler.GetAllByRegion).Methods("GET")
 	clusterRouter.HandleFunc("/config/rules/key/{key}", rulesHandler.GetAllByKey).Methods("GET")
+	clusterRouter.HandleFunc("/config/rules/status", rulesHandler.GetStatus).Methods("GET")
+	clusterRouter.HandleFunc("/config/rules/status/{group}/{id}/regions", rulesHandler.GetViolatedRegions).Methods("GET")
 	clusterRouter.HandleFunc("/config/rule/{group}/{id}", rulesHandler.Get).Methods("GET")
 	clusterRouter.HandleFunc("/config/rule", rulesHandler.Set).Methods("POST")
 	clusterRouter.HandleFunc("/config/rule/{group}/{id}", rulesHandler.Delete).Methods("DELETE")
+	clusterRouter.HandleFunc("/config/rules/batch", rulesHandler.Batch).Methods("POST")
+	clusterRouter.HandleFunc("/config/rule_groups", rulesHandler.GetGroups).Methods("GET")
+	clusterRouter.HandleFunc("/config/rule_group/{id}", rulesHandler.GetGroup).Methods("GET")
+	clusterRouter.HandleFunc("/config/rule_group", rulesHandler.SetGroup).Methods("POST")
+	clusterRouter.HandleFunc("/config/rule_group/{id}", rulesHandler.DeleteGroup).Methods("DELETE")
+	clusterRouter.HandleFunc("/config/placement-rule", rulesHandler.GetAllGroupBundles).Methods("GET")
+	clusterRouter.HandleFunc("/config/placement-rule", rulesHandler.SetAllGroupBundles).Methods("POST")
+	clusterRouter.HandleFunc("/config/placement-rule/{group}", rulesHandler.GetGroupBundle).Methods("GET")
+	clusterRouter.HandleFunc("/config/placement-rule/{group}", rulesHandler.SetGroupBundle).Methods("POST")
+	clusterRouter.HandleFunc("/config/placement-rule/{group}", rulesHandler.DeleteGroupBundle).Methods("DELETE")
+
+	regionLabelHandler := newRegionLabelHandler(svr, rd)
+	clusterRouter.HandleFunc("/config/region-label/rules", regionLabelHandler.GetAllRules).Methods("GET")
+	clusterRouter.HandleFunc("/config/region-label/rule/{id}", regionLabelHandler.GetRule).Methods("GET")
+	clusterRouter.HandleFunc("/config/region-label/rule", regionLabelHandler.SetRule).Methods("POST")
+	clusterRouter.HandleFunc("/config/region-label/rule/{id}", regionLabelHandler.DeleteRule).Methods("DELETE")
+	clusterRouter.HandleFunc("/config/region-label/region/{region}", regionLabelHandler.GetRegionLabels).Methods("GET")
+	clusterRouter.HandleFunc("/config/region-label/key/{key}", regionLabelHandler.GetKeyLabels).Methods("GET")
+
+	scheduleProfileHandler := newScheduleProfileHandler(svr, rd)
+	clusterRouter.HandleFunc("/config/schedule-profile/profiles", scheduleProfileHandler.GetAllProfiles).Methods("GET")
+	clusterRouter.HandleFunc("/config/schedule-profile/profile/{name}", scheduleProfileHandler.GetProfile).Methods("GET")
+	clusterRouter.HandleFunc("/config/schedule-profile/profile", scheduleProfileHandler.SetProfile).Methods("POST")
+	clusterRouter.HandleFunc("/config/schedule-profile/profile/{name}", scheduleProfileHandler.DeleteProfile).Methods("DELETE")
+	clusterRouter.HandleFunc("/config/schedule-profile/calendar", scheduleProfileHandler.GetCalendar).Methods("GET")
+	clusterRouter.HandleFunc("/config/schedule-profile/calendar", scheduleProfileHandler.SetCalendar).Methods("POST")
+	clusterRouter.HandleFunc("/config/schedule-profile/status", scheduleProfileHandler.GetStatus).Methods("GET")
 
 	storeHandler := newStoreHandler(handler, rd)
 	clusterRouter.HandleFunc("/store/{id}", storeHandler.Get).Methods("GET")
@@ -128,6 +164,7 @@ func createRouter(ctx context.Context, prefix string, svr *server.Server) (*mux.
 	hotStatusHandler := newHotStatusHandler(handler, rd)
 	apiRouter.HandleFunc("/hotspot/regions/write", hotStatusHandler.GetHotWriteRegions).Methods("GET")
 	apiRouter.HandleFunc("/hotspot/regions/read", hotStatusHandler.GetHotReadRegions).Methods("GET")
+	apiRouter.HandleFunc("/hotspot/regions/history", hotStatusHandler.GetHotRegionHistory).Methods("GET")
 	apiRouter.HandleFunc("/hotspot/stores", hotStatusHandler.GetHotStores).Methods("GET")
 
 	regionHandler := newRegionHandler(svr, rd)
@@ -156,6 +193,13 @@ func createRouter(ctx context.Context, prefix string, svr *server.Server) (*mux.
 	clusterRouter.HandleFunc("/regions/check/hist-size", regionsHandler.GetSizeHistogram).Methods("GET")
 	clusterRouter.HandleFunc("/regions/check/hist-keys", regionsHandler.GetKeysHistogram).Methods("GET")
 	clusterRouter.HandleFunc("/regions/sibling/{id}", regionsHandler.GetRegionSiblings).Methods("GET")
+	clusterRouter.HandleFunc("/regions/scatter", regionsHandler.ScatterRegions).Methods("POST")
+
+	mergeJobHandler := newMergeJobHandler(svr, rd)
+	clusterRouter.HandleFunc("/regions/merge-jobs", mergeJobHandler.List).Methods("GET")
+	clusterRouter.HandleFunc("/regions/merge-jobs", mergeJobHandler.Add).Methods("POST")
+	clusterRouter.HandleFunc("/regions/merge-jobs/{id}", mergeJobHandler.Get).Methods("GET")
+	clusterRouter.HandleFunc("/regions/merge-jobs/{id}", mergeJobHandler.Cancel).Methods("DELETE")
 
 	apiRouter.Handle("/version", newVersionHandler(rd)).Methods("GET")
 	apiRouter.Handle("/status", newStatusHandler(svr, rd)).Methods("GET")
@@ -174,12 +218,24 @@ func createRouter(ctx context.Context, prefix string, svr *server.Server) (*mux.
 	statsHandler := newStatsHandler(svr, rd)
 	clusterRouter.HandleFunc("/stats/region", statsHandler.Region).Methods("GET")
 
+	serviceGCSafepointHandler := newServiceGCSafepointHandler(svr, rd)
+	clusterRouter.HandleFunc("/gc/safepoint", serviceGCSafepointHandler.List).Methods("GET")
+	clusterRouter.HandleFunc("/gc/safepoint", serviceGCSafepointHandler.Update).Methods("POST")
+	clusterRouter.HandleFunc("/gc/safepoint/{service_id}", serviceGCSafepointHandler.Delete).Methods("DELETE")
+
+	replicateModeHandler := newReplicateModeHandler(svr, rd)
+	clusterRouter.HandleFunc("/replicate_mode/status", replicateModeHandler.GetStatus).Methods("GET")
+
+	watchHandler := newWatchHandler(svr, rd)
+	apiRouter.HandleFunc("/watch/{keyspace}", watchHandler.Watch).Methods("GET")
+
 	trendHandler := newTrendHandler(svr, rd)
 	apiRouter.HandleFunc("/trend", trendHandler.Handle).Methods("GET")
 
 	adminHandler := newAdminHandler(svr, rd)
 	clusterRouter.HandleFunc("/admin/cache/region/{id}", adminHandler.HandleDropCacheRegion).Methods("DELETE")
 	clusterRouter.HandleFunc("/admin/reset-ts", adminHandler.ResetTS).Methods("POST")
+	apiRouter.HandleFunc("/admin/audit", adminHandler.GetAuditRecords).Methods("GET")
 
 	logHandler := newlogHandler(svr, rd)
 	apiRouter.HandleFunc("/admin/log", logHandler.Handle).Methods("POST")
@@ -17,6 +17,7 @@ import (
 	"bytes"
 	"encoding/hex"
 	"net/http"
+	"sort"
 	"strconv"
 
 	"github.com/gorilla/mux"
@@ -238,3 +239,356 @@ func (h *ruleHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 	h.rd.JSON(w, http.StatusOK, nil)
 }
+
+// @Tags rule
+// @Summary Get placement status of all rules aggregated over all regions.
+// @Produce json
+// @Success 200 {array} placement.RuleStatus
+// @Failure 412 {string} string "Placement rules feature is disabled."
+// @Router /config/rules/status [get]
+func (h *ruleHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	if !cluster.IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	collector := placement.NewRuleStatusCollector(cluster, cluster.GetRuleManager().GetAllRules())
+	for _, region := range cluster.GetRegions() {
+		collector.Collect(cluster.FitRegion(region))
+	}
+	h.rd.JSON(w, http.StatusOK, collector.GetStatuses())
+}
+
+// @Tags rule
+// @Summary List regions that violate a rule.
+// @Param group path string true "The name of group"
+// @Param id path string true "Rule Id"
+// @Param violation query string false "The kind of violation, one of under-replicated, mismatched-role and orphan-peer. All kinds by default"
+// @Param offset query integer false "Offset of the regions sorted by region id" default(0)
+// @Param limit query integer false "Limit count" default(16)
+// @Produce json
+// @Success 200 {object} RegionsInfo
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 404 {string} string "The rule does not exist."
+// @Failure 412 {string} string "Placement rules feature is disabled."
+// @Router /config/rules/status/{group}/{id}/regions [get]
+func (h *ruleHandler) GetViolatedRegions(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	if !cluster.IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	group, id := mux.Vars(r)["group"], mux.Vars(r)["id"]
+	rule := cluster.GetRuleManager().GetRule(group, id)
+	if rule == nil {
+		h.rd.JSON(w, http.StatusNotFound, "rule not found")
+		return
+	}
+	query := r.URL.Query()
+	violation := placement.RuleViolation(query.Get("violation"))
+	if violation != "" && !placement.ValidateRuleViolation(violation) {
+		h.rd.JSON(w, http.StatusBadRequest, "invalid violation")
+		return
+	}
+	offset, limit := 0, defaultRegionLimit
+	var err error
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		if offset, err = strconv.Atoi(offsetStr); err != nil || offset < 0 {
+			h.rd.JSON(w, http.StatusBadRequest, "invalid offset")
+			return
+		}
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if limit, err = strconv.Atoi(limitStr); err != nil || limit <= 0 {
+			h.rd.JSON(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+	}
+	if limit > maxRegionLimit {
+		limit = maxRegionLimit
+	}
+
+	var regions []*core.RegionInfo
+	for _, region := range cluster.GetRegions() {
+		fit := cluster.FitRegion(region)
+		for _, rf := range fit.RuleFits {
+			if rf.Rule.Key() != rule.Key() {
+				continue
+			}
+			for _, v := range fit.Violations(rf) {
+				if violation == "" || v == violation {
+					regions = append(regions, region)
+					break
+				}
+			}
+		}
+	}
+	sort.Slice(regions, func(i, j int) bool { return regions[i].GetID() < regions[j].GetID() })
+
+	total := len(regions)
+	if offset > total {
+		offset = total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	regionsInfo := convertToAPIRegions(regions[offset:end])
+	regionsInfo.Count = total
+	h.rd.JSON(w, http.StatusOK, regionsInfo)
+}
+
+// @Tags rule
+// @Summary Batch operations for the cluster. Operations should be independent(different ID). If there are multiple operations with the same ID, the result is uncertain.
+// @Accept json
+// @Param operations body []placement.RuleOp true "Parameters of rule operations"
+// @Produce json
+// @Success 200 {string} string "Batch operations success."
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 412 {string} string "Placement rules feature is disabled."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /config/rules/batch [post]
+func (h *ruleHandler) Batch(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	if !cluster.IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	var opts []placement.RuleOp
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &opts); err != nil {
+		return
+	}
+	for _, opt := range opts {
+		if opt.Rule == nil {
+			h.rd.JSON(w, http.StatusBadRequest, "rule should not be empty")
+			return
+		}
+		if opt.Action != placement.RuleOpAdd {
+			continue
+		}
+		if err := h.checkRule(opt.Rule); err != nil {
+			h.rd.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if err := cluster.GetRuleManager().Batch(opts); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, nil)
+}
+
+// @Tags rule
+// @Summary List all rule groups of cluster.
+// @Produce json
+// @Success 200 {array} placement.RuleGroup
+// @Failure 412 {string} string "Placement rules feature is disabled."
+// @Router /config/rule_groups [get]
+func (h *ruleHandler) GetGroups(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	if !cluster.IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, cluster.GetRuleManager().GetRuleGroups())
+}
+
+// @Tags rule
+// @Summary Get rule group config by group id.
+// @Param id path string true "Group Id"
+// @Produce json
+// @Success 200 {object} placement.RuleGroup
+// @Failure 404 {string} string "The RuleGroup does not exist."
+// @Failure 412 {string} string "Placement rules feature is disabled."
+// @Router /config/rule_group/{id} [get]
+func (h *ruleHandler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	if !cluster.IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	id := mux.Vars(r)["id"]
+	group := cluster.GetRuleManager().GetRuleGroup(id)
+	if group == nil {
+		h.rd.JSON(w, http.StatusNotFound, nil)
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, group)
+}
+
+// @Tags rule
+// @Summary Update rule group config.
+// @Accept json
+// @Param rule body placement.RuleGroup true "Parameters of rule group"
+// @Produce json
+// @Success 200 {string} string "Update rule group config success."
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 412 {string} string "Placement rules feature is disabled."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /config/rule_group [post]
+func (h *ruleHandler) SetGroup(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	if !cluster.IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	var group placement.RuleGroup
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &group); err != nil {
+		return
+	}
+	if group.ID == "" {
+		h.rd.JSON(w, http.StatusBadRequest, "group ID should not be empty")
+		return
+	}
+	if group.Index < 0 {
+		h.rd.JSON(w, http.StatusBadRequest, "group index should not be negative")
+		return
+	}
+	if err := cluster.GetRuleManager().SetRuleGroup(&group); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, nil)
+}
+
+// @Tags rule
+// @Summary Delete rule group config. Rules of the group are kept and use default group config.
+// @Param id path string true "Group Id"
+// @Produce json
+// @Success 200 {string} string "Delete rule group config success."
+// @Failure 400 {string} string "The rules without the group config are invalid."
+// @Failure 412 {string} string "Placement rules feature is disabled."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /config/rule_group/{id} [delete]
+func (h *ruleHandler) DeleteGroup(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	if !cluster.IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if err := cluster.GetRuleManager().DeleteRuleGroup(id); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, nil)
+}
+
+// @Tags rule
+// @Summary List all rule groups and their rules.
+// @Produce json
+// @Success 200 {array} placement.GroupBundle
+// @Failure 412 {string} string "Placement rules feature is disabled."
+// @Router /config/placement-rule [get]
+func (h *ruleHandler) GetAllGroupBundles(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	if !cluster.IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, cluster.GetRuleManager().GetAllGroupBundles())
+}
+
+// @Tags rule
+// @Summary Replace all rule groups and rules with the bundles.
+// @Accept json
+// @Param rules body []placement.GroupBundle true "Parameters of rule groups and rules"
+// @Produce json
+// @Success 200 {string} string "Update rules success."
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 412 {string} string "Placement rules feature is disabled."
+// @Router /config/placement-rule [post]
+func (h *ruleHandler) SetAllGroupBundles(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	if !cluster.IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	var bundles []placement.GroupBundle
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &bundles); err != nil {
+		return
+	}
+	h.setGroupBundles(w, cluster.GetRuleManager(), bundles, true)
+}
+
+// @Tags rule
+// @Summary Get rule group config and its rules by group id.
+// @Param group path string true "The name of group"
+// @Produce json
+// @Success 200 {object} placement.GroupBundle
+// @Failure 412 {string} string "Placement rules feature is disabled."
+// @Router /config/placement-rule/{group} [get]
+func (h *ruleHandler) GetGroupBundle(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	if !cluster.IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	group := mux.Vars(r)["group"]
+	h.rd.JSON(w, http.StatusOK, cluster.GetRuleManager().GetGroupBundle(group))
+}
+
+// @Tags rule
+// @Summary Replace a rule group config and all rules of the group.
+// @Param group path string true "The name of group"
+// @Accept json
+// @Param group body placement.GroupBundle true "Parameters of rule group and rules"
+// @Produce json
+// @Success 200 {string} string "Update group and rules success."
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 412 {string} string "Placement rules feature is disabled."
+// @Router /config/placement-rule/{group} [post]
+func (h *ruleHandler) SetGroupBundle(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	if !cluster.IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	group := mux.Vars(r)["group"]
+	var bundle placement.GroupBundle
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &bundle); err != nil {
+		return
+	}
+	if bundle.ID == "" {
+		bundle.ID = group
+	}
+	if bundle.ID != group {
+		h.rd.JSON(w, http.StatusBadRequest, "group id mismatch")
+		return
+	}
+	h.setGroupBundles(w, cluster.GetRuleManager(), []placement.GroupBundle{bundle}, false)
+}
+
+// @Tags rule
+// @Summary Delete a rule group config and all rules of the group.
+// @Param group path string true "The name of group"
+// @Produce json
+// @Success 200 {string} string "Delete group and rules success."
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 412 {string} string "Placement rules feature is disabled."
+// @Router /config/placement-rule/{group} [delete]
+func (h *ruleHandler) DeleteGroupBundle(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	if !cluster.IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	group := mux.Vars(r)["group"]
+	h.setGroupBundles(w, cluster.GetRuleManager(), []placement.GroupBundle{{ID: group}}, false)
+}
+
+func (h *ruleHandler) setGroupBundles(w http.ResponseWriter, manager *placement.RuleManager, bundles []placement.GroupBundle, override bool) {
+	for _, b := range bundles {
+		for _, rule := range b.Rules {
+			if err := h.checkRule(rule); err != nil {
+				h.rd.JSON(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+	}
+	if err := manager.SetGroupBundles(bundles, override); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, nil)
+}
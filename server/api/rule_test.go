@@ -0,0 +1,182 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/config"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/placement"
+)
+
+var _ = Suite(&testRuleSuite{})
+
+type testRuleSuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func (s *testRuleSuite) SetUpSuite(c *C) {
+	s.svr, s.cleanup = mustNewServer(c, func(cfg *config.Config) { cfg.Replication.EnablePlacementRules = true })
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	addr := s.svr.GetAddr()
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1", addr, apiPrefix)
+
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testRuleSuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testRuleSuite) TestRuleStatus(c *C) {
+	for _, id := range []uint64{1, 2, 3} {
+		mustPutStore(c, s.svr, id, metapb.StoreState_Up, nil)
+	}
+	r1 := newTestRegionInfo(10, 1, []byte(""), []byte("b"))
+	r2 := newTestRegionInfo(20, 1, []byte("b"), []byte("c"),
+		core.WithAddPeer(&metapb.Peer{Id: 21, StoreId: 2}), core.WithAddPeer(&metapb.Peer{Id: 22, StoreId: 3}))
+	r3 := newTestRegionInfo(30, 2, []byte("c"), []byte(""))
+	mustRegionHeartbeat(c, s.svr, r1)
+	mustRegionHeartbeat(c, s.svr, r2)
+	mustRegionHeartbeat(c, s.svr, r3)
+
+	var statuses []*placement.RuleStatus
+	c.Assert(readJSON(s.urlPrefix+"/config/rules/status", &statuses), IsNil)
+	c.Assert(statuses, HasLen, 1)
+	status := statuses[0]
+	c.Assert(status.GroupID, Equals, "pd")
+	c.Assert(status.ID, Equals, "default")
+	c.Assert(status.RegionCount, Equals, 3)
+	c.Assert(status.SatisfiedCount, Equals, 1)
+	c.Assert(status.UnderReplicatedCount, Equals, 2)
+
+	url := s.urlPrefix + "/config/rules/status/pd/default/regions"
+	regions := &RegionsInfo{}
+	c.Assert(readJSON(url+"?violation=under-replicated", regions), IsNil)
+	c.Assert(regions.Count, Equals, 2)
+	c.Assert(regions.Regions, HasLen, 2)
+	c.Assert(regions.Regions[0].ID, Equals, uint64(10))
+	c.Assert(regions.Regions[1].ID, Equals, uint64(30))
+
+	regions = &RegionsInfo{}
+	c.Assert(readJSON(url+"?offset=1&limit=1", regions), IsNil)
+	c.Assert(regions.Count, Equals, 2)
+	c.Assert(regions.Regions, HasLen, 1)
+	c.Assert(regions.Regions[0].ID, Equals, uint64(30))
+
+	regions = &RegionsInfo{}
+	c.Assert(readJSON(url+"?violation=orphan-peer", regions), IsNil)
+	c.Assert(regions.Count, Equals, 0)
+
+	c.Assert(readJSON(url+"?violation=unknown", regions), NotNil)
+	c.Assert(readJSON(s.urlPrefix+"/config/rules/status/pd/notexist/regions", regions), NotNil)
+}
+
+func (s *testRuleSuite) TestBatch(c *C) {
+	opts := []placement.RuleOp{
+		{Rule: &placement.Rule{GroupID: "a", ID: "1", Role: "learner", Count: 1}, Action: placement.RuleOpAdd},
+		{Rule: &placement.Rule{GroupID: "a", ID: "2", Role: "learner", Count: 1}, Action: placement.RuleOpAdd},
+	}
+	data, err := json.Marshal(opts)
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix+"/config/rules/batch", data), IsNil)
+	var rules []*placement.Rule
+	c.Assert(readJSON(s.urlPrefix+"/config/rules/group/a", &rules), IsNil)
+	c.Assert(rules, HasLen, 2)
+
+	// deleting the only voter rule is rejected.
+	opts = []placement.RuleOp{
+		{Rule: &placement.Rule{GroupID: "a", ID: ""}, Action: placement.RuleOpDel, DeleteByIDPrefix: true},
+		{Rule: &placement.Rule{GroupID: "pd", ID: "default"}, Action: placement.RuleOpDel},
+	}
+	data, err = json.Marshal(opts)
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix+"/config/rules/batch", data), NotNil)
+	c.Assert(readJSON(s.urlPrefix+"/config/rules", &rules), IsNil)
+	c.Assert(rules, HasLen, 3)
+
+	data, err = json.Marshal(opts[:1])
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix+"/config/rules/batch", data), IsNil)
+	c.Assert(readJSON(s.urlPrefix+"/config/rules", &rules), IsNil)
+	c.Assert(rules, HasLen, 1)
+}
+
+func (s *testRuleSuite) TestGroupBundle(c *C) {
+	group := placement.RuleGroup{ID: "pd", Index: 10}
+	data, err := json.Marshal(group)
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix+"/config/rule_group", data), IsNil)
+	var g placement.RuleGroup
+	c.Assert(readJSON(s.urlPrefix+"/config/rule_group/pd", &g), IsNil)
+	c.Assert(g, DeepEquals, group)
+	data, err = json.Marshal(placement.RuleGroup{ID: "pd", Index: -1})
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix+"/config/rule_group", data), NotNil)
+
+	bundle := placement.GroupBundle{
+		ID:       "foo",
+		Index:    20,
+		Override: true,
+		Rules:    []*placement.Rule{{ID: "f1", Role: "voter", Count: 1}},
+	}
+	data, err = json.Marshal(bundle)
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix+"/config/placement-rule/foo", data), IsNil)
+	var bundles []placement.GroupBundle
+	c.Assert(readJSON(s.urlPrefix+"/config/placement-rule", &bundles), IsNil)
+	c.Assert(bundles, HasLen, 2)
+	c.Assert(bundles[0].ID, Equals, "pd")
+	c.Assert(bundles[0].Index, Equals, 10)
+	c.Assert(bundles[1].ID, Equals, "foo")
+	c.Assert(bundles[1].Override, IsTrue)
+	c.Assert(bundles[1].Rules[0].GroupID, Equals, "foo")
+
+	var groups []*placement.RuleGroup
+	c.Assert(readJSON(s.urlPrefix+"/config/rule_groups", &groups), IsNil)
+	c.Assert(groups, HasLen, 2)
+
+	res, err := doDelete(s.urlPrefix + "/config/placement-rule/foo")
+	c.Assert(err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	res, err = doDelete(s.urlPrefix + "/config/rule_group/pd")
+	c.Assert(err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	c.Assert(readJSON(s.urlPrefix+"/config/placement-rule", &bundles), IsNil)
+	c.Assert(bundles, HasLen, 1)
+	c.Assert(bundles[0].ID, Equals, "pd")
+	c.Assert(bundles[0].Index, Equals, 0)
+	c.Assert(bundles[0].Rules, HasLen, 1)
+
+	// a group cannot override the only voter rule.
+	data, err = json.Marshal(placement.Rule{GroupID: "l", ID: "1", Role: "learner", Count: 1})
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix+"/config/rule", data), IsNil)
+	data, err = json.Marshal(placement.RuleGroup{ID: "l", Index: 20, Override: true})
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix+"/config/rule_group", data), ErrorMatches, "(?s).*needs at least one leader or voter.*")
+	res, err = doDelete(s.urlPrefix + "/config/rule/l/1")
+	c.Assert(err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+}
@@ -0,0 +1,147 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pingcap/pd/v4/pkg/apiutil"
+	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/config"
+	"github.com/pingcap/pd/v4/server/schedule/profile"
+	"github.com/pkg/errors"
+	"github.com/unrolled/render"
+)
+
+type scheduleProfileHandler struct {
+	svr *server.Server
+	rd  *render.Render
+}
+
+func newScheduleProfileHandler(s *server.Server, rd *render.Render) *scheduleProfileHandler {
+	return &scheduleProfileHandler{
+		svr: s,
+		rd:  rd,
+	}
+}
+
+// @Tags schedule_profile
+// @Summary List all schedule profiles.
+// @Produce json
+// @Success 200 {array} config.ScheduleProfile
+// @Router /config/schedule-profile/profiles [get]
+func (h *scheduleProfileHandler) GetAllProfiles(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	h.rd.JSON(w, http.StatusOK, cluster.GetScheduleProfileManager().GetProfiles())
+}
+
+// @Tags schedule_profile
+// @Summary Get a schedule profile by name.
+// @Param name path string true "Profile name"
+// @Produce json
+// @Success 200 {object} config.ScheduleProfile
+// @Failure 404 {string} string "The profile does not exist."
+// @Router /config/schedule-profile/profile/{name} [get]
+func (h *scheduleProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	p := cluster.GetScheduleProfileManager().GetProfile(mux.Vars(r)["name"])
+	if p == nil {
+		h.rd.JSON(w, http.StatusNotFound, nil)
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, p)
+}
+
+// @Tags schedule_profile
+// @Summary Update a schedule profile.
+// @Accept json
+// @Param profile body config.ScheduleProfile true "Parameters of schedule profile"
+// @Produce json
+// @Success 200 {string} string "Update schedule profile success."
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /config/schedule-profile/profile [post]
+func (h *scheduleProfileHandler) SetProfile(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	var p config.ScheduleProfile
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &p); err != nil {
+		return
+	}
+	h.respondError(w, cluster.GetScheduleProfileManager().SetProfile(&p))
+}
+
+// @Tags schedule_profile
+// @Summary Delete a schedule profile by name.
+// @Param name path string true "Profile name"
+// @Produce json
+// @Success 200 {string} string "Delete schedule profile success."
+// @Failure 400 {string} string "The profile is used by the calendar."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /config/schedule-profile/profile/{name} [delete]
+func (h *scheduleProfileHandler) DeleteProfile(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	h.respondError(w, cluster.GetScheduleProfileManager().DeleteProfile(mux.Vars(r)["name"]))
+}
+
+// @Tags schedule_profile
+// @Summary Get the calendar of schedule profiles.
+// @Produce json
+// @Success 200 {object} profile.Calendar
+// @Router /config/schedule-profile/calendar [get]
+func (h *scheduleProfileHandler) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	h.rd.JSON(w, http.StatusOK, cluster.GetScheduleProfileManager().GetCalendar())
+}
+
+// @Tags schedule_profile
+// @Summary Replace the calendar of schedule profiles.
+// @Accept json
+// @Param calendar body profile.Calendar true "The weekly time windows of profiles"
+// @Produce json
+// @Success 200 {string} string "Update calendar success."
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /config/schedule-profile/calendar [post]
+func (h *scheduleProfileHandler) SetCalendar(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	var c profile.Calendar
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &c); err != nil {
+		return
+	}
+	h.respondError(w, cluster.GetScheduleProfileManager().SetCalendar(&c))
+}
+
+// @Tags schedule_profile
+// @Summary Get the active schedule profile and the schedule config in use.
+// @Produce json
+// @Success 200 {object} profile.Status
+// @Router /config/schedule-profile/status [get]
+func (h *scheduleProfileHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	h.rd.JSON(w, http.StatusOK, cluster.GetScheduleProfileManager().GetStatus())
+}
+
+func (h *scheduleProfileHandler) respondError(w http.ResponseWriter, err error) {
+	if err == nil {
+		h.rd.JSON(w, http.StatusOK, nil)
+		return
+	}
+	if errors.Cause(err) == profile.ErrInvalidProfile {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+}
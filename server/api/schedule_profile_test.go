@@ -0,0 +1,115 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/config"
+	"github.com/pingcap/pd/v4/server/schedule/profile"
+)
+
+var _ = Suite(&testScheduleProfileSuite{})
+
+type testScheduleProfileSuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func (s *testScheduleProfileSuite) SetUpSuite(c *C) {
+	s.svr, s.cleanup = mustNewServer(c)
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	addr := s.svr.GetAddr()
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1", addr, apiPrefix)
+
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testScheduleProfileSuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testScheduleProfileSuite) TestScheduleProfile(c *C) {
+	profileURL := s.urlPrefix + "/config/schedule-profile"
+	c.Assert(postJSON(s.urlPrefix+"/schedulers", []byte(`{"name": "shuffle-leader-scheduler"}`)), IsNil)
+	regionLimit := s.svr.GetScheduleConfig().RegionScheduleLimit
+
+	for _, data := range []string{
+		`{"name": "day", "config": {"region-schedule-limit": 1}, "paused-schedulers": ["shuffle-leader-scheduler"]}`,
+		`{"name": "night"}`,
+	} {
+		c.Assert(postJSON(profileURL+"/profile", []byte(data)), IsNil)
+	}
+	for _, data := range []string{
+		`{"name": ""}`,
+		`{"name": "bad", "config": {"unknown": 1}}`,
+		`{"name": "bad", "config": {"region-schedule-limit": "1"}}`,
+	} {
+		c.Assert(postJSON(profileURL+"/profile", []byte(data)), ErrorMatches, "(?s).*invalid schedule profile.*")
+	}
+	var profiles []*config.ScheduleProfile
+	c.Assert(readJSON(profileURL+"/profiles", &profiles), IsNil)
+	c.Assert(profiles, HasLen, 2)
+	c.Assert(profiles[0].Name, Equals, "day")
+	var p config.ScheduleProfile
+	c.Assert(readJSON(profileURL+"/profile/night", &p), IsNil)
+	c.Assert(p.Name, Equals, "night")
+	c.Assert(readJSON(profileURL+"/profile/unknown", &p), ErrorMatches, ".*404.*")
+
+	// The window lasting the whole day activates the profile at once.
+	c.Assert(postJSON(profileURL+"/calendar", []byte(`{"windows": [{"profile": "unknown", "start": "00:00", "end": "00:00"}]}`)), ErrorMatches, "(?s).*does not exist.*")
+	c.Assert(postJSON(profileURL+"/calendar", []byte(`{"windows": [{"profile": "day", "start": "9"}]}`)), ErrorMatches, "(?s).*invalid time.*")
+	c.Assert(postJSON(profileURL+"/calendar", []byte(`{"time-zone": "UTC", "windows": [{"profile": "day", "start": "00:00", "end": "00:00"}]}`)), IsNil)
+	var calendar profile.Calendar
+	c.Assert(readJSON(profileURL+"/calendar", &calendar), IsNil)
+	c.Assert(calendar.TimeZone, Equals, "UTC")
+	c.Assert(calendar.Windows, HasLen, 1)
+
+	var status profile.Status
+	c.Assert(readJSON(profileURL+"/status", &status), IsNil)
+	c.Assert(status.Profile, Equals, "day")
+	c.Assert(status.Window.Profile, Equals, "day")
+	c.Assert(status.Config.RegionScheduleLimit, Equals, uint64(1))
+	c.Assert(status.PausedSchedulers, DeepEquals, []string{"shuffle-leader-scheduler"})
+	// The schedule config API shows the config without the profile.
+	c.Assert(s.svr.GetScheduleConfig().RegionScheduleLimit, Equals, regionLimit)
+	var schedulers []*server.SchedulerStatus
+	c.Assert(readJSON(s.urlPrefix+"/schedulers?status=true", &schedulers), IsNil)
+	for _, sche := range schedulers {
+		if sche.Name == "shuffle-leader-scheduler" {
+			c.Assert(sche.Paused, IsTrue)
+			c.Assert(sche.PausedByProfile, Equals, "day")
+		}
+	}
+
+	res, err := doDelete(profileURL + "/profile/day")
+	c.Assert(err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusBadRequest)
+	c.Assert(postJSON(profileURL+"/calendar", []byte(`{"windows": []}`)), IsNil)
+	status = profile.Status{}
+	c.Assert(readJSON(profileURL+"/status", &status), IsNil)
+	c.Assert(status.Profile, Equals, "")
+	c.Assert(status.Config.RegionScheduleLimit, Equals, regionLimit)
+	res, err = doDelete(profileURL + "/profile/day")
+	c.Assert(err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	c.Assert(readJSON(profileURL+"/profiles", &profiles), IsNil)
+	c.Assert(profiles, HasLen, 1)
+}
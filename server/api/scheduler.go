@@ -16,18 +16,26 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/pingcap/pd/v4/pkg/apiutil"
 	"github.com/pingcap/pd/v4/server"
 	"github.com/pingcap/pd/v4/server/cluster"
+	"github.com/pingcap/pd/v4/server/schedule"
 	"github.com/pingcap/pd/v4/server/schedulers"
+	"github.com/pkg/errors"
 	"github.com/unrolled/render"
 )
 
-const schedulerConfigPrefix = "pd/api/v1/scheduler-config"
+const (
+	schedulerConfigPrefix = "pd/api/v1/scheduler-config"
+	// maxExplainRounds limits the rounds recorded in the explain mode.
+	maxExplainRounds = 100
+)
 
 type schedulerHandler struct {
 	*server.Handler
@@ -43,11 +51,21 @@ func newSchedulerHandler(handler *server.Handler, r *render.Render) *schedulerHa
 
 // @Tags scheduler
 // @Summary List running schedulers.
+// @Param status query boolean false "Whether to list the schedulers with their status, e.g. the pause state."
 // @Produce json
 // @Success 200 {array} string
 // @Failure 500 {string} string "PD server failed to proceed the request."
 // @Router /schedulers [get]
 func (h *schedulerHandler) List(w http.ResponseWriter, r *http.Request) {
+	if withStatus, _ := strconv.ParseBool(r.URL.Query().Get("status")); withStatus {
+		status, err := h.GetSchedulersStatus()
+		if err != nil {
+			h.r.JSON(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		h.r.JSON(w, http.StatusOK, status)
+		return
+	}
 	schedulers, err := h.GetSchedulers()
 	if err != nil {
 		h.r.JSON(w, http.StatusInternalServerError, err.Error())
@@ -188,6 +206,11 @@ func (h *schedulerHandler) Post(w http.ResponseWriter, r *http.Request) {
 			h.r.JSON(w, http.StatusInternalServerError, err.Error())
 			return
 		}
+	case schedulers.SplitHotRegionName:
+		if err := h.AddSplitHotRegionScheduler(); err != nil {
+			h.r.JSON(w, http.StatusInternalServerError, err.Error())
+			return
+		}
 	case schedulers.ShuffleHotRegionName:
 		limit := uint64(1)
 		l, ok := input["limit"].(float64)
@@ -273,24 +296,168 @@ func (h *schedulerHandler) redirectSchedulerDelete(name, schedulerName string) e
 // @Failure 500 {string} string "PD server failed to proceed the request."
 // @Router /schedulers/{name} [post]
 func (h *schedulerHandler) PauseOrResume(w http.ResponseWriter, r *http.Request) {
-	var input map[string]int
+	var input struct {
+		Delay  *int   `json:"delay"`
+		Reason string `json:"reason"`
+	}
 	if err := apiutil.ReadJSONRespondError(h.r, w, r.Body, &input); err != nil {
 		return
 	}
 
 	name := mux.Vars(r)["name"]
-	t, ok := input["delay"]
-	if !ok {
+	if input.Delay == nil {
 		h.r.JSON(w, http.StatusBadRequest, "missing pause time")
 		return
 	}
-	if err := h.PauseOrResumeScheduler(name, int64(t)); err != nil {
+	if err := h.PauseOrResumeScheduler(name, int64(*input.Delay), input.Reason); err != nil {
 		h.r.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 	h.r.JSON(w, http.StatusOK, nil)
 }
 
+// @Tags scheduler
+// @Summary Simulate a scheduler or the running schedulers with the checkers without dispatching the operators.
+// @Accept json
+// @Param body body cluster.SimulateRequest true "The scheduler and the configs to simulate"
+// @Produce json
+// @Success 200 {object} cluster.SimulateResult
+// @Failure 400 {string} string "Bad format request."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /schedulers/simulate [post]
+func (h *schedulerHandler) Simulate(w http.ResponseWriter, r *http.Request) {
+	var req cluster.SimulateRequest
+	if err := apiutil.ReadJSONRespondError(h.r, w, r.Body, &req); err != nil {
+		return
+	}
+	result, err := h.SimulateSchedule(&req)
+	if err != nil {
+		if errors.Cause(err) == cluster.ErrInvalidSimulation {
+			h.r.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, result)
+}
+
+// @Tags scheduler
+// @Summary Get the recorded rounds of a scheduler in explain mode.
+// @Param name path string true "The name of the scheduler."
+// @Produce json
+// @Success 200 {object} server.SchedulerExplain
+// @Failure 404 {string} string "The scheduler is not found."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /schedulers/{name}/explain [get]
+func (h *schedulerHandler) GetExplain(w http.ResponseWriter, r *http.Request) {
+	result, err := h.GetSchedulerExplain(mux.Vars(r)["name"])
+	if err != nil {
+		if errors.Cause(err) == cluster.ErrSchedulerNotFound {
+			h.r.JSON(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, result)
+}
+
+// @Tags scheduler
+// @Summary Turn on or off the explain mode of a scheduler.
+// @Accept json
+// @Param name path string true "The name of the scheduler."
+// @Param body body object true "json params"
+// @Produce json
+// @Success 200 {string} string "The explain mode is updated."
+// @Failure 400 {string} string "Bad format request."
+// @Failure 404 {string} string "The scheduler is not found."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /schedulers/{name}/explain [post]
+func (h *schedulerHandler) SetExplain(w http.ResponseWriter, r *http.Request) {
+	input := struct {
+		Enable *bool `json:"enable"`
+		Rounds int   `json:"rounds"`
+	}{Rounds: 10}
+	if err := apiutil.ReadJSONRespondError(h.r, w, r.Body, &input); err != nil {
+		return
+	}
+	if input.Enable == nil {
+		h.r.JSON(w, http.StatusBadRequest, "missing enable")
+		return
+	}
+	if input.Rounds <= 0 || input.Rounds > maxExplainRounds {
+		h.r.JSON(w, http.StatusBadRequest, fmt.Sprintf("rounds should be in [1, %d]", maxExplainRounds))
+		return
+	}
+	rounds := input.Rounds
+	if !*input.Enable {
+		rounds = 0
+	}
+	if err := h.SetSchedulerExplain(mux.Vars(r)["name"], rounds); err != nil {
+		if errors.Cause(err) == cluster.ErrSchedulerNotFound {
+			h.r.JSON(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, "The explain mode is updated.")
+}
+
+// @Tags scheduler
+// @Summary Get the config of a scheduler and its schema.
+// @Param name path string true "The name of the scheduler."
+// @Produce json
+// @Success 200 {object} server.SchedulerConfig
+// @Failure 404 {string} string "The scheduler is not found."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /scheduler-config/{name} [get]
+func (h *schedulerHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	cfg, err := h.GetSchedulerConfig(mux.Vars(r)["name"])
+	if err != nil {
+		if errors.Cause(err) == cluster.ErrSchedulerNotFound {
+			h.r.JSON(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, cfg)
+}
+
+// @Tags scheduler
+// @Summary Update some items of the scheduler config.
+// @Accept json
+// @Param name path string true "The name of the scheduler."
+// @Param body body object true "The config items to update."
+// @Produce json
+// @Success 200 {string} string "The config is updated."
+// @Failure 400 {string} string "The update is rejected by the schema."
+// @Failure 404 {string} string "The scheduler is not found."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /scheduler-config/{name} [post]
+func (h *schedulerHandler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
+	data, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := h.UpdateSchedulerConfig(mux.Vars(r)["name"], data); err != nil {
+		switch errors.Cause(err) {
+		case cluster.ErrSchedulerNotFound:
+			h.r.JSON(w, http.StatusNotFound, err.Error())
+		case schedule.ErrInvalidSchedulerConfig:
+			h.r.JSON(w, http.StatusBadRequest, err.Error())
+		default:
+			h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	h.r.JSON(w, http.StatusOK, "The config is updated.")
+}
+
 type schedulerConfigHandler struct {
 	svr *server.Server
 	rd  *render.Render
@@ -21,6 +21,8 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/cluster"
+	"github.com/pingcap/pd/v4/server/schedule"
 	_ "github.com/pingcap/pd/v4/server/schedulers"
 )
 
@@ -111,6 +113,8 @@ func (s *testScheduleSuite) TestAPI(c *C) {
 					"max-peer-number":           1000,
 					"byte-rate-rank-step-ratio": 0.05,
 					"key-rate-rank-step-ratio":  0.05,
+					"cpu-rank-step-ratio":       0.05,
+					"disk-rate-rank-step-ratio": 0.05,
 					"count-rank-step-ratio":     0.01,
 					"great-dec-ratio":           0.95,
 					"minor-dec-ratio":           0.99,
@@ -118,6 +122,9 @@ func (s *testScheduleSuite) TestAPI(c *C) {
 				for key := range expectMap {
 					c.Assert(resp[key], DeepEquals, expectMap[key])
 				}
+				c.Assert(resp["read-priorities"], DeepEquals, []interface{}{"byte", "key"})
+				c.Assert(resp["write-priorities"], DeepEquals, []interface{}{"byte", "key"})
+				c.Assert(resp["strict-picking-store"], Equals, true)
 				dataMap := make(map[string]interface{})
 				dataMap["max-zombie-rounds"] = 5.0
 				expectMap["max-zombie-rounds"] = 5.0
@@ -141,6 +148,22 @@ func (s *testScheduleSuite) TestAPI(c *C) {
 		{name: "balance-region-scheduler"},
 		{name: "shuffle-leader-scheduler"},
 		{name: "shuffle-region-scheduler"},
+		{
+			name: "split-hot-region-scheduler",
+			extraTestFunc: func(name string, c *C) {
+				configURL := fmt.Sprintf("%s%s%s/%s", s.svr.GetAddr(), apiPrefix, server.SchedulerConfigHandlerPath, name)
+				var cfg struct {
+					Config map[string]interface{} `json:"config"`
+				}
+				c.Assert(readJSON(configURL, &cfg), IsNil)
+				c.Assert(cfg.Config["min-hot-degree"], Equals, 10.0)
+				c.Assert(cfg.Config["split-policy"], Equals, "approximate")
+				c.Assert(postJSON(configURL, []byte(`{"split-policy":"scan"}`)), IsNil)
+				c.Assert(postJSON(configURL, []byte(`{"split-policy":"usekey"}`)), ErrorMatches, "(?s).*invalid scheduler config.*")
+				c.Assert(readJSON(configURL, &cfg), IsNil)
+				c.Assert(cfg.Config["split-policy"], Equals, "scan")
+			},
+		},
 		{
 			name:        "grant-leader-scheduler",
 			createdName: "grant-leader-scheduler",
@@ -400,3 +423,146 @@ func (s *testScheduleSuite) testPauseOrResume(name, createdName string, body []b
 
 	s.deleteScheduler(createdName, c)
 }
+
+func (s *testScheduleSuite) TestPauseStatus(c *C) {
+	handler := s.svr.GetHandler()
+	c.Assert(handler.AddBalanceLeaderScheduler(), IsNil)
+	defer s.deleteScheduler("balance-leader-scheduler", c)
+
+	body, err := json.Marshal(map[string]interface{}{"delay": 60, "reason": "incident"})
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix+"/balance-leader-scheduler", body), IsNil)
+	body, err = json.Marshal(map[string]interface{}{"reason": "incident"})
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix+"/balance-leader-scheduler", body), ErrorMatches, "(?s).*missing pause time.*")
+
+	var names []string
+	c.Assert(readJSON(s.urlPrefix, &names), IsNil)
+	c.Assert(names, DeepEquals, []string{"balance-leader-scheduler"})
+	var status []*server.SchedulerStatus
+	c.Assert(readJSON(s.urlPrefix+"?status=true", &status), IsNil)
+	c.Assert(status, HasLen, 1)
+	c.Assert(status[0].Name, Equals, "balance-leader-scheduler")
+	c.Assert(status[0].Paused, IsTrue)
+	c.Assert(status[0].Reason, Equals, "incident")
+	c.Assert(status[0].RemainingSeconds > 0 && status[0].RemainingSeconds <= 60, IsTrue)
+	c.Assert(status[0].PausedUntil.Sub(*status[0].PausedAt), Equals, time.Minute)
+
+	// The pause state is saved in storage.
+	var pause cluster.SchedulerPause
+	ok, err := s.svr.GetStorage().LoadSchedulerPause("balance-leader-scheduler", &pause)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	c.Assert(pause.Reason, Equals, "incident")
+
+	body, err = json.Marshal(map[string]interface{}{"delay": 0})
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix+"/balance-leader-scheduler", body), IsNil)
+	var resumed []*server.SchedulerStatus
+	c.Assert(readJSON(s.urlPrefix+"?status=true", &resumed), IsNil)
+	c.Assert(resumed, DeepEquals, []*server.SchedulerStatus{{Name: "balance-leader-scheduler"}})
+	ok, err = s.svr.GetStorage().LoadSchedulerPause("balance-leader-scheduler", &pause)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+}
+
+func (s *testScheduleSuite) TestSimulate(c *C) {
+	var result struct {
+		Operators []string                   `json:"operators"`
+		Stores    []*cluster.StoreScoreDelta `json:"stores"`
+	}
+	body := []byte(`{"scheduler":"evict-leader-scheduler","args":["1"],"schedule-config":{"leader-schedule-policy":"size"},"store-weights":{"2":{"leader-weight":2}}}`)
+	err := postJSON(s.urlPrefix+"/simulate", body, func(res []byte, code int) {
+		c.Assert(json.Unmarshal(res, &result), IsNil)
+	})
+	c.Assert(err, IsNil)
+	c.Assert(result.Operators, HasLen, 0)
+	c.Assert(result.Stores, HasLen, 2)
+	c.Assert(result.Stores[1].StoreID, Equals, uint64(2))
+	// The simulated scheduler is not added.
+	var names []string
+	c.Assert(readJSON(s.urlPrefix, &names), IsNil)
+	c.Assert(names, HasLen, 0)
+
+	for _, body := range []string{
+		`{"scheduler":"foo"}`,
+		`{"scheduler":"evict-leader-scheduler"}`,
+		`{"schedule-config":{"leader-schedule-policy":1}}`,
+		`{"store-weights":{"3":{"region-weight":2}}}`,
+	} {
+		err = postJSON(s.urlPrefix+"/simulate", []byte(body))
+		c.Assert(err, ErrorMatches, "(?s).*invalid simulation.*", Commentf(body))
+	}
+}
+
+func (s *testScheduleSuite) TestExplain(c *C) {
+	handler := s.svr.GetHandler()
+	c.Assert(handler.AddBalanceLeaderScheduler(), IsNil)
+	defer s.deleteScheduler("balance-leader-scheduler", c)
+
+	url := s.urlPrefix + "/balance-leader-scheduler/explain"
+	c.Assert(postJSON(url, []byte(`{"enable":true,"rounds":5}`)), IsNil)
+	var result server.SchedulerExplain
+	c.Assert(readJSON(url, &result), IsNil)
+	c.Assert(result.Name, Equals, "balance-leader-scheduler")
+	c.Assert(result.Enabled, IsTrue)
+	c.Assert(len(result.Rounds) <= 5, IsTrue)
+
+	c.Assert(postJSON(url, []byte(`{"rounds":5}`)), ErrorMatches, "(?s).*missing enable.*")
+	c.Assert(postJSON(url, []byte(`{"enable":true,"rounds":0}`)), ErrorMatches, "(?s).*rounds should be in.*")
+	c.Assert(postJSON(s.urlPrefix+"/foo/explain", []byte(`{"enable":true}`)), ErrorMatches, "(?s).*scheduler not found.*")
+	c.Assert(readJSON(s.urlPrefix+"/foo/explain", &result), ErrorMatches, ".*return code 404.*")
+
+	c.Assert(postJSON(url, []byte(`{"enable":false}`)), IsNil)
+	result = server.SchedulerExplain{}
+	c.Assert(readJSON(url, &result), IsNil)
+	c.Assert(result.Enabled, IsFalse)
+	c.Assert(result.Rounds, HasLen, 0)
+}
+
+func (s *testScheduleSuite) TestConfigAPI(c *C) {
+	handler := s.svr.GetHandler()
+	c.Assert(handler.AddBalanceHotRegionScheduler(), IsNil)
+	defer s.deleteScheduler("balance-hot-region-scheduler", c)
+	configURL := fmt.Sprintf("%s%s%s/%s", s.svr.GetAddr(), apiPrefix, server.SchedulerConfigHandlerPath, "balance-hot-region-scheduler")
+
+	var cfg struct {
+		Name   string                 `json:"name"`
+		Config map[string]interface{} `json:"config"`
+		Schema *schedule.ConfigSchema `json:"schema"`
+	}
+	c.Assert(readJSON(configURL, &cfg), IsNil)
+	c.Assert(cfg.Name, Equals, "balance-hot-region-scheduler")
+	c.Assert(cfg.Config["min-hot-byte-rate"], Equals, 100.0)
+	c.Assert(cfg.Schema.Properties["min-hot-byte-rate"].Type, Equals, "number")
+	c.Assert(*cfg.Schema.Properties["great-dec-ratio"].Maximum, Equals, 1.0)
+
+	// Update some items.
+	c.Assert(postJSON(configURL, []byte(`{"min-hot-byte-rate":200,"max-peer-number":500}`)), IsNil)
+	c.Assert(readJSON(configURL, &cfg), IsNil)
+	c.Assert(cfg.Config["min-hot-byte-rate"], Equals, 200.0)
+	c.Assert(cfg.Config["max-peer-number"], Equals, 500.0)
+	// The updated config is persisted.
+	_, data, err := s.svr.GetStorage().LoadAllScheduleConfig()
+	c.Assert(err, IsNil)
+	c.Assert(data, HasLen, 1)
+	c.Assert(data[0], Matches, `.*"min-hot-byte-rate":200.*`)
+	// The old API shares the config.
+	listURL := configURL + "/list"
+	var listed map[string]interface{}
+	c.Assert(readJSON(listURL, &listed), IsNil)
+	c.Assert(listed["max-peer-number"], Equals, 500.0)
+
+	// Invalid updates are rejected.
+	for _, update := range []string{`{"great-dec-ratio":2}`, `{"unknown":1}`, `{"max-peer-number":"1"}`} {
+		err = postJSON(configURL, []byte(update))
+		c.Assert(err, NotNil)
+		c.Assert(err, ErrorMatches, "(?s).*invalid scheduler config.*")
+	}
+	c.Assert(readJSON(configURL, &cfg), IsNil)
+	c.Assert(cfg.Config["great-dec-ratio"], Equals, 0.95)
+
+	// Unknown scheduler.
+	unknownURL := fmt.Sprintf("%s%s%s/%s", s.svr.GetAddr(), apiPrefix, server.SchedulerConfigHandlerPath, "unknown-scheduler")
+	c.Assert(readJSON(unknownURL, &cfg), ErrorMatches, ".*404.*")
+}
@@ -33,8 +33,11 @@ func NewHandler(ctx context.Context, svr *server.Server) (http.Handler, server.S
 	}
 	router := mux.NewRouter()
 	r, f := createRouter(ctx, apiPrefix, svr)
+	roleOf := newRouteRole(r)
 	router.PathPrefix(apiPrefix).Handler(negroni.New(
 		serverapi.NewRuntimeServiceValidator(svr, group),
+		serverapi.NewAuditor(svr, newRouteTemplate(r), roleOf),
+		serverapi.NewAuthChecker(svr, roleOf),
 		serverapi.NewRedirector(svr),
 		negroni.Wrap(r)),
 	)
@@ -0,0 +1,122 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pingcap/pd/v4/pkg/apiutil"
+	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pkg/errors"
+	"github.com/unrolled/render"
+)
+
+type serviceGCSafepointHandler struct {
+	svr *server.Server
+	rd  *render.Render
+}
+
+func newServiceGCSafepointHandler(svr *server.Server, rd *render.Render) *serviceGCSafepointHandler {
+	return &serviceGCSafepointHandler{
+		svr: svr,
+		rd:  rd,
+	}
+}
+
+type listServiceGCSafepoint struct {
+	ServiceGCSafepoints []*core.ServiceSafePoint `json:"service_gc_safe_points"`
+	GCSafePoint         uint64                   `json:"gc_safe_point"`
+}
+
+type updateServiceGCSafepoint struct {
+	ServiceID string `json:"service_id"`
+	TTL       int64  `json:"ttl"`
+	SafePoint uint64 `json:"safe_point"`
+}
+
+// @Tags service_gc_safepoint
+// @Summary Get all service GC safepoint.
+// @Produce json
+// @Success 200 {object} listServiceGCSafepoint
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /gc/safepoint [get]
+func (h *serviceGCSafepointHandler) List(w http.ResponseWriter, r *http.Request) {
+	storage := h.svr.GetStorage()
+	gcSafepoint, err := storage.LoadGCSafePoint()
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	ssps, err := h.svr.GetServiceGCSafePoints()
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	list := listServiceGCSafepoint{
+		GCSafePoint:         gcSafepoint,
+		ServiceGCSafepoints: ssps,
+	}
+	h.rd.JSON(w, http.StatusOK, list)
+}
+
+// @Tags service_gc_safepoint
+// @Summary Update a service GC safepoint. A non-positive TTL deletes the safepoint.
+// @Accept json
+// @Param body body updateServiceGCSafepoint true "The service id, ttl in seconds and safepoint"
+// @Produce json
+// @Success 200 {object} core.ServiceSafePoint "The minimum safepoint among all alive services."
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /gc/safepoint [post]
+func (h *serviceGCSafepointHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var input updateServiceGCSafepoint
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	min, err := h.svr.UpdateServiceGCSafePoint(input.ServiceID, input.TTL, input.SafePoint)
+	if err != nil {
+		switch errors.Cause(err) {
+		case server.ErrServiceSafePointRollback, server.ErrServiceSafePointTooSmall, server.ErrInvalidServiceID:
+			h.rd.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, min)
+}
+
+// @Tags service_gc_safepoint
+// @Summary Delete a service GC safepoint.
+// @Param service_id path string true "Service ID"
+// @Produce json
+// @Success 200 {string} string "Delete service GC safepoint successfully."
+// @Failure 400 {string} string "The service id is invalid."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /gc/safepoint/{service_id} [delete]
+func (h *serviceGCSafepointHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	serviceID := mux.Vars(r)["service_id"]
+	if _, err := h.svr.UpdateServiceGCSafePoint(serviceID, 0, 0); err != nil {
+		if errors.Cause(err) == server.ErrInvalidServiceID {
+			h.rd.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, "Delete service GC safepoint successfully.")
+}
@@ -0,0 +1,137 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/core"
+)
+
+var _ = Suite(&testServiceGCSafepointSuite{})
+
+type testServiceGCSafepointSuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func (s *testServiceGCSafepointSuite) SetUpSuite(c *C) {
+	s.svr, s.cleanup = mustNewServer(c)
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	addr := s.svr.GetAddr()
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1", addr, apiPrefix)
+
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testServiceGCSafepointSuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testServiceGCSafepointSuite) updateServiceSafePoint(serviceID string, ttl int64, safePoint uint64) error {
+	data, err := json.Marshal(&updateServiceGCSafepoint{ServiceID: serviceID, TTL: ttl, SafePoint: safePoint})
+	if err != nil {
+		return err
+	}
+	return postJSON(s.urlPrefix+"/gc/safepoint", data)
+}
+
+func (s *testServiceGCSafepointSuite) TestServiceGCSafepoint(c *C) {
+	sspURL := s.urlPrefix + "/gc/safepoint"
+
+	c.Assert(s.updateServiceSafePoint("a", 3600, 10), IsNil)
+	c.Assert(s.updateServiceSafePoint("b", 3600, 20), IsNil)
+	c.Assert(s.updateServiceSafePoint("c", 3600, 30), IsNil)
+
+	var list listServiceGCSafepoint
+	c.Assert(readJSON(sspURL, &list), IsNil)
+	c.Assert(list.ServiceGCSafepoints, HasLen, 3)
+	c.Assert(list.GCSafePoint, Equals, uint64(0))
+
+	// a service safepoint cannot be moved backwards.
+	c.Assert(s.updateServiceSafePoint("b", 3600, 15), NotNil)
+
+	// the gc safepoint is blocked by the minimum service safepoint.
+	resp, err := s.svr.UpdateGCSafePoint(context.Background(), &pdpb.UpdateGCSafePointRequest{
+		Header:    &pdpb.RequestHeader{ClusterId: s.svr.ClusterID()},
+		SafePoint: 25,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(resp.GetNewSafePoint(), Equals, uint64(10))
+
+	res, err := doDelete(sspURL + "/a")
+	c.Assert(err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+
+	resp, err = s.svr.UpdateGCSafePoint(context.Background(), &pdpb.UpdateGCSafePointRequest{
+		Header:    &pdpb.RequestHeader{ClusterId: s.svr.ClusterID()},
+		SafePoint: 25,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(resp.GetNewSafePoint(), Equals, uint64(20))
+
+	// a service safepoint cannot be less than the gc safepoint.
+	c.Assert(s.updateServiceSafePoint("d", 3600, 5), NotNil)
+
+	// an expired safepoint is removed.
+	c.Assert(s.svr.GetStorage().SaveServiceGCSafePoint(&core.ServiceSafePoint{ServiceID: "e", ExpiredAt: 1, SafePoint: 20}), IsNil)
+	c.Assert(readJSON(sspURL, &list), IsNil)
+	c.Assert(list.ServiceGCSafepoints, HasLen, 2)
+	c.Assert(list.GCSafePoint, Equals, uint64(20))
+
+	// a safepoint with a large ttl never expires.
+	c.Assert(s.updateServiceSafePoint("f", math.MaxInt64, 30), IsNil)
+	c.Assert(readJSON(sspURL, &list), IsNil)
+	c.Assert(list.ServiceGCSafepoints, HasLen, 3)
+	c.Assert(list.ServiceGCSafepoints[2].ServiceID, Equals, "f")
+	c.Assert(list.ServiceGCSafepoints[2].ExpiredAt, Equals, int64(math.MaxInt64))
+}
+
+func (s *testServiceGCSafepointSuite) TestServiceIDPath(c *C) {
+	sspURL := s.urlPrefix + "/gc/safepoint"
+	// The service ID cannot escape from the prefix of the safepoints.
+	serviceID := "../../../raft"
+	c.Assert(s.updateServiceSafePoint(serviceID, 3600, 100), NotNil)
+	res, err := doDelete(sspURL + "/" + url.PathEscape(serviceID))
+	c.Assert(err, IsNil)
+	c.Assert(res.StatusCode, Not(Equals), http.StatusOK)
+	c.Assert(s.updateServiceSafePoint("..", 3600, 100), NotNil)
+	c.Assert(s.updateServiceSafePoint("..raft", 3600, 100), IsNil)
+	res, err = doDelete(sspURL + "/..raft")
+	c.Assert(err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+
+	meta := &metapb.Cluster{}
+	ok, err := s.svr.GetStorage().LoadMeta(meta)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	c.Assert(meta.GetId(), Equals, s.svr.ClusterID())
+	var list listServiceGCSafepoint
+	c.Assert(readJSON(sspURL, &list), IsNil)
+	for _, ssp := range list.ServiceGCSafepoints {
+		c.Assert(ssp.ServiceID, Not(Equals), "..raft")
+	}
+}
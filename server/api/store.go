@@ -28,6 +28,7 @@ import (
 	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/storelimit"
 	"github.com/pkg/errors"
 	"github.com/unrolled/render"
 )
@@ -55,6 +56,8 @@ type StoreStatus struct {
 	ReceivingSnapCount uint32             `json:"receiving_snap_count,omitempty"`
 	ApplyingSnapCount  uint32             `json:"applying_snap_count,omitempty"`
 	IsBusy             bool               `json:"is_busy,omitempty"`
+	DiskState          string             `json:"disk_state"`
+	DiskStateChangedTS *time.Time         `json:"disk_state_changed_ts,omitempty"`
 	StartTS            *time.Time         `json:"start_ts,omitempty"`
 	LastHeartbeatTS    *time.Time         `json:"last_heartbeat_ts,omitempty"`
 	Uptime             *typeutil.Duration `json:"uptime,omitempty"`
@@ -93,6 +96,7 @@ func newStoreInfo(opt *config.ScheduleConfig, store *core.StoreInfo) *StoreInfo
 			ReceivingSnapCount: store.GetReceivingSnapCount(),
 			ApplyingSnapCount:  store.GetApplyingSnapCount(),
 			IsBusy:             store.IsBusy(),
+			DiskState:          store.DiskState(opt.DiskAlmostFullRatio, opt.DiskFullRatio).String(),
 		},
 	}
 
@@ -103,6 +107,9 @@ func newStoreInfo(opt *config.ScheduleConfig, store *core.StoreInfo) *StoreInfo
 	if lastHeartbeat := store.GetLastHeartbeatTS(); !lastHeartbeat.IsZero() {
 		s.Status.LastHeartbeatTS = &lastHeartbeat
 	}
+	if changedTime := store.GetDiskStateChangedTime(); !changedTime.IsZero() {
+		s.Status.DiskStateChangedTS = &changedTime
+	}
 	if upTime := store.GetUptime(); upTime > 0 {
 		duration := typeutil.NewDuration(upTime)
 		s.Status.Uptime = &duration
@@ -366,14 +373,39 @@ func (h *storeHandler) SetLimit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.SetStoreLimit(storeID, rate/schedule.StoreBalanceBaseTime); err != nil {
-		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+	limitTypes, err := parseStoreLimitTypes(input)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	for _, limitType := range limitTypes {
+		if err := h.SetStoreLimit(storeID, rate/schedule.StoreBalanceBaseTime, limitType); err != nil {
+			h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
 
 	h.rd.JSON(w, http.StatusOK, nil)
 }
 
+// parseStoreLimitTypes returns the store limit type in the input, all the
+// types are returned if it is not specified.
+func parseStoreLimitTypes(input map[string]interface{}) ([]storelimit.Type, error) {
+	typeVal, ok := input["type"]
+	if !ok {
+		return storelimit.Types(), nil
+	}
+	typeName, ok := typeVal.(string)
+	if !ok {
+		return nil, errors.New("badformat type")
+	}
+	limitType, ok := storelimit.ParseType(typeName)
+	if !ok {
+		return nil, errors.Errorf("unknown type %s", typeName)
+	}
+	return []storelimit.Type{limitType}, nil
+}
+
 type storesHandler struct {
 	*server.Handler
 	rd *render.Render
@@ -405,7 +437,7 @@ func (h *storesHandler) RemoveTombStone(w http.ResponseWriter, r *http.Request)
 
 // FIXME: details of input json body params
 // @Tags store
-// @Summary Set limit of all stores in the cluster.
+// @Summary Set limit of all stores in the cluster. The limit also applies to the stores added later.
 // @Accept json
 // @Param body body object true "json params"
 // @Produce json
@@ -430,10 +462,17 @@ func (h *storesHandler) SetAllLimit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.SetAllStoresLimit(rate / schedule.StoreBalanceBaseTime); err != nil {
-		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+	limitTypes, err := parseStoreLimitTypes(input)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	for _, limitType := range limitTypes {
+		if err := h.SetAllStoresLimit(rate/schedule.StoreBalanceBaseTime, limitType); err != nil {
+			h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
 
 	h.rd.JSON(w, http.StatusOK, nil)
 }
@@ -455,11 +494,14 @@ func (h *storesHandler) GetAllLimit(w http.ResponseWriter, r *http.Request) {
 		Rate float64 `json:"rate"`
 		Mode string  `json:"mode"`
 	}
-	resp := make(map[uint64]*LimitResp)
-	for s, l := range limits {
-		resp[s] = &LimitResp{
-			Rate: l.Rate() * schedule.StoreBalanceBaseTime,
-			Mode: l.Mode().String(),
+	resp := make(map[uint64]map[string]*LimitResp)
+	for s, storeLimits := range limits {
+		resp[s] = make(map[string]*LimitResp)
+		for limitType, l := range storeLimits {
+			resp[s][limitType.String()] = &LimitResp{
+				Rate: l.Rate() * schedule.StoreBalanceBaseTime,
+				Mode: l.Mode().String(),
+			}
 		}
 	}
 
@@ -353,3 +353,58 @@ func (s *testStoreSuite) TestDownState(c *C) {
 	storeInfo = newStoreInfo(s.svr.GetScheduleConfig(), newStore)
 	c.Assert(storeInfo.Store.StateName, Equals, downStateName)
 }
+
+func (s *testStoreSuite) TestDiskState(c *C) {
+	url := fmt.Sprintf("%s/store/4", s.urlPrefix)
+	heartbeat := func(available uint64) {
+		_, err := s.svr.StoreHeartbeat(
+			context.Background(), &pdpb.StoreHeartbeatRequest{
+				Header: &pdpb.RequestHeader{ClusterId: s.svr.ClusterID()},
+				Stats: &pdpb.StoreStats{
+					StoreId:   4,
+					Capacity:  100 * units.GiB,
+					Available: available * units.GiB,
+				},
+			},
+		)
+		c.Assert(err, IsNil)
+	}
+	diagnose := func() []*Recommendation {
+		var rdd []*Recommendation
+		c.Assert(readJSON(s.urlPrefix+"/diagnose", &rdd), IsNil)
+		var ret []*Recommendation
+		for _, r := range rdd {
+			if r.Module == modTiKV {
+				ret = append(ret, r)
+			}
+		}
+		return ret
+	}
+
+	heartbeat(50)
+	info := new(StoreInfo)
+	c.Assert(readJSON(url, info), IsNil)
+	c.Assert(info.Status.DiskState, Equals, "normal")
+	c.Assert(diagnose(), HasLen, 0)
+
+	heartbeat(8)
+	c.Assert(readJSON(url, info), IsNil)
+	c.Assert(info.Status.DiskState, Equals, "almost-full")
+	c.Assert(info.Status.DiskStateChangedTS, NotNil)
+	rdd := diagnose()
+	c.Assert(rdd, HasLen, 1)
+	c.Assert(rdd[0].Level, Equals, levelMajor)
+	c.Assert(strings.Contains(rdd[0].Description, "store ID 4,"), IsTrue)
+
+	heartbeat(3)
+	c.Assert(readJSON(url, info), IsNil)
+	c.Assert(info.Status.DiskState, Equals, "full")
+	rdd = diagnose()
+	c.Assert(rdd, HasLen, 1)
+	c.Assert(rdd[0].Level, Equals, levelCritical)
+
+	heartbeat(50)
+	c.Assert(readJSON(url, info), IsNil)
+	c.Assert(info.Status.DiskState, Equals, "normal")
+	c.Assert(diagnose(), HasLen, 0)
+}
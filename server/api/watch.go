@@ -0,0 +1,85 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/server"
+	"github.com/unrolled/render"
+	"go.uber.org/zap"
+)
+
+type watchHandler struct {
+	svr *server.Server
+	rd  *render.Render
+}
+
+func newWatchHandler(svr *server.Server, rd *render.Render) *watchHandler {
+	return &watchHandler{
+		svr: svr,
+		rd:  rd,
+	}
+}
+
+// @Tags watch
+// @Summary Watch the changes of a key space. The response is a stream of JSON arrays of events, one array per line. Requests with the PD-Allow-follower-handle header can be served by followers.
+// @Param keyspace path string true "The key space to watch" Enums(stores, config)
+// @Param revision query integer false "The revision to watch from. The current items are sent first if it is not positive."
+// @Produce json
+// @Success 200 {array} server.WatchEvent
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /watch/{keyspace} [get]
+func (h *watchHandler) Watch(w http.ResponseWriter, r *http.Request) {
+	var revision int64
+	if rev := r.URL.Query().Get("revision"); rev != "" {
+		var err error
+		if revision, err = strconv.ParseInt(rev, 10, 64); err != nil {
+			h.rd.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.rd.JSON(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+	keySpace := mux.Vars(r)["keyspace"]
+	ch, err := h.svr.Watch(r.Context(), keySpace, revision)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	// Close the connection once the watch ends instead of keeping it alive
+	// for the next request, a watch is long-lived anyway.
+	w.Header().Set("Connection", "close")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	encoder := json.NewEncoder(w)
+	for events := range ch {
+		if err := encoder.Encode(events); err != nil {
+			log.Info("watcher is gone", zap.String("key-space", keySpace), zap.Error(err))
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/cluster"
+	"github.com/pingcap/pd/v4/server/config"
+)
+
+var _ = Suite(&testWatchSuite{})
+
+type testWatchSuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func (s *testWatchSuite) SetUpSuite(c *C) {
+	s.svr, s.cleanup = mustNewServer(c)
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	addr := s.svr.GetAddr()
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1/watch", addr, apiPrefix)
+
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testWatchSuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testWatchSuite) mustWatch(c *C, ctx context.Context, url string) *json.Decoder {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	c.Assert(err, IsNil)
+	// Keep the connection alive to check that the server closes it.
+	cli := &http.Client{Transport: &http.Transport{}}
+	resp, err := cli.Do(req.WithContext(ctx))
+	c.Assert(err, IsNil)
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	c.Assert(resp.Close, IsTrue)
+	return json.NewDecoder(resp.Body)
+}
+
+func (s *testWatchSuite) TestWatchStores(c *C) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	decoder := s.mustWatch(c, ctx, s.urlPrefix+"/stores")
+
+	var events []*server.WatchEvent
+	c.Assert(decoder.Decode(&events), IsNil)
+	c.Assert(events, HasLen, 1)
+	c.Assert(events[0].Type, Equals, server.WatchEventPut)
+	c.Assert(events[0].Key, Equals, "1")
+	snapshotRev := events[0].Revision
+
+	// Only put the store without the heartbeat, which may save the store
+	// again, so that exactly one change is made.
+	_, err := s.svr.PutStore(context.Background(), &pdpb.PutStoreRequest{
+		Header: &pdpb.RequestHeader{ClusterId: s.svr.ClusterID()},
+		Store: &metapb.Store{
+			Id:      2,
+			Address: "tikv2",
+			State:   metapb.StoreState_Up,
+			Version: (*cluster.MinSupportedVersion(cluster.Version2_0)).String(),
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(decoder.Decode(&events), IsNil)
+	c.Assert(events, HasLen, 1)
+	c.Assert(events[0].Type, Equals, server.WatchEventPut)
+	c.Assert(events[0].Key, Equals, "2")
+	c.Assert(events[0].Revision, Greater, snapshotRev)
+	var store metapb.Store
+	c.Assert(json.Unmarshal(events[0].Value, &store), IsNil)
+	c.Assert(store.GetAddress(), Equals, "tikv2")
+
+	// Resume from the revision after the snapshot.
+	putRev := events[0].Revision
+	decoder = s.mustWatch(c, ctx, fmt.Sprintf("%s/stores?revision=%d", s.urlPrefix, snapshotRev+1))
+	c.Assert(decoder.Decode(&events), IsNil)
+	c.Assert(events, HasLen, 1)
+	c.Assert(events[0].Key, Equals, "2")
+	c.Assert(events[0].Revision, Equals, putRev)
+}
+
+func (s *testWatchSuite) TestWatchConfig(c *C) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	decoder := s.mustWatch(c, ctx, s.urlPrefix+"/config")
+
+	scheduleConfig := s.svr.GetScheduleConfig()
+	scheduleConfig.LeaderScheduleLimit = 100
+	c.Assert(s.svr.SetScheduleConfig(*scheduleConfig), IsNil)
+	// The first events may be the snapshot.
+	for {
+		var events []*server.WatchEvent
+		c.Assert(decoder.Decode(&events), IsNil)
+		c.Assert(events, HasLen, 1)
+		c.Assert(events[0].Key, Equals, server.WatchKeySpaceConfig)
+		cfg := &config.Config{}
+		c.Assert(json.Unmarshal(events[0].Value, cfg), IsNil)
+		if cfg.Schedule.LeaderScheduleLimit == 100 {
+			break
+		}
+	}
+
+	resp, err := dialClient.Get(s.urlPrefix + "/unknown")
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusBadRequest)
+}
@@ -0,0 +1,166 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/typeutil"
+	"github.com/pingcap/pd/v4/server/auth"
+	"github.com/pingcap/pd/v4/server/config"
+	"go.uber.org/zap"
+	lumberjack "gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Protocols of the requests.
+const (
+	ProtocolHTTP = "http"
+	ProtocolGRPC = "grpc"
+)
+
+// Record is the audit record of a request which changes the cluster.
+type Record struct {
+	Time time.Time `json:"time"`
+	// User, Role and AuthMethod are the caller of the request, they are empty
+	// if the authentication is disabled.
+	User       string `json:"user,omitempty"`
+	Role       string `json:"role,omitempty"`
+	AuthMethod string `json:"auth-method,omitempty"`
+	// Address is the remote address of the request.
+	Address string `json:"address"`
+	// RedirectedFrom is the name of the PD member which redirected the
+	// request to the leader.
+	RedirectedFrom string `json:"redirected-from,omitempty"`
+	Protocol       string `json:"protocol"`
+	// Method is the HTTP method, it is empty for gRPC.
+	Method string `json:"method,omitempty"`
+	// Route is the path template of the HTTP route or the gRPC method.
+	Route string `json:"route"`
+	// Path is the path and the query of the HTTP request.
+	Path string `json:"path,omitempty"`
+	// BodyDigest is the SHA-256 digest of the request body in hex, or the
+	// digest of the encoded gRPC request.
+	BodyDigest string `json:"body-digest"`
+	BodySize   int    `json:"body-size"`
+	// Status is the HTTP status code or the gRPC status code.
+	Status int `json:"status"`
+	// Error is the error of the gRPC request, including the error in the
+	// response header.
+	Error    string            `json:"error,omitempty"`
+	Duration typeutil.Duration `json:"duration"`
+}
+
+// SetIdentity sets the caller of the request.
+func (r *Record) SetIdentity(id *auth.Identity) {
+	if id == nil {
+		return
+	}
+	r.User, r.Role, r.AuthMethod = id.Name, id.Role.String(), id.Method
+}
+
+// SetBody sets the digest and the size of the request body.
+func (r *Record) SetBody(body []byte) {
+	digest := sha256.Sum256(body)
+	r.BodyDigest = hex.EncodeToString(digest[:])
+	r.BodySize = len(body)
+}
+
+// Logger writes the audit records to a rotating JSON lines file, which is
+// separated from the main log, and keeps the recent records in memory. It is
+// threadsafe.
+type Logger struct {
+	sync.Mutex
+	out io.WriteCloser
+	// recent is a ring of the recent records, next is the position of the
+	// next record.
+	recent []*Record
+	next   int
+	count  int
+}
+
+// NewLogger creates a Logger with the config.
+func NewLogger(cfg *config.AuditConfig) *Logger {
+	return newLogger(&lumberjack.Logger{
+		Filename:   cfg.File.Filename,
+		MaxSize:    cfg.File.MaxSize,
+		MaxBackups: cfg.File.MaxBackups,
+		MaxAge:     cfg.File.MaxDays,
+		LocalTime:  true,
+	}, cfg.RecentRecords)
+}
+
+func newLogger(out io.WriteCloser, recentRecords int) *Logger {
+	if recentRecords < 0 {
+		recentRecords = 0
+	}
+	return &Logger{
+		out:    out,
+		recent: make([]*Record, recentRecords),
+	}
+}
+
+// Log writes the record. The failure of writing is logged in the main log
+// and the record is still kept in memory.
+func (l *Logger) Log(r *Record) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		log.Error("failed to marshal audit record", zap.Error(err))
+		return
+	}
+	data = append(data, '\n')
+	l.Lock()
+	defer l.Unlock()
+	if _, err := l.out.Write(data); err != nil {
+		auditWriteFailedCounter.Inc()
+		log.Error("failed to write audit record", zap.ByteString("record", data), zap.Error(err))
+	}
+	if len(l.recent) == 0 {
+		return
+	}
+	l.recent[l.next] = r
+	l.next = (l.next + 1) % len(l.recent)
+	if l.count < len(l.recent) {
+		l.count++
+	}
+}
+
+// Recent returns the recent records from the newest to the oldest, at most
+// limit records are returned if limit is positive.
+func (l *Logger) Recent(limit int) []*Record {
+	l.Lock()
+	defer l.Unlock()
+	n := l.count
+	if limit > 0 && limit < n {
+		n = limit
+	}
+	records := make([]*Record, 0, n)
+	for i := 1; i <= n; i++ {
+		records = append(records, l.recent[(l.next-i+len(l.recent))%len(l.recent)])
+	}
+	return records
+}
+
+// Close closes the audit log file.
+func (l *Logger) Close() error {
+	l.Lock()
+	defer l.Unlock()
+	return l.out.Close()
+}
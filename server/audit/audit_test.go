@@ -0,0 +1,123 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/v4/server/auth"
+	"github.com/pingcap/pd/v4/server/config"
+	"github.com/pkg/errors"
+)
+
+func TestAudit(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testAuditSuite{})
+
+type testAuditSuite struct{}
+
+type mockWriter struct {
+	bytes.Buffer
+	err error
+}
+
+func (w *mockWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	return w.Buffer.Write(p)
+}
+
+func (w *mockWriter) Close() error { return nil }
+
+func (s *testAuditSuite) TestRecord(c *C) {
+	r := &Record{}
+	r.SetIdentity(nil)
+	c.Assert(r.User, Equals, "")
+	r.SetIdentity(&auth.Identity{Name: "alice", Role: auth.RoleAdmin, Method: "token"})
+	c.Assert(r.User, Equals, "alice")
+	c.Assert(r.Role, Equals, "admin")
+	c.Assert(r.AuthMethod, Equals, "token")
+	r.SetBody([]byte("abc"))
+	c.Assert(r.BodyDigest, Equals, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad")
+	c.Assert(r.BodySize, Equals, 3)
+}
+
+func (s *testAuditSuite) TestLogger(c *C) {
+	w := &mockWriter{}
+	l := newLogger(w, 3)
+	c.Assert(l.Recent(0), HasLen, 0)
+	for _, route := range []string{"a", "b"} {
+		l.Log(&Record{Protocol: ProtocolHTTP, Route: route})
+	}
+	checkRoutes(c, l.Recent(0), "b", "a")
+
+	// The records are written as JSON lines.
+	scanner := bufio.NewScanner(&w.Buffer)
+	var routes []string
+	for scanner.Scan() {
+		var r Record
+		c.Assert(json.Unmarshal(scanner.Bytes(), &r), IsNil)
+		routes = append(routes, r.Route)
+	}
+	c.Assert(routes, DeepEquals, []string{"a", "b"})
+
+	// Only the recent records are kept in memory.
+	for _, route := range []string{"c", "d"} {
+		l.Log(&Record{Protocol: ProtocolHTTP, Route: route})
+	}
+	checkRoutes(c, l.Recent(0), "d", "c", "b")
+	checkRoutes(c, l.Recent(2), "d", "c")
+	checkRoutes(c, l.Recent(10), "d", "c", "b")
+
+	// The record failed to write is still kept in memory.
+	w.err = errors.New("disk full")
+	l.Log(&Record{Protocol: ProtocolHTTP, Route: "e"})
+	checkRoutes(c, l.Recent(1), "e")
+
+	l = newLogger(&mockWriter{}, -1)
+	l.Log(&Record{Protocol: ProtocolHTTP, Route: "a"})
+	c.Assert(l.Recent(0), HasLen, 0)
+}
+
+func (s *testAuditSuite) TestFile(c *C) {
+	cfg := &config.AuditConfig{RecentRecords: 10}
+	cfg.File.Filename = filepath.Join(c.MkDir(), "audit.log")
+	l := NewLogger(cfg)
+	l.Log(&Record{Time: time.Now(), Protocol: ProtocolGRPC, Route: "/pdpb.PD/Bootstrap"})
+	c.Assert(l.Close(), IsNil)
+	data, err := ioutil.ReadFile(cfg.File.Filename)
+	c.Assert(err, IsNil)
+	var r Record
+	c.Assert(json.Unmarshal(data, &r), IsNil)
+	c.Assert(r.Route, Equals, "/pdpb.PD/Bootstrap")
+	c.Assert(data[len(data)-1], Equals, byte('\n'))
+}
+
+func checkRoutes(c *C, records []*Record, routes ...string) {
+	c.Assert(records, HasLen, len(routes))
+	for i, r := range records {
+		c.Assert(r.Route, Equals, routes[i])
+	}
+}
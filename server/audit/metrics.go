@@ -0,0 +1,29 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var auditWriteFailedCounter = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "pd",
+		Subsystem: "audit",
+		Name:      "write_failed_total",
+		Help:      "Counter of the audit records failed to write.",
+	})
+
+func init() {
+	prometheus.MustRegister(auditWriteFailedCounter)
+}
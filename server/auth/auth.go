@@ -0,0 +1,265 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+var (
+	// ErrUnauthenticated is returned when the request carries no valid
+	// credential.
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrPermissionDenied is returned when the role of the request is not
+	// allowed to do it.
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// Role is the role of the caller, a role is allowed to do everything the
+// lower roles are allowed to.
+type Role int
+
+// Roles.
+const (
+	// RoleNone is required by the requests open to everyone, such as the
+	// health check.
+	RoleNone Role = iota
+	// RoleReadOnly is allowed to read the status of the cluster.
+	RoleReadOnly
+	// RoleOperator is allowed to change the scheduling, such as adding
+	// operators and schedulers.
+	RoleOperator
+	// RoleAdmin is allowed to change the config and the members of the
+	// cluster.
+	RoleAdmin
+)
+
+var roleNames = map[Role]string{
+	RoleNone:     "none",
+	RoleReadOnly: "read-only",
+	RoleOperator: "operator",
+	RoleAdmin:    "admin",
+}
+
+func (r Role) String() string {
+	if name, ok := roleNames[r]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ParseRole parses the name of a role.
+func ParseRole(name string) (Role, error) {
+	for r, n := range roleNames {
+		if r != RoleNone && n == name {
+			return r, nil
+		}
+	}
+	return RoleNone, errors.Errorf("invalid role %s, it should be one of read-only, operator and admin", name)
+}
+
+// Identity is the caller of a request.
+type Identity struct {
+	// Name is the user of the token or the common name of the certificate.
+	Name string
+	Role Role
+	// Method is how the caller is authenticated, "token" or "cert".
+	Method string
+}
+
+// Token is a static bearer token in the token file.
+type Token struct {
+	Token string `json:"token"`
+	User  string `json:"user"`
+	Role  string `json:"role"`
+}
+
+// Authenticator authenticates the callers of the HTTP API and the gRPC
+// service by the bearer tokens and the common names of the client
+// certificates. It is immutable after created.
+type Authenticator struct {
+	tokens    map[string]*Identity
+	certRoles map[string]Role
+	members   map[string]struct{}
+}
+
+// NewAuthenticator creates an Authenticator with the tokens in the token
+// file, the roles of the certificate common names and the common names of
+// the certificates of the PD members. The token file is a JSON array of
+// tokens, it is skipped if the path is empty.
+func NewAuthenticator(tokenFile string, certRoles map[string]string, memberNames []string) (*Authenticator, error) {
+	a := &Authenticator{
+		tokens:    make(map[string]*Identity),
+		certRoles: make(map[string]Role, len(certRoles)),
+		members:   make(map[string]struct{}, len(memberNames)),
+	}
+	if tokenFile != "" {
+		data, err := ioutil.ReadFile(tokenFile)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		var tokens []*Token
+		if err := json.Unmarshal(data, &tokens); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse token file %s", tokenFile)
+		}
+		for _, t := range tokens {
+			if t.Token == "" {
+				return nil, errors.Errorf("empty token of user %s in token file %s", t.User, tokenFile)
+			}
+			if _, ok := a.tokens[t.Token]; ok {
+				return nil, errors.Errorf("duplicated token of user %s in token file %s", t.User, tokenFile)
+			}
+			role, err := ParseRole(t.Role)
+			if err != nil {
+				return nil, errors.Wrapf(err, "user %s", t.User)
+			}
+			a.tokens[t.Token] = &Identity{Name: t.User, Role: role, Method: "token"}
+		}
+	}
+	for cn, name := range certRoles {
+		role, err := ParseRole(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "common name %s", cn)
+		}
+		a.certRoles[cn] = role
+	}
+	for _, cn := range memberNames {
+		a.members[cn] = struct{}{}
+	}
+	return a, nil
+}
+
+// CertCommonName returns the common name of the certificate in the PEM file.
+func CertCommonName(certPath string) (string, error) {
+	data, err := ioutil.ReadFile(certPath)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return "", errors.Errorf("no certificate in %s", certPath)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse certificate %s", certPath)
+	}
+	return cert.Subject.CommonName, nil
+}
+
+// IsMember returns true if the HTTP request is sent by a PD member, which is
+// authenticated by the verified client certificate.
+func (a *Authenticator) IsMember(r *http.Request) bool {
+	cert := peerCertificate(r.TLS)
+	if cert == nil {
+		return false
+	}
+	_, ok := a.members[cert.Subject.CommonName]
+	return ok
+}
+
+// AuthenticateHTTP returns the caller of the HTTP request. The bearer token
+// is used if the request carries one, otherwise the client certificate is
+// used.
+func (a *Authenticator) AuthenticateHTTP(r *http.Request) (*Identity, error) {
+	if header := r.Header.Get("Authorization"); header != "" {
+		return a.authenticateToken(header)
+	}
+	return a.authenticateCert(r.TLS)
+}
+
+// AuthenticateGRPC returns the caller of the gRPC request in the same way as
+// AuthenticateHTTP, the token is read from the "authorization" metadata.
+func (a *Authenticator) AuthenticateGRPC(ctx context.Context) (*Identity, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			return a.authenticateToken(values[0])
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			return a.authenticateCert(&info.State)
+		}
+	}
+	return nil, errors.Wrap(ErrUnauthenticated, "no credential")
+}
+
+func (a *Authenticator) authenticateToken(header string) (*Identity, error) {
+	const prefix = "Bearer "
+	if !strings.HasPrefix(header, prefix) {
+		return nil, errors.Wrap(ErrUnauthenticated, "authorization should be a bearer token")
+	}
+	id, ok := a.tokens[strings.TrimSpace(header[len(prefix):])]
+	if !ok {
+		return nil, errors.Wrap(ErrUnauthenticated, "invalid token")
+	}
+	return id, nil
+}
+
+func (a *Authenticator) authenticateCert(state *tls.ConnectionState) (*Identity, error) {
+	cert := peerCertificate(state)
+	if cert == nil {
+		return nil, errors.Wrap(ErrUnauthenticated, "no credential")
+	}
+	cn := cert.Subject.CommonName
+	role, ok := a.certRoles[cn]
+	if !ok {
+		return nil, errors.Wrapf(ErrUnauthenticated, "no role for common name %s", cn)
+	}
+	return &Identity{Name: cn, Role: role, Method: "cert"}, nil
+}
+
+// peerCertificate returns the verified certificate of the peer. The
+// certificates are verified by the TLS handshake only if the CA is
+// configured, so the unverified ones are ignored.
+func peerCertificate(state *tls.ConnectionState) *x509.Certificate {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return state.VerifiedChains[0][0]
+}
+
+// Check returns an error if the caller is not allowed to do the things which
+// require the role.
+func Check(id *Identity, role Role) error {
+	if id.Role < role {
+		return errors.Wrapf(ErrPermissionDenied, "%s %s is %s, but %s is required", id.Method, id.Name, id.Role, role)
+	}
+	return nil
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a context carrying the caller.
+func WithIdentity(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// IdentityFrom returns the caller in the context, nil if there is none.
+func IdentityFrom(ctx context.Context) *Identity {
+	id, _ := ctx.Value(identityKey{}).(*Identity)
+	return id
+}
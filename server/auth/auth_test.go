@@ -0,0 +1,204 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestAuth(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testAuthSuite{})
+
+type testAuthSuite struct {
+	dir string
+}
+
+func (s *testAuthSuite) SetUpSuite(c *C) {
+	var err error
+	s.dir, err = ioutil.TempDir("", "pd_auth_test")
+	c.Assert(err, IsNil)
+}
+
+func (s *testAuthSuite) TearDownSuite(c *C) {
+	os.RemoveAll(s.dir)
+}
+
+func (s *testAuthSuite) writeTokenFile(c *C, content string) string {
+	path := filepath.Join(s.dir, "tokens.json")
+	c.Assert(ioutil.WriteFile(path, []byte(content), 0600), IsNil)
+	return path
+}
+
+func tlsState(cn string) *tls.ConnectionState {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn}}
+	return &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+		VerifiedChains:   [][]*x509.Certificate{{cert}},
+	}
+}
+
+func (s *testAuthSuite) TestRole(c *C) {
+	for _, r := range []Role{RoleReadOnly, RoleOperator, RoleAdmin} {
+		role, err := ParseRole(r.String())
+		c.Assert(err, IsNil)
+		c.Assert(role, Equals, r)
+	}
+	_, err := ParseRole("none")
+	c.Assert(err, NotNil)
+	_, err = ParseRole("root")
+	c.Assert(err, NotNil)
+
+	id := &Identity{Name: "alice", Role: RoleOperator, Method: "token"}
+	c.Assert(Check(id, RoleReadOnly), IsNil)
+	c.Assert(Check(id, RoleOperator), IsNil)
+	c.Assert(errors.Cause(Check(id, RoleAdmin)), Equals, ErrPermissionDenied)
+}
+
+func (s *testAuthSuite) TestNewAuthenticator(c *C) {
+	for _, content := range []string{
+		`{"token": "t"}`,
+		`[{"token": "", "user": "alice", "role": "admin"}]`,
+		`[{"token": "t", "user": "alice", "role": "root"}]`,
+		`[{"token": "t", "user": "alice", "role": "admin"}, {"token": "t", "user": "bob", "role": "operator"}]`,
+	} {
+		_, err := NewAuthenticator(s.writeTokenFile(c, content), nil, nil)
+		c.Assert(err, NotNil, Commentf("%s", content))
+	}
+	_, err := NewAuthenticator(filepath.Join(s.dir, "not-exist"), nil, nil)
+	c.Assert(err, NotNil)
+	_, err = NewAuthenticator("", map[string]string{"pd": "root"}, nil)
+	c.Assert(err, NotNil)
+}
+
+func (s *testAuthSuite) TestAuthenticateHTTP(c *C) {
+	tokenFile := s.writeTokenFile(c, `[
+		{"token": "admin-token", "user": "alice", "role": "admin"},
+		{"token": "reader-token", "user": "bob", "role": "read-only"}
+	]`)
+	a, err := NewAuthenticator(tokenFile, map[string]string{"pd-ctl": "operator"}, nil)
+	c.Assert(err, IsNil)
+
+	req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/pd/api/v1/stores", nil)
+	c.Assert(err, IsNil)
+	_, err = a.AuthenticateHTTP(req)
+	c.Assert(errors.Cause(err), Equals, ErrUnauthenticated)
+
+	req.Header.Set("Authorization", "Bearer admin-token")
+	id, err := a.AuthenticateHTTP(req)
+	c.Assert(err, IsNil)
+	c.Assert(*id, Equals, Identity{Name: "alice", Role: RoleAdmin, Method: "token"})
+	for _, header := range []string{"Bearer unknown", "Basic YWxpY2U6cGFzcw==", "admin-token"} {
+		req.Header.Set("Authorization", header)
+		_, err = a.AuthenticateHTTP(req)
+		c.Assert(errors.Cause(err), Equals, ErrUnauthenticated)
+	}
+
+	// The token is used prior to the certificate.
+	req.TLS = tlsState("pd-ctl")
+	req.Header.Set("Authorization", "Bearer reader-token")
+	id, err = a.AuthenticateHTTP(req)
+	c.Assert(err, IsNil)
+	c.Assert(id.Role, Equals, RoleReadOnly)
+	req.Header.Del("Authorization")
+	id, err = a.AuthenticateHTTP(req)
+	c.Assert(err, IsNil)
+	c.Assert(*id, Equals, Identity{Name: "pd-ctl", Role: RoleOperator, Method: "cert"})
+	req.TLS = tlsState("unknown")
+	_, err = a.AuthenticateHTTP(req)
+	c.Assert(errors.Cause(err), Equals, ErrUnauthenticated)
+	// The certificates which are not verified are ignored.
+	req.TLS = tlsState("pd-ctl")
+	req.TLS.VerifiedChains = nil
+	_, err = a.AuthenticateHTTP(req)
+	c.Assert(errors.Cause(err), Equals, ErrUnauthenticated)
+}
+
+func (s *testAuthSuite) TestAuthenticateGRPC(c *C) {
+	tokenFile := s.writeTokenFile(c, `[{"token": "tikv-token", "user": "tikv", "role": "operator"}]`)
+	a, err := NewAuthenticator(tokenFile, map[string]string{"tikv": "operator"}, nil)
+	c.Assert(err, IsNil)
+
+	_, err = a.AuthenticateGRPC(context.Background())
+	c.Assert(errors.Cause(err), Equals, ErrUnauthenticated)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer tikv-token"))
+	id, err := a.AuthenticateGRPC(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(*id, Equals, Identity{Name: "tikv", Role: RoleOperator, Method: "token"})
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer unknown"))
+	_, err = a.AuthenticateGRPC(ctx)
+	c.Assert(errors.Cause(err), Equals, ErrUnauthenticated)
+
+	ctx = peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: *tlsState("tikv")}})
+	id, err = a.AuthenticateGRPC(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(*id, Equals, Identity{Name: "tikv", Role: RoleOperator, Method: "cert"})
+}
+
+func (s *testAuthSuite) TestMember(c *C) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "pd-server"}}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	c.Assert(err, IsNil)
+	certPath := filepath.Join(s.dir, "pd-server.pem")
+	c.Assert(ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600), IsNil)
+	cn, err := CertCommonName(certPath)
+	c.Assert(err, IsNil)
+	c.Assert(cn, Equals, "pd-server")
+	_, err = CertCommonName(s.writeTokenFile(c, "[]"))
+	c.Assert(err, NotNil)
+
+	a, err := NewAuthenticator("", map[string]string{"pd-ctl": "admin"}, []string{cn})
+	c.Assert(err, IsNil)
+	req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/pd/api/v1/stores", nil)
+	c.Assert(err, IsNil)
+	c.Assert(a.IsMember(req), IsFalse)
+	req.TLS = tlsState("pd-ctl")
+	c.Assert(a.IsMember(req), IsFalse)
+	req.TLS = tlsState("pd-server")
+	c.Assert(a.IsMember(req), IsTrue)
+	// The certificates which are not verified are ignored.
+	req.TLS.VerifiedChains = nil
+	c.Assert(a.IsMember(req), IsFalse)
+}
+
+func (s *testAuthSuite) TestIdentityContext(c *C) {
+	c.Assert(IdentityFrom(context.Background()), IsNil)
+	id := &Identity{Name: "alice", Role: RoleAdmin, Method: "token"}
+	c.Assert(IdentityFrom(WithIdentity(context.Background(), id)), Equals, id)
+}
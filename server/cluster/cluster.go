@@ -17,6 +17,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -34,11 +35,16 @@ import (
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/id"
 	syncer "github.com/pingcap/pd/v4/server/region_syncer"
+	"github.com/pingcap/pd/v4/server/replay"
 	"github.com/pingcap/pd/v4/server/replicate"
 	"github.com/pingcap/pd/v4/server/schedule"
 	"github.com/pingcap/pd/v4/server/schedule/checker"
+	"github.com/pingcap/pd/v4/server/schedule/explain"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
 	"github.com/pingcap/pd/v4/server/schedule/placement"
+	"github.com/pingcap/pd/v4/server/schedule/profile"
+	"github.com/pingcap/pd/v4/server/schedule/storelimit"
 	"github.com/pingcap/pd/v4/server/statistics"
 	"github.com/pkg/errors"
 	"go.etcd.io/etcd/clientv3"
@@ -102,11 +108,22 @@ type RaftCluster struct {
 	quit         chan struct{}
 	regionSyncer *syncer.RegionSyncer
 
-	ruleManager *placement.RuleManager
-	client      *clientv3.Client
+	ruleManager      *placement.RuleManager
+	regionLabeler    *labeler.RegionLabeler
+	scheduleProfiles *profile.Manager
+	client           *clientv3.Client
 
 	replicateMode *replicate.ModeManager
 
+	// recorder records heartbeats and operators for pd-replay, nil if
+	// recording is disabled.
+	recorder *replay.Recorder
+	// history saves the finished operators, nil if it is disabled.
+	history *schedule.OperatorHistory
+	// hotRegionHistory saves the snapshots of the hot regions, nil if it is
+	// disabled.
+	hotRegionHistory *statistics.HotRegionHistory
+
 	schedulersCallback func()
 	configCheck        bool
 }
@@ -218,23 +235,63 @@ func (c *RaftCluster) Start(s Server) error {
 		}
 	}
 
-	c.replicateMode, err = replicate.NewReplicateModeManager(s.GetConfig().ReplicateMode, s.GetStorage(), s.GetAllocator())
+	c.regionLabeler, err = labeler.NewRegionLabeler(c.storage)
+	if err != nil {
+		return err
+	}
+
+	c.scheduleProfiles, err = profile.NewManager(c.storage, c.opt)
+	if err != nil {
+		return err
+	}
+	c.scheduleProfiles.Update(time.Now())
+
+	c.replicateMode, err = replicate.NewReplicateModeManager(s.GetConfig().ReplicateMode, s.GetStorage(), s.GetAllocator(), cluster)
 	if err != nil {
 		return err
 	}
 
 	c.coordinator = newCoordinator(c.ctx, cluster, s.GetHBStreams())
+	if err := c.loadStoreLimits(); err != nil {
+		return err
+	}
+	if cfg := s.GetConfig().HeartbeatRecord; cfg.Dir != "" {
+		c.recorder, err = replay.NewRecorder(cfg.Dir, int64(cfg.MaxFileSize), cfg.MaxFiles)
+		if err != nil {
+			return err
+		}
+		c.coordinator.opController.SetRecorder(c.recorder)
+	}
+	if cfg := s.GetConfig(); cfg.OperatorHistory.Retention.Duration > 0 {
+		c.history, err = schedule.NewOperatorHistory(filepath.Join(cfg.DataDir, "operator-history"), cfg.OperatorHistory.Retention.Duration)
+		if err != nil {
+			return err
+		}
+		c.coordinator.opController.SetHistory(c.history)
+	}
+	hotRegionHistoryCfg := s.GetConfig().HotRegionHistory
+	if hotRegionHistoryCfg.Interval.Duration > 0 && hotRegionHistoryCfg.Retention.Duration > 0 {
+		c.hotRegionHistory, err = statistics.NewHotRegionHistory(filepath.Join(s.GetConfig().DataDir, "hot-region-history"), hotRegionHistoryCfg.Retention.Duration)
+		if err != nil {
+			return err
+		}
+	}
 	c.regionStats = statistics.NewRegionStatistics(c.opt)
 	c.limiter = NewStoreLimiter(c.coordinator.opController)
 	c.quit = make(chan struct{})
 
-	c.wg.Add(3)
+	c.wg.Add(4)
 	go c.runCoordinator()
 	failpoint.Inject("highFrequencyClusterJobs", func() {
 		backgroundJobInterval = 100 * time.Microsecond
 	})
 	go c.runBackgroundJobs(backgroundJobInterval)
 	go c.syncRegions()
+	go c.runReplicateMode()
+	if c.hotRegionHistory != nil {
+		c.wg.Add(1)
+		go c.runHotRegionHistoryJob(hotRegionHistoryCfg.Interval.Duration)
+	}
 	c.running = true
 
 	return nil
@@ -288,12 +345,16 @@ func (c *RaftCluster) runBackgroundJobs(interval time.Duration) {
 		case <-c.quit:
 			log.Info("metrics are reset")
 			c.resetMetrics()
+			c.scheduleProfiles.Deactivate()
 			log.Info("background jobs has been stopped")
 			return
 		case <-ticker.C:
 			c.checkStores()
 			c.collectMetrics()
 			c.coordinator.opController.PruneHistory()
+			c.purgeOperatorHistory()
+			c.removeExpiredLabelRules()
+			c.scheduleProfiles.Update(time.Now())
 		}
 	}
 }
@@ -316,6 +377,12 @@ func (c *RaftCluster) syncRegions() {
 	c.regionSyncer.RunServer(c.changedRegionNotifier(), c.quit)
 }
 
+func (c *RaftCluster) runReplicateMode() {
+	defer logutil.LogPanic()
+	defer c.wg.Done()
+	c.replicateMode.Run(c.quit)
+}
+
 // Stop stops the cluster.
 func (c *RaftCluster) Stop() {
 	c.Lock()
@@ -331,6 +398,128 @@ func (c *RaftCluster) Stop() {
 	c.coordinator.stop()
 	c.Unlock()
 	c.wg.Wait()
+	if c.recorder != nil {
+		if err := c.recorder.Close(); err != nil {
+			log.Error("failed to close heartbeat recorder", zap.Error(err))
+		}
+	}
+	c.Lock()
+	defer c.Unlock()
+	if c.history != nil {
+		if err := c.history.Close(); err != nil {
+			log.Error("failed to close operator history", zap.Error(err))
+		}
+		c.history = nil
+	}
+	if c.hotRegionHistory != nil {
+		if err := c.hotRegionHistory.Close(); err != nil {
+			log.Error("failed to close hot region history", zap.Error(err))
+		}
+		c.hotRegionHistory = nil
+	}
+}
+
+func (c *RaftCluster) purgeOperatorHistory() {
+	c.RLock()
+	history := c.history
+	c.RUnlock()
+	if history == nil {
+		return
+	}
+	// The history is closed after the background jobs exit, so it is safe to
+	// purge it without holding the lock.
+	if err := history.Purge(time.Now()); err != nil {
+		log.Error("failed to purge operator history", zap.Error(err))
+	}
+}
+
+func (c *RaftCluster) runHotRegionHistoryJob(interval time.Duration) {
+	defer logutil.LogPanic()
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.quit:
+			log.Info("hot region history job has been stopped")
+			return
+		case now := <-ticker.C:
+			c.saveHotRegionHistory(now)
+		}
+	}
+}
+
+// saveHotRegionHistory saves a snapshot of the hot peers whose hot degree
+// reaches the threshold, and purges the expired snapshots.
+func (c *RaftCluster) saveHotRegionHistory(now time.Time) {
+	c.RLock()
+	history := c.hotRegionHistory
+	if history == nil {
+		c.RUnlock()
+		return
+	}
+	minHotDegree := c.opt.GetHotRegionCacheHitsThreshold()
+	var records []*statistics.HotRegionHistoryRecord
+	for _, kind := range []statistics.FlowKind{statistics.WriteFlow, statistics.ReadFlow} {
+		for _, peers := range c.hotSpotCache.RegionStats(kind) {
+			for _, peer := range peers {
+				if peer.HotDegree < minHotDegree {
+					continue
+				}
+				region := c.core.GetRegion(peer.RegionID)
+				if region == nil {
+					continue
+				}
+				records = append(records, statistics.NewHotRegionHistoryRecord(now, peer, region))
+			}
+		}
+	}
+	c.RUnlock()
+
+	// The history is closed after the background jobs exit, so it is safe to
+	// write it without holding the lock.
+	if err := history.Save(records); err != nil {
+		log.Error("failed to save hot region history", zap.Error(err))
+	}
+	if err := history.Purge(now); err != nil {
+		log.Error("failed to purge hot region history", zap.Error(err))
+	}
+}
+
+// QueryHotRegionHistory returns the hot peers in the history selected by the filter.
+func (c *RaftCluster) QueryHotRegionHistory(filter *statistics.HotRegionHistoryFilter) ([]*statistics.HotRegionHistoryRecord, error) {
+	c.RLock()
+	history := c.hotRegionHistory
+	c.RUnlock()
+	if history == nil {
+		return nil, errors.New("hot region history is disabled")
+	}
+	// Scan the history without holding the lock. The query fails with an
+	// error if the history is closed by Stop meanwhile.
+	return history.Query(filter)
+}
+
+func (c *RaftCluster) removeExpiredLabelRules() {
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.regionLabeler.RemoveExpiredRules(time.Now()); err != nil {
+		log.Error("failed to remove expired region label rules", zap.Error(err))
+	}
+}
+
+// QueryOperatorHistory returns the finished operators selected by the filter.
+func (c *RaftCluster) QueryOperatorHistory(filter *schedule.OperatorHistoryFilter) ([]*schedule.OperatorHistoryRecord, error) {
+	c.RLock()
+	history := c.history
+	c.RUnlock()
+	if history == nil {
+		return nil, errors.New("operator history is disabled")
+	}
+	// Scan the history without holding the lock. The query fails with an
+	// error if the history is closed by Stop meanwhile.
+	return history.Query(filter)
 }
 
 // IsRunning return if the cluster is running.
@@ -354,6 +543,13 @@ func (c *RaftCluster) GetRegionScatter() *schedule.RegionScatterer {
 	return c.coordinator.regionScatterer
 }
 
+// GetMergeJobController returns the merge job controller.
+func (c *RaftCluster) GetMergeJobController() *schedule.MergeJobController {
+	c.RLock()
+	defer c.RUnlock()
+	return c.coordinator.mergeJobs
+}
+
 // GetHeartbeatStreams returns the heartbeat streams.
 func (c *RaftCluster) GetHeartbeatStreams() opt.HeartbeatStreams {
 	c.RLock()
@@ -406,7 +602,21 @@ func (c *RaftCluster) HandleStoreHeartbeat(stats *pdpb.StoreStats) error {
 	if store == nil {
 		return core.NewStoreNotFoundErr(storeID)
 	}
+	if c.recorder != nil {
+		c.recorder.RecordStoreHeartbeat(store.GetMeta(), stats)
+	}
 	newStore := store.Clone(core.SetStoreStats(stats), core.SetLastHeartbeatTS(time.Now()))
+	almostFullRatio, fullRatio := c.GetDiskAlmostFullRatio(), c.GetDiskFullRatio()
+	if oldState, newState := store.DiskState(almostFullRatio, fullRatio), newStore.DiskState(almostFullRatio, fullRatio); oldState != newState {
+		log.Warn("store disk state changed",
+			zap.Uint64("store-id", newStore.GetID()),
+			zap.Stringer("old-state", oldState),
+			zap.Stringer("new-state", newState),
+			zap.Uint64("capacity", newStore.GetCapacity()),
+			zap.Uint64("available", newStore.GetAvailable()))
+		storeDiskStateChangedCounter.WithLabelValues(newState.String()).Inc()
+		newStore = newStore.Clone(core.SetDiskStateChangedTime(time.Now()))
+	}
 	if newStore.IsLowSpace(c.GetLowSpaceRatio()) {
 		log.Warn("store does not have enough disk space",
 			zap.Uint64("store-id", newStore.GetID()),
@@ -425,7 +635,7 @@ func (c *RaftCluster) HandleStoreHeartbeat(stats *pdpb.StoreStats) error {
 	c.storesStats.UpdateTotalBytesRate(c.core.GetStores)
 
 	// c.limiter is nil before "start" is called
-	if c.limiter != nil && c.opt.Load().StoreLimitMode == "auto" {
+	if c.limiter != nil && c.opt.LoadEffective().StoreLimitMode == "auto" {
 		c.limiter.Collect(newStore.GetStoreStats())
 	}
 
@@ -435,6 +645,9 @@ func (c *RaftCluster) HandleStoreHeartbeat(stats *pdpb.StoreStats) error {
 // processRegionHeartbeat updates the region information.
 func (c *RaftCluster) processRegionHeartbeat(region *core.RegionInfo) error {
 	c.RLock()
+	if c.recorder != nil {
+		c.recorder.RecordRegionHeartbeat(region)
+	}
 	origin, err := c.core.PreCheckPutRegion(region)
 	if err != nil {
 		c.RUnlock()
@@ -502,6 +715,11 @@ func (c *RaftCluster) processRegionHeartbeat(region *core.RegionInfo) error {
 			saveCache = true
 		}
 
+		if region.GetReplicateStatus().GetState() != origin.GetReplicateStatus().GetState() ||
+			region.GetReplicateStatus().GetRecoverId() != origin.GetReplicateStatus().GetRecoverId() {
+			saveCache = true
+		}
+
 		if region.GetBytesWritten() != origin.GetBytesWritten() ||
 			region.GetBytesRead() != origin.GetBytesRead() ||
 			region.GetKeysWritten() != origin.GetKeysWritten() ||
@@ -961,7 +1179,7 @@ func (c *RaftCluster) BuryStore(storeID uint64, force bool) error {
 		zap.String("store-address", newStore.GetAddress()))
 	err := c.putStoreLocked(newStore)
 	if err == nil {
-		c.coordinator.opController.RemoveStoreLimit(store.GetID())
+		c.removeStoreLimit(store.GetID())
 	}
 	return err
 }
@@ -977,8 +1195,8 @@ func (c *RaftCluster) UnblockStore(storeID uint64) {
 }
 
 // AttachAvailableFunc attaches an available function to a specific store.
-func (c *RaftCluster) AttachAvailableFunc(storeID uint64, f func() bool) {
-	c.core.AttachAvailableFunc(storeID, f)
+func (c *RaftCluster) AttachAvailableFunc(storeID uint64, limitType storelimit.Type, f func() bool) {
+	c.core.AttachAvailableFunc(storeID, limitType, f)
 }
 
 // SetConfigCheck sets a flag for preventing outdated config.
@@ -1034,6 +1252,69 @@ func (c *RaftCluster) SetStoreWeight(storeID uint64, leaderWeight, regionWeight
 	return c.putStoreLocked(newStore)
 }
 
+// SetStoreLimit sets the limit of a store and saves it to storage, so that
+// the limit survives the leader change.
+func (c *RaftCluster) SetStoreLimit(storeID uint64, rate float64, limitType storelimit.Type) error {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.GetStore(storeID) == nil {
+		return core.NewStoreNotFoundErr(storeID)
+	}
+	if err := c.storage.SaveStoreLimit(storeID, limitType, rate); err != nil {
+		return err
+	}
+	c.coordinator.opController.SetStoreLimit(storeID, rate, schedule.StoreLimitManual, limitType)
+	return nil
+}
+
+// SetAllStoresLimit sets the limit of all stores and saves them to storage.
+// The limit is also the default of the stores added later.
+func (c *RaftCluster) SetAllStoresLimit(rate float64, limitType storelimit.Type) error {
+	c.Lock()
+	defer c.Unlock()
+
+	if err := c.storage.SaveDefaultStoreLimit(limitType, rate); err != nil {
+		return err
+	}
+	for _, store := range c.GetStores() {
+		if store.IsTombstone() {
+			continue
+		}
+		if err := c.storage.SaveStoreLimit(store.GetID(), limitType, rate); err != nil {
+			return err
+		}
+	}
+	c.coordinator.opController.SetAllStoresLimit(rate, schedule.StoreLimitManual, limitType)
+	return nil
+}
+
+// loadStoreLimits loads the store limits set by the user from storage.
+func (c *RaftCluster) loadStoreLimits() error {
+	if err := c.storage.LoadDefaultStoreLimits(func(limitType storelimit.Type, rate float64) {
+		c.coordinator.opController.SetDefaultStoreLimit(rate, limitType)
+	}); err != nil {
+		return err
+	}
+	return c.storage.LoadStoreLimits(func(storeID uint64, limitType storelimit.Type, rate float64) {
+		if store := c.GetStore(storeID); store == nil || store.IsTombstone() {
+			return
+		}
+		c.coordinator.opController.SetStoreLimit(storeID, rate, schedule.StoreLimitManual, limitType)
+	})
+}
+
+// removeStoreLimit removes the limits of a store which is removed from the
+// cluster.
+func (c *RaftCluster) removeStoreLimit(storeID uint64) {
+	c.coordinator.opController.RemoveStoreLimit(storeID)
+	if err := c.storage.DeleteStoreLimit(storeID); err != nil {
+		log.Error("failed to delete store limit",
+			zap.Uint64("store-id", storeID),
+			zap.Error(err))
+	}
+}
+
 func (c *RaftCluster) putStoreLocked(store *core.StoreInfo) error {
 	if c.storage != nil {
 		if err := c.storage.SaveStore(store.GetMeta()); err != nil {
@@ -1103,7 +1384,7 @@ func (c *RaftCluster) RemoveTombStoneRecords() error {
 					zap.Error(err))
 				return err
 			}
-			c.coordinator.opController.RemoveStoreLimit(store.GetID())
+			c.removeStoreLimit(store.GetID())
 			log.Info("delete store succeeded",
 				zap.Stringer("store", store.GetMeta()))
 		}
@@ -1347,11 +1628,32 @@ func (c *RaftCluster) GetHighSpaceRatio() float64 {
 	return c.opt.GetHighSpaceRatio()
 }
 
+// GetDiskAlmostFullRatio returns the disk usage ratio of an almost full store.
+func (c *RaftCluster) GetDiskAlmostFullRatio() float64 {
+	return c.opt.GetDiskAlmostFullRatio()
+}
+
+// GetDiskFullRatio returns the disk usage ratio of a full store.
+func (c *RaftCluster) GetDiskFullRatio() float64 {
+	return c.opt.GetDiskFullRatio()
+}
+
 // GetSchedulerMaxWaitingOperator returns the number of the max waiting operators.
 func (c *RaftCluster) GetSchedulerMaxWaitingOperator() uint64 {
 	return c.opt.GetSchedulerMaxWaitingOperator()
 }
 
+// GetSchedulerWeight returns the weight of the waiting queue of the scheduler.
+func (c *RaftCluster) GetSchedulerWeight(name string) float64 {
+	return c.opt.GetSchedulerWeight(name)
+}
+
+// GetSchedulerInflightQuota returns the max number of running operators of the
+// scheduler.
+func (c *RaftCluster) GetSchedulerInflightQuota(name string) uint64 {
+	return c.opt.GetSchedulerInflightQuota(name)
+}
+
 // GetMaxSnapshotCount returns the number of the max snapshot which is allowed to send.
 func (c *RaftCluster) GetMaxSnapshotCount() uint64 {
 	return c.opt.GetMaxSnapshotCount()
@@ -1502,6 +1804,27 @@ func (c *RaftCluster) GetStoresKeysReadStat() map[uint64]float64 {
 	return c.storesStats.GetStoresKeysReadStat()
 }
 
+// GetStoresCPUUsage returns the cpu usage stat of all StoreInfo.
+func (c *RaftCluster) GetStoresCPUUsage() map[uint64]float64 {
+	c.RLock()
+	defer c.RUnlock()
+	return c.storesStats.GetStoresCPUUsage()
+}
+
+// GetStoresDiskReadRate returns the disk read rate stat of all StoreInfo.
+func (c *RaftCluster) GetStoresDiskReadRate() map[uint64]float64 {
+	c.RLock()
+	defer c.RUnlock()
+	return c.storesStats.GetStoresDiskReadRate()
+}
+
+// GetStoresDiskWriteRate returns the disk write rate stat of all StoreInfo.
+func (c *RaftCluster) GetStoresDiskWriteRate() map[uint64]float64 {
+	c.RLock()
+	defer c.RUnlock()
+	return c.storesStats.GetStoresDiskWriteRate()
+}
+
 // RegionReadStats returns hot region's read stats.
 func (c *RaftCluster) RegionReadStats() map[uint64][]*statistics.HotPeerStat {
 	// RegionStats is a thread-safe method
@@ -1545,6 +1868,20 @@ func (c *RaftCluster) GetRuleManager() *placement.RuleManager {
 	return c.ruleManager
 }
 
+// GetScheduleProfileManager returns the schedule profile manager.
+func (c *RaftCluster) GetScheduleProfileManager() *profile.Manager {
+	c.RLock()
+	defer c.RUnlock()
+	return c.scheduleProfiles
+}
+
+// GetRegionLabeler returns the region labeler.
+func (c *RaftCluster) GetRegionLabeler() *labeler.RegionLabeler {
+	c.RLock()
+	defer c.RUnlock()
+	return c.regionLabeler
+}
+
 // FitRegion tries to fit the region with placement rules.
 func (c *RaftCluster) FitRegion(region *core.RegionInfo) *placement.RegionFit {
 	return c.GetRuleManager().FitRegion(c, region)
@@ -1632,10 +1969,27 @@ func (c *RaftCluster) RemoveScheduler(name string) error {
 }
 
 // PauseOrResumeScheduler pauses or resumes a scheduler.
-func (c *RaftCluster) PauseOrResumeScheduler(name string, t int64) error {
+func (c *RaftCluster) PauseOrResumeScheduler(name string, t int64, reason string) error {
+	c.RLock()
+	defer c.RUnlock()
+	return c.coordinator.pauseOrResumeScheduler(name, t, reason)
+}
+
+// SetSchedulerExplain turns on the explain mode of a scheduler to record the
+// last rounds of scheduling, or turns it off if rounds is not positive.
+func (c *RaftCluster) SetSchedulerExplain(name string, rounds int) error {
 	c.RLock()
 	defer c.RUnlock()
-	return c.coordinator.pauseOrResumeScheduler(name, t)
+	return c.coordinator.setSchedulerExplain(name, rounds)
+}
+
+// GetExplainRecorder returns the recorder of a scheduler in explain mode. It
+// does not hold the lock of the cluster since the filters call it frequently.
+func (c *RaftCluster) GetExplainRecorder(name string) *explain.Recorder {
+	if c.coordinator == nil {
+		return nil
+	}
+	return c.coordinator.getExplainRecorder(name)
 }
 
 // GetStoreLimiter returns the dynamic adjusting limiter
@@ -17,13 +17,13 @@ import (
 	"context"
 	"fmt"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/pingcap/log"
 	"github.com/pingcap/pd/v4/pkg/logutil"
 	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/explain"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
 	"github.com/pingcap/pd/v4/server/schedulers"
@@ -53,6 +53,8 @@ var (
 	ErrSchedulerExisted = errors.New("scheduler existed")
 	// ErrSchedulerNotFound is error info for scheduler is not found.
 	ErrSchedulerNotFound = errors.New("scheduler not found")
+	// ErrInvalidSimulation is error info for the simulation request is invalid.
+	ErrInvalidSimulation = errors.New("invalid simulation")
 )
 
 // coordinator is used to manage all schedulers and checkers to decide if the region needs to be scheduled.
@@ -65,10 +67,16 @@ type coordinator struct {
 	cluster         *RaftCluster
 	checkers        *schedule.CheckerController
 	regionScatterer *schedule.RegionScatterer
+	mergeJobs       *schedule.MergeJobController
 	schedulers      map[string]*scheduleController
 	opController    *schedule.OperatorController
 	hbStreams       opt.HeartbeatStreams
 	pluginInterface *schedule.PluginInterface
+
+	// explainers are the recorders of the schedulers in explain mode. They
+	// are guarded by a separate lock because the filters look them up.
+	explainMu  sync.RWMutex
+	explainers map[string]*explain.Recorder
 }
 
 // newCoordinator creates a new coordinator.
@@ -80,11 +88,13 @@ func newCoordinator(ctx context.Context, cluster *RaftCluster, hbStreams opt.Hea
 		cancel:          cancel,
 		cluster:         cluster,
 		checkers:        schedule.NewCheckerController(ctx, cluster, cluster.ruleManager, opController),
-		regionScatterer: schedule.NewRegionScatterer(cluster),
+		regionScatterer: schedule.NewRegionScatterer(cluster, opController, cluster.storage),
+		mergeJobs:       schedule.NewMergeJobController(cluster, opController),
 		schedulers:      make(map[string]*scheduleController),
 		opController:    opController,
 		hbStreams:       hbStreams,
 		pluginInterface: schedule.NewPluginInterface(),
+		explainers:      make(map[string]*explain.Recorder),
 	}
 }
 
@@ -160,6 +170,24 @@ func (c *coordinator) drivePushOperator() {
 	}
 }
 
+// driveMergeJobs is used to run the merge jobs.
+func (c *coordinator) driveMergeJobs() {
+	defer logutil.LogPanic()
+
+	defer c.wg.Done()
+	ticker := time.NewTicker(schedule.MergeJobTickInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			log.Info("drive merge jobs has been stopped")
+			return
+		case <-ticker.C:
+			c.mergeJobs.Tick()
+		}
+	}
+}
+
 func (c *coordinator) run() {
 	ticker := time.NewTicker(runSchedulerCheckInterval)
 	defer ticker.Stop()
@@ -257,10 +285,11 @@ func (c *coordinator) run() {
 		log.Error("cannot persist schedule config", zap.Error(err))
 	}
 
-	c.wg.Add(2)
+	c.wg.Add(3)
 	// Starts to patrol regions.
 	go c.patrolRegions()
 	go c.drivePushOperator()
+	go c.driveMergeJobs()
 }
 
 // LoadPlugin load user plugin
@@ -473,6 +502,7 @@ func (c *coordinator) addScheduler(scheduler schedule.Scheduler, args ...string)
 	if err := s.Prepare(c.cluster); err != nil {
 		return err
 	}
+	c.restoreSchedulerPause(s)
 
 	c.wg.Add(1)
 	go c.runScheduler(s)
@@ -497,6 +527,10 @@ func (c *coordinator) removeScheduler(name string) error {
 	s.Stop()
 	schedulerStatusGauge.WithLabelValues(name, "allow").Set(0)
 	delete(c.schedulers, name)
+	c.opController.RemoveWaitingQueue(name)
+	c.explainMu.Lock()
+	delete(c.explainers, name)
+	c.explainMu.Unlock()
 
 	var err error
 	opt := c.cluster.opt
@@ -509,13 +543,16 @@ func (c *coordinator) removeScheduler(name string) error {
 		if err != nil {
 			log.Error("can not remove the scheduler config", zap.Error(err))
 		}
+		if err := c.cluster.storage.RemoveSchedulerPause(name); err != nil {
+			log.Error("can not remove the scheduler pause state", zap.Error(err))
+		}
 	}
 
 	c.cluster.schedulersCallback()
 	return err
 }
 
-func (c *coordinator) pauseOrResumeScheduler(name string, t int64) error {
+func (c *coordinator) pauseOrResumeScheduler(name string, t int64, reason string) error {
 	c.Lock()
 	defer c.Unlock()
 	if c.cluster == nil {
@@ -533,15 +570,68 @@ func (c *coordinator) pauseOrResumeScheduler(name string, t int64) error {
 			s = append(s, sc)
 		}
 	}
-	var err error
 	for _, sc := range s {
-		var delayUntil int64 = 0
+		var pause SchedulerPause
 		if t > 0 {
-			delayUntil = time.Now().Unix() + t
+			now := time.Now().Unix()
+			pause = SchedulerPause{PausedAt: now, PausedUntil: now + t, Reason: reason}
+			if err := c.cluster.storage.SaveSchedulerPause(sc.GetName(), pause); err != nil {
+				return err
+			}
+		} else if err := c.cluster.storage.RemoveSchedulerPause(sc.GetName()); err != nil {
+			return err
 		}
-		atomic.StoreInt64(&sc.delayUntil, delayUntil)
+		sc.setPause(pause)
 	}
-	return err
+	return nil
+}
+
+// setSchedulerExplain turns on the explain mode of the scheduler to record
+// the last rounds of scheduling, or turns it off if rounds is not positive.
+func (c *coordinator) setSchedulerExplain(name string, rounds int) error {
+	c.RLock()
+	defer c.RUnlock()
+	if c.cluster == nil {
+		return ErrNotBootstrapped
+	}
+	if _, ok := c.schedulers[name]; !ok {
+		return ErrSchedulerNotFound
+	}
+	c.explainMu.Lock()
+	defer c.explainMu.Unlock()
+	if rounds <= 0 {
+		delete(c.explainers, name)
+	} else {
+		c.explainers[name] = explain.NewRecorder(rounds)
+	}
+	return nil
+}
+
+// getExplainRecorder returns the recorder of the scheduler, or nil if the
+// scheduler is not in explain mode.
+func (c *coordinator) getExplainRecorder(name string) *explain.Recorder {
+	c.explainMu.RLock()
+	defer c.explainMu.RUnlock()
+	return c.explainers[name]
+}
+
+// restoreSchedulerPause restores the pause state saved by the previous
+// leader.
+func (c *coordinator) restoreSchedulerPause(s *scheduleController) {
+	var pause SchedulerPause
+	ok, err := c.cluster.storage.LoadSchedulerPause(s.GetName(), &pause)
+	if err != nil {
+		log.Error("can not load the scheduler pause state", zap.String("scheduler-name", s.GetName()), zap.Error(err))
+		return
+	}
+	if !ok || pause.PausedUntil <= time.Now().Unix() {
+		return
+	}
+	s.setPause(pause)
+	log.Info("scheduler is still paused",
+		zap.String("scheduler-name", s.GetName()),
+		zap.Time("paused-until", time.Unix(pause.PausedUntil, 0)),
+		zap.String("reason", pause.Reason))
 }
 
 func (c *coordinator) runScheduler(s *scheduleController) {
@@ -556,13 +646,25 @@ func (c *coordinator) runScheduler(s *scheduleController) {
 		select {
 		case <-timer.C:
 			timer.Reset(s.GetInterval())
+			recorder := c.getExplainRecorder(s.GetName())
+			if s.IsPaused() {
+				recorder.SkipRound("paused")
+				continue
+			}
 			if !s.AllowSchedule() {
+				recorder.SkipRound("not allowed, the schedule limit may be reached")
 				continue
 			}
+			recorder.BeginRound()
+			added := 0
 			if op := s.Schedule(); op != nil {
-				added := c.opController.AddWaitingOperator(op...)
+				for _, o := range op {
+					o.SetSource(s.GetName())
+				}
+				added = c.opController.AddWaitingOperator(op...)
 				log.Debug("add operator", zap.Int("added", added), zap.Int("total", len(op)), zap.String("scheduler", s.GetName()))
 			}
+			recorder.EndRound(added)
 
 		case <-s.Ctx().Done():
 			log.Info("scheduler has been stopped",
@@ -581,7 +683,17 @@ type scheduleController struct {
 	nextInterval time.Duration
 	ctx          context.Context
 	cancel       context.CancelFunc
-	delayUntil   int64
+
+	pauseMu sync.RWMutex
+	pause   SchedulerPause
+}
+
+// SchedulerPause is the pause state of a scheduler, it is saved in storage so
+// that the scheduler keeps paused after the leader changes.
+type SchedulerPause struct {
+	PausedAt    int64  `json:"paused-at"`
+	PausedUntil int64  `json:"paused-until"`
+	Reason      string `json:"reason,omitempty"`
 }
 
 // newScheduleController creates a new scheduleController.
@@ -627,8 +739,26 @@ func (s *scheduleController) AllowSchedule() bool {
 	return s.Scheduler.IsScheduleAllowed(s.cluster) && !s.IsPaused()
 }
 
-// isPaused returns if a schedueler is paused.
+// IsPaused returns if a schedueler is paused, by the API or by the active
+// schedule profile.
 func (s *scheduleController) IsPaused() bool {
-	delayUntil := atomic.LoadInt64(&s.delayUntil)
-	return time.Now().Unix() < delayUntil
+	if s.cluster.opt.IsSchedulerPausedByProfile(s.GetName()) {
+		return true
+	}
+	s.pauseMu.RLock()
+	defer s.pauseMu.RUnlock()
+	return time.Now().Unix() < s.pause.PausedUntil
+}
+
+// GetPause returns the pause state of the scheduler.
+func (s *scheduleController) GetPause() SchedulerPause {
+	s.pauseMu.RLock()
+	defer s.pauseMu.RUnlock()
+	return s.pause
+}
+
+func (s *scheduleController) setPause(pause SchedulerPause) {
+	s.pauseMu.Lock()
+	defer s.pauseMu.Unlock()
+	s.pause = pause
 }
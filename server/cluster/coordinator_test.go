@@ -31,8 +31,10 @@ import (
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/kv"
 	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/explain"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pingcap/pd/v4/server/schedule/storelimit"
 	"github.com/pingcap/pd/v4/server/schedulers"
 	"github.com/pingcap/pd/v4/server/statistics"
 )
@@ -178,6 +180,29 @@ func (s *testCoordinatorSuite) TestBasic(c *C) {
 	c.Assert(oc.GetOperator(1).RegionID(), Equals, op3.RegionID())
 }
 
+func (s *testCoordinatorSuite) TestDefaultStoreLimit(c *C) {
+	tc, co, cleanup := prepare(nil, nil, nil, c)
+	defer cleanup()
+	tc.coordinator = co
+
+	c.Assert(tc.addRegionStore(1, 10), IsNil)
+	c.Assert(tc.SetAllStoresLimit(1, storelimit.AddPeer), IsNil)
+
+	// The limit is reloaded as the default of the stores added later.
+	hbStreams := mockhbstream.NewHeartbeatStreams(tc.getClusterID(), false /* need to run */)
+	defer hbStreams.Close()
+	tc.coordinator = newCoordinator(s.ctx, tc.RaftCluster, hbStreams)
+	c.Assert(tc.loadStoreLimits(), IsNil)
+	c.Assert(tc.addRegionStore(2, 10), IsNil)
+	c.Assert(tc.addLeaderRegion(1, 1), IsNil)
+	oc := tc.coordinator.opController
+	op := operator.NewOperator("test", "test", 1, tc.GetRegion(1).GetRegionEpoch(), operator.OpRegion, operator.AddPeer{ToStore: 2, PeerID: 10})
+	c.Assert(oc.AddOperator(op), IsTrue)
+	limit := oc.GetAllStoresLimit()[2][storelimit.AddPeer]
+	c.Assert(limit.Rate(), Equals, 1.0)
+	c.Assert(limit.Mode(), Equals, schedule.StoreLimitManual)
+}
+
 func (s *testCoordinatorSuite) TestDispatch(c *C) {
 	tc, co, cleanup := prepare(nil, func(tc *testCluster) { tc.prepareChecker.isPrepared = true }, nil, c)
 	defer cleanup()
@@ -361,6 +386,7 @@ func (s *testCoordinatorSuite) TestCheckRegion(c *C) {
 func (s *testCoordinatorSuite) TestCheckerIsBusy(c *C) {
 	tc, co, cleanup := prepare(func(cfg *config.ScheduleConfig) {
 		cfg.ReplicaScheduleLimit = 0 // ensure replica checker is busy
+		cfg.LeaderScheduleLimit = 0  // ensure leader store checker is busy
 		cfg.MergeScheduleLimit = 10
 	}, nil, func(co *coordinator) { co.run() }, c)
 	defer cleanup()
@@ -724,6 +750,107 @@ func (s *testCoordinatorSuite) TestPersistScheduler(c *C) {
 	c.Assert(co.schedulers, HasLen, 3)
 }
 
+func (s *testCoordinatorSuite) TestPauseScheduler(c *C) {
+	tc, co, cleanup := prepare(nil, nil, func(co *coordinator) { co.run() }, c)
+	hbStreams := co.hbStreams
+	defer cleanup()
+
+	c.Assert(co.pauseOrResumeScheduler(schedulers.BalanceLeaderName, 60, "incident"), IsNil)
+	c.Assert(co.schedulers[schedulers.BalanceLeaderName].IsPaused(), IsTrue)
+	c.Assert(co.schedulers[schedulers.BalanceRegionName].IsPaused(), IsFalse)
+	c.Assert(co.pauseOrResumeScheduler("unknown", 60, ""), Equals, ErrSchedulerNotFound)
+
+	// The pause is restored after restart.
+	co.stop()
+	co.wg.Wait()
+	co = newCoordinator(s.ctx, tc.RaftCluster, hbStreams)
+	co.run()
+	sc := co.schedulers[schedulers.BalanceLeaderName]
+	c.Assert(sc.IsPaused(), IsTrue)
+	pause := sc.GetPause()
+	c.Assert(pause.Reason, Equals, "incident")
+	c.Assert(pause.PausedUntil-pause.PausedAt, Equals, int64(60))
+
+	// The resumed scheduler is not paused after restart.
+	c.Assert(co.pauseOrResumeScheduler("all", 0, ""), IsNil)
+	c.Assert(sc.IsPaused(), IsFalse)
+	co.stop()
+	co.wg.Wait()
+	co = newCoordinator(s.ctx, tc.RaftCluster, hbStreams)
+	co.run()
+	c.Assert(co.schedulers[schedulers.BalanceLeaderName].IsPaused(), IsFalse)
+	co.stop()
+	co.wg.Wait()
+}
+
+func (s *testCoordinatorSuite) TestSchedulerExplain(c *C) {
+	tc, co, cleanup := prepare(nil, nil, func(co *coordinator) {
+		co.cluster.coordinator = co
+		co.run()
+	}, c)
+	defer cleanup()
+
+	c.Assert(tc.addLeaderStore(1, 20), IsNil)
+	c.Assert(tc.addLeaderStore(2, 0), IsNil)
+	c.Assert(tc.addLeaderStore(3, 0), IsNil)
+	c.Assert(tc.setStoreOffline(3), IsNil)
+	for i := uint64(1); i <= 20; i++ {
+		c.Assert(tc.addLeaderRegion(i, 1, 2, 3), IsNil)
+	}
+
+	c.Assert(co.setSchedulerExplain(schedulers.BalanceLeaderName, 100), IsNil)
+	c.Assert(co.setSchedulerExplain("unknown", 100), Equals, ErrSchedulerNotFound)
+	c.Assert(tc.GetExplainRecorder(schedulers.BalanceRegionName), IsNil)
+	recorder := tc.GetExplainRecorder(schedulers.BalanceLeaderName)
+	c.Assert(recorder, NotNil)
+
+	var round *explain.Round
+	testutil.WaitUntil(c, func(c *C) bool {
+		for _, r := range recorder.Rounds() {
+			if r.Operators > 0 {
+				round = r
+				return true
+			}
+		}
+		return false
+	})
+	c.Assert(len(recorder.Rounds()) <= 100, IsTrue)
+	c.Assert(round.Skipped, Equals, "")
+	// The stores are not visited in order.
+	var source *explain.Candidate
+	for _, candidate := range round.Sources {
+		if candidate.StoreID == 1 {
+			source = candidate
+		}
+	}
+	c.Assert(source, NotNil)
+	c.Assert(source.Passed > 0, IsTrue)
+	var rejected *explain.Rejection
+	for _, t := range round.Targets {
+		if t.StoreID == 3 {
+			c.Assert(t.Rejected, Not(HasLen), 0)
+			rejected = t.Rejected[0]
+		}
+	}
+	c.Assert(rejected, DeepEquals, &explain.Rejection{Scope: schedulers.BalanceLeaderName, Type: "store-state-filter", Count: rejected.Count})
+	c.Assert(round.Scores, Not(HasLen), 0)
+	cmp := round.Scores[len(round.Scores)-1]
+	c.Assert(cmp.SourceID, Equals, uint64(1))
+	c.Assert(cmp.TargetID, Equals, uint64(2))
+	c.Assert(cmp.Balance, IsTrue)
+
+	// The paused rounds are recorded as skipped.
+	c.Assert(co.pauseOrResumeScheduler(schedulers.BalanceLeaderName, 60, ""), IsNil)
+	testutil.WaitUntil(c, func(c *C) bool {
+		rounds := recorder.Rounds()
+		return len(rounds) > 0 && rounds[0].Skipped == "paused"
+	})
+
+	// The explain mode is turned off.
+	c.Assert(co.setSchedulerExplain(schedulers.BalanceLeaderName, 0), IsNil)
+	c.Assert(tc.GetExplainRecorder(schedulers.BalanceLeaderName), IsNil)
+}
+
 func (s *testCoordinatorSuite) TestRemoveScheduler(c *C) {
 	tc, co, cleanup := prepare(func(cfg *config.ScheduleConfig) {
 		cfg.ReplicaScheduleLimit = 0
@@ -936,7 +1063,8 @@ func (s *testOperatorControllerSuite) TestStoreOverloaded(c *C) {
 
 	// reset all stores' limit
 	// scheduling one time needs 1/10 seconds
-	oc.SetAllStoresLimit(10, schedule.StoreLimitManual)
+	oc.SetAllStoresLimit(10, schedule.StoreLimitManual, storelimit.AddPeer)
+	oc.SetAllStoresLimit(10, schedule.StoreLimitManual, storelimit.RemovePeer)
 	for i := 0; i < 10; i++ {
 		op1 := lb.Schedule(tc)[0]
 		c.Assert(op1, NotNil)
@@ -71,6 +71,14 @@ var (
 			Name:      "cluster_state_current",
 			Help:      "Current state of the cluster",
 		}, []string{"state"})
+
+	storeDiskStateChangedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "cluster",
+			Name:      "store_disk_state_changed",
+			Help:      "Counter of the changes of the store disk state.",
+		}, []string{"state"})
 )
 
 func init() {
@@ -81,4 +89,5 @@ func init() {
 	prometheus.MustRegister(patrolCheckRegionsHistogram)
 	prometheus.MustRegister(clusterStateCPUGuage)
 	prometheus.MustRegister(clusterStateCurrent)
+	prometheus.MustRegister(storeDiskStateChangedCounter)
 }
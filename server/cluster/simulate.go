@@ -0,0 +1,379 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/v4/pkg/mock/mockid"
+	"github.com/pingcap/pd/v4/server/config"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/kv"
+	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/explain"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pingcap/pd/v4/server/schedule/storelimit"
+	"github.com/pkg/errors"
+)
+
+// simulateMaxRounds is the max number of times to run a scheduler in a
+// simulation, it stops earlier once the scheduler is not allowed to schedule
+// or creates nothing. The operators rejected by the controller still take a
+// round.
+const simulateMaxRounds = 1024
+
+// simulateMaxCheckRegions is the max number of regions the checkers check in
+// a simulation, from the first region. It bounds the work of the request, and
+// the checkers are simulated to see how they compete with the schedulers for
+// the schedule limits rather than to find all the regions to fix.
+const simulateMaxCheckRegions = 4096
+
+// SimulateRequest describes a dry run of the scheduling.
+type SimulateRequest struct {
+	// Scheduler is the type or the name of the scheduler to simulate, the
+	// running schedulers are simulated if it is empty.
+	Scheduler string `json:"scheduler,omitempty"`
+	// Args are the arguments to create the scheduler, the same as adding it.
+	Args []string `json:"args,omitempty"`
+	// Config is the config to create the scheduler, it takes precedence
+	// over Args.
+	Config json.RawMessage `json:"config,omitempty"`
+	// ScheduleConfig is the items of the schedule config to change.
+	ScheduleConfig json.RawMessage `json:"schedule-config,omitempty"`
+	// StoreWeights are the weights of the stores to change.
+	StoreWeights map[uint64]*SimulateStoreWeight `json:"store-weights,omitempty"`
+}
+
+// SimulateStoreWeight is the weights of a store in a simulation, the weight
+// is unchanged if it is nil.
+type SimulateStoreWeight struct {
+	LeaderWeight *float64 `json:"leader-weight,omitempty"`
+	RegionWeight *float64 `json:"region-weight,omitempty"`
+}
+
+// SimulateResult is the result of a simulation.
+type SimulateResult struct {
+	Operators []*operator.Operator `json:"operators"`
+	Stores    []*StoreScoreDelta   `json:"stores"`
+}
+
+// StoreScoreDelta is the balance scores of a store before the simulated
+// operators and how the operators change them.
+type StoreScoreDelta struct {
+	StoreID          uint64  `json:"store-id"`
+	LeaderScore      float64 `json:"leader-score"`
+	LeaderScoreDelta float64 `json:"leader-score-delta"`
+	RegionScore      float64 `json:"region-score"`
+	RegionScoreDelta float64 `json:"region-score-delta"`
+}
+
+// Simulate runs the checkers and the schedulers against a snapshot of the
+// cluster with the changed configs, and returns the operators they create
+// without dispatching them. The running operators are taken into account.
+// The simulated operators never finish, so the result is one wave of the
+// scheduling which is bounded by the schedule limits.
+func (c *RaftCluster) Simulate(req *SimulateRequest) (*SimulateResult, error) {
+	sim, err := c.newSimulateCluster(req)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	oc := schedule.NewOperatorController(ctx, sim, simulateHeartbeatStreams{})
+	// The running operators are copied because the simulated ones may
+	// replace them.
+	for _, op := range c.GetOperatorController().GetOperators() {
+		steps := make([]operator.OpStep, 0, op.Len())
+		for i := 0; i < op.Len(); i++ {
+			steps = append(steps, op.Step(i))
+		}
+		running := operator.NewOperator(op.Desc(), op.Brief(), op.RegionID(), op.RegionEpoch(), op.Kind(), steps...)
+		running.SetPriorityLevel(op.GetPriorityLevel())
+		oc.SetOperator(running)
+	}
+	schedulers, err := c.simulateSchedulers(oc, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var ops []*operator.Operator
+	checkers := schedule.NewCheckerController(ctx, sim, sim.ruleManager, oc)
+	for _, region := range sim.ScanRegions(nil, nil, simulateMaxCheckRegions) {
+		if oc.GetOperator(region.GetID()) != nil {
+			continue
+		}
+		checkerIsBusy, checkerOps := checkers.CheckRegion(region)
+		if checkerIsBusy {
+			break
+		}
+		if oc.AddWaitingOperator(checkerOps...) > 0 {
+			ops = append(ops, checkerOps...)
+		}
+	}
+
+	for _, s := range schedulers {
+		if err := s.Prepare(sim); err != nil {
+			return nil, err
+		}
+		for i := 0; i < simulateMaxRounds && s.IsScheduleAllowed(sim); i++ {
+			schedulerOps := s.Schedule(sim)
+			if len(schedulerOps) == 0 {
+				break
+			}
+			for _, op := range schedulerOps {
+				op.SetSource(s.GetName())
+			}
+			if oc.AddWaitingOperator(schedulerOps...) > 0 {
+				ops = append(ops, schedulerOps...)
+			}
+		}
+		s.Cleanup(sim)
+	}
+
+	// The operators rejected or replaced by the controller are dropped.
+	result := &SimulateResult{Operators: make([]*operator.Operator, 0, len(ops))}
+	for _, op := range ops {
+		if !op.IsEnd() {
+			result.Operators = append(result.Operators, op)
+		}
+	}
+	result.Stores = simulateScoreDeltas(sim, result.Operators)
+	return result, nil
+}
+
+// simulateSchedulers creates the schedulers to simulate with the simulated
+// operator controller, their configs are not saved.
+func (c *RaftCluster) simulateSchedulers(oc *schedule.OperatorController, req *SimulateRequest) ([]schedule.Scheduler, error) {
+	storage := core.NewStorage(kv.NewMemoryKV())
+	if req.Scheduler != "" {
+		typ := req.Scheduler
+		if !schedule.IsSchedulerRegistered(typ) {
+			typ = schedule.FindSchedulerTypeByName(typ)
+		}
+		if typ == "" {
+			return nil, errors.Wrapf(ErrInvalidSimulation, "unknown scheduler %s", req.Scheduler)
+		}
+		dec := schedule.ConfigSliceDecoder(typ, req.Args)
+		if len(req.Config) > 0 {
+			dec = schedule.ConfigJSONDecoder(req.Config)
+		}
+		s, err := schedule.CreateScheduler(typ, oc, storage, dec)
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidSimulation, "scheduler %s: %v", req.Scheduler, err)
+		}
+		return []schedule.Scheduler{s}, nil
+	}
+
+	// Recreate the running schedulers from their configs, so that the
+	// simulation does not change their states.
+	var running []schedule.Scheduler
+	c.RLock()
+	for _, sc := range c.coordinator.getSchedulers() {
+		if !sc.IsPaused() {
+			running = append(running, sc.Scheduler)
+		}
+	}
+	c.RUnlock()
+	sort.Slice(running, func(i, j int) bool { return running[i].GetName() < running[j].GetName() })
+	schedulers := make([]schedule.Scheduler, 0, len(running))
+	for _, r := range running {
+		data, err := r.EncodeConfig()
+		if err != nil {
+			return nil, err
+		}
+		s, err := schedule.CreateScheduler(r.GetType(), oc, storage, schedule.ConfigJSONDecoder(data))
+		if err != nil {
+			return nil, err
+		}
+		schedulers = append(schedulers, s)
+	}
+	return schedulers, nil
+}
+
+func simulateScoreDeltas(sim *simulateCluster, ops []*operator.Operator) []*StoreScoreDelta {
+	influence := schedule.NewTotalOpInfluence(ops, sim)
+	leaderKind := core.NewScheduleKind(core.LeaderKind, sim.GetLeaderSchedulePolicy())
+	highSpaceRatio, lowSpaceRatio := sim.GetHighSpaceRatio(), sim.GetLowSpaceRatio()
+	var deltas []*StoreScoreDelta
+	for _, store := range sim.GetStores() {
+		if store.IsTombstone() {
+			continue
+		}
+		si := influence.GetStoreInfluence(store.GetID())
+		leaderScore := store.LeaderScore(leaderKind.Policy, 0)
+		regionScore := store.RegionScore(highSpaceRatio, lowSpaceRatio, 0)
+		deltas = append(deltas, &StoreScoreDelta{
+			StoreID:          store.GetID(),
+			LeaderScore:      leaderScore,
+			LeaderScoreDelta: store.LeaderScore(leaderKind.Policy, si.ResourceProperty(leaderKind)) - leaderScore,
+			RegionScore:      regionScore,
+			RegionScoreDelta: store.RegionScore(highSpaceRatio, lowSpaceRatio, si.RegionSize) - regionScore,
+		})
+	}
+	sort.Slice(deltas, func(i, j int) bool { return deltas[i].StoreID < deltas[j].StoreID })
+	return deltas
+}
+
+// simulateCluster is a snapshot of the cluster for the simulation. It shares
+// the regions, the stores and the statistics with the cluster, the changed
+// configs and store weights only take effect on it, and the changes to the
+// stores are ignored.
+type simulateCluster struct {
+	*RaftCluster
+	// stores are the stores whose weights are changed.
+	stores map[uint64]*core.StoreInfo
+}
+
+func (c *RaftCluster) newSimulateCluster(req *SimulateRequest) (*simulateCluster, error) {
+	scheduleCfg := c.opt.LoadEffective().Clone()
+	if len(req.ScheduleConfig) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(req.ScheduleConfig))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(scheduleCfg); err != nil {
+			return nil, errors.Wrapf(ErrInvalidSimulation, "schedule config: %v", err)
+		}
+		if err := scheduleCfg.Validate(); err != nil {
+			return nil, errors.Wrapf(ErrInvalidSimulation, "schedule config: %v", err)
+		}
+	}
+
+	c.RLock()
+	defer c.RUnlock()
+	sim := &simulateCluster{
+		RaftCluster: &RaftCluster{
+			ctx:         c.ctx,
+			running:     true,
+			clusterID:   c.clusterID,
+			clusterRoot: c.clusterRoot,
+			core:        c.core,
+			meta:        c.meta,
+			opt: config.NewScheduleOption(&config.Config{
+				Schedule:       *scheduleCfg,
+				Replication:    *c.opt.GetReplication().Load(),
+				PDServerCfg:    *c.opt.LoadPDServerConfig(),
+				LabelProperty:  c.opt.LoadLabelPropertyConfig(),
+				ClusterVersion: *c.opt.LoadClusterVersion(),
+				Log:            *c.opt.LoadLogConfig(),
+			}),
+			storage: c.storage,
+			// The simulated operators should not take the real IDs.
+			id:              mockid.NewIDAllocator(),
+			labelLevelStats: c.labelLevelStats,
+			regionStats:     c.regionStats,
+			storesStats:     c.storesStats,
+			hotSpotCache:    c.hotSpotCache,
+			ruleManager:     c.ruleManager,
+			regionLabeler:   c.regionLabeler,
+		},
+		stores: make(map[uint64]*core.StoreInfo),
+	}
+	for id, w := range req.StoreWeights {
+		store := c.core.GetStore(id)
+		if store == nil {
+			return nil, errors.Wrapf(ErrInvalidSimulation, "store %d not found", id)
+		}
+		var opts []core.StoreCreateOption
+		if w.LeaderWeight != nil {
+			if *w.LeaderWeight < 0 {
+				return nil, errors.Wrapf(ErrInvalidSimulation, "the leader weight of store %d should be nonnegative", id)
+			}
+			opts = append(opts, core.SetLeaderWeight(*w.LeaderWeight))
+		}
+		if w.RegionWeight != nil {
+			if *w.RegionWeight < 0 {
+				return nil, errors.Wrapf(ErrInvalidSimulation, "the region weight of store %d should be nonnegative", id)
+			}
+			opts = append(opts, core.SetRegionWeight(*w.RegionWeight))
+		}
+		sim.stores[id] = store.Clone(opts...)
+	}
+	return sim, nil
+}
+
+func (s *simulateCluster) replace(stores []*core.StoreInfo) []*core.StoreInfo {
+	for i, store := range stores {
+		if changed, ok := s.stores[store.GetID()]; ok {
+			stores[i] = changed
+		}
+	}
+	return stores
+}
+
+// GetStores returns all stores with the changed weights.
+func (s *simulateCluster) GetStores() []*core.StoreInfo {
+	return s.replace(s.RaftCluster.GetStores())
+}
+
+// GetStore returns the store with the changed weights.
+func (s *simulateCluster) GetStore(storeID uint64) *core.StoreInfo {
+	if store, ok := s.stores[storeID]; ok {
+		return store
+	}
+	return s.RaftCluster.GetStore(storeID)
+}
+
+// GetRegionStores returns the stores of the region with the changed weights.
+func (s *simulateCluster) GetRegionStores(region *core.RegionInfo) []*core.StoreInfo {
+	return s.replace(s.RaftCluster.GetRegionStores(region))
+}
+
+// GetFollowerStores returns the follower stores of the region with the
+// changed weights.
+func (s *simulateCluster) GetFollowerStores(region *core.RegionInfo) []*core.StoreInfo {
+	return s.replace(s.RaftCluster.GetFollowerStores(region))
+}
+
+// GetLeaderStore returns the leader store of the region with the changed
+// weights.
+func (s *simulateCluster) GetLeaderStore(region *core.RegionInfo) *core.StoreInfo {
+	store := s.RaftCluster.GetLeaderStore(region)
+	if store == nil {
+		return nil
+	}
+	return s.GetStore(store.GetID())
+}
+
+// BlockStore is ignored in the simulation.
+func (s *simulateCluster) BlockStore(storeID uint64) error { return nil }
+
+// UnblockStore is ignored in the simulation.
+func (s *simulateCluster) UnblockStore(storeID uint64) {}
+
+// AttachAvailableFunc is ignored in the simulation, the store limits of the
+// simulated operator controller must not affect the cluster.
+func (s *simulateCluster) AttachAvailableFunc(storeID uint64, limitType storelimit.Type, f func() bool) {
+}
+
+// RemoveScheduler is ignored in the simulation.
+func (s *simulateCluster) RemoveScheduler(name string) error { return nil }
+
+// GetExplainRecorder returns nil, the simulated schedulers share the names
+// with the running ones but must not be explained as them.
+func (s *simulateCluster) GetExplainRecorder(name string) *explain.Recorder { return nil }
+
+// simulateHeartbeatStreams drops the messages of the simulated operators.
+type simulateHeartbeatStreams struct{}
+
+func (simulateHeartbeatStreams) SendMsg(region *core.RegionInfo, msg *pdpb.RegionHeartbeatResponse) {
+}
+
+func (simulateHeartbeatStreams) BindStream(storeID uint64, stream opt.HeartbeatStream) {}
+
+var _ opt.Cluster = (*simulateCluster)(nil)
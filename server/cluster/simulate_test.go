@@ -0,0 +1,105 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedulers"
+	"github.com/pkg/errors"
+)
+
+var _ = Suite(&testSimulateSuite{})
+
+type testSimulateSuite struct{}
+
+func (s *testSimulateSuite) TestSimulate(c *C) {
+	tc, co, cleanup := prepare(nil, nil, nil, c)
+	defer cleanup()
+	tc.coordinator = co
+
+	c.Assert(tc.addLeaderStore(1, 20), IsNil)
+	c.Assert(tc.addLeaderStore(2, 0), IsNil)
+	c.Assert(tc.addLeaderStore(3, 0), IsNil)
+	for i := uint64(1); i <= 20; i++ {
+		c.Assert(tc.addLeaderRegion(i, 1, 2, 3), IsNil)
+	}
+
+	res, err := tc.Simulate(&SimulateRequest{Scheduler: schedulers.BalanceLeaderName})
+	c.Assert(err, IsNil)
+	// The operators are bounded by the leader schedule limit.
+	c.Assert(res.Operators, HasLen, int(tc.GetLeaderScheduleLimit()))
+	for _, op := range res.Operators {
+		c.Assert(op.Source(), Equals, schedulers.BalanceLeaderName)
+		c.Assert(op.Step(0).(operator.TransferLeader).FromStore, Equals, uint64(1))
+	}
+	c.Assert(res.Stores, HasLen, 3)
+	c.Assert(res.Stores[0].StoreID, Equals, uint64(1))
+	c.Assert(res.Stores[0].LeaderScore, Equals, 20.0)
+	c.Assert(res.Stores[0].LeaderScoreDelta, Equals, -float64(len(res.Operators)))
+	c.Assert(res.Stores[1].LeaderScoreDelta+res.Stores[2].LeaderScoreDelta, Equals, float64(len(res.Operators)))
+	// Nothing is dispatched.
+	c.Assert(co.opController.GetOperators(), HasLen, 0)
+
+	// The configs only take effect on the simulation.
+	weight := 2.0
+	res, err = tc.Simulate(&SimulateRequest{
+		Scheduler:      schedulers.BalanceLeaderType,
+		ScheduleConfig: []byte(`{"leader-schedule-limit":1}`),
+		StoreWeights:   map[uint64]*SimulateStoreWeight{1: {LeaderWeight: &weight}},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(res.Operators, HasLen, 1)
+	c.Assert(res.Stores[0].LeaderScore, Equals, 10.0)
+	c.Assert(tc.GetLeaderScheduleLimit(), Not(Equals), uint64(1))
+	c.Assert(tc.GetStore(1).GetLeaderWeight(), Equals, 1.0)
+
+	// The running operators are taken into account.
+	op := newTestOperator(1, tc.GetRegion(1).GetRegionEpoch(), operator.OpLeader, operator.TransferLeader{FromStore: 1, ToStore: 2})
+	c.Assert(co.opController.AddOperator(op), IsTrue)
+	res, err = tc.Simulate(&SimulateRequest{Scheduler: schedulers.BalanceLeaderName})
+	c.Assert(err, IsNil)
+	c.Assert(res.Operators, HasLen, int(tc.GetLeaderScheduleLimit())-1)
+	c.Assert(op.Status(), Equals, operator.STARTED)
+
+	// The peers of the simulated operators do not take the real IDs.
+	c.Assert(tc.addRegionStore(5, 0), IsNil)
+	c.Assert(tc.addLeaderRegion(21, 1, 2), IsNil)
+	id, err := tc.id.Alloc()
+	c.Assert(err, IsNil)
+	res, err = tc.Simulate(&SimulateRequest{Scheduler: schedulers.BalanceLeaderName})
+	c.Assert(err, IsNil)
+	var addPeer bool
+	for _, op := range res.Operators {
+		if op.RegionID() == 21 {
+			addPeer = true
+		}
+	}
+	c.Assert(addPeer, IsTrue)
+	next, err := tc.id.Alloc()
+	c.Assert(err, IsNil)
+	c.Assert(next, Equals, id+1)
+
+	for _, req := range []*SimulateRequest{
+		{Scheduler: "foo"},
+		{Scheduler: schedulers.EvictLeaderType},
+		{ScheduleConfig: []byte(`{"foo":1}`)},
+		{ScheduleConfig: []byte(`{"low-space-ratio":2}`)},
+		{StoreWeights: map[uint64]*SimulateStoreWeight{4: {LeaderWeight: &weight}}},
+	} {
+		_, err = tc.Simulate(req)
+		c.Assert(errors.Cause(err), Equals, ErrInvalidSimulation, Commentf("%+v", req))
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/log"
 	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/storelimit"
 	"go.uber.org/zap"
 )
 
@@ -63,7 +64,9 @@ func (s *StoreLimiter) Collect(stats *pdpb.StoreStats) {
 	}
 
 	if rate > 0 {
-		s.oc.SetAllStoresLimitAuto(rate)
+		// The scenes describe how many snapshots the stores can receive under
+		// the load, so they only apply to adding peers.
+		s.oc.SetAllStoresLimitAuto(rate, storelimit.AddPeer)
 		log.Info("change store limit for cluster", zap.Stringer("state", state), zap.Float64("rate", rate))
 		s.current = state
 		collectClusterStateCurrent(state)
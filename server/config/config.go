@@ -79,6 +79,14 @@ type Config struct {
 
 	// TsoSaveInterval is the interval to save timestamp.
 	TsoSaveInterval typeutil.Duration `toml:"tso-save-interval" json:"tso-save-interval"`
+	// TsoMaxBatchSize is the max number of tso requests from different streams
+	// merged into one allocation. Merging is disabled if it is not greater
+	// than 1, which is the default.
+	TsoMaxBatchSize int `toml:"tso-max-batch-size" json:"tso-max-batch-size"`
+	// TsoBatchWaitDuration is the max time to wait for more tso requests
+	// before allocating for a non-full batch. 0 means only the requests that
+	// have already arrived are merged.
+	TsoBatchWaitDuration typeutil.Duration `toml:"tso-batch-wait-duration" json:"tso-batch-wait-duration"`
 
 	Metric metricutil.MetricConfig `toml:"metric" json:"metric"`
 
@@ -138,6 +146,16 @@ type Config struct {
 	Dashboard DashboardConfig `toml:"dashboard" json:"dashboard"`
 
 	ReplicateMode ReplicateModeConfig `toml:"replicate-mode" json:"replicate-mode"`
+
+	HeartbeatRecord HeartbeatRecordConfig `toml:"heartbeat-record" json:"heartbeat-record"`
+
+	OperatorHistory OperatorHistoryConfig `toml:"operator-history" json:"operator-history"`
+
+	HotRegionHistory HotRegionHistoryConfig `toml:"hot-region-history" json:"hot-region-history"`
+
+	Auth AuthConfig `toml:"auth" json:"auth"`
+
+	Audit AuditConfig `toml:"audit" json:"audit"`
 }
 
 // NewConfig creates a new config.
@@ -213,6 +231,17 @@ const (
 
 	defaultDRWaitStoreTimeout = time.Minute
 	defaultDRWaitSyncTimeout  = time.Minute
+
+	defaultHeartbeatRecordMaxFileSize = typeutil.ByteSize(64 * 1024 * 1024) // 64MB
+	defaultHeartbeatRecordMaxFiles    = 8
+
+	defaultOperatorHistoryRetention = 7 * 24 * time.Hour
+
+	defaultHotRegionHistoryInterval  = 10 * time.Minute
+	defaultHotRegionHistoryRetention = 7 * 24 * time.Hour
+
+	defaultAuditMaxSize       = 300 // MB
+	defaultAuditRecentRecords = 1000
 )
 
 var (
@@ -463,6 +492,14 @@ func (c *Config) Adjust(meta *toml.MetaData) error {
 
 	c.ReplicateMode.adjust(configMetaData.Child("replicate-mode"))
 
+	c.HeartbeatRecord.adjust(configMetaData.Child("heartbeat-record"))
+
+	c.OperatorHistory.adjust(configMetaData.Child("operator-history"))
+
+	c.HotRegionHistory.adjust(configMetaData.Child("hot-region-history"))
+
+	c.Audit.adjust(configMetaData.Child("audit"), c.DataDir)
+
 	return nil
 }
 
@@ -548,8 +585,25 @@ type ScheduleConfig struct {
 	// HighSpaceRatio is the highest usage ratio of store which regraded as high space.
 	// High space means there is a lot of spare capacity, and store region score varies directly with used size.
 	HighSpaceRatio float64 `toml:"high-space-ratio" json:"high-space-ratio"`
+	// DiskAlmostFullRatio is the usage ratio of the disk at which a store is
+	// regarded as almost full. An almost full store does not receive new peers
+	// from balancing, and its leaders are moved away.
+	DiskAlmostFullRatio float64 `toml:"disk-almost-full-ratio" json:"disk-almost-full-ratio"`
+	// DiskFullRatio is the usage ratio of the disk at which a store is regarded
+	// as full. A full store does not receive any new peer, and its leaders and
+	// regions are moved away.
+	DiskFullRatio float64 `toml:"disk-full-ratio" json:"disk-full-ratio"`
 	// SchedulerMaxWaitingOperator is the max coexist operators for each scheduler.
 	SchedulerMaxWaitingOperator uint64 `toml:"scheduler-max-waiting-operator" json:"scheduler-max-waiting-operator"`
+	// SchedulerWeights are the weights of the waiting queues of the schedulers.
+	// The waiting operators are promoted from the queues in proportion to their
+	// weights, the weight of a scheduler which is not set is 1. The operators
+	// created by checkers are queued by their descriptions, e.g. "make-up-replica".
+	SchedulerWeights map[string]float64 `toml:"scheduler-weights" json:"scheduler-weights"`
+	// SchedulerInflightQuotas are the max numbers of running operators of the
+	// schedulers. The waiting operators of a scheduler which runs out of its
+	// quota are not promoted. 0 or not set means no limit.
+	SchedulerInflightQuotas map[string]uint64 `toml:"scheduler-inflight-quotas" json:"scheduler-inflight-quotas"`
 	// WARN: DisableLearner is deprecated.
 	// DisableLearner is the option to disable using AddLearnerNode instead of AddNode.
 	DisableLearner bool `toml:"disable-raft-learner" json:"disable-raft-learner,string,omitempty"`
@@ -606,6 +660,20 @@ type ScheduleConfig struct {
 func (c *ScheduleConfig) Clone() *ScheduleConfig {
 	schedulers := make(SchedulerConfigs, len(c.Schedulers))
 	copy(schedulers, c.Schedulers)
+	var weights map[string]float64
+	if c.SchedulerWeights != nil {
+		weights = make(map[string]float64, len(c.SchedulerWeights))
+		for name, weight := range c.SchedulerWeights {
+			weights[name] = weight
+		}
+	}
+	var quotas map[string]uint64
+	if c.SchedulerInflightQuotas != nil {
+		quotas = make(map[string]uint64, len(c.SchedulerInflightQuotas))
+		for name, quota := range c.SchedulerInflightQuotas {
+			quotas[name] = quota
+		}
+	}
 	return &ScheduleConfig{
 		MaxSnapshotCount:             c.MaxSnapshotCount,
 		MaxPendingPeerCount:          c.MaxPendingPeerCount,
@@ -627,7 +695,11 @@ func (c *ScheduleConfig) Clone() *ScheduleConfig {
 		TolerantSizeRatio:            c.TolerantSizeRatio,
 		LowSpaceRatio:                c.LowSpaceRatio,
 		HighSpaceRatio:               c.HighSpaceRatio,
+		DiskAlmostFullRatio:          c.DiskAlmostFullRatio,
+		DiskFullRatio:                c.DiskFullRatio,
 		SchedulerMaxWaitingOperator:  c.SchedulerMaxWaitingOperator,
+		SchedulerWeights:             weights,
+		SchedulerInflightQuotas:      quotas,
 		DisableLearner:               c.DisableLearner,
 		DisableRemoveDownReplica:     c.DisableRemoveDownReplica,
 		DisableReplaceOfflineReplica: c.DisableReplaceOfflineReplica,
@@ -663,6 +735,8 @@ const (
 	defaultTolerantSizeRatio      = 0
 	defaultLowSpaceRatio          = 0.8
 	defaultHighSpaceRatio         = 0.7
+	defaultDiskAlmostFullRatio    = 0.9
+	defaultDiskFullRatio          = 0.95
 	// defaultHotRegionCacheHitsThreshold is the low hit number threshold of the
 	// hot region.
 	defaultHotRegionCacheHitsThreshold = 3
@@ -720,7 +794,15 @@ func (c *ScheduleConfig) adjust(meta *configMetaData) error {
 	adjustFloat64(&c.StoreBalanceRate, defaultStoreBalanceRate)
 	adjustFloat64(&c.LowSpaceRatio, defaultLowSpaceRatio)
 	adjustFloat64(&c.HighSpaceRatio, defaultHighSpaceRatio)
+	adjustFloat64(&c.DiskAlmostFullRatio, defaultDiskAlmostFullRatio)
+	adjustFloat64(&c.DiskFullRatio, defaultDiskFullRatio)
 	adjustSchedulers(&c.Schedulers, defaultSchedulers)
+	if c.SchedulerWeights == nil {
+		c.SchedulerWeights = make(map[string]float64)
+	}
+	if c.SchedulerInflightQuotas == nil {
+		c.SchedulerInflightQuotas = make(map[string]uint64)
+	}
 
 	for k, b := range c.migrateConfigurationMap() {
 		v, err := c.parseDeprecatedFlag(meta, k, *b[0], *b[1])
@@ -787,6 +869,20 @@ func (c *ScheduleConfig) Validate() error {
 	if c.LowSpaceRatio <= c.HighSpaceRatio {
 		return errors.New("low-space-ratio should be larger than high-space-ratio")
 	}
+	if c.DiskAlmostFullRatio <= 0 || c.DiskAlmostFullRatio > 1 {
+		return errors.New("disk-almost-full-ratio should between 0 and 1")
+	}
+	if c.DiskFullRatio <= 0 || c.DiskFullRatio > 1 {
+		return errors.New("disk-full-ratio should between 0 and 1")
+	}
+	if c.DiskFullRatio < c.DiskAlmostFullRatio {
+		return errors.New("disk-full-ratio should not be less than disk-almost-full-ratio")
+	}
+	for name, weight := range c.SchedulerWeights {
+		if weight <= 0 {
+			return errors.Errorf("the weight of scheduler %s should be positive", name)
+		}
+	}
 	for _, scheduleConfig := range c.Schedulers {
 		if !schedule.IsSchedulerRegistered(scheduleConfig.Type) {
 			return errors.Errorf("create func of %v is not registered, maybe misspelled", scheduleConfig.Type)
@@ -1153,6 +1249,98 @@ func (c *ReplicateModeConfig) adjust(meta *configMetaData) {
 	c.DRAutoSync.adjust(meta.Child("dr-autosync"))
 }
 
+// HeartbeatRecordConfig is the configuration for recording the heartbeats
+// received by the leader and the operators it creates, which can be replayed
+// by pd-replay.
+type HeartbeatRecordConfig struct {
+	// Dir is the directory of the recording files. Recording is disabled if
+	// it is empty.
+	Dir string `toml:"dir" json:"dir"`
+	// MaxFileSize is the size of a recording file to rotate at.
+	MaxFileSize typeutil.ByteSize `toml:"max-file-size" json:"max-file-size"`
+	// MaxFiles is the number of recording files to keep, the oldest ones are
+	// removed after rotation.
+	MaxFiles int `toml:"max-files" json:"max-files"`
+}
+
+func (c *HeartbeatRecordConfig) adjust(meta *configMetaData) {
+	if !meta.IsDefined("max-file-size") {
+		c.MaxFileSize = defaultHeartbeatRecordMaxFileSize
+	}
+	if !meta.IsDefined("max-files") {
+		c.MaxFiles = defaultHeartbeatRecordMaxFiles
+	}
+}
+
+// OperatorHistoryConfig is the configuration for saving the finished operators
+// in the data directory of the leader.
+type OperatorHistoryConfig struct {
+	// Retention is how long to keep the finished operators. The history is
+	// disabled if it is 0.
+	Retention typeutil.Duration `toml:"retention" json:"retention"`
+}
+
+func (c *OperatorHistoryConfig) adjust(meta *configMetaData) {
+	if !meta.IsDefined("retention") {
+		c.Retention = typeutil.NewDuration(defaultOperatorHistoryRetention)
+	}
+}
+
+// HotRegionHistoryConfig is the configuration for saving the snapshots of the
+// hot regions in the data directory of the leader.
+type HotRegionHistoryConfig struct {
+	// Interval is how often to save the snapshot of the hot regions.
+	Interval typeutil.Duration `toml:"interval" json:"interval"`
+	// Retention is how long to keep the snapshots. The history is disabled if
+	// it is 0.
+	Retention typeutil.Duration `toml:"retention" json:"retention"`
+}
+
+func (c *HotRegionHistoryConfig) adjust(meta *configMetaData) {
+	if !meta.IsDefined("interval") {
+		c.Interval = typeutil.NewDuration(defaultHotRegionHistoryInterval)
+	}
+	if !meta.IsDefined("retention") {
+		c.Retention = typeutil.NewDuration(defaultHotRegionHistoryRetention)
+	}
+}
+
+// AuthConfig is the configuration for authenticating and authorizing the
+// callers of the HTTP API and the gRPC service.
+type AuthConfig struct {
+	// Enable enables the authentication, everything is open to everyone if
+	// it is false.
+	Enable bool `toml:"enable" json:"enable"`
+	// TokenFile is the path of the JSON file of the static bearer tokens,
+	// such as [{"token": "xxx", "user": "alice", "role": "admin"}].
+	TokenFile string `toml:"token-file" json:"token-file"`
+	// CertRoles maps the common names of the client certificates to the
+	// roles. The roles are read-only, operator and admin.
+	CertRoles map[string]string `toml:"cert-roles" json:"cert-roles"`
+}
+
+// AuditConfig is the configuration for the audit log of the requests which
+// change the cluster.
+type AuditConfig struct {
+	Enable bool `toml:"enable" json:"enable"`
+	// File is the rotating audit log file, which is "audit.log" in the data
+	// directory by default.
+	File log.FileLogConfig `toml:"file" json:"file"`
+	// RecentRecords is the number of the recent records kept in memory to be
+	// queried by the API.
+	RecentRecords int `toml:"recent-records" json:"recent-records"`
+}
+
+func (c *AuditConfig) adjust(meta *configMetaData, dataDir string) {
+	adjustString(&c.File.Filename, filepath.Join(dataDir, "audit.log"))
+	if !meta.Child("file").IsDefined("max-size") {
+		c.File.MaxSize = defaultAuditMaxSize
+	}
+	if !meta.IsDefined("recent-records") {
+		c.RecentRecords = defaultAuditRecentRecords
+	}
+}
+
 // DRAutoSyncReplicateConfig is the configuration for auto sync mode between 2 data centers.
 type DRAutoSyncReplicateConfig struct {
 	LabelKey         string            `toml:"label-key" json:"label-key"`
@@ -103,10 +103,48 @@ func (s *testConfigSuite) TestValidation(c *C) {
 	c.Assert(cfg.QuotaBackendBytes, Equals, defaultQuotaBackendBytes)
 }
 
+func (s *testConfigSuite) TestScheduleProfile(c *C) {
+	opt, err := newTestScheduleOption()
+	c.Assert(err, IsNil)
+	base := opt.Load()
+
+	// Validate the config items.
+	p := &ScheduleProfile{Name: "day", Config: map[string]interface{}{"region-schedule-limit": 1, "max-store-down-time": "1h"}}
+	c.Assert(p.Validate(base), IsNil)
+	c.Assert((&ScheduleProfile{}).Validate(base), NotNil)
+	c.Assert((&ScheduleProfile{Name: "a", Config: map[string]interface{}{"unknown": 1}}).Validate(base), NotNil)
+	c.Assert((&ScheduleProfile{Name: "a", Config: map[string]interface{}{"schedulers-v2": nil}}).Validate(base), NotNil)
+	c.Assert((&ScheduleProfile{Name: "a", Config: map[string]interface{}{"region-schedule-limit": "x"}}).Validate(base), NotNil)
+	c.Assert((&ScheduleProfile{Name: "a", Config: map[string]interface{}{"low-space-ratio": 0.1}}).Validate(base), NotNil)
+
+	// The profile overrides the config while it is active.
+	p.PausedSchedulers = []string{"balance-region-scheduler"}
+	regionLimit := opt.GetRegionScheduleLimit()
+	opt.SetActiveProfile(p)
+	c.Assert(opt.GetRegionScheduleLimit(), Equals, uint64(1))
+	c.Assert(opt.GetMaxStoreDownTime(), Equals, time.Hour)
+	c.Assert(opt.Load().RegionScheduleLimit, Equals, regionLimit)
+	c.Assert(opt.IsSchedulerPausedByProfile("balance-region-scheduler"), IsTrue)
+	c.Assert(opt.IsSchedulerPausedByProfile("balance-leader-scheduler"), IsFalse)
+
+	// The changes of the config are applied under the profile.
+	cfg := opt.Load().Clone()
+	cfg.RegionScheduleLimit = 100
+	cfg.LeaderScheduleLimit = 100
+	opt.Store(cfg)
+	c.Assert(opt.GetRegionScheduleLimit(), Equals, uint64(1))
+	c.Assert(opt.GetLeaderScheduleLimit(), Equals, uint64(100))
+
+	opt.SetActiveProfile(nil)
+	c.Assert(opt.GetRegionScheduleLimit(), Equals, uint64(100))
+	c.Assert(opt.IsSchedulerPausedByProfile("balance-region-scheduler"), IsFalse)
+}
+
 func (s *testConfigSuite) TestAdjust(c *C) {
 	cfgData := `
 name = ""
 lease = 0
+tso-max-batch-size = 10000
 
 [pd-server]
 metric-storage = "http://127.0.0.1:9090"
@@ -131,10 +169,15 @@ leader-schedule-limit = 0
 	c.Assert(cfg.Schedule.MaxMergeRegionSize, Equals, uint64(0))
 	c.Assert(cfg.Schedule.EnableOneWayMerge, Equals, true)
 	c.Assert(cfg.Schedule.LeaderScheduleLimit, Equals, uint64(0))
+	c.Assert(cfg.TsoMaxBatchSize, Equals, 10000)
 	// When undefined, use default values.
 	c.Assert(cfg.PreVote, IsTrue)
+	c.Assert(cfg.TsoBatchWaitDuration.Duration, Equals, time.Duration(0))
 	c.Assert(cfg.Schedule.MaxMergeRegionKeys, Equals, uint64(defaultMaxMergeRegionKeys))
 	c.Assert(cfg.PDServerCfg.MetricStorage, Equals, "http://127.0.0.1:9090")
+	c.Assert(cfg.Audit.File.Filename, Equals, path.Join(cfg.DataDir, "audit.log"))
+	c.Assert(cfg.Audit.File.MaxSize, Equals, defaultAuditMaxSize)
+	c.Assert(cfg.Audit.RecentRecords, Equals, defaultAuditRecentRecords)
 
 	// Check undefined config fields
 	cfgData = `
@@ -16,6 +16,7 @@ package config
 import (
 	"context"
 	"reflect"
+	"sync"
 	"sync/atomic"
 	"time"
 	"unsafe"
@@ -27,11 +28,18 @@ import (
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/kv"
 	"github.com/pingcap/pd/v4/server/schedule"
+	"go.uber.org/zap"
 )
 
 // ScheduleOption is a wrapper to access the configuration safely.
 type ScheduleOption struct {
-	schedule       atomic.Value
+	schedule atomic.Value
+	// effective is the schedule config with the active profile applied, it
+	// is the same as schedule if no profile is active.
+	effective      atomic.Value
+	profile        atomic.Value
+	profileErr     atomic.Value
+	profileMu      sync.Mutex
 	replication    *Replication
 	labelProperty  atomic.Value
 	clusterVersion unsafe.Pointer
@@ -42,6 +50,8 @@ type ScheduleOption struct {
 // NewScheduleOption creates a new ScheduleOption.
 func NewScheduleOption(cfg *Config) *ScheduleOption {
 	o := &ScheduleOption{}
+	o.profile.Store((*ScheduleProfile)(nil))
+	o.profileErr.Store("")
 	o.Store(&cfg.Schedule)
 	o.replication = newReplication(&cfg.Replication)
 	o.pdServerConfig.Store(&cfg.PDServerCfg)
@@ -58,7 +68,68 @@ func (o *ScheduleOption) Load() *ScheduleConfig {
 
 // Store sets scheduling configurations.
 func (o *ScheduleOption) Store(cfg *ScheduleConfig) {
+	o.profileMu.Lock()
+	defer o.profileMu.Unlock()
 	o.schedule.Store(cfg)
+	o.applyProfile()
+}
+
+// LoadEffective returns the scheduling configurations with the active profile
+// applied, which are used to schedule.
+func (o *ScheduleOption) LoadEffective() *ScheduleConfig {
+	return o.effective.Load().(*ScheduleConfig)
+}
+
+// GetActiveProfile returns the active schedule profile, nil if no profile is
+// active.
+func (o *ScheduleOption) GetActiveProfile() *ScheduleProfile {
+	return o.profile.Load().(*ScheduleProfile)
+}
+
+// SetActiveProfile activates a schedule profile, or deactivates the active
+// profile if it is nil.
+func (o *ScheduleOption) SetActiveProfile(p *ScheduleProfile) {
+	o.profileMu.Lock()
+	defer o.profileMu.Unlock()
+	o.profile.Store(p)
+	o.applyProfile()
+}
+
+// GetProfileApplyError returns why the active profile fails to be applied to
+// the schedule config, it is empty if the profile is applied or no profile is
+// active.
+func (o *ScheduleOption) GetProfileApplyError() string {
+	return o.profileErr.Load().(string)
+}
+
+// IsSchedulerPausedByProfile returns if the scheduler is paused by the active
+// profile.
+func (o *ScheduleOption) IsSchedulerPausedByProfile(name string) bool {
+	p := o.GetActiveProfile()
+	return p != nil && p.IsSchedulerPaused(name)
+}
+
+func (o *ScheduleOption) applyProfile() {
+	cfg := o.Load()
+	p := o.GetActiveProfile()
+	if p == nil {
+		o.effective.Store(cfg)
+		o.profileErr.Store("")
+		return
+	}
+	c, err := p.ApplyTo(cfg)
+	if err == nil {
+		err = c.Validate()
+	}
+	if err != nil {
+		// The schedule config may be changed after the profile is saved.
+		log.Error("failed to apply schedule profile", zap.String("profile", p.Name), zap.Error(err))
+		o.effective.Store(cfg)
+		o.profileErr.Store(err.Error())
+		return
+	}
+	o.effective.Store(c)
+	o.profileErr.Store("")
 }
 
 // GetReplication returns replication configurations.
@@ -108,27 +179,27 @@ func (o *ScheduleOption) IsPlacementRulesEnabled() bool {
 
 // GetMaxSnapshotCount returns the number of the max snapshot which is allowed to send.
 func (o *ScheduleOption) GetMaxSnapshotCount() uint64 {
-	return o.Load().MaxSnapshotCount
+	return o.LoadEffective().MaxSnapshotCount
 }
 
 // GetMaxPendingPeerCount returns the number of the max pending peers.
 func (o *ScheduleOption) GetMaxPendingPeerCount() uint64 {
-	return o.Load().MaxPendingPeerCount
+	return o.LoadEffective().MaxPendingPeerCount
 }
 
 // GetMaxMergeRegionSize returns the max region size.
 func (o *ScheduleOption) GetMaxMergeRegionSize() uint64 {
-	return o.Load().MaxMergeRegionSize
+	return o.LoadEffective().MaxMergeRegionSize
 }
 
 // GetMaxMergeRegionKeys returns the max number of keys.
 func (o *ScheduleOption) GetMaxMergeRegionKeys() uint64 {
-	return o.Load().MaxMergeRegionKeys
+	return o.LoadEffective().MaxMergeRegionKeys
 }
 
 // GetSplitMergeInterval returns the interval between finishing split and starting to merge.
 func (o *ScheduleOption) GetSplitMergeInterval() time.Duration {
-	return o.Load().SplitMergeInterval.Duration
+	return o.LoadEffective().SplitMergeInterval.Duration
 }
 
 // SetSplitMergeInterval to set the interval between finishing split and starting to merge. It's only used to test.
@@ -138,77 +209,101 @@ func (o *ScheduleOption) SetSplitMergeInterval(splitMergeInterval time.Duration)
 
 // IsOneWayMergeEnabled returns if a region can only be merged into the next region of it.
 func (o *ScheduleOption) IsOneWayMergeEnabled() bool {
-	return o.Load().EnableOneWayMerge
+	return o.LoadEffective().EnableOneWayMerge
 }
 
 // IsCrossTableMergeEnabled returns if across table merge is enabled.
 func (o *ScheduleOption) IsCrossTableMergeEnabled() bool {
-	return o.Load().EnableCrossTableMerge
+	return o.LoadEffective().EnableCrossTableMerge
 }
 
 // GetPatrolRegionInterval returns the interval of patroling region.
 func (o *ScheduleOption) GetPatrolRegionInterval() time.Duration {
-	return o.Load().PatrolRegionInterval.Duration
+	return o.LoadEffective().PatrolRegionInterval.Duration
 }
 
 // GetMaxStoreDownTime returns the max down time of a store.
 func (o *ScheduleOption) GetMaxStoreDownTime() time.Duration {
-	return o.Load().MaxStoreDownTime.Duration
+	return o.LoadEffective().MaxStoreDownTime.Duration
 }
 
 // GetLeaderScheduleLimit returns the limit for leader schedule.
 func (o *ScheduleOption) GetLeaderScheduleLimit() uint64 {
-	return o.Load().LeaderScheduleLimit
+	return o.LoadEffective().LeaderScheduleLimit
 }
 
 // GetRegionScheduleLimit returns the limit for region schedule.
 func (o *ScheduleOption) GetRegionScheduleLimit() uint64 {
-	return o.Load().RegionScheduleLimit
+	return o.LoadEffective().RegionScheduleLimit
 }
 
 // GetReplicaScheduleLimit returns the limit for replica schedule.
 func (o *ScheduleOption) GetReplicaScheduleLimit() uint64 {
-	return o.Load().ReplicaScheduleLimit
+	return o.LoadEffective().ReplicaScheduleLimit
 }
 
 // GetMergeScheduleLimit returns the limit for merge schedule.
 func (o *ScheduleOption) GetMergeScheduleLimit() uint64 {
-	return o.Load().MergeScheduleLimit
+	return o.LoadEffective().MergeScheduleLimit
 }
 
 // GetHotRegionScheduleLimit returns the limit for hot region schedule.
 func (o *ScheduleOption) GetHotRegionScheduleLimit() uint64 {
-	return o.Load().HotRegionScheduleLimit
+	return o.LoadEffective().HotRegionScheduleLimit
 }
 
 // GetStoreBalanceRate returns the balance rate of a store.
 func (o *ScheduleOption) GetStoreBalanceRate() float64 {
-	return o.Load().StoreBalanceRate
+	return o.LoadEffective().StoreBalanceRate
 }
 
 // GetTolerantSizeRatio gets the tolerant size ratio.
 func (o *ScheduleOption) GetTolerantSizeRatio() float64 {
-	return o.Load().TolerantSizeRatio
+	return o.LoadEffective().TolerantSizeRatio
 }
 
 // GetLowSpaceRatio returns the low space ratio.
 func (o *ScheduleOption) GetLowSpaceRatio() float64 {
-	return o.Load().LowSpaceRatio
+	return o.LoadEffective().LowSpaceRatio
 }
 
 // GetHighSpaceRatio returns the high space ratio.
 func (o *ScheduleOption) GetHighSpaceRatio() float64 {
-	return o.Load().HighSpaceRatio
+	return o.LoadEffective().HighSpaceRatio
+}
+
+// GetDiskAlmostFullRatio returns the disk usage ratio of an almost full store.
+func (o *ScheduleOption) GetDiskAlmostFullRatio() float64 {
+	return o.LoadEffective().DiskAlmostFullRatio
+}
+
+// GetDiskFullRatio returns the disk usage ratio of a full store.
+func (o *ScheduleOption) GetDiskFullRatio() float64 {
+	return o.LoadEffective().DiskFullRatio
 }
 
 // GetSchedulerMaxWaitingOperator returns the number of the max waiting operators.
 func (o *ScheduleOption) GetSchedulerMaxWaitingOperator() uint64 {
-	return o.Load().SchedulerMaxWaitingOperator
+	return o.LoadEffective().SchedulerMaxWaitingOperator
+}
+
+// GetSchedulerWeight returns the weight of the waiting queue of the scheduler.
+func (o *ScheduleOption) GetSchedulerWeight(name string) float64 {
+	if weight, ok := o.LoadEffective().SchedulerWeights[name]; ok {
+		return weight
+	}
+	return 1
+}
+
+// GetSchedulerInflightQuota returns the max number of running operators of the
+// scheduler, 0 means no limit.
+func (o *ScheduleOption) GetSchedulerInflightQuota(name string) uint64 {
+	return o.LoadEffective().SchedulerInflightQuotas[name]
 }
 
 // GetLeaderSchedulePolicy is to get leader schedule policy.
 func (o *ScheduleOption) GetLeaderSchedulePolicy() core.SchedulePolicy {
-	return core.StringToSchedulePolicy(o.Load().LeaderSchedulePolicy)
+	return core.StringToSchedulePolicy(o.LoadEffective().LeaderSchedulePolicy)
 }
 
 // GetKeyType is to get key type.
@@ -223,32 +318,32 @@ func (o *ScheduleOption) GetDashboardAddress() string {
 
 // IsRemoveDownReplicaEnabled returns if remove down replica is enabled.
 func (o *ScheduleOption) IsRemoveDownReplicaEnabled() bool {
-	return o.Load().EnableRemoveDownReplica
+	return o.LoadEffective().EnableRemoveDownReplica
 }
 
 // IsReplaceOfflineReplicaEnabled returns if replace offline replica is enabled.
 func (o *ScheduleOption) IsReplaceOfflineReplicaEnabled() bool {
-	return o.Load().EnableReplaceOfflineReplica
+	return o.LoadEffective().EnableReplaceOfflineReplica
 }
 
 // IsMakeUpReplicaEnabled returns if make up replica is enabled.
 func (o *ScheduleOption) IsMakeUpReplicaEnabled() bool {
-	return o.Load().EnableMakeUpReplica
+	return o.LoadEffective().EnableMakeUpReplica
 }
 
 // IsRemoveExtraReplicaEnabled returns if remove extra replica is enabled.
 func (o *ScheduleOption) IsRemoveExtraReplicaEnabled() bool {
-	return o.Load().EnableRemoveExtraReplica
+	return o.LoadEffective().EnableRemoveExtraReplica
 }
 
 // IsLocationReplacementEnabled returns if location replace is enabled.
 func (o *ScheduleOption) IsLocationReplacementEnabled() bool {
-	return o.Load().EnableLocationReplacement
+	return o.LoadEffective().EnableLocationReplacement
 }
 
 // IsDebugMetricsEnabled mocks method
 func (o *ScheduleOption) IsDebugMetricsEnabled() bool {
-	return o.Load().EnableDebugMetrics
+	return o.LoadEffective().EnableDebugMetrics
 }
 
 // GetSchedulers gets the scheduler configurations.
@@ -439,7 +534,7 @@ func (o *ScheduleOption) adjustScheduleCfg(persistentCfg *Config) {
 
 // GetHotRegionCacheHitsThreshold is a threshold to decide if a region is hot.
 func (o *ScheduleOption) GetHotRegionCacheHitsThreshold() int {
-	return int(o.Load().HotRegionCacheHitsThreshold)
+	return int(o.LoadEffective().HotRegionCacheHitsThreshold)
 }
 
 // CheckLabelProperty checks the label property.
@@ -0,0 +1,102 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ScheduleProfile is a named set of scheduling configurations, which overrides
+// the schedule config while it is active.
+type ScheduleProfile struct {
+	Name string `json:"name"`
+	// Config is a part of the schedule config in the same format as the
+	// schedule config API, such as {"region-schedule-limit": 2}.
+	Config map[string]interface{} `json:"config,omitempty"`
+	// PausedSchedulers are the names of the schedulers paused while the
+	// profile is active.
+	PausedSchedulers []string `json:"paused-schedulers,omitempty"`
+}
+
+// The schedulers are added and removed by the scheduler API, so a profile can
+// only pause them.
+var unprofilableScheduleConfigs = map[string]struct{}{
+	"schedulers-v2":      {},
+	"schedulers-payload": {},
+}
+
+// Validate checks if the profile can be applied to the schedule config.
+func (p *ScheduleProfile) Validate(cfg *ScheduleConfig) error {
+	if p.Name == "" {
+		return errors.New("profile name is empty")
+	}
+	fields := make(map[string]struct{})
+	t := reflect.TypeOf(ScheduleConfig{})
+	for i := 0; i < t.NumField(); i++ {
+		jsonTag := t.Field(i).Tag.Get("json")
+		if i := strings.Index(jsonTag, ","); i != -1 {
+			jsonTag = jsonTag[:i]
+		}
+		fields[jsonTag] = struct{}{}
+	}
+	for k := range p.Config {
+		if _, ok := fields[k]; !ok {
+			return errors.Errorf("config item %s not found", k)
+		}
+		if _, ok := unprofilableScheduleConfigs[k]; ok {
+			return errors.Errorf("config item %s can not be set by a profile", k)
+		}
+	}
+	for _, name := range p.PausedSchedulers {
+		if name == "" {
+			return errors.New("scheduler name is empty")
+		}
+	}
+	c, err := p.ApplyTo(cfg)
+	if err != nil {
+		return err
+	}
+	return c.Validate()
+}
+
+// ApplyTo returns a copy of the schedule config with the profile applied.
+func (p *ScheduleProfile) ApplyTo(cfg *ScheduleConfig) (*ScheduleConfig, error) {
+	c := cfg.Clone()
+	if len(p.Config) == 0 {
+		return c, nil
+	}
+	data, err := json.Marshal(p.Config)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return c, nil
+}
+
+// IsSchedulerPaused returns if the scheduler is paused by the profile.
+func (p *ScheduleProfile) IsSchedulerPaused(name string) bool {
+	for _, s := range p.PausedSchedulers {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
@@ -16,6 +16,7 @@ package core
 import (
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/kvproto/pkg/replicate_mode"
 )
 
 // RegionOption is used to select region.
@@ -195,6 +196,13 @@ func SetReportInterval(v uint64) RegionCreateOption {
 	}
 }
 
+// SetReplicateStatus sets the replicate status for the region.
+func SetReplicateStatus(status *replicate_mode.RegionReplicateStatus) RegionCreateOption {
+	return func(region *RegionInfo) {
+		region.replicateStatus = status
+	}
+}
+
 // SetRegionConfVer sets the config version for the reigon.
 func SetRegionConfVer(confVer uint64) RegionCreateOption {
 	return func(region *RegionInfo) {
@@ -15,6 +15,7 @@ package core
 
 import (
 	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -23,24 +24,33 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/gogo/protobuf/proto"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/pd/v4/server/kv"
+	"github.com/pingcap/pd/v4/server/schedule/storelimit"
 	"github.com/pkg/errors"
 	"go.etcd.io/etcd/clientv3"
 )
 
 const (
-	clusterPath   = "raft"
-	configPath    = "config"
-	schedulePath  = "schedule"
-	gcPath        = "gc"
-	rulesPath     = "rules"
-	replicatePath = "replicate"
+	clusterPath     = "raft"
+	configPath      = "config"
+	schedulePath    = "schedule"
+	gcPath          = "gc"
+	rulesPath       = "rules"
+	ruleGroupPath   = "rule_group"
+	regionLabelPath = "region_label"
+	replicatePath   = "replicate"
+
+	scheduleProfilePath         = "schedule_profile"
+	scheduleProfileCalendarPath = "schedule_profile_calendar"
+	scatterGroupsPath           = "scatter_groups"
 
 	customScheduleConfigPath = "scheduler_config"
+	schedulerPausePath       = "scheduler_pause"
 	componentsConfigPath     = "components_config"
 )
 
@@ -107,6 +117,14 @@ func (s *Storage) storeRegionWeightPath(storeID uint64) string {
 	return path.Join(schedulePath, "store_weight", fmt.Sprintf("%020d", storeID), "region")
 }
 
+func (s *Storage) storeLimitPath(storeID uint64, limitType storelimit.Type) string {
+	return path.Join(schedulePath, "store_limit", fmt.Sprintf("%020d", storeID), limitType.String())
+}
+
+func (s *Storage) defaultStoreLimitPath(limitType storelimit.Type) string {
+	return path.Join(schedulePath, "default_store_limit", limitType.String())
+}
+
 // SaveScheduleConfig saves the config of scheduler.
 func (s *Storage) SaveScheduleConfig(scheduleName string, data []byte) error {
 	configPath := path.Join(customScheduleConfigPath, scheduleName)
@@ -125,6 +143,35 @@ func (s *Storage) LoadScheduleConfig(scheduleName string) (string, error) {
 	return s.Load(configPath)
 }
 
+// SaveSchedulerPause saves the pause state of a scheduler.
+func (s *Storage) SaveSchedulerPause(scheduleName string, pause interface{}) error {
+	value, err := json.Marshal(pause)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return s.Save(path.Join(schedulerPausePath, scheduleName), string(value))
+}
+
+// LoadSchedulerPause loads the pause state of a scheduler.
+func (s *Storage) LoadSchedulerPause(scheduleName string, pause interface{}) (bool, error) {
+	v, err := s.Load(path.Join(schedulerPausePath, scheduleName))
+	if err != nil {
+		return false, err
+	}
+	if v == "" {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(v), pause); err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+// RemoveSchedulerPause removes the pause state of a scheduler.
+func (s *Storage) RemoveSchedulerPause(scheduleName string) error {
+	return s.Remove(path.Join(schedulerPausePath, scheduleName))
+}
+
 // LoadMeta loads cluster meta from storage.
 func (s *Storage) LoadMeta(meta *metapb.Cluster) (bool, error) {
 	return loadProto(s.Base, clusterPath, meta)
@@ -239,10 +286,174 @@ func (s *Storage) DeleteRule(ruleKey string) error {
 
 // LoadRules loads placement rules from storage.
 func (s *Storage) LoadRules(f func(k, v string)) (bool, error) {
-	// Range is ['rule/\x00', 'rule0'). 'rule0' is the upper bound of all rules because '0' is next char of '/' in
+	return s.loadRangeByPrefix(rulesPath, f)
+}
+
+// SaveRuleGroup stores a rule group config to storage.
+func (s *Storage) SaveRuleGroup(groupID string, group interface{}) error {
+	value, err := json.Marshal(group)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return s.Save(path.Join(ruleGroupPath, groupID), string(value))
+}
+
+// DeleteRuleGroup removes a rule group from storage.
+func (s *Storage) DeleteRuleGroup(groupID string) error {
+	return s.Base.Remove(path.Join(ruleGroupPath, groupID))
+}
+
+// LoadRuleGroups loads all rule groups from storage.
+func (s *Storage) LoadRuleGroups(f func(k, v string)) (bool, error) {
+	return s.loadRangeByPrefix(ruleGroupPath, f)
+}
+
+// SaveRegionLabelRule stores a region label rule to storage.
+func (s *Storage) SaveRegionLabelRule(ruleKey string, rule interface{}) error {
+	value, err := json.Marshal(rule)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return s.Save(path.Join(regionLabelPath, ruleKey), string(value))
+}
+
+// DeleteRegionLabelRule removes a region label rule from storage.
+func (s *Storage) DeleteRegionLabelRule(ruleKey string) error {
+	return s.Base.Remove(path.Join(regionLabelPath, ruleKey))
+}
+
+// LoadRegionLabelRules loads all region label rules from storage.
+func (s *Storage) LoadRegionLabelRules(f func(k, v string)) (bool, error) {
+	return s.loadRangeByPrefix(regionLabelPath, f)
+}
+
+// SaveScheduleProfile stores a schedule profile to storage.
+func (s *Storage) SaveScheduleProfile(profileKey string, profile interface{}) error {
+	value, err := json.Marshal(profile)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return s.Save(path.Join(scheduleProfilePath, profileKey), string(value))
+}
+
+// DeleteScheduleProfile removes a schedule profile from storage.
+func (s *Storage) DeleteScheduleProfile(profileKey string) error {
+	return s.Base.Remove(path.Join(scheduleProfilePath, profileKey))
+}
+
+// LoadScheduleProfiles loads all schedule profiles from storage.
+func (s *Storage) LoadScheduleProfiles(f func(k, v string)) (bool, error) {
+	return s.loadRangeByPrefix(scheduleProfilePath, f)
+}
+
+// SaveScheduleProfileCalendar stores the calendar of schedule profiles.
+func (s *Storage) SaveScheduleProfileCalendar(calendar interface{}) error {
+	value, err := json.Marshal(calendar)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return s.Save(scheduleProfileCalendarPath, string(value))
+}
+
+// LoadScheduleProfileCalendar loads the calendar of schedule profiles.
+func (s *Storage) LoadScheduleProfileCalendar(calendar interface{}) (bool, error) {
+	v, err := s.Load(scheduleProfileCalendarPath)
+	if err != nil {
+		return false, err
+	}
+	if v == "" {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(v), calendar); err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+// SaveScatterGroups stores the counts of the scatter groups.
+func (s *Storage) SaveScatterGroups(groups interface{}) error {
+	value, err := json.Marshal(groups)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return s.Save(scatterGroupsPath, string(value))
+}
+
+// LoadScatterGroups loads the counts of the scatter groups.
+func (s *Storage) LoadScatterGroups(groups interface{}) (bool, error) {
+	v, err := s.Load(scatterGroupsPath)
+	if err != nil {
+		return false, err
+	}
+	if v == "" {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(v), groups); err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+// MaxRuleBatchSize is the max number of changes in a RuleBatch, which is the
+// default limit of etcd on the operations in a transaction.
+const MaxRuleBatchSize = 128
+
+// RuleBatch collects changes of placement rules and rule groups, which are
+// committed to storage in one transaction by SaveRuleBatch.
+type RuleBatch struct {
+	ops []kv.Op
+}
+
+// SaveRule adds a rule saving to the batch.
+func (b *RuleBatch) SaveRule(ruleKey string, rule interface{}) error {
+	value, err := json.Marshal(rule)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	b.ops = append(b.ops, kv.Op{Key: path.Join(rulesPath, ruleKey), Value: string(value)})
+	return nil
+}
+
+// DeleteRule adds a rule removal to the batch.
+func (b *RuleBatch) DeleteRule(ruleKey string) {
+	b.ops = append(b.ops, kv.Op{Key: path.Join(rulesPath, ruleKey), Remove: true})
+}
+
+// SaveRuleGroup adds a rule group saving to the batch.
+func (b *RuleBatch) SaveRuleGroup(groupID string, group interface{}) error {
+	value, err := json.Marshal(group)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	b.ops = append(b.ops, kv.Op{Key: path.Join(ruleGroupPath, groupID), Value: string(value)})
+	return nil
+}
+
+// DeleteRuleGroup adds a rule group removal to the batch.
+func (b *RuleBatch) DeleteRuleGroup(groupID string) {
+	b.ops = append(b.ops, kv.Op{Key: path.Join(ruleGroupPath, groupID), Remove: true})
+}
+
+// Len returns the number of changes in the batch.
+func (b *RuleBatch) Len() int {
+	return len(b.ops)
+}
+
+// SaveRuleBatch commits all changes in the batch atomically.
+func (s *Storage) SaveRuleBatch(b *RuleBatch) error {
+	if len(b.ops) == 0 {
+		return nil
+	}
+	return s.SaveBatch(b.ops)
+}
+
+// loadRangeByPrefix iterates all key-value pairs under the prefix, the
+// prefix is trimmed from keys passed to f.
+func (s *Storage) loadRangeByPrefix(prefix string, f func(k, v string)) (bool, error) {
+	// Range is ['prefix/\x00', 'prefix0'). 'prefix0' is the upper bound of all keys because '0' is next char of '/' in
 	// ascii order.
-	nextKey := path.Join(rulesPath, "\x00")
-	endKey := rulesPath + "0"
+	nextKey := path.Join(prefix, "\x00")
+	endKey := prefix + "0"
 	for {
 		keys, values, err := s.LoadRange(nextKey, endKey, minKVRangeLimit)
 		if err != nil {
@@ -252,7 +463,7 @@ func (s *Storage) LoadRules(f func(k, v string)) (bool, error) {
 			return false, nil
 		}
 		for i := range keys {
-			f(strings.TrimPrefix(keys[i], rulesPath+"/"), values[i])
+			f(strings.TrimPrefix(keys[i], prefix+"/"), values[i])
 		}
 		if len(keys) < minKVRangeLimit {
 			return true, nil
@@ -330,6 +541,83 @@ func (s *Storage) SaveStoreWeight(storeID uint64, leader, region float64) error
 	return s.Save(s.storeRegionWeightPath(storeID), regionValue)
 }
 
+// SaveStoreLimit saves the rate of a store limit set by the user to storage.
+func (s *Storage) SaveStoreLimit(storeID uint64, limitType storelimit.Type, rate float64) error {
+	return s.Save(s.storeLimitPath(storeID, limitType), strconv.FormatFloat(rate, 'f', -1, 64))
+}
+
+// DeleteStoreLimit removes all the limits of a store from storage.
+func (s *Storage) DeleteStoreLimit(storeID uint64) error {
+	for _, limitType := range storelimit.Types() {
+		if err := s.Remove(s.storeLimitPath(storeID, limitType)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveDefaultStoreLimit saves the rate of the limit for the stores which have
+// no limit of their own.
+func (s *Storage) SaveDefaultStoreLimit(limitType storelimit.Type, rate float64) error {
+	return s.Save(s.defaultStoreLimitPath(limitType), strconv.FormatFloat(rate, 'f', -1, 64))
+}
+
+// LoadDefaultStoreLimits loads the default store limits set by the user from
+// storage.
+func (s *Storage) LoadDefaultStoreLimits(f func(limitType storelimit.Type, rate float64)) error {
+	for _, limitType := range storelimit.Types() {
+		value, err := s.Load(s.defaultStoreLimitPath(limitType))
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			continue
+		}
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		f(limitType, rate)
+	}
+	return nil
+}
+
+// LoadStoreLimits loads all the store limits set by the user from storage.
+func (s *Storage) LoadStoreLimits(f func(storeID uint64, limitType storelimit.Type, rate float64)) error {
+	var err error
+	_, loadErr := s.loadRangeByPrefix(path.Join(schedulePath, "store_limit"), func(k, v string) {
+		if err != nil {
+			return
+		}
+		// The key is in the form of "{store-id}/{limit-type}".
+		parts := strings.Split(k, "/")
+		if len(parts) != 2 {
+			err = errors.Errorf("invalid store limit key %s", k)
+			return
+		}
+		storeID, e := strconv.ParseUint(parts[0], 10, 64)
+		if e != nil {
+			err = errors.WithStack(e)
+			return
+		}
+		limitType, ok := storelimit.ParseType(parts[1])
+		if !ok {
+			err = errors.Errorf("unknown store limit type %s", parts[1])
+			return
+		}
+		rate, e := strconv.ParseFloat(v, 64)
+		if e != nil {
+			err = errors.WithStack(e)
+			return
+		}
+		f(storeID, limitType, rate)
+	})
+	if loadErr != nil {
+		return loadErr
+	}
+	return err
+}
+
 func (s *Storage) loadFloatWithDefaultValue(path string, def float64) (float64, error) {
 	res, err := s.Load(path)
 	if err != nil {
@@ -385,6 +673,105 @@ func (s *Storage) LoadGCSafePoint() (uint64, error) {
 	return safePoint, nil
 }
 
+// ServiceSafePoint is the safepoint for a specific service.
+type ServiceSafePoint struct {
+	ServiceID string `json:"service_id"`
+	ExpiredAt int64  `json:"expired_at"`
+	SafePoint uint64 `json:"safe_point"`
+}
+
+// gcSafePointServicePath returns the key of the safepoint of the service, the
+// service ID is encoded so it cannot escape from the prefix.
+func gcSafePointServicePath(serviceID string) string {
+	return path.Join(gcPath, "safe_point", "service", hex.EncodeToString([]byte(serviceID)))
+}
+
+// SaveServiceGCSafePoint saves a GC safepoint for the service.
+func (s *Storage) SaveServiceGCSafePoint(ssp *ServiceSafePoint) error {
+	if ssp.ServiceID == "" {
+		return errors.New("service id of service safepoint cannot be empty")
+	}
+	value, err := json.Marshal(ssp)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return s.Save(gcSafePointServicePath(ssp.ServiceID), string(value))
+}
+
+// LoadServiceGCSafePoint loads the GC safepoint of the service. It returns
+// nil if the service has no safepoint.
+func (s *Storage) LoadServiceGCSafePoint(serviceID string) (*ServiceSafePoint, error) {
+	value, err := s.Load(gcSafePointServicePath(serviceID))
+	if err != nil || value == "" {
+		return nil, err
+	}
+	ssp := &ServiceSafePoint{}
+	if err := json.Unmarshal([]byte(value), ssp); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return ssp, nil
+}
+
+// RemoveServiceGCSafePoint removes a GC safepoint for the service.
+func (s *Storage) RemoveServiceGCSafePoint(serviceID string) error {
+	return s.Remove(gcSafePointServicePath(serviceID))
+}
+
+// LoadAllServiceGCSafePoints loads the GC safepoints of all services.
+func (s *Storage) LoadAllServiceGCSafePoints() ([]*ServiceSafePoint, error) {
+	prefix := gcSafePointServicePath("") + "/"
+	prefixEnd := clientv3.GetPrefixRangeEnd(prefix)
+	_, values, err := s.LoadRange(prefix, prefixEnd, maxKVRangeLimit)
+	if err != nil {
+		return nil, err
+	}
+	ssps := make([]*ServiceSafePoint, 0, len(values))
+	for _, value := range values {
+		ssp := &ServiceSafePoint{}
+		if err := json.Unmarshal([]byte(value), ssp); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		ssps = append(ssps, ssp)
+	}
+	return ssps, nil
+}
+
+// LoadMinServiceGCSafePoint returns the minimum safepoint across all alive
+// services. It returns nil if no service is alive.
+func (s *Storage) LoadMinServiceGCSafePoint(now time.Time) (*ServiceSafePoint, error) {
+	ssps, err := s.LoadAllServiceGCSafePoints()
+	if err != nil {
+		return nil, err
+	}
+	var min *ServiceSafePoint
+	for _, ssp := range ssps {
+		if ssp.ExpiredAt < now.Unix() {
+			continue
+		}
+		if min == nil || ssp.SafePoint < min.SafePoint {
+			min = ssp
+		}
+	}
+	return min, nil
+}
+
+// RemoveExpiredServiceGCSafePoints removes the safepoints of the services
+// which are expired.
+func (s *Storage) RemoveExpiredServiceGCSafePoints(now time.Time) error {
+	ssps, err := s.LoadAllServiceGCSafePoints()
+	if err != nil {
+		return err
+	}
+	for _, ssp := range ssps {
+		if ssp.ExpiredAt < now.Unix() {
+			if err := s.RemoveServiceGCSafePoint(ssp.ServiceID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // LoadAllScheduleConfig loads all schedulers' config.
 func (s *Storage) LoadAllScheduleConfig() ([]string, []string, error) {
 	keys, values, err := s.LoadRange(customScheduleConfigPath, clientv3.GetPrefixRangeEnd(customScheduleConfigPath), 1000)
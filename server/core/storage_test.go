@@ -16,10 +16,12 @@ package core
 import (
 	"fmt"
 	"math"
+	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/pd/v4/server/kv"
+	"github.com/pingcap/pd/v4/server/schedule/storelimit"
 	"github.com/pkg/errors"
 )
 
@@ -118,6 +120,36 @@ func (s *testKVSuite) TestStoreWeight(c *C) {
 	}
 }
 
+func (s *testKVSuite) TestStoreLimit(c *C) {
+	storage := NewStorage(kv.NewMemoryKV())
+	c.Assert(storage.SaveStoreLimit(1, storelimit.AddPeer, 0.5), IsNil)
+	c.Assert(storage.SaveStoreLimit(1, storelimit.RemovePeer, 2), IsNil)
+	c.Assert(storage.SaveStoreLimit(2, storelimit.AddPeer, 1), IsNil)
+	c.Assert(storage.SaveStoreLimit(2, storelimit.AddPeer, 3), IsNil)
+	c.Assert(storage.SaveStoreLimit(3, storelimit.RemovePeer, 4), IsNil)
+	c.Assert(storage.DeleteStoreLimit(3), IsNil)
+
+	limits := make(map[uint64]map[storelimit.Type]float64)
+	c.Assert(storage.LoadStoreLimits(func(storeID uint64, limitType storelimit.Type, rate float64) {
+		if limits[storeID] == nil {
+			limits[storeID] = make(map[storelimit.Type]float64)
+		}
+		limits[storeID][limitType] = rate
+	}), IsNil)
+	c.Assert(limits, DeepEquals, map[uint64]map[storelimit.Type]float64{
+		1: {storelimit.AddPeer: 0.5, storelimit.RemovePeer: 2},
+		2: {storelimit.AddPeer: 3},
+	})
+
+	defaults := make(map[storelimit.Type]float64)
+	load := func(limitType storelimit.Type, rate float64) { defaults[limitType] = rate }
+	c.Assert(storage.LoadDefaultStoreLimits(load), IsNil)
+	c.Assert(defaults, HasLen, 0)
+	c.Assert(storage.SaveDefaultStoreLimit(storelimit.AddPeer, 1.5), IsNil)
+	c.Assert(storage.LoadDefaultStoreLimits(load), IsNil)
+	c.Assert(defaults, DeepEquals, map[storelimit.Type]float64{storelimit.AddPeer: 1.5})
+}
+
 func mustSaveRegions(c *C, s *Storage, n int) []*metapb.Region {
 	regions := make([]*metapb.Region, 0, n)
 	for i := 0; i < n; i++ {
@@ -194,6 +226,77 @@ func (s *testKVSuite) TestLoadGCSafePoint(c *C) {
 	}
 }
 
+func (s *testKVSuite) TestLoadMinServiceGCSafePoint(c *C) {
+	storage := NewStorage(kv.NewMemoryKV())
+	expireAt := time.Now().Add(100 * time.Second).Unix()
+	serviceSafePoints := []*ServiceSafePoint{
+		{"0", expireAt, 1},
+		{"1", expireAt, 2},
+		{"2", expireAt, 3},
+	}
+
+	for _, ssp := range serviceSafePoints {
+		c.Assert(storage.SaveServiceGCSafePoint(ssp), IsNil)
+	}
+
+	ssp, err := storage.LoadMinServiceGCSafePoint(time.Now())
+	c.Assert(err, IsNil)
+	c.Assert(ssp.ServiceID, Equals, "0")
+	c.Assert(ssp.ExpiredAt, Equals, expireAt)
+	c.Assert(ssp.SafePoint, Equals, uint64(1))
+
+	// the expired safepoints are skipped, but not removed until
+	// RemoveExpiredServiceGCSafePoints.
+	ssp, err = storage.LoadMinServiceGCSafePoint(time.Now().Add(200 * time.Second))
+	c.Assert(err, IsNil)
+	c.Assert(ssp, IsNil)
+	ssps, err := storage.LoadAllServiceGCSafePoints()
+	c.Assert(err, IsNil)
+	c.Assert(ssps, HasLen, 3)
+	c.Assert(storage.RemoveExpiredServiceGCSafePoints(time.Now()), IsNil)
+	ssps, err = storage.LoadAllServiceGCSafePoints()
+	c.Assert(err, IsNil)
+	c.Assert(ssps, HasLen, 3)
+	c.Assert(storage.RemoveExpiredServiceGCSafePoints(time.Now().Add(200*time.Second)), IsNil)
+	ssps, err = storage.LoadAllServiceGCSafePoints()
+	c.Assert(err, IsNil)
+	c.Assert(ssps, HasLen, 0)
+
+	c.Assert(storage.SaveServiceGCSafePoint(&ServiceSafePoint{"1", expireAt, 2}), IsNil)
+	ssp, err = storage.LoadServiceGCSafePoint("1")
+	c.Assert(err, IsNil)
+	c.Assert(ssp.SafePoint, Equals, uint64(2))
+	c.Assert(storage.RemoveServiceGCSafePoint("1"), IsNil)
+	ssp, err = storage.LoadServiceGCSafePoint("1")
+	c.Assert(err, IsNil)
+	c.Assert(ssp, IsNil)
+}
+
+func (s *testKVSuite) TestServiceGCSafePointPath(c *C) {
+	storage := NewStorage(kv.NewMemoryKV())
+	meta := &metapb.Cluster{Id: 123}
+	c.Assert(storage.SaveMeta(meta), IsNil)
+
+	// The service ID cannot escape from the prefix of the safepoints.
+	serviceID := "../../../" + clusterPath
+	expireAt := time.Now().Add(100 * time.Second).Unix()
+	c.Assert(storage.SaveServiceGCSafePoint(&ServiceSafePoint{serviceID, expireAt, 1}), IsNil)
+	ssps, err := storage.LoadAllServiceGCSafePoints()
+	c.Assert(err, IsNil)
+	c.Assert(ssps, HasLen, 1)
+	c.Assert(ssps[0].ServiceID, Equals, serviceID)
+	c.Assert(storage.RemoveServiceGCSafePoint(serviceID), IsNil)
+	ssps, err = storage.LoadAllServiceGCSafePoints()
+	c.Assert(err, IsNil)
+	c.Assert(ssps, HasLen, 0)
+
+	newMeta := &metapb.Cluster{}
+	ok, err := storage.LoadMeta(newMeta)
+	c.Assert(ok, IsTrue)
+	c.Assert(err, IsNil)
+	c.Assert(newMeta, DeepEquals, meta)
+}
+
 type KVWithMaxRangeLimit struct {
 	kv.Base
 	rangeLimit int
@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/server/schedule/storelimit"
 	"go.uber.org/zap"
 )
 
@@ -49,7 +50,10 @@ type StoreInfo struct {
 	lastPersistTime  time.Time
 	leaderWeight     float64
 	regionWeight     float64
-	available        func() bool
+	available        map[storelimit.Type]func() bool
+	// diskStateChangedTime is the last time the disk state of the store is
+	// changed, see DiskState.
+	diskStateChangedTime time.Time
 }
 
 // NewStoreInfo creates StoreInfo with meta data.
@@ -82,6 +86,8 @@ func (s *StoreInfo) Clone(opts ...StoreCreateOption) *StoreInfo {
 		leaderWeight:     s.leaderWeight,
 		regionWeight:     s.regionWeight,
 		available:        s.available,
+
+		diskStateChangedTime: s.diskStateChangedTime,
 	}
 
 	for _, opt := range opts {
@@ -96,11 +102,11 @@ func (s *StoreInfo) IsBlocked() bool {
 }
 
 // IsAvailable returns if the store bucket of limitation is available
-func (s *StoreInfo) IsAvailable() bool {
-	if s.available == nil {
-		return true
+func (s *StoreInfo) IsAvailable(limitType storelimit.Type) bool {
+	if s.available != nil && s.available[limitType] != nil {
+		return s.available[limitType]()
 	}
-	return s.available()
+	return true
 }
 
 // IsUp checks if the store's state is Up.
@@ -340,6 +346,57 @@ func (s *StoreInfo) IsLowSpace(lowSpaceRatio float64) bool {
 	return s.GetStoreStats() != nil && available <= s.GetSpaceThreshold(lowSpaceRatio, lowSpaceThreshold)
 }
 
+// StoreDiskState is the state of the disk usage of a store.
+type StoreDiskState int
+
+// Disk states of a store, from the least used to the most used.
+const (
+	// DiskStateNormal means the store can be scheduled as usual.
+	DiskStateNormal StoreDiskState = iota
+	// DiskStateAlmostFull means the store should not receive new peers from
+	// balancing, and its leaders should be moved away.
+	DiskStateAlmostFull
+	// DiskStateFull means the store should not receive any new peer, and its
+	// leaders and regions should be moved away.
+	DiskStateFull
+)
+
+func (s StoreDiskState) String() string {
+	switch s {
+	case DiskStateAlmostFull:
+		return "almost-full"
+	case DiskStateFull:
+		return "full"
+	}
+	return "normal"
+}
+
+// DiskState returns the disk state of the store by the ratio of the used
+// space to the capacity.
+func (s *StoreInfo) DiskState(almostFullRatio, fullRatio float64) StoreDiskState {
+	capacity := s.GetCapacity()
+	if capacity == 0 {
+		return DiskStateNormal
+	}
+	available := s.GetAvailable()
+	if available > capacity {
+		available = capacity
+	}
+	usedRatio := 1 - float64(available)/float64(capacity)
+	switch {
+	case usedRatio >= fullRatio:
+		return DiskStateFull
+	case usedRatio >= almostFullRatio:
+		return DiskStateAlmostFull
+	}
+	return DiskStateNormal
+}
+
+// GetDiskStateChangedTime returns the last time the disk state is changed.
+func (s *StoreInfo) GetDiskStateChangedTime() time.Time {
+	return s.diskStateChangedTime
+}
+
 // ResourceCount returns count of leader/region in the store.
 func (s *StoreInfo) ResourceCount(kind ResourceKind) uint64 {
 	switch kind {
@@ -567,9 +624,9 @@ func (s *StoresInfo) UnblockStore(storeID uint64) {
 }
 
 // AttachAvailableFunc attaches f to a specific store.
-func (s *StoresInfo) AttachAvailableFunc(storeID uint64, f func() bool) {
+func (s *StoresInfo) AttachAvailableFunc(storeID uint64, limitType storelimit.Type, f func() bool) {
 	if store, ok := s.stores[storeID]; ok {
-		s.stores[storeID] = store.Clone(SetAvailableFunc(f))
+		s.stores[storeID] = store.Clone(SetAvailableFunc(limitType, f))
 	}
 }
 
@@ -19,6 +19,7 @@ import (
 	"github.com/gogo/protobuf/proto"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/v4/server/schedule/storelimit"
 )
 
 // StoreCreateOption is used to create store.
@@ -149,6 +150,14 @@ func SetLastPersistTime(lastPersist time.Time) StoreCreateOption {
 	}
 }
 
+// SetDiskStateChangedTime sets the time when the disk state of the store is
+// changed.
+func SetDiskStateChangedTime(changedTime time.Time) StoreCreateOption {
+	return func(store *StoreInfo) {
+		store.diskStateChangedTime = changedTime
+	}
+}
+
 // SetStoreStats sets the statistics information for the store.
 func SetStoreStats(stats *pdpb.StoreStats) StoreCreateOption {
 	return func(store *StoreInfo) {
@@ -157,8 +166,14 @@ func SetStoreStats(stats *pdpb.StoreStats) StoreCreateOption {
 }
 
 // SetAvailableFunc sets a customize function for the store. The function f returns true if the store limit is not exceeded.
-func SetAvailableFunc(f func() bool) StoreCreateOption {
-	return func(store *StoreInfo) {
-		store.available = f
+func SetAvailableFunc(limitType storelimit.Type, f func() bool) StoreCreateOption {
+	return func(store *StoreInfo) {
+		// The map is shared by the clones, copy it before updating.
+		available := make(map[storelimit.Type]func() bool, len(store.available)+1)
+		for t, fn := range store.available {
+			available[t] = fn
+		}
+		available[limitType] = f
+		store.available = available
 	}
 }
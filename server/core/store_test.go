@@ -123,3 +123,27 @@ func (s *testStoreSuite) TestLowSpaceThreshold(c *C) {
 	c.Assert(fmt.Sprintf("%.2f", threshold), Equals, fmt.Sprintf("%.2f", 100*0.2))
 	c.Assert(store.IsLowSpace(0.8), Equals, true)
 }
+
+func (s *testStoreSuite) TestDiskState(c *C) {
+	store := NewStoreInfo(&metapb.Store{Id: 1})
+	// No stats are reported yet.
+	c.Assert(store.DiskState(0.9, 0.95), Equals, DiskStateNormal)
+
+	testCases := []struct {
+		available uint64
+		state     StoreDiskState
+	}{
+		{50, DiskStateNormal},
+		{11, DiskStateNormal},
+		{10, DiskStateAlmostFull},
+		{6, DiskStateAlmostFull},
+		{5, DiskStateFull},
+		{0, DiskStateFull},
+		// The available space may exceed the capacity.
+		{200, DiskStateNormal},
+	}
+	for _, t := range testCases {
+		store = store.Clone(SetStoreStats(&pdpb.StoreStats{Capacity: 100, Available: t.available}))
+		c.Assert(store.DiskState(0.9, 0.95), Equals, t.state)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrServiceSafePointRollback is returned when a service tries to move
+	// its GC safepoint backwards.
+	ErrServiceSafePointRollback = errors.New("service safepoint cannot be moved backwards")
+	// ErrServiceSafePointTooSmall is returned when a service safepoint is less
+	// than the GC safepoint, which means the data may be already collected.
+	ErrServiceSafePointTooSmall = errors.New("service safepoint is less than the gc safepoint")
+	// ErrInvalidServiceID is returned when the service ID cannot be a path
+	// segment of the HTTP API, so the safepoint could not be deleted.
+	ErrInvalidServiceID = errors.New("service id cannot be empty, . or .., or contain /")
+)
+
+// UpdateServiceGCSafePoint updates the GC safepoint of the service, which
+// will expire after ttl seconds. A non-positive ttl removes the safepoint of
+// the service. It returns the minimum safepoint among all alive services.
+func (s *Server) UpdateServiceGCSafePoint(serviceID string, ttl int64, safePoint uint64) (*core.ServiceSafePoint, error) {
+	if serviceID == "" || serviceID == "." || serviceID == ".." || strings.Contains(serviceID, "/") {
+		return nil, errors.Wrapf(ErrInvalidServiceID, "service id %q", serviceID)
+	}
+	s.serviceSafePointLock.Lock()
+	defer s.serviceSafePointLock.Unlock()
+
+	now := time.Now()
+	if err := s.storage.RemoveExpiredServiceGCSafePoints(now); err != nil {
+		return nil, err
+	}
+	if ttl <= 0 {
+		if err := s.storage.RemoveServiceGCSafePoint(serviceID); err != nil {
+			return nil, err
+		}
+		log.Info("removed service gc safe point", zap.String("service-id", serviceID))
+		return s.storage.LoadMinServiceGCSafePoint(now)
+	}
+
+	old, err := s.storage.LoadServiceGCSafePoint(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if old != nil && old.ExpiredAt >= now.Unix() && safePoint < old.SafePoint {
+		return nil, errors.Wrapf(ErrServiceSafePointRollback, "service %s, old %d, new %d", serviceID, old.SafePoint, safePoint)
+	}
+	gcSafePoint, err := s.storage.LoadGCSafePoint()
+	if err != nil {
+		return nil, err
+	}
+	if safePoint < gcSafePoint {
+		return nil, errors.Wrapf(ErrServiceSafePointTooSmall, "service %s, gc safepoint %d, new %d", serviceID, gcSafePoint, safePoint)
+	}
+
+	ssp := &core.ServiceSafePoint{
+		ServiceID: serviceID,
+		ExpiredAt: now.Unix() + ttl,
+		SafePoint: safePoint,
+	}
+	// A large ttl means the safepoint never expires.
+	if ttl > math.MaxInt64-now.Unix() {
+		ssp.ExpiredAt = math.MaxInt64
+	}
+	if err := s.storage.SaveServiceGCSafePoint(ssp); err != nil {
+		return nil, err
+	}
+	log.Info("updated service gc safe point",
+		zap.String("service-id", serviceID),
+		zap.Int64("expire-at", ssp.ExpiredAt),
+		zap.Uint64("safepoint", safePoint))
+	return s.storage.LoadMinServiceGCSafePoint(now)
+}
+
+// GetServiceGCSafePoints returns the GC safepoints of all alive services.
+func (s *Server) GetServiceGCSafePoints() ([]*core.ServiceSafePoint, error) {
+	s.serviceSafePointLock.Lock()
+	defer s.serviceSafePointLock.Unlock()
+
+	ssps, err := s.storage.LoadAllServiceGCSafePoints()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	alive := ssps[:0]
+	for _, ssp := range ssps {
+		if ssp.ExpiredAt >= now {
+			alive = append(alive, ssp)
+		}
+	}
+	return alive, nil
+}
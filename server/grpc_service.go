@@ -21,14 +21,20 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/typeutil"
+	"github.com/pingcap/pd/v4/server/audit"
+	"github.com/pingcap/pd/v4/server/auth"
 	"github.com/pingcap/pd/v4/server/cluster"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
@@ -88,7 +94,12 @@ func (s *Server) Tso(stream pdpb.PD_TsoServer) error {
 			return status.Errorf(codes.FailedPrecondition, "mismatch cluster id, need %d but got %d", s.clusterID, request.GetHeader().GetClusterId())
 		}
 		count := request.GetCount()
-		ts, err := s.tso.GetRespTS(count)
+		var ts pdpb.Timestamp
+		if s.tsoDispatcher != nil {
+			ts, err = s.tsoDispatcher.GetRespTS(stream.Context(), count)
+		} else {
+			ts, err = s.tso.GetRespTS(count)
+		}
 		if err != nil {
 			return status.Errorf(codes.Unknown, err.Error())
 		}
@@ -110,10 +121,14 @@ func (s *Server) Tso(stream pdpb.PD_TsoServer) error {
 }
 
 // Bootstrap implements gRPC PDServer.
-func (s *Server) Bootstrap(ctx context.Context, request *pdpb.BootstrapRequest) (*pdpb.BootstrapResponse, error) {
+func (s *Server) Bootstrap(ctx context.Context, request *pdpb.BootstrapRequest) (resp *pdpb.BootstrapResponse, err error) {
 	if err := s.validateRequest(request.GetHeader()); err != nil {
 		return nil, err
 	}
+	defer func(start time.Time) { s.auditGRPC(ctx, "Bootstrap", request, resp.GetHeader(), err, start) }(time.Now())
+	if err := s.checkRole(ctx, auth.RoleOperator); err != nil {
+		return nil, err
+	}
 
 	rc := s.GetRaftCluster()
 	if rc != nil {
@@ -153,6 +168,9 @@ func (s *Server) AllocID(ctx context.Context, request *pdpb.AllocIDRequest) (*pd
 	if err := s.validateRequest(request.GetHeader()); err != nil {
 		return nil, err
 	}
+	if err := s.checkRole(ctx, auth.RoleOperator); err != nil {
+		return nil, err
+	}
 
 	// We can use an allocator for all types ID allocation.
 	id, err := s.idAllocator.Alloc()
@@ -209,6 +227,9 @@ func (s *Server) PutStore(ctx context.Context, request *pdpb.PutStoreRequest) (*
 	if err := s.validateRequest(request.GetHeader()); err != nil {
 		return nil, err
 	}
+	if err := s.checkRole(ctx, auth.RoleOperator); err != nil {
+		return nil, err
+	}
 
 	rc := s.GetRaftCluster()
 	if rc == nil {
@@ -281,6 +302,9 @@ func (s *Server) StoreHeartbeat(ctx context.Context, request *pdpb.StoreHeartbea
 	if err := s.validateRequest(request.GetHeader()); err != nil {
 		return nil, err
 	}
+	if err := s.checkRole(ctx, auth.RoleOperator); err != nil {
+		return nil, err
+	}
 
 	if request.GetStats() == nil {
 		return nil, errors.Errorf("invalid store heartbeat command, but %v", request)
@@ -350,6 +374,10 @@ func (s *heartbeatServer) Recv() (*pdpb.RegionHeartbeatRequest, error) {
 
 // RegionHeartbeat implements gRPC PDServer.
 func (s *Server) RegionHeartbeat(stream pdpb.PD_RegionHeartbeatServer) error {
+	// The credentials of the stream never change, so it is checked only once.
+	if err := s.checkRole(stream.Context(), auth.RoleOperator); err != nil {
+		return err
+	}
 	server := &heartbeatServer{stream: stream}
 	rc := s.GetRaftCluster()
 	if rc == nil {
@@ -496,6 +524,9 @@ func (s *Server) AskSplit(ctx context.Context, request *pdpb.AskSplitRequest) (*
 	if err := s.validateRequest(request.GetHeader()); err != nil {
 		return nil, err
 	}
+	if err := s.checkRole(ctx, auth.RoleOperator); err != nil {
+		return nil, err
+	}
 
 	rc := s.GetRaftCluster()
 	if rc == nil {
@@ -524,6 +555,9 @@ func (s *Server) AskBatchSplit(ctx context.Context, request *pdpb.AskBatchSplitR
 	if err := s.validateRequest(request.GetHeader()); err != nil {
 		return nil, err
 	}
+	if err := s.checkRole(ctx, auth.RoleOperator); err != nil {
+		return nil, err
+	}
 
 	rc := s.GetRaftCluster()
 	if rc == nil {
@@ -556,6 +590,9 @@ func (s *Server) ReportSplit(ctx context.Context, request *pdpb.ReportSplitReque
 	if err := s.validateRequest(request.GetHeader()); err != nil {
 		return nil, err
 	}
+	if err := s.checkRole(ctx, auth.RoleOperator); err != nil {
+		return nil, err
+	}
 
 	rc := s.GetRaftCluster()
 	if rc == nil {
@@ -576,6 +613,9 @@ func (s *Server) ReportBatchSplit(ctx context.Context, request *pdpb.ReportBatch
 	if err := s.validateRequest(request.GetHeader()); err != nil {
 		return nil, err
 	}
+	if err := s.checkRole(ctx, auth.RoleOperator); err != nil {
+		return nil, err
+	}
 
 	rc := s.GetRaftCluster()
 	if rc == nil {
@@ -609,10 +649,14 @@ func (s *Server) GetClusterConfig(ctx context.Context, request *pdpb.GetClusterC
 }
 
 // PutClusterConfig implements gRPC PDServer.
-func (s *Server) PutClusterConfig(ctx context.Context, request *pdpb.PutClusterConfigRequest) (*pdpb.PutClusterConfigResponse, error) {
+func (s *Server) PutClusterConfig(ctx context.Context, request *pdpb.PutClusterConfigRequest) (resp *pdpb.PutClusterConfigResponse, err error) {
 	if err := s.validateRequest(request.GetHeader()); err != nil {
 		return nil, err
 	}
+	defer func(start time.Time) { s.auditGRPC(ctx, "PutClusterConfig", request, resp.GetHeader(), err, start) }(time.Now())
+	if err := s.checkRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
 
 	rc := s.GetRaftCluster()
 	if rc == nil {
@@ -635,6 +679,9 @@ func (s *Server) ScatterRegion(ctx context.Context, request *pdpb.ScatterRegionR
 	if err := s.validateRequest(request.GetHeader()); err != nil {
 		return nil, err
 	}
+	if err := s.checkRole(ctx, auth.RoleOperator); err != nil {
+		return nil, err
+	}
 
 	rc := s.GetRaftCluster()
 	if rc == nil {
@@ -653,13 +700,12 @@ func (s *Server) ScatterRegion(ctx context.Context, request *pdpb.ScatterRegionR
 		return nil, errors.Errorf("region %d is a hot region", region.GetID())
 	}
 
-	op, err := rc.GetRegionScatter().Scatter(region)
-	if err != nil {
+	// The request has no group, so the region is scattered in the default group.
+	// The operator may fail to be added if the region already has one, which
+	// is not an error for the request.
+	if _, err := rc.GetRegionScatter().Scatter(region, ""); err != nil && errors.Cause(err) != schedule.ErrAddScatterOperator {
 		return nil, err
 	}
-	if op != nil {
-		rc.GetOperatorController().AddOperator(op)
-	}
 
 	return &pdpb.ScatterRegionResponse{
 		Header: s.header(),
@@ -697,16 +743,23 @@ func (s *Server) SyncRegions(stream pdpb.PD_SyncRegionsServer) error {
 }
 
 // UpdateGCSafePoint implements gRPC PDServer.
-func (s *Server) UpdateGCSafePoint(ctx context.Context, request *pdpb.UpdateGCSafePointRequest) (*pdpb.UpdateGCSafePointResponse, error) {
+func (s *Server) UpdateGCSafePoint(ctx context.Context, request *pdpb.UpdateGCSafePointRequest) (resp *pdpb.UpdateGCSafePointResponse, err error) {
 	if err := s.validateRequest(request.GetHeader()); err != nil {
 		return nil, err
 	}
+	defer func(start time.Time) { s.auditGRPC(ctx, "UpdateGCSafePoint", request, resp.GetHeader(), err, start) }(time.Now())
+	if err := s.checkRole(ctx, auth.RoleOperator); err != nil {
+		return nil, err
+	}
 
 	rc := s.GetRaftCluster()
 	if rc == nil {
 		return &pdpb.UpdateGCSafePointResponse{Header: s.notBootstrappedHeader()}, nil
 	}
 
+	s.serviceSafePointLock.Lock()
+	defer s.serviceSafePointLock.Unlock()
+
 	oldSafePoint, err := s.storage.LoadGCSafePoint()
 	if err != nil {
 		return nil, err
@@ -714,6 +767,23 @@ func (s *Server) UpdateGCSafePoint(ctx context.Context, request *pdpb.UpdateGCSa
 
 	newSafePoint := request.SafePoint
 
+	// The gc safe point cannot exceed the safe point of any alive service.
+	now := time.Now()
+	if err = s.storage.RemoveExpiredServiceGCSafePoints(now); err != nil {
+		return nil, err
+	}
+	minServiceSafePoint, err := s.storage.LoadMinServiceGCSafePoint(now)
+	if err != nil {
+		return nil, err
+	}
+	if minServiceSafePoint != nil && newSafePoint > minServiceSafePoint.SafePoint {
+		log.Info("gc safe point is blocked by service safe point",
+			zap.String("service-id", minServiceSafePoint.ServiceID),
+			zap.Uint64("service-safe-point", minServiceSafePoint.SafePoint),
+			zap.Uint64("request-safe-point", newSafePoint))
+		newSafePoint = minServiceSafePoint.SafePoint
+	}
+
 	// Only save the safe point if it's greater than the previous one
 	if newSafePoint > oldSafePoint {
 		if err := s.storage.SaveGCSafePoint(newSafePoint); err != nil {
@@ -777,6 +847,54 @@ func (s *Server) validateRequest(header *pdpb.RequestHeader) error {
 	return nil
 }
 
+// checkRole checks if the caller is allowed to do the things which require
+// the role. It does nothing if the authentication is disabled.
+func (s *Server) checkRole(ctx context.Context, role auth.Role) error {
+	if s.authenticator == nil {
+		return nil
+	}
+	id, err := s.authenticator.AuthenticateGRPC(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, err.Error())
+	}
+	if err := auth.Check(id, role); err != nil {
+		return status.Errorf(codes.PermissionDenied, err.Error())
+	}
+	return nil
+}
+
+// auditGRPC records the gRPC request which changes the cluster in the audit
+// log, header is the header of the response.
+func (s *Server) auditGRPC(ctx context.Context, method string, request proto.Message, header *pdpb.ResponseHeader, err error, start time.Time) {
+	if s.auditLogger == nil {
+		return
+	}
+	record := &audit.Record{
+		Time:     start,
+		Protocol: audit.ProtocolGRPC,
+		Route:    "/pdpb.PD/" + method,
+		Status:   int(status.Code(err)),
+		Duration: typeutil.NewDuration(time.Since(start)),
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		record.Address = p.Addr.String()
+	}
+	if s.authenticator != nil {
+		if id, err := s.authenticator.AuthenticateGRPC(ctx); err == nil {
+			record.SetIdentity(id)
+		}
+	}
+	if data, err := proto.Marshal(request); err == nil {
+		record.SetBody(data)
+	}
+	if err != nil {
+		record.Error = err.Error()
+	} else if e := header.GetError(); e != nil {
+		record.Error = fmt.Sprintf("%s: %s", e.GetType(), e.GetMessage())
+	}
+	s.auditLogger.Log(record)
+}
+
 func (s *Server) header() *pdpb.ResponseHeader {
 	return &pdpb.ResponseHeader{ClusterId: s.clusterID}
 }
@@ -16,8 +16,10 @@ package server
 import (
 	"bytes"
 	"encoding/hex"
+	"encoding/json"
 	"net/http"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -31,8 +33,10 @@ import (
 	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/explain"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pingcap/pd/v4/server/schedule/storelimit"
 	"github.com/pingcap/pd/v4/server/schedulers"
 	"github.com/pingcap/pd/v4/server/statistics"
 	"github.com/pkg/errors"
@@ -130,6 +134,48 @@ func (h *Handler) GetSchedulers() ([]string, error) {
 	return names, nil
 }
 
+// SchedulerStatus is the status of a running scheduler.
+type SchedulerStatus struct {
+	Name   string `json:"name"`
+	Paused bool   `json:"paused"`
+	// The fields below are set only if the scheduler is paused.
+	PausedAt         *time.Time `json:"paused-at,omitempty"`
+	PausedUntil      *time.Time `json:"paused-until,omitempty"`
+	RemainingSeconds int64      `json:"remaining-seconds,omitempty"`
+	Reason           string     `json:"reason,omitempty"`
+	// PausedByProfile is the name of the active schedule profile if the
+	// scheduler is paused by it.
+	PausedByProfile string `json:"paused-by-profile,omitempty"`
+}
+
+// GetSchedulersStatus returns the status of all schedulers sorted by name.
+func (h *Handler) GetSchedulersStatus() ([]*SchedulerStatus, error) {
+	c, err := h.GetRaftCluster()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	status := make([]*SchedulerStatus, 0, len(c.GetSchedulers()))
+	for name, sc := range c.GetSchedulers() {
+		s := &SchedulerStatus{Name: name}
+		if pause := sc.GetPause(); now.Unix() < pause.PausedUntil {
+			pausedAt, pausedUntil := time.Unix(pause.PausedAt, 0), time.Unix(pause.PausedUntil, 0)
+			s.Paused = true
+			s.PausedAt = &pausedAt
+			s.PausedUntil = &pausedUntil
+			s.RemainingSeconds = pause.PausedUntil - now.Unix()
+			s.Reason = pause.Reason
+		}
+		if p := c.GetOpt().GetActiveProfile(); p != nil && p.IsSchedulerPaused(name) {
+			s.Paused = true
+			s.PausedByProfile = p.Name
+		}
+		status = append(status, s)
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Name < status[j].Name })
+	return status, nil
+}
+
 // GetStores returns all stores in the cluster.
 func (h *Handler) GetStores() ([]*core.StoreInfo, error) {
 	rc := h.s.GetRaftCluster()
@@ -167,6 +213,15 @@ func (h *Handler) GetHotReadRegions() *statistics.StoreHotPeersInfos {
 	return c.GetHotReadRegions()
 }
 
+// GetHotRegionHistory gets the hot peers in the history selected by the filter.
+func (h *Handler) GetHotRegionHistory(filter *statistics.HotRegionHistoryFilter) ([]*statistics.HotRegionHistoryRecord, error) {
+	c, err := h.GetRaftCluster()
+	if err != nil {
+		return nil, err
+	}
+	return c.QueryHotRegionHistory(filter)
+}
+
 // GetHotBytesWriteStores gets all hot write stores stats.
 func (h *Handler) GetHotBytesWriteStores() map[uint64]float64 {
 	rc := h.s.GetRaftCluster()
@@ -203,6 +258,33 @@ func (h *Handler) GetHotKeysReadStores() map[uint64]float64 {
 	return rc.GetStoresKeysReadStat()
 }
 
+// GetHotCPUUsageStores gets the cpu usage of all stores.
+func (h *Handler) GetHotCPUUsageStores() map[uint64]float64 {
+	rc := h.s.GetRaftCluster()
+	if rc == nil {
+		return nil
+	}
+	return rc.GetStoresCPUUsage()
+}
+
+// GetHotDiskReadStores gets the disk read rate of all stores.
+func (h *Handler) GetHotDiskReadStores() map[uint64]float64 {
+	rc := h.s.GetRaftCluster()
+	if rc == nil {
+		return nil
+	}
+	return rc.GetStoresDiskReadRate()
+}
+
+// GetHotDiskWriteStores gets the disk write rate of all stores.
+func (h *Handler) GetHotDiskWriteStores() map[uint64]float64 {
+	rc := h.s.GetRaftCluster()
+	if rc == nil {
+		return nil
+	}
+	return rc.GetStoresDiskWriteRate()
+}
+
 // AddScheduler adds a scheduler.
 func (h *Handler) AddScheduler(name string, args ...string) error {
 	c, err := h.GetRaftCluster()
@@ -223,6 +305,16 @@ func (h *Handler) AddScheduler(name string, args ...string) error {
 	return err
 }
 
+// SimulateSchedule runs the schedulers and the checkers against a snapshot of
+// the cluster without dispatching the operators.
+func (h *Handler) SimulateSchedule(req *cluster.SimulateRequest) (*cluster.SimulateResult, error) {
+	c, err := h.GetRaftCluster()
+	if err != nil {
+		return nil, err
+	}
+	return c.Simulate(req)
+}
+
 // RemoveScheduler removes a scheduler by name.
 func (h *Handler) RemoveScheduler(name string) error {
 	c, err := h.GetRaftCluster()
@@ -238,12 +330,13 @@ func (h *Handler) RemoveScheduler(name string) error {
 // PauseOrResumeScheduler pasues a scheduler for delay seconds or resume a paused scheduler.
 // t == 0 : resume scheduler.
 // t > 0 : scheduler delays t seconds.
-func (h *Handler) PauseOrResumeScheduler(name string, t int64) error {
+// The pause is kept after the leader changes, the reason is recorded with it.
+func (h *Handler) PauseOrResumeScheduler(name string, t int64, reason string) error {
 	c, err := h.GetRaftCluster()
 	if err != nil {
 		return err
 	}
-	if err = c.PauseOrResumeScheduler(name, t); err != nil {
+	if err = c.PauseOrResumeScheduler(name, t, reason); err != nil {
 		if t == 0 {
 			log.Error("can not resume scheduler", zap.String("scheduler-name", name), zap.Error(err))
 		} else {
@@ -253,6 +346,42 @@ func (h *Handler) PauseOrResumeScheduler(name string, t int64) error {
 	return err
 }
 
+// SchedulerExplain is the recorded rounds of a scheduler in explain mode.
+type SchedulerExplain struct {
+	Name    string           `json:"name"`
+	Enabled bool             `json:"enabled"`
+	Rounds  []*explain.Round `json:"rounds"`
+}
+
+// GetSchedulerExplain returns the recorded rounds of the scheduler, the latest
+// first.
+func (h *Handler) GetSchedulerExplain(name string) (*SchedulerExplain, error) {
+	c, err := h.GetRaftCluster()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := c.GetSchedulers()[name]; !ok {
+		return nil, cluster.ErrSchedulerNotFound
+	}
+	recorder := c.GetExplainRecorder(name)
+	return &SchedulerExplain{
+		Name:    name,
+		Enabled: recorder != nil,
+		Rounds:  recorder.Rounds(),
+	}, nil
+}
+
+// SetSchedulerExplain turns on the explain mode of the scheduler to record the
+// last rounds of scheduling, or turns it off if rounds is not positive. The
+// explain mode is not kept after the leader changes.
+func (h *Handler) SetSchedulerExplain(name string, rounds int) error {
+	c, err := h.GetRaftCluster()
+	if err != nil {
+		return err
+	}
+	return c.SetSchedulerExplain(name, rounds)
+}
+
 // AddBalanceLeaderScheduler adds a balance-leader-scheduler.
 func (h *Handler) AddBalanceLeaderScheduler() error {
 	return h.AddScheduler(schedulers.BalanceLeaderType)
@@ -308,6 +437,11 @@ func (h *Handler) AddShuffleHotRegionScheduler(limit uint64) error {
 	return h.AddScheduler(schedulers.ShuffleHotRegionType, strconv.FormatUint(limit, 10))
 }
 
+// AddSplitHotRegionScheduler adds a split-hot-region-scheduler.
+func (h *Handler) AddSplitHotRegionScheduler() error {
+	return h.AddScheduler(schedulers.SplitHotRegionType)
+}
+
 // AddRandomMergeScheduler adds a random-merge-scheduler.
 func (h *Handler) AddRandomMergeScheduler() error {
 	return h.AddScheduler(schedulers.RandomMergeType)
@@ -377,6 +511,24 @@ func (h *Handler) GetWaitingOperators() ([]*operator.Operator, error) {
 	return c.GetWaitingOperators(), nil
 }
 
+// GetWaitingQueues returns the status of the waiting queues.
+func (h *Handler) GetWaitingQueues() ([]*schedule.WaitingQueueStatus, error) {
+	c, err := h.GetOperatorController()
+	if err != nil {
+		return nil, err
+	}
+	return c.GetWaitingQueues(), nil
+}
+
+// GetOperatorHistory returns the finished operators selected by the filter.
+func (h *Handler) GetOperatorHistory(filter *schedule.OperatorHistoryFilter) ([]*schedule.OperatorHistoryRecord, error) {
+	c, err := h.GetRaftCluster()
+	if err != nil {
+		return nil, err
+	}
+	return c.QueryOperatorHistory(filter)
+}
+
 // GetAdminOperators returns the running admin operators.
 func (h *Handler) GetAdminOperators() ([]*operator.Operator, error) {
 	return h.GetOperatorsOfKind(operator.OpAdmin)
@@ -417,17 +569,16 @@ func (h *Handler) GetHistory(start time.Time) ([]operator.OpHistory, error) {
 }
 
 // SetAllStoresLimit is used to set limit of all stores.
-func (h *Handler) SetAllStoresLimit(rate float64) error {
-	c, err := h.GetOperatorController()
+func (h *Handler) SetAllStoresLimit(rate float64, limitType storelimit.Type) error {
+	c, err := h.GetRaftCluster()
 	if err != nil {
 		return err
 	}
-	c.SetAllStoresLimit(rate, schedule.StoreLimitManual)
-	return nil
+	return c.SetAllStoresLimit(rate, limitType)
 }
 
 // GetAllStoresLimit is used to get limit of all stores.
-func (h *Handler) GetAllStoresLimit() (map[uint64]*schedule.StoreLimit, error) {
+func (h *Handler) GetAllStoresLimit() (map[uint64]map[storelimit.Type]*schedule.StoreLimit, error) {
 	c, err := h.GetOperatorController()
 	if err != nil {
 		return nil, err
@@ -436,13 +587,12 @@ func (h *Handler) GetAllStoresLimit() (map[uint64]*schedule.StoreLimit, error) {
 }
 
 // SetStoreLimit is used to set the limit of a store.
-func (h *Handler) SetStoreLimit(storeID uint64, rate float64) error {
-	c, err := h.GetOperatorController()
+func (h *Handler) SetStoreLimit(storeID uint64, rate float64, limitType storelimit.Type) error {
+	c, err := h.GetRaftCluster()
 	if err != nil {
 		return err
 	}
-	c.SetStoreLimit(storeID, rate, schedule.StoreLimitManual)
-	return nil
+	return c.SetStoreLimit(storeID, rate, limitType)
 }
 
 // AddTransferLeaderOperator adds an operator to transfer leader to the store.
@@ -731,8 +881,8 @@ func (h *Handler) AddSplitRegionOperator(regionID uint64, policyStr string, keys
 	return nil
 }
 
-// AddScatterRegionOperator adds an operator to scatter a region.
-func (h *Handler) AddScatterRegionOperator(regionID uint64) error {
+// AddScatterRegionOperator adds an operator to scatter a region in the group.
+func (h *Handler) AddScatterRegionOperator(regionID uint64, group string) error {
 	c, err := h.GetRaftCluster()
 	if err != nil {
 		return err
@@ -747,18 +897,23 @@ func (h *Handler) AddScatterRegionOperator(regionID uint64) error {
 		return errors.Errorf("region %d is a hot region", regionID)
 	}
 
-	op, err := c.GetRegionScatter().Scatter(region)
-	if err != nil {
-		return err
-	}
+	_, err = c.GetRegionScatter().Scatter(region, group)
+	return err
+}
 
-	if op == nil {
-		return nil
+// ScatterRegions adds the operators to scatter the regions in the group, and
+// returns the errors of the regions which fail to scatter.
+func (h *Handler) ScatterRegions(regionIDs []uint64, group string) (map[uint64]error, error) {
+	if _, err := h.GetRaftCluster(); err != nil {
+		return nil, err
 	}
-	if ok := c.GetOperatorController().AddOperator(op); !ok {
-		return errors.WithStack(ErrAddOperator)
+	failures := make(map[uint64]error)
+	for _, id := range regionIDs {
+		if err := h.AddScatterRegionOperator(id, group); err != nil {
+			failures[id] = err
+		}
 	}
-	return nil
+	return failures, nil
 }
 
 // GetDownPeerRegions gets the region with down peer.
@@ -812,6 +967,92 @@ func (h *Handler) GetSchedulerConfigHandler() http.Handler {
 	return mux
 }
 
+// SchedulerConfig is the config of a scheduler with its schema.
+type SchedulerConfig struct {
+	Name   string                 `json:"name"`
+	Config json.RawMessage        `json:"config"`
+	Schema *schedule.ConfigSchema `json:"schema,omitempty"`
+}
+
+func (h *Handler) getScheduler(name string) (schedule.Scheduler, error) {
+	c, err := h.GetRaftCluster()
+	if err != nil {
+		return nil, err
+	}
+	s, ok := c.GetSchedulers()[name]
+	if !ok {
+		return nil, cluster.ErrSchedulerNotFound
+	}
+	return s.Scheduler, nil
+}
+
+// GetSchedulerConfig returns the config of the scheduler. The schema is absent
+// if the scheduler is not a ConfigurableScheduler.
+func (h *Handler) GetSchedulerConfig(name string) (*SchedulerConfig, error) {
+	s, err := h.getScheduler(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.EncodeConfig()
+	if err != nil {
+		return nil, err
+	}
+	cfg := &SchedulerConfig{Name: name, Config: data}
+	if cs, ok := s.(schedule.ConfigurableScheduler); ok {
+		cfg.Schema = cs.GetConfigSchema()
+	}
+	return cfg, nil
+}
+
+// UpdateSchedulerConfig applies the partial update in JSON to the scheduler
+// config and persists it. The update is reverted if it fails to persist.
+func (h *Handler) UpdateSchedulerConfig(name string, data []byte) error {
+	s, err := h.getScheduler(name)
+	if err != nil {
+		return err
+	}
+	cs, ok := s.(schedule.ConfigurableScheduler)
+	if !ok {
+		return errors.Wrapf(schedule.ErrInvalidSchedulerConfig, "scheduler %s does not support updating config", name)
+	}
+	old, err := cs.EncodeConfig()
+	if err != nil {
+		return err
+	}
+	if err := cs.UpdateConfig(data); err != nil {
+		return err
+	}
+	cfg, err := cs.EncodeConfig()
+	if err == nil {
+		err = h.s.GetStorage().SaveScheduleConfig(name, cfg)
+	}
+	if err != nil {
+		if rerr := cs.UpdateConfig(revertSchedulerConfig(old, data)); rerr != nil {
+			log.Error("failed to revert scheduler config", zap.String("scheduler-name", name), zap.Error(rerr))
+		}
+		return err
+	}
+	log.Info("scheduler config is updated", zap.String("scheduler-name", name), zap.ByteString("update", data))
+	return nil
+}
+
+// revertSchedulerConfig returns the update which restores the items in the
+// update to the old config.
+func revertSchedulerConfig(old, update []byte) []byte {
+	var oldItems, items map[string]json.RawMessage
+	if err := json.Unmarshal(old, &oldItems); err != nil {
+		return nil
+	}
+	if err := json.Unmarshal(update, &items); err != nil {
+		return nil
+	}
+	for key := range items {
+		items[key] = oldItems[key]
+	}
+	data, _ := json.Marshal(items)
+	return data
+}
+
 // GetOfflinePeer gets the region with offline peer.
 func (h *Handler) GetOfflinePeer() ([]*core.RegionInfo, error) {
 	c := h.s.GetRaftCluster()
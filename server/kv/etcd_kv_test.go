@@ -87,6 +87,17 @@ func (s *testEtcdKVSuite) TestEtcdKV(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(v, Equals, "")
 
+	err = kv.SaveBatch([]Op{
+		{Key: keys[0], Remove: true},
+		{Key: keys[1], Value: "val2"},
+		{Key: keys[2], Value: "val33"},
+	})
+	c.Assert(err, IsNil)
+	ks, vs, err = kv.LoadRange(keys[0], "test/zzz", 100)
+	c.Assert(err, IsNil)
+	c.Assert(ks, DeepEquals, keys[1:])
+	c.Assert(vs, DeepEquals, []string{"val2", "val33", "val4", "val5"})
+
 	etcd.Close()
 	cleanConfig(cfg)
 }
@@ -13,10 +13,20 @@
 
 package kv
 
+// Op is a write operation in a batch. It saves Value to Key, or removes Key
+// if Remove is true.
+type Op struct {
+	Key    string
+	Value  string
+	Remove bool
+}
+
 // Base is an abstract interface for load/save pd cluster data.
 type Base interface {
 	Load(key string) (string, error)
 	LoadRange(key, endKey string, limit int) (keys []string, values []string, err error)
 	Save(key, value string) error
 	Remove(key string) error
+	// SaveBatch applies all operations atomically.
+	SaveBatch(ops []Op) error
 }
@@ -147,7 +147,7 @@ func (m *Member) CheckLeader(name string) (*pdpb.Member, int64, bool) {
 			// oh, we are already leader, we may meet something wrong
 			// in previous CampaignLeader. we can delete and campaign again.
 			log.Warn("the leader has not changed, delete and campaign again", zap.Stringer("old-leader", leader))
-			if err = m.deleteLeaderKey(); err != nil {
+			if err = m.DeleteLeaderKey(); err != nil {
 				log.Error("delete leader key meet error", zap.Error(err))
 				time.Sleep(200 * time.Millisecond)
 				return nil, 0, true
@@ -399,7 +399,9 @@ func (m *Member) SetMemberBinaryVersion(id uint64, releaseVersion string) error
 	return nil
 }
 
-func (m *Member) deleteLeaderKey() error {
+// DeleteLeaderKey deletes the leader key if the server is the leader, so that
+// other servers can start a new election at once.
+func (m *Member) DeleteLeaderKey() error {
 	// delete leader itself and let others start a new election again.
 	leaderKey := m.GetLeaderPath()
 	resp, err := m.LeaderTxn().Then(clientv3.OpDelete(leaderKey)).Commit()
@@ -161,7 +161,11 @@ func (s *RegionSyncer) StartSyncWithLeader(addr string) {
 					}
 				}
 				log.Error("server failed to establish sync stream with leader", zap.String("server", s.server.Name()), zap.String("leader", s.server.GetLeader().GetName()), zap.Error(err))
-				time.Sleep(time.Second)
+				select {
+				case <-closed:
+					return
+				case <-time.After(time.Second):
+				}
 				continue
 			}
 			log.Info("server starts to synchronize with leader", zap.String("server", s.server.Name()), zap.String("leader", s.server.GetLeader().GetName()), zap.Uint64("request-index", s.history.GetNextIndex()))
@@ -172,7 +176,13 @@ func (s *RegionSyncer) StartSyncWithLeader(addr string) {
 					if err = stream.CloseSend(); err != nil {
 						log.Error("failed to terminate client stream", zap.Error(err))
 					}
-					time.Sleep(time.Second)
+					// Do not block StopSyncWithLeader, or the server campaigns
+					// leader late after the leader is changed.
+					select {
+					case <-closed:
+						return
+					case <-time.After(time.Second):
+					}
 					break
 				}
 				if s.history.GetNextIndex() != resp.GetStartIndex() {
@@ -0,0 +1,80 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// peerIDPattern matches the peer IDs in the step strings. The IDs are
+// allocated by PD, so they are different between the recording and the
+// replay.
+var peerIDPattern = regexp.MustCompile(`peer \d+ `)
+
+// key returns the identity of the operator used to compare operators.
+func (r *OperatorRecord) key() string {
+	steps := make([]string, 0, len(r.Steps))
+	for _, step := range r.Steps {
+		steps = append(steps, peerIDPattern.ReplaceAllString(step, "peer "))
+	}
+	return fmt.Sprintf("region %d %s: %s", r.RegionID, r.Desc, strings.Join(steps, ", "))
+}
+
+// OperatorDiff is the result of DiffOperators.
+type OperatorDiff struct {
+	Matched int
+	// OnlyRecorded are the recorded operators which are not created in the
+	// replay.
+	OnlyRecorded []*OperatorRecord
+	// OnlyReplayed are the operators created in the replay but not recorded.
+	OnlyReplayed []*OperatorRecord
+}
+
+// DiffOperators compares the recorded operators with the replayed ones. The
+// operators are compared by the region, the description and the steps
+// without the peer IDs, and the order is ignored.
+func DiffOperators(recorded, replayed []*OperatorRecord) *OperatorDiff {
+	diff := &OperatorDiff{}
+	pending := make(map[string][]*OperatorRecord)
+	for _, op := range recorded {
+		k := op.key()
+		pending[k] = append(pending[k], op)
+	}
+	for _, op := range replayed {
+		k := op.key()
+		if ops := pending[k]; len(ops) > 0 {
+			pending[k] = ops[1:]
+			diff.Matched++
+			continue
+		}
+		diff.OnlyReplayed = append(diff.OnlyReplayed, op)
+	}
+	// Keep the order of the recording.
+	for _, op := range recorded {
+		k := op.key()
+		if ops := pending[k]; len(ops) > 0 && ops[0] == op {
+			pending[k] = ops[1:]
+			diff.OnlyRecorded = append(diff.OnlyRecorded, op)
+		}
+	}
+	return diff
+}
+
+// String implements fmt.Stringer.
+func (r *OperatorRecord) String() string {
+	return fmt.Sprintf("region %d %s: %s", r.RegionID, r.Desc, strings.Join(r.Steps, ", "))
+}
@@ -0,0 +1,173 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pkg/errors"
+)
+
+// RecordType is the type of a record.
+type RecordType byte
+
+// Record types.
+const (
+	RecordRegionHeartbeat RecordType = iota + 1
+	RecordStoreHeartbeat
+	RecordOperator
+)
+
+// fileMagic is written at the beginning of each recording file.
+const fileMagic = "PDHB0001"
+
+// OperatorRecord is an operator created by PD.
+type OperatorRecord struct {
+	RegionID uint64   `json:"region_id"`
+	Desc     string   `json:"desc"`
+	Kind     string   `json:"kind"`
+	Steps    []string `json:"steps"`
+}
+
+// Record is a heartbeat received or an operator created by PD.
+type Record struct {
+	Type RecordType
+	Time time.Time
+	// Region is set for RecordRegionHeartbeat.
+	Region *pdpb.RegionHeartbeatRequest
+	// Store and StoreStats are set for RecordStoreHeartbeat.
+	Store      *metapb.Store
+	StoreStats *pdpb.StoreStats
+	// Operator is set for RecordOperator.
+	Operator *OperatorRecord
+}
+
+// encodeRecord encodes a record as the type, the time in unix nanoseconds and
+// the length-prefixed chunks of the payload.
+func encodeRecord(w io.Writer, r *Record) (int, error) {
+	var chunks [][]byte
+	switch r.Type {
+	case RecordRegionHeartbeat:
+		data, err := proto.Marshal(r.Region)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+		chunks = append(chunks, data)
+	case RecordStoreHeartbeat:
+		store, err := proto.Marshal(r.Store)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+		stats, err := proto.Marshal(r.StoreStats)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+		chunks = append(chunks, store, stats)
+	case RecordOperator:
+		data, err := json.Marshal(r.Operator)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+		chunks = append(chunks, data)
+	default:
+		return 0, errors.Errorf("unknown record type %d", r.Type)
+	}
+
+	buf := make([]byte, 0, 1+binary.MaxVarintLen64*(1+len(chunks)))
+	buf = append(buf, byte(r.Type))
+	buf = appendUvarint(buf, uint64(r.Time.UnixNano()))
+	for _, chunk := range chunks {
+		buf = appendUvarint(buf, uint64(len(chunk)))
+		buf = append(buf, chunk...)
+	}
+	n, err := w.Write(buf)
+	return n, errors.WithStack(err)
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append(buf, tmp[:n]...)
+}
+
+// decodeRecord decodes a record. It returns io.EOF if there is no more record,
+// and io.ErrUnexpectedEOF if the record is truncated.
+func decodeRecord(r *bufio.Reader) (*Record, error) {
+	typ, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	ts, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, truncated(err)
+	}
+	record := &Record{Type: RecordType(typ), Time: time.Unix(0, int64(ts))}
+	switch record.Type {
+	case RecordRegionHeartbeat:
+		record.Region = &pdpb.RegionHeartbeatRequest{}
+		err = readProtoChunk(r, record.Region)
+	case RecordStoreHeartbeat:
+		record.Store, record.StoreStats = &metapb.Store{}, &pdpb.StoreStats{}
+		if err = readProtoChunk(r, record.Store); err == nil {
+			err = readProtoChunk(r, record.StoreStats)
+		}
+	case RecordOperator:
+		var data []byte
+		if data, err = readChunk(r); err == nil {
+			record.Operator = &OperatorRecord{}
+			err = errors.WithStack(json.Unmarshal(data, record.Operator))
+		}
+	default:
+		return nil, errors.Errorf("unknown record type %d", typ)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func readChunk(r *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, truncated(err)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, truncated(err)
+	}
+	return data, nil
+}
+
+func readProtoChunk(r *bufio.Reader, msg proto.Message) error {
+	data, err := readChunk(r)
+	if err != nil {
+		return err
+	}
+	return errors.WithStack(proto.Unmarshal(data, msg))
+}
+
+func truncated(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
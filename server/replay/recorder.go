@@ -0,0 +1,335 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	filePrefix    = "heartbeat-"
+	fileSuffix    = ".rec"
+	flushInterval = time.Second
+	// recordChanSize is the number of records that can be queued before the
+	// writer. Records are dropped when the queue is full so that recording
+	// never blocks heartbeats.
+	recordChanSize = 10240
+)
+
+// Recorder records heartbeats and operators into rotating files. It is safe
+// for concurrent use. The records are queued and written by a background
+// goroutine, which owns the file.
+type Recorder struct {
+	dir         string
+	maxFileSize int64
+	maxFiles    int
+
+	file   *os.File
+	writer *bufio.Writer
+	size   int64
+
+	records chan *Record
+	dropped uint64
+	quit    chan struct{}
+	once    sync.Once
+	wg      sync.WaitGroup
+	err     error
+}
+
+// NewRecorder creates a Recorder which writes to dir.
+func NewRecorder(dir string, maxFileSize int64, maxFiles int) (*Recorder, error) {
+	r, err := newRecorder(dir, maxFileSize, maxFiles, recordChanSize)
+	if err != nil {
+		return nil, err
+	}
+	r.start()
+	return r, nil
+}
+
+func newRecorder(dir string, maxFileSize int64, maxFiles int, chanSize int) (*Recorder, error) {
+	if maxFiles < 1 {
+		maxFiles = 1
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	r := &Recorder{
+		dir:         dir,
+		maxFileSize: maxFileSize,
+		maxFiles:    maxFiles,
+		records:     make(chan *Record, chanSize),
+		quit:        make(chan struct{}),
+	}
+	if err := r.rotate(); err != nil {
+		r.closeFile()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Recorder) start() {
+	r.wg.Add(1)
+	go r.run()
+}
+
+// RecordRegionHeartbeat records a region heartbeat.
+func (r *Recorder) RecordRegionHeartbeat(region *core.RegionInfo) {
+	r.record(&Record{
+		Type: RecordRegionHeartbeat,
+		Time: time.Now(),
+		Region: &pdpb.RegionHeartbeatRequest{
+			Region:          region.GetMeta(),
+			Leader:          region.GetLeader(),
+			DownPeers:       region.GetDownPeers(),
+			PendingPeers:    region.GetPendingPeers(),
+			BytesWritten:    region.GetBytesWritten(),
+			BytesRead:       region.GetBytesRead(),
+			KeysWritten:     region.GetKeysWritten(),
+			KeysRead:        region.GetKeysRead(),
+			ApproximateSize: uint64(region.GetApproximateSize()) << 20,
+			ApproximateKeys: uint64(region.GetApproximateKeys()),
+			Interval:        region.GetInterval(),
+			ReplicateStatus: region.GetReplicateStatus(),
+		},
+	})
+}
+
+// RecordStoreHeartbeat records a store heartbeat with the meta of the store.
+func (r *Recorder) RecordStoreHeartbeat(store *metapb.Store, stats *pdpb.StoreStats) {
+	r.record(&Record{
+		Type:       RecordStoreHeartbeat,
+		Time:       time.Now(),
+		Store:      store,
+		StoreStats: stats,
+	})
+}
+
+// RecordOperator records an operator.
+func (r *Recorder) RecordOperator(op *operator.Operator) {
+	steps := make([]string, 0, op.Len())
+	for i := 0; i < op.Len(); i++ {
+		steps = append(steps, op.Step(i).String())
+	}
+	r.record(&Record{
+		Type: RecordOperator,
+		Time: time.Now(),
+		Operator: &OperatorRecord{
+			RegionID: op.RegionID(),
+			Desc:     op.Desc(),
+			Kind:     op.Kind().String(),
+			Steps:    steps,
+		},
+	})
+}
+
+func (r *Recorder) record(record *Record) {
+	select {
+	case <-r.quit:
+		return
+	default:
+	}
+	select {
+	case r.records <- record:
+	default:
+		atomic.AddUint64(&r.dropped, 1)
+	}
+}
+
+func (r *Recorder) run() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case record := <-r.records:
+			r.write(record)
+		case <-ticker.C:
+			r.flush()
+		case <-r.quit:
+			// Write the queued records before closing the file.
+			for {
+				select {
+				case record := <-r.records:
+					r.write(record)
+				default:
+					r.logDropped()
+					r.err = r.closeFile()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (r *Recorder) write(record *Record) {
+	// The writer is nil if the last rotation failed, retry it.
+	if r.writer == nil {
+		if err := r.rotate(); err != nil {
+			log.Error("failed to rotate heartbeat recording file", zap.Error(err))
+			atomic.AddUint64(&r.dropped, 1)
+			return
+		}
+	}
+	n, err := encodeRecord(r.writer, record)
+	if err != nil {
+		log.Error("failed to record heartbeat", zap.Error(err))
+		return
+	}
+	r.size += int64(n)
+	if r.size >= r.maxFileSize {
+		if err := r.rotate(); err != nil {
+			log.Error("failed to rotate heartbeat recording file", zap.Error(err))
+		}
+	}
+}
+
+func (r *Recorder) flush() {
+	r.logDropped()
+	if r.writer == nil {
+		return
+	}
+	if err := r.writer.Flush(); err != nil {
+		log.Error("failed to flush heartbeat recording file", zap.Error(err))
+	}
+}
+
+func (r *Recorder) logDropped() {
+	if dropped := atomic.SwapUint64(&r.dropped, 0); dropped > 0 {
+		log.Warn("heartbeat records are dropped", zap.Uint64("count", dropped))
+	}
+}
+
+// rotate closes the current file, opens a new one and removes the oldest
+// files. It should only be called by the constructor or the writer goroutine.
+// The writer is left nil if it fails.
+func (r *Recorder) rotate() error {
+	if err := r.closeFile(); err != nil {
+		log.Error("failed to close heartbeat recording file", zap.Error(err))
+	}
+	name := filepath.Join(r.dir, fmt.Sprintf("%s%020d%s", filePrefix, time.Now().UnixNano(), fileSuffix))
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	r.file, r.writer, r.size = f, bufio.NewWriter(f), 0
+	n, err := r.writer.WriteString(fileMagic)
+	if err != nil {
+		r.closeFile()
+		return errors.WithStack(err)
+	}
+	r.size += int64(n)
+
+	files, err := ListFiles(r.dir)
+	if err != nil {
+		return err
+	}
+	for len(files) > r.maxFiles {
+		if err := os.Remove(files[0]); err != nil {
+			return errors.WithStack(err)
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+// closeFile flushes and closes the current file. The file and the writer are
+// always reset.
+func (r *Recorder) closeFile() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.writer.Flush()
+	if cerr := r.file.Close(); err == nil {
+		err = cerr
+	}
+	r.file, r.writer = nil, nil
+	return errors.WithStack(err)
+}
+
+// Close writes the queued records and closes the recording file. Records
+// after closed are ignored.
+func (r *Recorder) Close() error {
+	r.once.Do(func() {
+		close(r.quit)
+		r.wg.Wait()
+	})
+	return r.err
+}
+
+// ListFiles returns the recording files in dir from the oldest to the newest.
+func ListFiles(dir string) ([]string, error) {
+	entries, err := filepath.Glob(filepath.Join(dir, filePrefix+"*"+fileSuffix))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	sort.Strings(entries)
+	return entries, nil
+}
+
+// ReadFiles reads the records of the files in order. A truncated record at
+// the end of a file, which may be left by a crash, is ignored.
+func ReadFiles(files []string, f func(*Record) error) error {
+	for _, name := range files {
+		if err := readFile(name, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readFile(name string, f func(*Record) error) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer file.Close()
+	reader := bufio.NewReader(file)
+	magic := make([]byte, len(fileMagic))
+	if _, err := io.ReadFull(reader, magic); err != nil || string(magic) != fileMagic {
+		return errors.Errorf("%s is not a heartbeat recording file", name)
+	}
+	for {
+		record, err := decodeRecord(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err == io.ErrUnexpectedEOF {
+			log.Warn("ignore the truncated record", zap.String("file", name))
+			return nil
+		}
+		if err != nil {
+			return errors.WithMessage(err, name)
+		}
+		if err := f(record); err != nil {
+			return err
+		}
+	}
+}
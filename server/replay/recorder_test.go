@@ -0,0 +1,207 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+)
+
+func TestReplay(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testRecorderSuite{})
+
+type testRecorderSuite struct {
+	dir string
+}
+
+func (s *testRecorderSuite) SetUpTest(c *C) {
+	var err error
+	s.dir, err = ioutil.TempDir("", "pd-replay")
+	c.Assert(err, IsNil)
+}
+
+func (s *testRecorderSuite) TearDownTest(c *C) {
+	os.RemoveAll(s.dir)
+}
+
+func newTestRegion(id uint64) *core.RegionInfo {
+	peers := []*metapb.Peer{{Id: id + 1, StoreId: 1}, {Id: id + 2, StoreId: 2}}
+	return core.NewRegionInfo(&metapb.Region{
+		Id:          id,
+		StartKey:    []byte{byte(id)},
+		EndKey:      []byte{byte(id + 1)},
+		Peers:       peers,
+		RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+	}, peers[0], core.SetApproximateSize(10), core.SetWrittenBytes(100))
+}
+
+func (s *testRecorderSuite) readAll(c *C) []*Record {
+	files, err := ListFiles(s.dir)
+	c.Assert(err, IsNil)
+	var records []*Record
+	c.Assert(ReadFiles(files, func(r *Record) error {
+		records = append(records, r)
+		return nil
+	}), IsNil)
+	return records
+}
+
+func (s *testRecorderSuite) TestRecord(c *C) {
+	r, err := NewRecorder(s.dir, 1<<20, 2)
+	c.Assert(err, IsNil)
+	region := newTestRegion(10)
+	r.RecordRegionHeartbeat(region)
+	store := &metapb.Store{Id: 1, Address: "mock://1", Labels: []*metapb.StoreLabel{{Key: "zone", Value: "z1"}}}
+	r.RecordStoreHeartbeat(store, &pdpb.StoreStats{StoreId: 1, Capacity: 100, Available: 50})
+	op := operator.NewOperator("test", "test", 10, region.GetRegionEpoch(), operator.OpRegion,
+		operator.AddLearner{ToStore: 3, PeerID: 13}, operator.PromoteLearner{ToStore: 3, PeerID: 13}, operator.RemovePeer{FromStore: 1})
+	r.RecordOperator(op)
+	c.Assert(r.Close(), IsNil)
+	// Records after closed are ignored.
+	r.RecordRegionHeartbeat(region)
+	c.Assert(r.Close(), IsNil)
+
+	records := s.readAll(c)
+	c.Assert(records, HasLen, 3)
+	c.Assert(records[0].Type, Equals, RecordRegionHeartbeat)
+	c.Assert(records[0].Region.GetRegion(), DeepEquals, region.GetMeta())
+	c.Assert(records[0].Region.GetLeader(), DeepEquals, region.GetLeader())
+	c.Assert(records[0].Region.GetApproximateSize(), Equals, uint64(10<<20))
+	c.Assert(records[0].Region.GetBytesWritten(), Equals, uint64(100))
+	c.Assert(records[1].Type, Equals, RecordStoreHeartbeat)
+	c.Assert(records[1].Store, DeepEquals, store)
+	c.Assert(records[1].StoreStats.GetAvailable(), Equals, uint64(50))
+	c.Assert(records[2].Type, Equals, RecordOperator)
+	c.Assert(records[2].Operator, DeepEquals, &OperatorRecord{
+		RegionID: 10,
+		Desc:     "test",
+		Kind:     op.Kind().String(),
+		Steps: []string{
+			"add learner peer 13 on store 3",
+			"promote learner peer 13 on store 3 to voter",
+			"remove peer on store 1",
+		},
+	})
+	c.Assert(records[0].Time.After(records[2].Time), IsFalse)
+}
+
+func (s *testRecorderSuite) TestRotate(c *C) {
+	r, err := NewRecorder(s.dir, 512, 2)
+	c.Assert(err, IsNil)
+	for i := uint64(1); i <= 100; i++ {
+		r.RecordRegionHeartbeat(newTestRegion(i))
+	}
+	c.Assert(r.Close(), IsNil)
+
+	files, err := ListFiles(s.dir)
+	c.Assert(err, IsNil)
+	c.Assert(files, HasLen, 2)
+	// Only the latest records are kept, and they are in order.
+	records := s.readAll(c)
+	c.Assert(len(records), Less, 100)
+	c.Assert(records[len(records)-1].Region.GetRegion().GetId(), Equals, uint64(100))
+	for i := 1; i < len(records); i++ {
+		c.Assert(records[i].Region.GetRegion().GetId(), Equals, records[i-1].Region.GetRegion().GetId()+1)
+	}
+}
+
+func (s *testRecorderSuite) TestTruncated(c *C) {
+	r, err := NewRecorder(s.dir, 1<<20, 1)
+	c.Assert(err, IsNil)
+	r.RecordRegionHeartbeat(newTestRegion(1))
+	r.RecordRegionHeartbeat(newTestRegion(2))
+	c.Assert(r.Close(), IsNil)
+
+	files, err := ListFiles(s.dir)
+	c.Assert(err, IsNil)
+	c.Assert(files, HasLen, 1)
+	info, err := os.Stat(files[0])
+	c.Assert(err, IsNil)
+	c.Assert(os.Truncate(files[0], info.Size()-3), IsNil)
+
+	records := s.readAll(c)
+	c.Assert(records, HasLen, 1)
+	c.Assert(records[0].Region.GetRegion().GetId(), Equals, uint64(1))
+}
+
+func (s *testRecorderSuite) TestDropWhenFull(c *C) {
+	r, err := newRecorder(s.dir, 1<<20, 1, 2)
+	c.Assert(err, IsNil)
+	// The writer is not started, so the queue is full after two records.
+	for i := uint64(1); i <= 5; i++ {
+		r.RecordRegionHeartbeat(newTestRegion(i))
+	}
+	c.Assert(r.dropped, Equals, uint64(3))
+	r.start()
+	c.Assert(r.Close(), IsNil)
+
+	records := s.readAll(c)
+	c.Assert(records, HasLen, 2)
+	c.Assert(records[0].Region.GetRegion().GetId(), Equals, uint64(1))
+	c.Assert(records[1].Region.GetRegion().GetId(), Equals, uint64(2))
+}
+
+func (s *testRecorderSuite) TestRotateFailed(c *C) {
+	r, err := newRecorder(s.dir, 512, 2, 100)
+	c.Assert(err, IsNil)
+	// Opening a new file fails after the directory is removed.
+	c.Assert(os.RemoveAll(s.dir), IsNil)
+	for i := uint64(1); i <= 20; i++ {
+		r.write(&Record{Type: RecordRegionHeartbeat, Region: &pdpb.RegionHeartbeatRequest{Region: newTestRegion(i).GetMeta()}})
+	}
+	c.Assert(r.writer, IsNil)
+	r.flush()
+	// It is retried after the directory is back.
+	c.Assert(os.MkdirAll(s.dir, 0755), IsNil)
+	r.write(&Record{Type: RecordRegionHeartbeat, Region: &pdpb.RegionHeartbeatRequest{Region: newTestRegion(21).GetMeta()}})
+	c.Assert(r.writer, NotNil)
+	r.start()
+	c.Assert(r.Close(), IsNil)
+
+	records := s.readAll(c)
+	c.Assert(records, HasLen, 1)
+	c.Assert(records[0].Region.GetRegion().GetId(), Equals, uint64(21))
+}
+
+func (s *testRecorderSuite) TestDiffOperators(c *C) {
+	newOp := func(regionID uint64, desc string, steps ...string) *OperatorRecord {
+		return &OperatorRecord{RegionID: regionID, Desc: desc, Steps: steps}
+	}
+	recorded := []*OperatorRecord{
+		newOp(1, "balance-region", "add learner peer 5 on store 4", "remove peer on store 1"),
+		newOp(2, "balance-leader", "transfer leader from store 1 to store 2"),
+		newOp(2, "balance-leader", "transfer leader from store 1 to store 2"),
+		newOp(3, "merge-region", "merge region 3 into region 4"),
+	}
+	replayed := []*OperatorRecord{
+		newOp(2, "balance-leader", "transfer leader from store 1 to store 2"),
+		newOp(1, "balance-region", "add learner peer 20 on store 4", "remove peer on store 1"),
+		newOp(5, "balance-leader", "transfer leader from store 1 to store 3"),
+	}
+	diff := DiffOperators(recorded, replayed)
+	c.Assert(diff.Matched, Equals, 2)
+	c.Assert(diff.OnlyRecorded, DeepEquals, []*OperatorRecord{recorded[2], recorded[3]})
+	c.Assert(diff.OnlyReplayed, DeepEquals, []*OperatorRecord{replayed[2]})
+}
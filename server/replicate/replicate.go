@@ -16,11 +16,16 @@ package replicate
 import (
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/pingcap/kvproto/pkg/metapb"
 	pb "github.com/pingcap/kvproto/pkg/replicate_mode"
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/logutil"
 	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/id"
+	"go.uber.org/zap"
 )
 
 const (
@@ -28,6 +33,13 @@ const (
 	modeDRAutosync = "dr_autosync"
 )
 
+// Cluster is the subset of cluster information used by ModeManager.
+type Cluster interface {
+	GetStores() []*core.StoreInfo
+	GetRegionCount() int
+	ScanRegions(startKey, endKey []byte, limit int) []*core.RegionInfo
+}
+
 // ModeManager is used to control how raft logs are synchronized between
 // different tikv nodes.
 type ModeManager struct {
@@ -35,16 +47,24 @@ type ModeManager struct {
 	config  config.ReplicateModeConfig
 	storage *core.Storage
 	idAlloc id.Allocator
+	cluster Cluster
 
 	drAutosync drAutosyncStatus
+	// intermediate progress of sync_recover, reset on every state change.
+	drRecoverKey   []byte
+	drRecoverCount int
+	// the time when switched to sync_recover, the recovery restarts if
+	// regions have not caught up in WaitSyncTimeout since then.
+	drRecoverStart time.Time
 }
 
 // NewReplicateModeManager creates the replicate mode manager.
-func NewReplicateModeManager(config config.ReplicateModeConfig, storage *core.Storage, idAlloc id.Allocator) (*ModeManager, error) {
+func NewReplicateModeManager(config config.ReplicateModeConfig, storage *core.Storage, idAlloc id.Allocator, cluster Cluster) (*ModeManager, error) {
 	m := &ModeManager{
 		config:  config,
 		storage: storage,
 		idAlloc: idAlloc,
+		cluster: cluster,
 	}
 	switch config.ReplicateMode {
 	case modeMajority:
@@ -90,6 +110,41 @@ type drAutosyncStatus struct {
 	RecoverID uint64 `json:"recover_id,omitempty"`
 }
 
+// HTTPReplicateStatus is the replicate status exposed by the HTTP API.
+type HTTPReplicateStatus struct {
+	Mode       string                `json:"mode"`
+	DrAutosync *HTTPDRAutosyncStatus `json:"dr_autosync,omitempty"`
+}
+
+// HTTPDRAutosyncStatus is the dr_autosync status exposed by the HTTP API.
+type HTTPDRAutosyncStatus struct {
+	LabelKey        string  `json:"label_key"`
+	State           string  `json:"state"`
+	RecoverID       uint64  `json:"recover_id,omitempty"`
+	RecoverProgress float32 `json:"recover_progress,omitempty"`
+}
+
+// GetReplicateStatusHTTP returns the status for the HTTP API.
+func (m *ModeManager) GetReplicateStatusHTTP() *HTTPReplicateStatus {
+	m.RLock()
+	defer m.RUnlock()
+	var status HTTPReplicateStatus
+	status.Mode = m.config.ReplicateMode
+	switch m.config.ReplicateMode {
+	case modeMajority:
+	case modeDRAutosync:
+		status.DrAutosync = &HTTPDRAutosyncStatus{
+			LabelKey: m.config.DRAutoSync.LabelKey,
+			State:    m.drAutosync.State,
+		}
+		if m.drAutosync.State == drStateSyncRecover {
+			status.DrAutosync.RecoverID = m.drAutosync.RecoverID
+			status.DrAutosync.RecoverProgress = m.estimateProgress()
+		}
+	}
+	return &status
+}
+
 func (m *ModeManager) loadDRAutosync() error {
 	ok, err := m.storage.LoadReplicateStatus(modeDRAutosync, &m.drAutosync)
 	if err != nil {
@@ -99,6 +154,9 @@ func (m *ModeManager) loadDRAutosync() error {
 		// initialize
 		m.drAutosync = drAutosyncStatus{State: drStateSync}
 	}
+	if m.drAutosync.State == drStateSyncRecover {
+		m.drRecoverStart = time.Now()
+	}
 	return nil
 }
 
@@ -107,9 +165,11 @@ func (m *ModeManager) drSwitchToAsync() error {
 	defer m.Unlock()
 	dr := drAutosyncStatus{State: drStateAsync}
 	if err := m.storage.SaveReplicateStatus(modeDRAutosync, dr); err != nil {
+		log.Warn("failed to switch to async state", zap.String("replicate-mode", modeDRAutosync), zap.Error(err))
 		return err
 	}
 	m.drAutosync = dr
+	log.Info("switched to async state", zap.String("replicate-mode", modeDRAutosync))
 	return nil
 }
 
@@ -118,13 +178,18 @@ func (m *ModeManager) drSwitchToSyncRecover() error {
 	defer m.Unlock()
 	id, err := m.idAlloc.Alloc()
 	if err != nil {
+		log.Warn("failed to switch to sync_recover state", zap.String("replicate-mode", modeDRAutosync), zap.Error(err))
 		return err
 	}
 	dr := drAutosyncStatus{State: drStateSyncRecover, RecoverID: id}
 	if err = m.storage.SaveReplicateStatus(modeDRAutosync, dr); err != nil {
+		log.Warn("failed to switch to sync_recover state", zap.String("replicate-mode", modeDRAutosync), zap.Error(err))
 		return err
 	}
 	m.drAutosync = dr
+	m.drRecoverKey, m.drRecoverCount = nil, 0
+	m.drRecoverStart = time.Now()
+	log.Info("switched to sync_recover state", zap.String("replicate-mode", modeDRAutosync), zap.Uint64("recover-id", id))
 	return nil
 }
 
@@ -133,8 +198,166 @@ func (m *ModeManager) drSwitchToSync() error {
 	defer m.Unlock()
 	dr := drAutosyncStatus{State: drStateSync}
 	if err := m.storage.SaveReplicateStatus(modeDRAutosync, dr); err != nil {
+		log.Warn("failed to switch to sync state", zap.String("replicate-mode", modeDRAutosync), zap.Error(err))
 		return err
 	}
 	m.drAutosync = dr
+	log.Info("switched to sync state", zap.String("replicate-mode", modeDRAutosync))
 	return nil
 }
+
+func (m *ModeManager) drGetState() string {
+	m.RLock()
+	defer m.RUnlock()
+	return m.drAutosync.State
+}
+
+var (
+	tickInterval = 10 * time.Second
+	// the maximum number of regions checked in one tick for sync_recover.
+	regionScanBatchSize = 1024
+	regionScanBatches   = 10
+)
+
+// Run starts the background job.
+func (m *ModeManager) Run(quit <-chan struct{}) {
+	defer logutil.LogPanic()
+	// Wait for a while when just start, in case tikv do not connect in time.
+	select {
+	case <-time.After(m.config.DRAutoSync.WaitStoreTimeout.Duration):
+	case <-quit:
+		return
+	}
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.tick()
+		case <-quit:
+			log.Info("replicate mode manager has been stopped")
+			return
+		}
+	}
+}
+
+func (m *ModeManager) tick() {
+	m.RLock()
+	mode := m.config.ReplicateMode
+	m.RUnlock()
+
+	switch mode {
+	case modeMajority:
+	case modeDRAutosync:
+		m.tickDR()
+	}
+}
+
+func (m *ModeManager) tickDR() {
+	downPrimary, downDR, upPrimary, upDR := m.checkStoreStatus()
+
+	canSync := m.config.DRAutoSync.PrimaryReplicas > downPrimary && m.config.DRAutoSync.DRReplicas > downDR
+	hasMajority := upPrimary+upDR > (m.config.DRAutoSync.PrimaryReplicas+m.config.DRAutoSync.DRReplicas)/2
+
+	// The state is not changed if a switch fails, and the switch is retried
+	// in the next tick.
+
+	// If hasMajority is false, the cluster is always unavailable. Switch to async won't help.
+	if !canSync && hasMajority && m.drGetState() != drStateAsync {
+		if m.drSwitchToAsync() != nil {
+			return
+		}
+	}
+
+	if canSync && m.drGetState() == drStateAsync {
+		if m.drSwitchToSyncRecover() != nil {
+			return
+		}
+	}
+
+	if m.drGetState() == drStateSyncRecover {
+		if m.updateProgress() {
+			m.drSwitchToSync()
+		} else if m.drRecoverTimeout() {
+			// Switch back to async, so that the recovery starts again with a
+			// new recover ID in the next tick.
+			log.Warn("regions have not caught up in time, recover again", zap.String("replicate-mode", modeDRAutosync), zap.Duration("wait-sync-timeout", m.config.DRAutoSync.WaitSyncTimeout.Duration))
+			m.drSwitchToAsync()
+		}
+	}
+}
+
+func (m *ModeManager) drRecoverTimeout() bool {
+	m.RLock()
+	defer m.RUnlock()
+	return time.Since(m.drRecoverStart) >= m.config.DRAutoSync.WaitSyncTimeout.Duration
+}
+
+// checkStoreStatus counts the stores in both data centers. A store is
+// considered down if it has not sent heartbeat for WaitStoreTimeout.
+func (m *ModeManager) checkStoreStatus() (downPrimary, downDR, upPrimary, upDR int) {
+	m.RLock()
+	defer m.RUnlock()
+	for _, s := range m.cluster.GetStores() {
+		if s.GetState() == metapb.StoreState_Tombstone {
+			continue
+		}
+		down := s.DownTime() >= m.config.DRAutoSync.WaitStoreTimeout.Duration
+		labelValue := s.GetLabelValue(m.config.DRAutoSync.LabelKey)
+		if labelValue == m.config.DRAutoSync.Primary {
+			if down {
+				downPrimary++
+			} else {
+				upPrimary++
+			}
+		}
+		if labelValue == m.config.DRAutoSync.DR {
+			if down {
+				downDR++
+			} else {
+				upDR++
+			}
+		}
+	}
+	return
+}
+
+// updateProgress scans regions from the last checked key and checks whether
+// they have caught up with the current recover ID. It returns true once all
+// regions have been synced.
+func (m *ModeManager) updateProgress() bool {
+	m.Lock()
+	defer m.Unlock()
+
+	for i := 0; i < regionScanBatches; i++ {
+		regions := m.cluster.ScanRegions(m.drRecoverKey, nil, regionScanBatchSize)
+		if len(regions) == 0 {
+			log.Warn("no region is found", zap.String("replicate-mode", modeDRAutosync), zap.String("start-key", core.HexRegionKeyStr(m.drRecoverKey)))
+			return false
+		}
+		for _, r := range regions {
+			if !m.checkRegionRecover(r) {
+				return false
+			}
+			m.drRecoverKey = r.GetEndKey()
+			m.drRecoverCount++
+			if len(m.drRecoverKey) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *ModeManager) checkRegionRecover(region *core.RegionInfo) bool {
+	return region.GetReplicateStatus().GetState() == pb.RegionReplicateStatus_INTEGRITY_OVER_LABEL &&
+		region.GetReplicateStatus().GetRecoverId() == m.drAutosync.RecoverID
+}
+
+func (m *ModeManager) estimateProgress() float32 {
+	total := m.cluster.GetRegionCount()
+	if total == 0 || m.drRecoverCount >= total {
+		return 1
+	}
+	return float32(m.drRecoverCount) / float32(total)
+}
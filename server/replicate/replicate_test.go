@@ -19,11 +19,14 @@ import (
 
 	. "github.com/pingcap/check"
 	pb "github.com/pingcap/kvproto/pkg/replicate_mode"
+	"github.com/pingcap/pd/v4/pkg/mock/mockcluster"
 	"github.com/pingcap/pd/v4/pkg/mock/mockid"
+	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
 	"github.com/pingcap/pd/v4/pkg/typeutil"
 	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/kv"
+	"github.com/pkg/errors"
 )
 
 func TestReplicateMode(t *testing.T) {
@@ -38,7 +41,7 @@ func (s *testReplicateMode) TestInitial(c *C) {
 	store := core.NewStorage(kv.NewMemoryKV())
 	id := mockid.NewIDAllocator()
 	conf := config.ReplicateModeConfig{ReplicateMode: modeMajority}
-	rep, err := NewReplicateModeManager(conf, store, id)
+	rep, err := NewReplicateModeManager(conf, store, id, nil)
 	c.Assert(err, IsNil)
 	c.Assert(rep.GetReplicateStatus(), DeepEquals, &pb.ReplicateStatus{Mode: pb.ReplicateStatus_MAJORITY})
 
@@ -51,7 +54,7 @@ func (s *testReplicateMode) TestInitial(c *C) {
 		WaitStoreTimeout: typeutil.Duration{Duration: time.Minute},
 		WaitSyncTimeout:  typeutil.Duration{Duration: time.Minute},
 	}}
-	rep, err = NewReplicateModeManager(conf, store, id)
+	rep, err = NewReplicateModeManager(conf, store, id, nil)
 	c.Assert(err, IsNil)
 	c.Assert(rep.GetReplicateStatus(), DeepEquals, &pb.ReplicateStatus{
 		Mode: pb.ReplicateStatus_DR_AUTOSYNC,
@@ -69,7 +72,7 @@ func (s *testReplicateMode) TestStatus(c *C) {
 		LabelKey:        "dr-label",
 		WaitSyncTimeout: typeutil.Duration{Duration: time.Minute},
 	}}
-	rep, err := NewReplicateModeManager(conf, store, id)
+	rep, err := NewReplicateModeManager(conf, store, id, nil)
 	c.Assert(err, IsNil)
 	c.Assert(rep.GetReplicateStatus(), DeepEquals, &pb.ReplicateStatus{
 		Mode: pb.ReplicateStatus_DR_AUTOSYNC,
@@ -103,7 +106,7 @@ func (s *testReplicateMode) TestStatus(c *C) {
 	})
 
 	// test reload
-	rep, err = NewReplicateModeManager(conf, store, id)
+	rep, err = NewReplicateModeManager(conf, store, id, nil)
 	c.Assert(err, IsNil)
 	c.Assert(rep.drAutosync.State, Equals, drStateSyncRecover)
 
@@ -117,3 +120,132 @@ func (s *testReplicateMode) TestStatus(c *C) {
 		},
 	})
 }
+
+func (s *testReplicateMode) TestStateSwitch(c *C) {
+	store := core.NewStorage(kv.NewMemoryKV())
+	conf := config.ReplicateModeConfig{ReplicateMode: modeDRAutosync, DRAutoSync: config.DRAutoSyncReplicateConfig{
+		LabelKey:         "zone",
+		Primary:          "zone1",
+		DR:               "zone2",
+		PrimaryReplicas:  2,
+		DRReplicas:       1,
+		WaitStoreTimeout: typeutil.Duration{Duration: time.Minute},
+		WaitSyncTimeout:  typeutil.Duration{Duration: time.Minute},
+	}}
+	cluster := mockcluster.NewCluster(mockoption.NewScheduleOptions())
+	rep, err := NewReplicateModeManager(conf, store, cluster, cluster)
+	c.Assert(err, IsNil)
+
+	cluster.AddLabelsStore(1, 1, map[string]string{"zone": "zone1"})
+	cluster.AddLabelsStore(2, 1, map[string]string{"zone": "zone1"})
+	cluster.AddLabelsStore(3, 1, map[string]string{"zone": "zone2"})
+	cluster.AddLeaderRegionWithRange(1, "", "a", 1, 2, 3)
+	cluster.AddLeaderRegionWithRange(2, "a", "", 1, 2, 3)
+
+	// initial state is sync
+	c.Assert(rep.drGetState(), Equals, drStateSync)
+
+	// dr center is down
+	cluster.SetStoreDown(3)
+	rep.tickDR()
+	c.Assert(rep.drGetState(), Equals, drStateAsync)
+
+	// the state should be persisted
+	rep2, err := NewReplicateModeManager(conf, store, cluster, cluster)
+	c.Assert(err, IsNil)
+	c.Assert(rep2.drGetState(), Equals, drStateAsync)
+
+	// both primary and dr are down, cannot switch to async
+	rep.drSwitchToSync()
+	cluster.SetStoreDown(1)
+	cluster.SetStoreDown(2)
+	rep.tickDR()
+	c.Assert(rep.drGetState(), Equals, drStateSync)
+
+	// dr center is back
+	cluster.SetStoreUp(1)
+	cluster.SetStoreUp(2)
+	rep.drSwitchToAsync()
+	cluster.SetStoreUp(3)
+	rep.tickDR()
+	c.Assert(rep.drGetState(), Equals, drStateSyncRecover)
+	recoverID := rep.drAutosync.RecoverID
+
+	// regions have not caught up yet
+	rep.tickDR()
+	c.Assert(rep.drGetState(), Equals, drStateSyncRecover)
+	c.Assert(rep.GetReplicateStatusHTTP().DrAutosync.RecoverProgress, Equals, float32(0))
+
+	setRegionRecovered(cluster, 1, recoverID)
+	rep.tickDR()
+	c.Assert(rep.drGetState(), Equals, drStateSyncRecover)
+	c.Assert(rep.GetReplicateStatusHTTP().DrAutosync.RecoverProgress, Equals, float32(0.5))
+
+	setRegionRecovered(cluster, 2, recoverID)
+	rep.tickDR()
+	c.Assert(rep.drGetState(), Equals, drStateSync)
+
+	// regions have not caught up in WaitSyncTimeout, recover again
+	rep.drSwitchToAsync()
+	rep.tickDR()
+	c.Assert(rep.drGetState(), Equals, drStateSyncRecover)
+	recoverID = rep.drAutosync.RecoverID
+	rep.drRecoverStart = time.Now().Add(-time.Minute)
+	rep.tickDR()
+	c.Assert(rep.drGetState(), Equals, drStateAsync)
+	rep.tickDR()
+	c.Assert(rep.drGetState(), Equals, drStateSyncRecover)
+	c.Assert(rep.drAutosync.RecoverID, Not(Equals), recoverID)
+	recoverID = rep.drAutosync.RecoverID
+	setRegionRecovered(cluster, 1, recoverID)
+	setRegionRecovered(cluster, 2, recoverID)
+	rep.tickDR()
+	c.Assert(rep.drGetState(), Equals, drStateSync)
+}
+
+type errAllocator struct {
+	err error
+}
+
+func (a *errAllocator) Alloc() (uint64, error) {
+	if a.err != nil {
+		return 0, a.err
+	}
+	return 1, nil
+}
+
+func (s *testReplicateMode) TestSwitchRetry(c *C) {
+	store := core.NewStorage(kv.NewMemoryKV())
+	conf := config.ReplicateModeConfig{ReplicateMode: modeDRAutosync, DRAutoSync: config.DRAutoSyncReplicateConfig{
+		LabelKey:         "zone",
+		Primary:          "zone1",
+		DR:               "zone2",
+		PrimaryReplicas:  1,
+		DRReplicas:       1,
+		WaitStoreTimeout: typeutil.Duration{Duration: time.Minute},
+		WaitSyncTimeout:  typeutil.Duration{Duration: time.Minute},
+	}}
+	cluster := mockcluster.NewCluster(mockoption.NewScheduleOptions())
+	alloc := &errAllocator{err: errors.New("alloc failed")}
+	rep, err := NewReplicateModeManager(conf, store, alloc, cluster)
+	c.Assert(err, IsNil)
+	cluster.AddLabelsStore(1, 1, map[string]string{"zone": "zone1"})
+	cluster.AddLabelsStore(2, 1, map[string]string{"zone": "zone2"})
+	cluster.AddLeaderRegionWithRange(1, "", "", 1, 2)
+	c.Assert(rep.drSwitchToAsync(), IsNil)
+
+	// the failed switch is retried in the next tick
+	rep.tickDR()
+	c.Assert(rep.drGetState(), Equals, drStateAsync)
+	alloc.err = nil
+	rep.tickDR()
+	c.Assert(rep.drGetState(), Equals, drStateSyncRecover)
+}
+
+func setRegionRecovered(cluster *mockcluster.Cluster, regionID, recoverID uint64) {
+	region := cluster.GetRegion(regionID).Clone(core.SetReplicateStatus(&pb.RegionReplicateStatus{
+		State:     pb.RegionReplicateStatus_INTEGRITY_OVER_LABEL,
+		RecoverId: recoverID,
+	}))
+	cluster.PutRegion(region)
+}
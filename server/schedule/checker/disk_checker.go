@@ -0,0 +1,134 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/filter"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"go.uber.org/zap"
+)
+
+const diskCheckerName = "disk-checker"
+
+// DiskChecker moves the leaders away from the stores whose disk is almost full
+// or full, and moves the regions away from the stores whose disk is full.
+type DiskChecker struct {
+	cluster opt.Cluster
+}
+
+// NewDiskChecker creates a disk checker.
+func NewDiskChecker(cluster opt.Cluster) *DiskChecker {
+	return &DiskChecker{
+		cluster: cluster,
+	}
+}
+
+// Check verifies whether the region has a leader or a peer on the stores
+// whose disk is almost full or full, creating an Operator if need.
+func (d *DiskChecker) Check(region *core.RegionInfo) *operator.Operator {
+	checkerCounter.WithLabelValues("disk_checker", "check").Inc()
+	leaderStoreID := region.GetLeader().GetStoreId()
+	if d.diskState(leaderStoreID) != core.DiskStateNormal {
+		if op := d.transferLeader(region); op != nil {
+			checkerCounter.WithLabelValues("disk_checker", "new-operator").Inc()
+			return op
+		}
+	}
+	for _, peer := range region.GetPeers() {
+		if d.diskState(peer.GetStoreId()) != core.DiskStateFull {
+			continue
+		}
+		if op := d.movePeer(region, peer); op != nil {
+			checkerCounter.WithLabelValues("disk_checker", "new-operator").Inc()
+			op.SetPriorityLevel(core.HighPriority)
+			return op
+		}
+	}
+	return nil
+}
+
+func (d *DiskChecker) diskState(storeID uint64) core.StoreDiskState {
+	store := d.cluster.GetStore(storeID)
+	if store == nil {
+		return core.DiskStateNormal
+	}
+	return store.DiskState(d.cluster.GetDiskAlmostFullRatio(), d.cluster.GetDiskFullRatio())
+}
+
+// transferLeader transfers the leader to the follower with the lowest leader
+// score.
+func (d *DiskChecker) transferLeader(region *core.RegionInfo) *operator.Operator {
+	stores := d.cluster.GetFollowerStores(region)
+	stores = filter.SelectTargetStores(stores, []filter.Filter{
+		filter.StoreStateFilter{ActionScope: diskCheckerName, TransferLeader: true},
+		filter.NewLeaderStoreFilter(diskCheckerName, d.cluster, region),
+	}, d.cluster)
+	policy := d.cluster.GetLeaderSchedulePolicy()
+	var target *core.StoreInfo
+	for _, store := range stores {
+		if target == nil || store.LeaderScore(policy, 0) < target.LeaderScore(policy, 0) {
+			target = store
+		}
+	}
+	if target == nil {
+		checkerCounter.WithLabelValues("disk_checker", "no-target-store").Inc()
+		return nil
+	}
+	op, err := operator.CreateTransferLeaderOperator("disk-transfer-leader", d.cluster, region, region.GetLeader().GetStoreId(), target.GetID(), operator.OpLeader)
+	if err != nil {
+		log.Debug("fail to create disk transfer leader operator", zap.Error(err))
+		return nil
+	}
+	return op
+}
+
+// movePeer moves the peer to the best store which keeps the region fit.
+func (d *DiskChecker) movePeer(region *core.RegionInfo, peer *metapb.Peer) *operator.Operator {
+	var target *core.StoreInfo
+	if d.cluster.IsPlacementRulesEnabled() {
+		fit := d.cluster.FitRegion(region)
+		rf := fit.GetRuleFit(peer.GetId())
+		if rf == nil {
+			checkerCounter.WithLabelValues("disk_checker", "skip-orphan-peer").Inc()
+			return nil
+		}
+		target = SelectStoreToReplacePeerByRule(diskCheckerName, d.cluster, region, fit, rf, peer,
+			filter.NewRuleFitFilter(diskCheckerName, d.cluster, region, peer.GetStoreId()),
+			filter.NewDiskStateFilter(diskCheckerName, true))
+	} else {
+		source := d.cluster.GetStore(peer.GetStoreId())
+		scoreGuard := filter.NewDistinctScoreFilter(diskCheckerName, d.cluster.GetLocationLabels(), d.cluster.GetRegionStores(region), source)
+		storeID, _ := NewReplicaChecker(d.cluster, diskCheckerName).SelectBestReplacementStore(region, peer,
+			filter.StoreStateFilter{ActionScope: diskCheckerName, MoveRegion: true}, filter.NewDiskStateFilter(diskCheckerName, true), scoreGuard)
+		if storeID != 0 {
+			target = d.cluster.GetStore(storeID)
+		}
+	}
+	if target == nil {
+		checkerCounter.WithLabelValues("disk_checker", "no-target-store").Inc()
+		return nil
+	}
+	newPeer := &metapb.Peer{StoreId: target.GetID(), IsLearner: peer.GetIsLearner()}
+	op, err := operator.CreateMovePeerOperator("disk-move-peer", d.cluster, region, operator.OpReplica, peer.GetStoreId(), newPeer)
+	if err != nil {
+		log.Debug("fail to create disk move peer operator", zap.Error(err))
+		return nil
+	}
+	return op
+}
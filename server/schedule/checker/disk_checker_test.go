@@ -0,0 +1,83 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/v4/pkg/mock/mockcluster"
+	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+)
+
+var _ = Suite(&testDiskCheckerSuite{})
+
+type testDiskCheckerSuite struct {
+	cluster *mockcluster.Cluster
+	dc      *DiskChecker
+}
+
+func (s *testDiskCheckerSuite) SetUpTest(c *C) {
+	cfg := mockoption.NewScheduleOptions()
+	s.cluster = mockcluster.NewCluster(cfg)
+	s.dc = NewDiskChecker(s.cluster)
+	for i := uint64(1); i <= 4; i++ {
+		s.cluster.AddRegionStore(i, 1)
+		s.cluster.UpdateStorageRatio(i, 0.5, 0.5)
+	}
+}
+
+func (s *testDiskCheckerSuite) TestNormal(c *C) {
+	s.cluster.AddLeaderRegion(1, 1, 2, 3)
+	c.Assert(s.dc.Check(s.cluster.GetRegion(1)), IsNil)
+}
+
+func (s *testDiskCheckerSuite) TestAlmostFull(c *C) {
+	s.cluster.AddLeaderRegion(1, 1, 2, 3)
+	s.cluster.UpdateStorageRatio(1, 0.92, 0.08)
+	s.cluster.UpdateLeaderCount(2, 10)
+	// The leader is transferred to the follower with the lowest leader score.
+	op := s.dc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Kind()&operator.OpLeader, Not(Equals), operator.OpKind(0))
+	c.Assert(op.Step(0).(operator.TransferLeader).ToStore, Equals, uint64(3))
+
+	// The peers on an almost full store are not moved.
+	s.cluster.AddLeaderRegion(2, 2, 1, 3)
+	c.Assert(s.dc.Check(s.cluster.GetRegion(2)), IsNil)
+}
+
+func (s *testDiskCheckerSuite) TestFull(c *C) {
+	s.cluster.AddLeaderRegion(1, 1, 2, 3)
+	s.cluster.UpdateStorageRatio(1, 0.97, 0.03)
+	// Move the leader first.
+	op := s.dc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Len(), Equals, 1)
+	c.Assert(op.Step(0), FitsTypeOf, operator.TransferLeader{})
+
+	// Then move the region.
+	s.cluster.AddLeaderRegion(1, 2, 1, 3)
+	op = s.dc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "disk-move-peer")
+	c.Assert(op.GetPriorityLevel(), Equals, core.HighPriority)
+	c.Assert(op.Step(0).(operator.AddLearner).ToStore, Equals, uint64(4))
+	c.Assert(op.Step(op.Len()-1).(operator.RemovePeer).FromStore, Equals, uint64(1))
+
+	// There is no store to move to.
+	s.cluster.UpdateStorageRatio(4, 0.92, 0.08)
+	c.Assert(s.dc.Check(s.cluster.GetRegion(1)), IsNil)
+}
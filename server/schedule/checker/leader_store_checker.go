@@ -0,0 +1,76 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/filter"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"go.uber.org/zap"
+)
+
+const leaderStoreCheckerName = "leader-store-checker"
+
+// LeaderStoreChecker moves the leaders away from the stores which they are not
+// allowed to be on by the region labels.
+type LeaderStoreChecker struct {
+	cluster opt.Cluster
+}
+
+// NewLeaderStoreChecker creates a leader store checker.
+func NewLeaderStoreChecker(cluster opt.Cluster) *LeaderStoreChecker {
+	return &LeaderStoreChecker{
+		cluster: cluster,
+	}
+}
+
+// Check verifies whether the leader of the region is on a store which it is
+// not allowed to be on, creating an Operator if need.
+func (l *LeaderStoreChecker) Check(region *core.RegionInfo) *operator.Operator {
+	checkerCounter.WithLabelValues("leader_store_checker", "check").Inc()
+	leaderStore := l.cluster.GetStore(region.GetLeader().GetStoreId())
+	if leaderStore == nil || opt.IsLeaderStoreAllowed(l.cluster, region, leaderStore) {
+		return nil
+	}
+	if !opt.IsRegionScheduleAllowed(l.cluster, region) {
+		checkerCounter.WithLabelValues("leader_store_checker", "schedule-denied").Inc()
+		return nil
+	}
+	stores := l.cluster.GetFollowerStores(region)
+	stores = filter.SelectTargetStores(stores, []filter.Filter{
+		filter.StoreStateFilter{ActionScope: leaderStoreCheckerName, TransferLeader: true},
+		filter.NewLeaderStoreFilter(leaderStoreCheckerName, l.cluster, region),
+	}, l.cluster)
+	policy := l.cluster.GetLeaderSchedulePolicy()
+	var target *core.StoreInfo
+	for _, store := range stores {
+		if target == nil || store.LeaderScore(policy, 0) < target.LeaderScore(policy, 0) {
+			target = store
+		}
+	}
+	if target == nil {
+		checkerCounter.WithLabelValues("leader_store_checker", "no-target-store").Inc()
+		return nil
+	}
+	op, err := operator.CreateTransferLeaderOperator("leader-store-transfer-leader", l.cluster, region, leaderStore.GetID(), target.GetID(), operator.OpLeader)
+	if err != nil {
+		log.Debug("fail to create leader store transfer leader operator", zap.Error(err))
+		return nil
+	}
+	checkerCounter.WithLabelValues("leader_store_checker", "new-operator").Inc()
+	return op
+}
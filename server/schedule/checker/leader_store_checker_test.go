@@ -0,0 +1,78 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/v4/pkg/mock/mockcluster"
+	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+)
+
+var _ = Suite(&testLeaderStoreCheckerSuite{})
+
+type testLeaderStoreCheckerSuite struct {
+	cluster *mockcluster.Cluster
+	lc      *LeaderStoreChecker
+}
+
+func (s *testLeaderStoreCheckerSuite) SetUpTest(c *C) {
+	cfg := mockoption.NewScheduleOptions()
+	s.cluster = mockcluster.NewCluster(cfg)
+	s.lc = NewLeaderStoreChecker(s.cluster)
+	s.cluster.AddLabelsStore(1, 1, map[string]string{"zone": "z1"})
+	s.cluster.AddLabelsStore(2, 1, map[string]string{"zone": "z2"})
+	s.cluster.AddLabelsStore(3, 1, map[string]string{"zone": "z2"})
+	s.cluster.AddLabelsStore(4, 1, map[string]string{"zone": "z3"})
+}
+
+func (s *testLeaderStoreCheckerSuite) setRule(c *C, labels ...*labeler.RegionLabel) {
+	c.Assert(s.cluster.GetRegionLabeler().SetLabelRule(&labeler.LabelRule{ID: "rule1", Labels: labels}), IsNil)
+}
+
+func (s *testLeaderStoreCheckerSuite) TestNoLabel(c *C) {
+	s.cluster.AddLeaderRegion(1, 1, 2, 3)
+	c.Assert(s.lc.Check(s.cluster.GetRegion(1)), IsNil)
+}
+
+func (s *testLeaderStoreCheckerSuite) TestTransferLeader(c *C) {
+	s.setRule(c, &labeler.RegionLabel{Key: labeler.LeaderStoreLabelKey, Value: "zone=z2"})
+	s.cluster.AddLeaderRegion(1, 2, 1, 3)
+	c.Assert(s.lc.Check(s.cluster.GetRegion(1)), IsNil)
+
+	// The leader is transferred to the allowed follower with the lowest
+	// leader score.
+	s.cluster.AddLeaderRegion(1, 1, 2, 3)
+	s.cluster.UpdateLeaderCount(2, 10)
+	op := s.lc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "leader-store-transfer-leader")
+	c.Assert(op.Kind()&operator.OpLeader, Not(Equals), operator.OpKind(0))
+	c.Assert(op.Step(0).(operator.TransferLeader).ToStore, Equals, uint64(3))
+
+	// There is no allowed follower.
+	s.cluster.AddLeaderRegion(1, 1, 4)
+	c.Assert(s.lc.Check(s.cluster.GetRegion(1)), IsNil)
+}
+
+func (s *testLeaderStoreCheckerSuite) TestScheduleDenied(c *C) {
+	s.setRule(c,
+		&labeler.RegionLabel{Key: labeler.LeaderStoreLabelKey, Value: "zone=z2"},
+		&labeler.RegionLabel{Key: labeler.ScheduleKey, Value: labeler.DenyValue},
+	)
+	s.cluster.AddLeaderRegion(1, 1, 2, 3)
+	c.Assert(s.lc.Check(s.cluster.GetRegion(1)), IsNil)
+}
@@ -101,6 +101,11 @@ func (m *MergeChecker) Check(region *core.RegionInfo) []*operator.Operator {
 		return nil
 	}
 
+	if !opt.IsRegionMergeAllowed(m.cluster, region) {
+		checkerCounter.WithLabelValues("merge_checker", "label-deny").Inc()
+		return nil
+	}
+
 	prev, next := m.cluster.GetAdjacentRegions(region)
 
 	var target *core.RegionInfo
@@ -134,7 +139,8 @@ func (m *MergeChecker) Check(region *core.RegionInfo) []*operator.Operator {
 
 func (m *MergeChecker) checkTarget(region, adjacent *core.RegionInfo) bool {
 	return adjacent != nil && !m.cluster.IsRegionHot(adjacent) && AllowMerge(m.cluster, region, adjacent) &&
-		opt.IsRegionHealthy(m.cluster, adjacent) && opt.IsRegionReplicated(m.cluster, adjacent)
+		opt.IsRegionHealthy(m.cluster, adjacent) && opt.IsRegionReplicated(m.cluster, adjacent) &&
+		opt.IsRegionMergeAllowed(m.cluster, adjacent)
 }
 
 // AllowMerge returns true if two regions can be merged according to the key type.
@@ -25,6 +25,7 @@ import (
 	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
 	"github.com/pingcap/pd/v4/pkg/testutil"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
 	"github.com/pingcap/pd/v4/server/schedule/placement"
@@ -185,6 +186,22 @@ func (s *testMergeCheckerSuite) TestBasic(c *C) {
 	c.Assert(ops[1].RegionID(), Equals, s.regions[1].GetID())
 	s.cluster.RuleManager.DeleteRule("test", "test")
 
+	// The regions labeled to deny merge are not merged.
+	s.cluster.GetRegionLabeler().SetLabelRule(&labeler.LabelRule{
+		ID:          "test",
+		Labels:      []*labeler.RegionLabel{{Key: labeler.MergeKey, Value: labeler.DenyValue}},
+		StartKeyHex: hex.EncodeToString([]byte("x")),
+		EndKeyHex:   hex.EncodeToString([]byte("z")),
+	})
+	// region 2 can only merge with previous region now.
+	ops = s.mc.Check(s.regions[2])
+	c.Assert(ops, NotNil)
+	c.Assert(ops[0].RegionID(), Equals, s.regions[2].GetID())
+	c.Assert(ops[1].RegionID(), Equals, s.regions[1].GetID())
+	ops = s.mc.Check(s.regions[3])
+	c.Assert(ops, IsNil)
+	s.cluster.GetRegionLabeler().DeleteLabelRule("test")
+
 	// Skip recently split regions.
 	s.cluster.ScheduleOptions.SplitMergeInterval = time.Hour
 	s.mc.RecordRegionSplit([]uint64{s.regions[2].GetID()})
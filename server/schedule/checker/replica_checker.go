@@ -141,6 +141,7 @@ func (r *ReplicaChecker) selectBestStoreToAddReplica(region *core.RegionInfo, fi
 	newFilters := []filter.Filter{
 		filter.NewStateFilter(r.name),
 		filter.NewExcludedFilter(r.name, nil, region.GetStoreIds()),
+		filter.NewDiskStateFilter(r.name, false),
 	}
 	filters = append(filters, r.filters...)
 	filters = append(filters, newFilters...)
@@ -230,7 +231,7 @@ func (r *ReplicaChecker) checkBestReplacement(region *core.RegionInfo) *operator
 		checkerCounter.WithLabelValues("replica_checker", "all-right").Inc()
 		return nil
 	}
-	storeID, newScore := r.SelectBestReplacementStore(region, oldPeer, filter.NewStorageThresholdFilter(r.name))
+	storeID, newScore := r.SelectBestReplacementStore(region, oldPeer, filter.NewStorageThresholdFilter(r.name), filter.NewDiskStateFilter(r.name, true))
 	if storeID == 0 {
 		checkerCounter.WithLabelValues("replica_checker", "no-replacement-store").Inc()
 		return nil
@@ -139,7 +139,7 @@ func (c *RuleChecker) fixLooseMatchPeer(region *core.RegionInfo, fit *placement.
 	if region.GetLeader().GetId() == peer.GetId() && rf.Rule.Role == placement.Follower {
 		checkerCounter.WithLabelValues("rule_checker", "fix-leader-role").Inc()
 		for _, p := range region.GetPeers() {
-			if c.allowLeader(fit, p) {
+			if c.allowLeader(region, fit, p) {
 				return operator.CreateTransferLeaderOperator("fix-peer-role", c.cluster, region, peer.GetStoreId(), p.GetStoreId(), 0)
 			}
 		}
@@ -149,7 +149,7 @@ func (c *RuleChecker) fixLooseMatchPeer(region *core.RegionInfo, fit *placement.
 	return nil, nil
 }
 
-func (c *RuleChecker) allowLeader(fit *placement.RegionFit, peer *metapb.Peer) bool {
+func (c *RuleChecker) allowLeader(region *core.RegionInfo, fit *placement.RegionFit, peer *metapb.Peer) bool {
 	if peer.GetIsLearner() {
 		return false
 	}
@@ -157,8 +157,11 @@ func (c *RuleChecker) allowLeader(fit *placement.RegionFit, peer *metapb.Peer) b
 	if s == nil {
 		return false
 	}
-	stateFilter := filter.StoreStateFilter{ActionScope: "rule-checker", TransferLeader: true}
-	if !stateFilter.Target(c.cluster, s) {
+	filters := []filter.Filter{
+		filter.StoreStateFilter{ActionScope: "rule-checker", TransferLeader: true},
+		filter.NewLeaderStoreFilter("rule-checker", c.cluster, region),
+	}
+	if !filter.Target(c.cluster, s, filters) {
 		return false
 	}
 	for _, rf := range fit.RuleFits {
@@ -74,6 +74,22 @@ func (s *testRuleCheckerSuite) TestAddRulePeer(c *C) {
 	c.Assert(op.Step(0).(operator.AddLearner).ToStore, Equals, uint64(3))
 }
 
+func (s *testRuleCheckerSuite) TestAddRulePeerToAlmostFullStore(c *C) {
+	s.cluster.LowSpaceRatio = 0.99
+	s.cluster.AddLeaderStore(1, 1)
+	s.cluster.AddLeaderStore(2, 1)
+	s.cluster.AddLeaderStore(3, 1)
+	s.cluster.AddLeaderRegionWithRange(1, "", "", 1, 2)
+	// The missing replica can be added to an almost full store.
+	s.cluster.UpdateStorageRatio(3, 0.92, 0.08)
+	op := s.rc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Step(0).(operator.AddLearner).ToStore, Equals, uint64(3))
+	// But not to a full one.
+	s.cluster.UpdateStorageRatio(3, 0.97, 0.03)
+	c.Assert(s.rc.Check(s.cluster.GetRegion(1)), IsNil)
+}
+
 func (s *testRuleCheckerSuite) TestFixPeer(c *C) {
 	s.cluster.AddLeaderStore(1, 1)
 	s.cluster.AddLeaderStore(2, 1)
@@ -30,7 +30,10 @@ type CheckerController struct {
 	learnerChecker *checker.LearnerChecker
 	replicaChecker *checker.ReplicaChecker
 	ruleChecker    *checker.RuleChecker
+	diskChecker    *checker.DiskChecker
 	mergeChecker   *checker.MergeChecker
+
+	leaderStoreChecker *checker.LeaderStoreChecker
 }
 
 // NewCheckerController create a new CheckerController.
@@ -42,7 +45,10 @@ func NewCheckerController(ctx context.Context, cluster opt.Cluster, ruleManager
 		learnerChecker: checker.NewLearnerChecker(cluster),
 		replicaChecker: checker.NewReplicaChecker(cluster),
 		ruleChecker:    checker.NewRuleChecker(cluster, ruleManager),
+		diskChecker:    checker.NewDiskChecker(cluster),
 		mergeChecker:   checker.NewMergeChecker(ctx, cluster),
+
+		leaderStoreChecker: checker.NewLeaderStoreChecker(cluster),
 	}
 }
 
@@ -71,6 +77,20 @@ func (c *CheckerController) CheckRegion(region *core.RegionInfo) (bool, []*opera
 		}
 	}
 
+	if opController.OperatorCount(operator.OpReplica) < c.cluster.GetReplicaScheduleLimit() {
+		checkerIsBusy = false
+		if op := c.diskChecker.Check(region); op != nil {
+			return checkerIsBusy, []*operator.Operator{op}
+		}
+	}
+
+	if opController.OperatorCount(operator.OpLeader) < c.cluster.GetLeaderScheduleLimit() {
+		checkerIsBusy = false
+		if op := c.leaderStoreChecker.Check(region); op != nil {
+			return checkerIsBusy, []*operator.Operator{op}
+		}
+	}
+
 	if c.mergeChecker != nil && opController.OperatorCount(operator.OpMerge) < c.cluster.GetMergeScheduleLimit() {
 		checkerIsBusy = false
 		if ops := c.mergeChecker.Check(region); ops != nil {
@@ -0,0 +1,222 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package explain
+
+import (
+	"sync"
+	"time"
+)
+
+// maxComparisonsPerRound limits the score comparisons kept in a round, a
+// scheduler may compare many pairs of stores when it retries.
+const maxComparisonsPerRound = 256
+
+// Round is what a scheduler considered in a round of scheduling.
+type Round struct {
+	Start time.Time `json:"start"`
+	// Skipped is the reason why the scheduler does not schedule in the round.
+	Skipped    string        `json:"skipped,omitempty"`
+	Sources    []*Candidate  `json:"sources"`
+	Targets    []*Candidate  `json:"targets"`
+	Scores     []*Comparison `json:"scores"`
+	Operators  int           `json:"operators"`
+	sourcesIdx map[uint64]*Candidate
+	targetsIdx map[uint64]*Candidate
+}
+
+// Candidate is a store which is considered as the source or the target.
+type Candidate struct {
+	StoreID uint64 `json:"store-id"`
+	// Passed is the times that the store passes all the filters.
+	Passed   int          `json:"passed"`
+	Rejected []*Rejection `json:"rejected,omitempty"`
+}
+
+// Rejection is a filter which rejects a candidate.
+type Rejection struct {
+	Scope string `json:"scope"`
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+// Comparison is the scores of the source and the target which are compared to
+// decide whether to move a region.
+type Comparison struct {
+	RegionID    uint64  `json:"region-id"`
+	Kind        string  `json:"kind"`
+	SourceID    uint64  `json:"source-id"`
+	TargetID    uint64  `json:"target-id"`
+	SourceScore float64 `json:"source-score"`
+	TargetScore float64 `json:"target-score"`
+	Balance     bool    `json:"balance"`
+}
+
+// Recorder records the decisions of a scheduler in the last rounds. All the
+// methods are safe to call on a nil Recorder, which records nothing.
+type Recorder struct {
+	mu       sync.Mutex
+	capacity int
+	current  *Round
+	rounds   []*Round
+}
+
+// NewRecorder creates a Recorder which keeps the last capacity rounds.
+func NewRecorder(capacity int) *Recorder {
+	return &Recorder{capacity: capacity}
+}
+
+// BeginRound starts a round, the records out of a round are dropped.
+func (r *Recorder) BeginRound() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current = &Round{
+		Start:      time.Now(),
+		sourcesIdx: make(map[uint64]*Candidate),
+		targetsIdx: make(map[uint64]*Candidate),
+	}
+}
+
+// EndRound finishes the current round with the number of the operators it
+// creates.
+func (r *Recorder) EndRound(operators int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current == nil {
+		return
+	}
+	r.current.Operators = operators
+	r.current.sourcesIdx, r.current.targetsIdx = nil, nil
+	r.push(r.current)
+	r.current = nil
+}
+
+// SkipRound records a round in which the scheduler does not schedule.
+func (r *Recorder) SkipRound(reason string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current = nil
+	r.push(&Round{Start: time.Now(), Skipped: reason})
+}
+
+func (r *Recorder) push(round *Round) {
+	r.rounds = append(r.rounds, round)
+	if len(r.rounds) > r.capacity {
+		r.rounds = r.rounds[len(r.rounds)-r.capacity:]
+	}
+}
+
+// Rounds returns the recorded rounds, the latest first.
+func (r *Recorder) Rounds() []*Round {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rounds := make([]*Round, 0, len(r.rounds))
+	for i := len(r.rounds) - 1; i >= 0; i-- {
+		rounds = append(rounds, r.rounds[i])
+	}
+	return rounds
+}
+
+// PassSource records that the store passes all the filters as a source.
+func (r *Recorder) PassSource(storeID uint64) {
+	r.record(storeID, true, func(c *Candidate) { c.Passed++ })
+}
+
+// RejectSource records that the store is rejected as a source by the filter.
+func (r *Recorder) RejectSource(storeID uint64, scope, typ string) {
+	r.record(storeID, true, func(c *Candidate) { c.reject(scope, typ) })
+}
+
+// PassTarget records that the store passes all the filters as a target.
+func (r *Recorder) PassTarget(storeID uint64) {
+	r.record(storeID, false, func(c *Candidate) { c.Passed++ })
+}
+
+// RejectTarget records that the store is rejected as a target by the filter.
+func (r *Recorder) RejectTarget(storeID uint64, scope, typ string) {
+	r.record(storeID, false, func(c *Candidate) { c.reject(scope, typ) })
+}
+
+// Compare records the scores compared to decide whether to move a region.
+func (r *Recorder) Compare(cmp *Comparison) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current == nil || len(r.current.Scores) >= maxComparisonsPerRound {
+		return
+	}
+	r.current.Scores = append(r.current.Scores, cmp)
+}
+
+func (r *Recorder) record(storeID uint64, source bool, f func(*Candidate)) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	round := r.current
+	if round == nil {
+		return
+	}
+	idx, candidates := round.targetsIdx, &round.Targets
+	if source {
+		idx, candidates = round.sourcesIdx, &round.Sources
+	}
+	c, ok := idx[storeID]
+	if !ok {
+		c = &Candidate{StoreID: storeID}
+		idx[storeID] = c
+		*candidates = append(*candidates, c)
+	}
+	f(c)
+}
+
+func (c *Candidate) reject(scope, typ string) {
+	for _, rej := range c.Rejected {
+		if rej.Scope == scope && rej.Type == typ {
+			rej.Count++
+			return
+		}
+	}
+	c.Rejected = append(c.Rejected, &Rejection{Scope: scope, Type: typ, Count: 1})
+}
+
+// Provider provides the recorders of the schedulers in explain mode. It is
+// implemented by the cluster.
+type Provider interface {
+	GetExplainRecorder(name string) *Recorder
+}
+
+// RecorderOf returns the recorder of the scheduler named name. It returns nil
+// if v is not a Provider or the scheduler is not in explain mode.
+func RecorderOf(v interface{}, name string) *Recorder {
+	if p, ok := v.(Provider); ok {
+		return p.GetExplainRecorder(name)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package explain
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testExplainSuite{})
+
+type testExplainSuite struct{}
+
+func (s *testExplainSuite) TestRecorder(c *C) {
+	r := NewRecorder(2)
+	// The records out of a round are dropped.
+	r.PassSource(1)
+	c.Assert(r.Rounds(), HasLen, 0)
+
+	r.BeginRound()
+	r.PassSource(1)
+	r.PassSource(1)
+	r.RejectSource(2, "test", "store-state-filter")
+	r.RejectTarget(2, "test", "store-state-filter")
+	r.RejectTarget(2, "test", "store-state-filter")
+	r.RejectTarget(2, "test", "special-use-filter")
+	r.PassTarget(3)
+	r.Compare(&Comparison{RegionID: 1, SourceID: 1, TargetID: 3, SourceScore: 2, TargetScore: 1, Balance: true})
+	r.EndRound(1)
+
+	rounds := r.Rounds()
+	c.Assert(rounds, HasLen, 1)
+	round := rounds[0]
+	c.Assert(round.Operators, Equals, 1)
+	c.Assert(round.Sources, DeepEquals, []*Candidate{
+		{StoreID: 1, Passed: 2},
+		{StoreID: 2, Rejected: []*Rejection{{Scope: "test", Type: "store-state-filter", Count: 1}}},
+	})
+	c.Assert(round.Targets, DeepEquals, []*Candidate{
+		{StoreID: 2, Rejected: []*Rejection{
+			{Scope: "test", Type: "store-state-filter", Count: 2},
+			{Scope: "test", Type: "special-use-filter", Count: 1},
+		}},
+		{StoreID: 3, Passed: 1},
+	})
+	c.Assert(round.Scores, HasLen, 1)
+
+	// Only the last rounds are kept, the latest first.
+	r.SkipRound("paused")
+	r.BeginRound()
+	r.EndRound(0)
+	rounds = r.Rounds()
+	c.Assert(rounds, HasLen, 2)
+	c.Assert(rounds[0].Skipped, Equals, "")
+	c.Assert(rounds[1].Skipped, Equals, "paused")
+
+	// A nil recorder records nothing.
+	var nilRecorder *Recorder
+	nilRecorder.BeginRound()
+	nilRecorder.PassSource(1)
+	nilRecorder.EndRound(0)
+	c.Assert(nilRecorder.Rounds(), HasLen, 0)
+}
+
+type testProvider map[string]*Recorder
+
+func (p testProvider) GetExplainRecorder(name string) *Recorder {
+	return p[name]
+}
+
+func (s *testExplainSuite) TestRecorderOf(c *C) {
+	r := NewRecorder(1)
+	p := testProvider{"foo": r}
+	c.Assert(RecorderOf(p, "foo"), Equals, r)
+	c.Assert(RecorderOf(p, "bar"), IsNil)
+	c.Assert(RecorderOf(struct{}{}, "foo"), IsNil)
+}
@@ -18,8 +18,10 @@ import (
 
 	"github.com/pingcap/pd/v4/pkg/slice"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/explain"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
 	"github.com/pingcap/pd/v4/server/schedule/placement"
+	"github.com/pingcap/pd/v4/server/schedule/storelimit"
 )
 
 // revive:disable:unused-parameter
@@ -27,14 +29,28 @@ import (
 // SelectSourceStores selects stores that be selected as source store from the list.
 func SelectSourceStores(stores []*core.StoreInfo, filters []Filter, opt opt.Options) []*core.StoreInfo {
 	return filterStoresBy(stores, func(s *core.StoreInfo) bool {
-		return slice.AllOf(filters, func(i int) bool { return filters[i].Source(opt, s) })
+		for _, f := range filters {
+			if !f.Source(opt, s) {
+				explainSource(opt, s, filters, f)
+				return false
+			}
+		}
+		explainSource(opt, s, filters, nil)
+		return true
 	})
 }
 
 // SelectTargetStores selects stores that be selected as target store from the list.
 func SelectTargetStores(stores []*core.StoreInfo, filters []Filter, opt opt.Options) []*core.StoreInfo {
 	return filterStoresBy(stores, func(s *core.StoreInfo) bool {
-		return slice.AllOf(filters, func(i int) bool { return filters[i].Target(opt, s) })
+		for _, f := range filters {
+			if !f.Target(opt, s) {
+				explainTarget(opt, s, filters, f)
+				return false
+			}
+		}
+		explainTarget(opt, s, filters, nil)
+		return true
 	})
 }
 
@@ -47,6 +63,27 @@ func filterStoresBy(stores []*core.StoreInfo, keepPred func(*core.StoreInfo) boo
 	return
 }
 
+// explainSource records the source store to the recorder of the scheduler in
+// explain mode. The filters are supposed to share the scope, which is the
+// name of the scheduler.
+func explainSource(opt opt.Options, store *core.StoreInfo, filters []Filter, rejectedBy Filter) {
+	if rejectedBy != nil {
+		explain.RecorderOf(opt, rejectedBy.Scope()).RejectSource(store.GetID(), rejectedBy.Scope(), rejectedBy.Type())
+	} else if len(filters) > 0 {
+		explain.RecorderOf(opt, filters[0].Scope()).PassSource(store.GetID())
+	}
+}
+
+// explainTarget records the target store to the recorder of the scheduler in
+// explain mode.
+func explainTarget(opt opt.Options, store *core.StoreInfo, filters []Filter, rejectedBy Filter) {
+	if rejectedBy != nil {
+		explain.RecorderOf(opt, rejectedBy.Scope()).RejectTarget(store.GetID(), rejectedBy.Scope(), rejectedBy.Type())
+	} else if len(filters) > 0 {
+		explain.RecorderOf(opt, filters[0].Scope()).PassTarget(store.GetID())
+	}
+}
+
 // Filter is an interface to filter source and target store.
 type Filter interface {
 	// Scope is used to indicate where the filter will act on.
@@ -65,9 +102,11 @@ func Source(opt opt.Options, store *core.StoreInfo, filters []Filter) bool {
 	for _, filter := range filters {
 		if !filter.Source(opt, store) {
 			filterCounter.WithLabelValues("filter-source", storeAddress, storeID, filter.Scope(), filter.Type()).Inc()
+			explainSource(opt, store, filters, filter)
 			return false
 		}
 	}
+	explainSource(opt, store, filters, nil)
 	return true
 }
 
@@ -78,9 +117,11 @@ func Target(opt opt.Options, store *core.StoreInfo, filters []Filter) bool {
 	for _, filter := range filters {
 		if !filter.Target(opt, store) {
 			filterCounter.WithLabelValues("filter-target", storeAddress, storeID, filter.Scope(), filter.Type()).Inc()
+			explainTarget(opt, store, filters, filter)
 			return false
 		}
 	}
+	explainTarget(opt, store, filters, nil)
 	return true
 }
 
@@ -133,11 +174,11 @@ func (f *storeLimitFilter) Type() string {
 }
 
 func (f *storeLimitFilter) Source(opt opt.Options, store *core.StoreInfo) bool {
-	return store.IsAvailable()
+	return store.IsAvailable(storelimit.RemovePeer)
 }
 
 func (f *storeLimitFilter) Target(opt opt.Options, store *core.StoreInfo) bool {
-	return store.IsAvailable()
+	return store.IsAvailable(storelimit.AddPeer)
 }
 
 type stateFilter struct{ scope string }
@@ -278,6 +319,40 @@ func (f *storageThresholdFilter) Target(opt opt.Options, store *core.StoreInfo)
 	return !store.IsLowSpace(opt.GetLowSpaceRatio())
 }
 
+type diskStateFilter struct {
+	scope            string
+	rejectAlmostFull bool
+}
+
+// NewDiskStateFilter creates a Filter that filters all stores whose disk is
+// full. The stores whose disk is almost full are also filtered if
+// rejectAlmostFull is set.
+func NewDiskStateFilter(scope string, rejectAlmostFull bool) Filter {
+	return &diskStateFilter{scope: scope, rejectAlmostFull: rejectAlmostFull}
+}
+
+func (f *diskStateFilter) Scope() string {
+	return f.scope
+}
+
+func (f *diskStateFilter) Type() string {
+	return "disk-state-filter"
+}
+
+func (f *diskStateFilter) Source(opt opt.Options, store *core.StoreInfo) bool {
+	return true
+}
+
+func (f *diskStateFilter) Target(opt opt.Options, store *core.StoreInfo) bool {
+	switch store.DiskState(opt.GetDiskAlmostFullRatio(), opt.GetDiskFullRatio()) {
+	case core.DiskStateFull:
+		return false
+	case core.DiskStateAlmostFull:
+		return !f.rejectAlmostFull
+	}
+	return true
+}
+
 // distinctScoreFilter ensures that distinct score will not decrease.
 type distinctScoreFilter struct {
 	scope     string
@@ -352,7 +427,7 @@ func (f StoreStateFilter) Source(opt opt.Options, store *core.StoreInfo) bool {
 		return false
 	}
 
-	if f.MoveRegion && !f.filterMoveRegion(opt, store) {
+	if f.MoveRegion && !f.filterMoveRegion(opt, store, storelimit.RemovePeer) {
 		return false
 	}
 	return true
@@ -366,10 +441,16 @@ func (f StoreStateFilter) Target(opts opt.Options, store *core.StoreInfo) bool {
 		store.DownTime() > opts.GetMaxStoreDownTime() {
 		return false
 	}
+	// The leaders are moved away from the stores whose disk is almost full or
+	// full. The regions are only kept away from the full ones so that the
+	// replicas can still be repaired, and the balance schedulers use
+	// NewDiskStateFilter to skip the almost full ones.
+	diskState := store.DiskState(opts.GetDiskAlmostFullRatio(), opts.GetDiskFullRatio())
 	if f.TransferLeader &&
 		(store.IsDisconnected() ||
 			store.IsBlocked() ||
 			store.IsBusy() ||
+			diskState != core.DiskStateNormal ||
 			opts.CheckLabelProperty(opt.RejectLeader, store.GetLabels())) {
 		return false
 	}
@@ -380,19 +461,23 @@ func (f StoreStateFilter) Target(opts opt.Options, store *core.StoreInfo) bool {
 			return false
 		}
 
-		if !f.filterMoveRegion(opts, store) {
+		if diskState == core.DiskStateFull {
+			return false
+		}
+
+		if !f.filterMoveRegion(opts, store, storelimit.AddPeer) {
 			return false
 		}
 	}
 	return true
 }
 
-func (f StoreStateFilter) filterMoveRegion(opt opt.Options, store *core.StoreInfo) bool {
+func (f StoreStateFilter) filterMoveRegion(opt opt.Options, store *core.StoreInfo, limitType storelimit.Type) bool {
 	if store.IsBusy() {
 		return false
 	}
 
-	if !store.IsAvailable() {
+	if !store.IsAvailable(limitType) {
 		return false
 	}
 
@@ -542,6 +627,34 @@ func (f *ruleFitFilter) Target(opt opt.Options, store *core.StoreInfo) bool {
 	return placement.CompareRegionFit(f.oldFit, newFit) <= 0
 }
 
+type leaderStoreFilter struct {
+	scope   string
+	cluster opt.Cluster
+	region  *core.RegionInfo
+}
+
+// NewLeaderStoreFilter creates a filter that filters the stores which the
+// leader of the region is not allowed to be on by the region labels.
+func NewLeaderStoreFilter(scope string, cluster opt.Cluster, region *core.RegionInfo) Filter {
+	return &leaderStoreFilter{scope: scope, cluster: cluster, region: region}
+}
+
+func (f *leaderStoreFilter) Scope() string {
+	return f.scope
+}
+
+func (f *leaderStoreFilter) Type() string {
+	return "leader-store-filter"
+}
+
+func (f *leaderStoreFilter) Source(opt opt.Options, store *core.StoreInfo) bool {
+	return true
+}
+
+func (f *leaderStoreFilter) Target(_ opt.Options, store *core.StoreInfo) bool {
+	return opt.IsLeaderStoreAllowed(f.cluster, f.region, store)
+}
+
 type specialUseFilter struct {
 	scope      string
 	constraint placement.LabelConstraint
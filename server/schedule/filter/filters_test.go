@@ -20,6 +20,7 @@ import (
 	"github.com/pingcap/pd/v4/pkg/mock/mockcluster"
 	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/server/schedule/placement"
 )
 
@@ -80,3 +81,53 @@ func (s *testFiltersSuite) TestRuleFitFilter(c *C) {
 	c.Assert(filter.Target(tc, tc.GetStore(4)), IsFalse)
 	c.Assert(filter.Source(tc, tc.GetStore(4)), IsTrue)
 }
+
+func (s *testFiltersSuite) TestDiskStateFilter(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	tc.AddRegionStore(1, 1)
+	tc.AddRegionStore(2, 1)
+	tc.AddRegionStore(3, 1)
+	tc.UpdateStorageRatio(2, 0.92, 0.08)
+	tc.UpdateStorageRatio(3, 0.97, 0.03)
+
+	testCases := []struct {
+		filter Filter
+		target []bool
+	}{
+		{NewDiskStateFilter("", false), []bool{true, true, false}},
+		{NewDiskStateFilter("", true), []bool{true, false, false}},
+		{StoreStateFilter{MoveRegion: true}, []bool{true, true, false}},
+		{StoreStateFilter{TransferLeader: true}, []bool{true, false, false}},
+	}
+	for _, t := range testCases {
+		for i, target := range t.target {
+			store := tc.GetStore(uint64(i + 1))
+			c.Assert(t.filter.Source(tc, store), IsTrue)
+			c.Assert(t.filter.Target(tc, store), Equals, target)
+		}
+	}
+}
+
+func (s *testFiltersSuite) TestLeaderStoreFilter(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	tc.AddLabelsStore(1, 1, map[string]string{"zone": "z1"})
+	tc.AddLabelsStore(2, 1, map[string]string{"zone": "z2"})
+	region := core.NewRegionInfo(&metapb.Region{Id: 1, Peers: []*metapb.Peer{
+		{StoreId: 1, Id: 1},
+		{StoreId: 2, Id: 2},
+	}}, &metapb.Peer{StoreId: 1, Id: 1})
+
+	filter := NewLeaderStoreFilter("", tc, region)
+	c.Assert(filter.Target(tc, tc.GetStore(1)), IsTrue)
+	c.Assert(filter.Target(tc, tc.GetStore(2)), IsTrue)
+
+	c.Assert(tc.GetRegionLabeler().SetLabelRule(&labeler.LabelRule{
+		ID:     "rule1",
+		Labels: []*labeler.RegionLabel{{Key: labeler.LeaderStoreLabelKey, Value: "zone=z2"}},
+	}), IsNil)
+	c.Assert(filter.Source(tc, tc.GetStore(1)), IsTrue)
+	c.Assert(filter.Target(tc, tc.GetStore(1)), IsFalse)
+	c.Assert(filter.Target(tc, tc.GetStore(2)), IsTrue)
+}
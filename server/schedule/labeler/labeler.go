@@ -0,0 +1,311 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package labeler
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/server/core"
+	"go.uber.org/zap"
+)
+
+// RegionLabeler is responsible for the lifecycle of the region label rules
+// and assigns the labels to the regions by their key ranges. It is
+// threadsafe.
+type RegionLabeler struct {
+	storage *core.Storage
+	sync.RWMutex
+	labelRules map[string]*LabelRule
+	rangeList  rangeList
+}
+
+// NewRegionLabeler creates a RegionLabeler and loads the rules from storage.
+func NewRegionLabeler(storage *core.Storage) (*RegionLabeler, error) {
+	l := &RegionLabeler{
+		storage:    storage,
+		labelRules: make(map[string]*LabelRule),
+	}
+	if err := l.loadRules(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *RegionLabeler) loadRules() error {
+	var toDelete []string
+	now := time.Now()
+	_, err := l.storage.LoadRegionLabelRules(func(k, v string) {
+		var r LabelRule
+		if err := json.Unmarshal([]byte(v), &r); err != nil {
+			log.Error("failed to unmarshal label rule value", zap.String("rule-key", k), zap.String("rule-value", v))
+			toDelete = append(toDelete, k)
+			return
+		}
+		if err := r.adjust(); err != nil {
+			log.Error("label rule is in bad format", zap.Error(err), zap.String("rule-key", k), zap.String("rule-value", v))
+			toDelete = append(toDelete, k)
+			return
+		}
+		if r.expired(now) {
+			toDelete = append(toDelete, k)
+			return
+		}
+		l.labelRules[r.ID] = &r
+	})
+	if err != nil {
+		return err
+	}
+	for _, d := range toDelete {
+		if err = l.storage.DeleteRegionLabelRule(d); err != nil {
+			return err
+		}
+	}
+	l.rangeList = buildRangeList(l.labelRules)
+	return nil
+}
+
+// GetAllLabelRules returns all the label rules which have not expired, sorted
+// by ID.
+func (l *RegionLabeler) GetAllLabelRules() []*LabelRule {
+	l.RLock()
+	defer l.RUnlock()
+	now := time.Now()
+	rules := make([]*LabelRule, 0, len(l.labelRules))
+	for _, r := range l.labelRules {
+		if !r.expired(now) {
+			rules = append(rules, r.clone())
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules
+}
+
+// GetLabelRule returns the label rule with the ID, or nil if it does not exist
+// or has expired.
+func (l *RegionLabeler) GetLabelRule(id string) *LabelRule {
+	l.RLock()
+	defer l.RUnlock()
+	r, ok := l.labelRules[id]
+	if !ok || r.expired(time.Now()) {
+		return nil
+	}
+	return r.clone()
+}
+
+// SetLabelRule inserts or updates a label rule. If the TTL of the rule is set,
+// it expires after the TTL from now. The labeler keeps a copy of the rule, so
+// the caller can modify the rule afterwards.
+func (l *RegionLabeler) SetLabelRule(rule *LabelRule) error {
+	if err := rule.adjust(); err != nil {
+		return err
+	}
+	rule.ExpireAt = nil
+	if rule.TTL != "" {
+		ttl, _ := time.ParseDuration(rule.TTL)
+		expireAt := time.Now().Add(ttl)
+		rule.ExpireAt = &expireAt
+	}
+	l.Lock()
+	defer l.Unlock()
+	if err := l.storage.SaveRegionLabelRule(rule.storeKey(), rule); err != nil {
+		return err
+	}
+	l.labelRules[rule.ID] = rule.clone()
+	l.rangeList = buildRangeList(l.labelRules)
+	return nil
+}
+
+// DeleteLabelRule removes a label rule.
+func (l *RegionLabeler) DeleteLabelRule(id string) error {
+	l.Lock()
+	defer l.Unlock()
+	r, ok := l.labelRules[id]
+	if !ok {
+		return nil
+	}
+	if err := l.storage.DeleteRegionLabelRule(r.storeKey()); err != nil {
+		return err
+	}
+	delete(l.labelRules, id)
+	l.rangeList = buildRangeList(l.labelRules)
+	return nil
+}
+
+// RemoveExpiredRules removes the label rules which have expired.
+func (l *RegionLabeler) RemoveExpiredRules(now time.Time) error {
+	l.Lock()
+	defer l.Unlock()
+	var removed bool
+	for id, r := range l.labelRules {
+		if !r.expired(now) {
+			continue
+		}
+		if err := l.storage.DeleteRegionLabelRule(r.storeKey()); err != nil {
+			return err
+		}
+		delete(l.labelRules, id)
+		removed = true
+	}
+	if removed {
+		l.rangeList = buildRangeList(l.labelRules)
+	}
+	return nil
+}
+
+// GetRegionLabels returns the labels of the rules whose key ranges overlap
+// with the region. If the rules assign different values to a label, the rule
+// with the smallest ID takes effect.
+func (l *RegionLabeler) GetRegionLabels(region *core.RegionInfo) []*RegionLabel {
+	if l == nil {
+		return nil
+	}
+	l.RLock()
+	defer l.RUnlock()
+	return mergeLabels(l.rangeList.getRulesByRange(region.GetStartKey(), region.GetEndKey()), time.Now())
+}
+
+// GetKeyLabels returns the labels of the rules whose key ranges contain the
+// key.
+func (l *RegionLabeler) GetKeyLabels(key []byte) []*RegionLabel {
+	l.RLock()
+	defer l.RUnlock()
+	return mergeLabels(l.rangeList.getRulesByKey(key), time.Now())
+}
+
+// GetRegionLabel returns the value of the label of the region, or an empty
+// string if the region does not have the label. It is safe to call on a nil
+// RegionLabeler.
+func (l *RegionLabeler) GetRegionLabel(region *core.RegionInfo, key string) string {
+	for _, label := range l.GetRegionLabels(region) {
+		if label.Key == key {
+			return label.Value
+		}
+	}
+	return ""
+}
+
+// IsLeaderStoreAllowed returns false if the region is labeled to keep the
+// leader on the stores with a store label and the store does not have it.
+func (l *RegionLabeler) IsLeaderStoreAllowed(region *core.RegionInfo, store *core.StoreInfo) bool {
+	key, value, ok := parseStoreLabel(l.GetRegionLabel(region, LeaderStoreLabelKey))
+	return !ok || store.GetLabelValue(key) == value
+}
+
+// mergeLabels merges the labels of the rules sorted by ID, the first rule wins
+// if the rules assign different values to a label.
+func mergeLabels(rules []*LabelRule, now time.Time) []*RegionLabel {
+	var labels []*RegionLabel
+	seen := make(map[string]struct{})
+	for _, r := range rules {
+		if r.expired(now) {
+			continue
+		}
+		for _, label := range r.Labels {
+			if _, ok := seen[label.Key]; ok {
+				continue
+			}
+			seen[label.Key] = struct{}{}
+			labels = append(labels, &RegionLabel{Key: label.Key, Value: label.Value})
+		}
+	}
+	return labels
+}
+
+type rangeRules struct {
+	startKey []byte
+	rules    []*LabelRule // sorted by ID
+}
+
+type rangeList struct {
+	ranges []rangeRules // ranges[i] contains rules apply to [ranges[i].startKey, ranges[i+1].startKey).
+}
+
+func buildRangeList(rules map[string]*LabelRule) rangeList {
+	if len(rules) == 0 {
+		return rangeList{}
+	}
+	type splitPoint struct {
+		start bool
+		key   []byte
+		rule  *LabelRule
+	}
+	var points []splitPoint
+	for _, r := range rules {
+		points = append(points, splitPoint{start: true, key: r.StartKey, rule: r})
+		if len(r.EndKey) > 0 {
+			points = append(points, splitPoint{start: false, key: r.EndKey, rule: r})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return bytes.Compare(points[i].key, points[j].key) < 0
+	})
+
+	var rl rangeList
+	active := make(map[string]*LabelRule)
+	for i, p := range points {
+		if p.start {
+			active[p.rule.ID] = p.rule
+		} else {
+			delete(active, p.rule.ID)
+		}
+		if i == len(points)-1 || !bytes.Equal(p.key, points[i+1].key) {
+			rr := make([]*LabelRule, 0, len(active))
+			for _, r := range active {
+				rr = append(rr, r)
+			}
+			sort.Slice(rr, func(i, j int) bool { return rr[i].ID < rr[j].ID })
+			rl.ranges = append(rl.ranges, rangeRules{startKey: p.key, rules: rr})
+		}
+	}
+	return rl
+}
+
+func (rl rangeList) getRulesByKey(key []byte) []*LabelRule {
+	i := sort.Search(len(rl.ranges), func(i int) bool {
+		return bytes.Compare(rl.ranges[i].startKey, key) > 0
+	})
+	if i == 0 {
+		return nil
+	}
+	return rl.ranges[i-1].rules
+}
+
+// getRulesByRange returns the rules overlapping with [start, end), sorted by
+// ID.
+func (rl rangeList) getRulesByRange(start, end []byte) []*LabelRule {
+	i := sort.Search(len(rl.ranges), func(i int) bool {
+		return bytes.Compare(rl.ranges[i].startKey, start) > 0
+	})
+	if i > 0 {
+		i--
+	}
+	var rules []*LabelRule
+	seen := make(map[string]struct{})
+	for ; i < len(rl.ranges) && (len(end) == 0 || bytes.Compare(rl.ranges[i].startKey, end) < 0); i++ {
+		for _, r := range rl.ranges[i].rules {
+			if _, ok := seen[r.ID]; !ok {
+				seen[r.ID] = struct{}{}
+				rules = append(rules, r)
+			}
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules
+}
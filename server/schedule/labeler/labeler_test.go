@@ -0,0 +1,179 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package labeler
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/kv"
+)
+
+func TestLabeler(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testLabelerSuite{})
+
+type testLabelerSuite struct {
+	store   *core.Storage
+	labeler *RegionLabeler
+}
+
+func (s *testLabelerSuite) SetUpTest(c *C) {
+	s.store = core.NewStorage(kv.NewMemoryKV())
+	var err error
+	s.labeler, err = NewRegionLabeler(s.store)
+	c.Assert(err, IsNil)
+}
+
+func newRule(id, start, end string, labels ...*RegionLabel) *LabelRule {
+	return &LabelRule{
+		ID:          id,
+		Labels:      labels,
+		StartKeyHex: hex.EncodeToString([]byte(start)),
+		EndKeyHex:   hex.EncodeToString([]byte(end)),
+	}
+}
+
+func newRegion(start, end string) *core.RegionInfo {
+	return core.NewRegionInfo(&metapb.Region{Id: 1, StartKey: []byte(start), EndKey: []byte(end)}, nil)
+}
+
+func (s *testLabelerSuite) TestAdjustRule(c *C) {
+	testCases := []struct {
+		rule  *LabelRule
+		valid bool
+	}{
+		{newRule("a", "", "", &RegionLabel{Key: "k", Value: "v"}), true},
+		{newRule("", "", "", &RegionLabel{Key: "k", Value: "v"}), false},
+		{newRule("a", "", ""), false},
+		{newRule("a", "", "", &RegionLabel{Value: "v"}), false},
+		{newRule("a", "", "", &RegionLabel{Key: ScheduleKey, Value: DenyValue}), true},
+		{newRule("a", "", "", &RegionLabel{Key: MergeKey, Value: "v"}), false},
+		{newRule("a", "", "", &RegionLabel{Key: LeaderStoreLabelKey, Value: "zone=z1"}), true},
+		{newRule("a", "", "", &RegionLabel{Key: LeaderStoreLabelKey, Value: "z1"}), false},
+		{newRule("a", "b", "a", &RegionLabel{Key: "k", Value: "v"}), false},
+		{newRule("a", "a", "", &RegionLabel{Key: "k", Value: "v"}), true},
+	}
+	for _, t := range testCases {
+		err := t.rule.adjust()
+		c.Assert(err == nil, Equals, t.valid)
+	}
+
+	rule := newRule("a", "", "", &RegionLabel{Key: "k", Value: "v"})
+	rule.StartKeyHex = "zz"
+	c.Assert(rule.adjust(), NotNil)
+	rule = newRule("a", "", "", &RegionLabel{Key: "k", Value: "v"})
+	rule.TTL = "0s"
+	c.Assert(rule.adjust(), NotNil)
+}
+
+func (s *testLabelerSuite) TestRegionLabels(c *C) {
+	rules := []*LabelRule{
+		newRule("rule1", "a", "c", &RegionLabel{Key: "k1", Value: "v1"}),
+		newRule("rule2", "b", "d", &RegionLabel{Key: "k1", Value: "v2"}, &RegionLabel{Key: "k2", Value: "v2"}),
+		newRule("rule3", "f", "", &RegionLabel{Key: "k3", Value: "v3"}),
+	}
+	for _, r := range rules {
+		c.Assert(s.labeler.SetLabelRule(r), IsNil)
+	}
+
+	testCases := []struct {
+		start, end string
+		labels     []*RegionLabel
+	}{
+		{"", "a", nil},
+		{"", "b", []*RegionLabel{{Key: "k1", Value: "v1"}}},
+		{"b", "c", []*RegionLabel{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}}},
+		{"c", "d", []*RegionLabel{{Key: "k1", Value: "v2"}, {Key: "k2", Value: "v2"}}},
+		{"d", "f", nil},
+		{"e", "", []*RegionLabel{{Key: "k3", Value: "v3"}}},
+		{"", "", []*RegionLabel{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}, {Key: "k3", Value: "v3"}}},
+	}
+	for _, t := range testCases {
+		c.Assert(s.labeler.GetRegionLabels(newRegion(t.start, t.end)), DeepEquals, t.labels)
+	}
+	c.Assert(s.labeler.GetKeyLabels([]byte("a")), DeepEquals, []*RegionLabel{{Key: "k1", Value: "v1"}})
+	c.Assert(s.labeler.GetKeyLabels([]byte("c")), DeepEquals, []*RegionLabel{{Key: "k1", Value: "v2"}, {Key: "k2", Value: "v2"}})
+	c.Assert(s.labeler.GetKeyLabels([]byte("z")), DeepEquals, []*RegionLabel{{Key: "k3", Value: "v3"}})
+	c.Assert(s.labeler.GetRegionLabel(newRegion("c", "d"), "k1"), Equals, "v2")
+	c.Assert(s.labeler.GetRegionLabel(newRegion("c", "d"), "k3"), Equals, "")
+
+	c.Assert(s.labeler.DeleteLabelRule("rule2"), IsNil)
+	c.Assert(s.labeler.GetLabelRule("rule2"), IsNil)
+	c.Assert(s.labeler.GetRegionLabels(newRegion("c", "d")), HasLen, 0)
+
+	// The rules are persisted.
+	labeler, err := NewRegionLabeler(s.store)
+	c.Assert(err, IsNil)
+	c.Assert(labeler.GetAllLabelRules(), HasLen, 2)
+	c.Assert(labeler.GetKeyLabels([]byte("b")), DeepEquals, []*RegionLabel{{Key: "k1", Value: "v1"}})
+
+	// A nil labeler assigns no label.
+	var nilLabeler *RegionLabeler
+	c.Assert(nilLabeler.GetRegionLabel(newRegion("", ""), "k1"), Equals, "")
+}
+
+func (s *testLabelerSuite) TestLeaderStoreLabel(c *C) {
+	rule := newRule("rule1", "", "", &RegionLabel{Key: LeaderStoreLabelKey, Value: "zone=z1"})
+	c.Assert(s.labeler.SetLabelRule(rule), IsNil)
+	store1 := core.NewStoreInfo(&metapb.Store{Id: 1, Labels: []*metapb.StoreLabel{{Key: "zone", Value: "z1"}}})
+	store2 := core.NewStoreInfo(&metapb.Store{Id: 2, Labels: []*metapb.StoreLabel{{Key: "zone", Value: "z2"}}})
+	region := newRegion("a", "b")
+	c.Assert(s.labeler.IsLeaderStoreAllowed(region, store1), IsTrue)
+	c.Assert(s.labeler.IsLeaderStoreAllowed(region, store2), IsFalse)
+	c.Assert(s.labeler.DeleteLabelRule("rule1"), IsNil)
+	c.Assert(s.labeler.IsLeaderStoreAllowed(region, store2), IsTrue)
+}
+
+func (s *testLabelerSuite) TestCopyRule(c *C) {
+	rule := newRule("rule1", "a", "b", &RegionLabel{Key: "k1", Value: "v1"})
+	c.Assert(s.labeler.SetLabelRule(rule), IsNil)
+	rule.Labels[0].Value = "v2"
+	rule.Labels = append(rule.Labels, &RegionLabel{Key: "k2", Value: "v2"})
+	c.Assert(s.labeler.GetRegionLabels(newRegion("a", "b")), DeepEquals, []*RegionLabel{{Key: "k1", Value: "v1"}})
+
+	got := s.labeler.GetLabelRule("rule1")
+	got.Labels[0].Value = "v3"
+	c.Assert(s.labeler.GetLabelRule("rule1").Labels, DeepEquals, []*RegionLabel{{Key: "k1", Value: "v1"}})
+}
+
+func (s *testLabelerSuite) TestTTL(c *C) {
+	rule := newRule("rule1", "", "", &RegionLabel{Key: "k1", Value: "v1"})
+	rule.TTL = "1h"
+	c.Assert(s.labeler.SetLabelRule(rule), IsNil)
+	c.Assert(s.labeler.GetLabelRule("rule1").ExpireAt, NotNil)
+	c.Assert(s.labeler.GetKeyLabels([]byte("a")), HasLen, 1)
+
+	c.Assert(s.labeler.RemoveExpiredRules(time.Now()), IsNil)
+	c.Assert(s.labeler.GetAllLabelRules(), HasLen, 1)
+	c.Assert(s.labeler.RemoveExpiredRules(time.Now().Add(2*time.Hour)), IsNil)
+	c.Assert(s.labeler.GetAllLabelRules(), HasLen, 0)
+	c.Assert(s.labeler.GetKeyLabels([]byte("a")), HasLen, 0)
+
+	// The expired rules are dropped when loading.
+	expireAt := time.Now().Add(-time.Minute)
+	rule = newRule("rule2", "", "", &RegionLabel{Key: "k1", Value: "v1"})
+	rule.ExpireAt = &expireAt
+	c.Assert(s.store.SaveRegionLabelRule(rule.storeKey(), rule), IsNil)
+	labeler, err := NewRegionLabeler(s.store)
+	c.Assert(err, IsNil)
+	c.Assert(labeler.GetAllLabelRules(), HasLen, 0)
+}
@@ -0,0 +1,151 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package labeler
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The labels with the following keys control the scheduling of the regions.
+const (
+	// ScheduleKey denies the balance schedulers and the region scatterer to
+	// move the regions when its value is DenyValue.
+	ScheduleKey = "schedule"
+	// MergeKey denies the merge checker to merge the regions when its value is
+	// DenyValue.
+	MergeKey = "merge"
+	// LeaderStoreLabelKey keeps the leaders of the regions on the stores with
+	// the store label, the value is in the format of "key=value".
+	LeaderStoreLabelKey = "leader-store-label"
+	// DenyValue is the value of ScheduleKey and MergeKey to deny.
+	DenyValue = "deny"
+	// AllowValue is the value of ScheduleKey and MergeKey to allow, which is
+	// the same as not setting the label.
+	AllowValue = "allow"
+)
+
+// ErrInvalidLabelRule is returned when the label rule is invalid.
+var ErrInvalidLabelRule = errors.New("invalid label rule")
+
+// RegionLabel is a label of the regions.
+type RegionLabel struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// LabelRule assigns the labels to the regions in the key range.
+type LabelRule struct {
+	ID          string         `json:"id"`
+	Labels      []*RegionLabel `json:"labels"`
+	StartKey    []byte         `json:"-"`         // range start key
+	StartKeyHex string         `json:"start_key"` // hex format start key, for marshal/unmarshal
+	EndKey      []byte         `json:"-"`         // range end key
+	EndKeyHex   string         `json:"end_key"`   // hex format end key, for marshal/unmarshal
+	// TTL is the duration for the rule to live, such as "1h". The rule lives
+	// forever if it is empty. ExpireAt is set according to it when the rule
+	// is saved.
+	TTL      string     `json:"ttl,omitempty"`
+	ExpireAt *time.Time `json:"expire_at,omitempty"`
+}
+
+func (r *LabelRule) storeKey() string {
+	return hex.EncodeToString([]byte(r.ID))
+}
+
+func (r *LabelRule) expired(now time.Time) bool {
+	return r.ExpireAt != nil && !now.Before(*r.ExpireAt)
+}
+
+func (r *LabelRule) clone() *LabelRule {
+	rule := *r
+	rule.Labels = make([]*RegionLabel, 0, len(r.Labels))
+	for _, l := range r.Labels {
+		label := *l
+		rule.Labels = append(rule.Labels, &label)
+	}
+	rule.StartKey = append([]byte(nil), r.StartKey...)
+	rule.EndKey = append([]byte(nil), r.EndKey...)
+	if r.ExpireAt != nil {
+		expireAt := *r.ExpireAt
+		rule.ExpireAt = &expireAt
+	}
+	return &rule
+}
+
+// adjust checks the rule and decodes the keys.
+func (r *LabelRule) adjust() (err error) {
+	if r.ID == "" {
+		return errors.Wrap(ErrInvalidLabelRule, "id is empty")
+	}
+	if len(r.Labels) == 0 {
+		return errors.Wrap(ErrInvalidLabelRule, "no label")
+	}
+	for _, l := range r.Labels {
+		if l == nil {
+			return errors.Wrap(ErrInvalidLabelRule, "label is null")
+		}
+		if err := l.validate(); err != nil {
+			return err
+		}
+	}
+	r.StartKey, err = hex.DecodeString(r.StartKeyHex)
+	if err != nil {
+		return errors.Wrap(ErrInvalidLabelRule, "start key is not in hex format")
+	}
+	r.EndKey, err = hex.DecodeString(r.EndKeyHex)
+	if err != nil {
+		return errors.Wrap(ErrInvalidLabelRule, "end key is not in hex format")
+	}
+	if len(r.EndKey) > 0 && bytes.Compare(r.EndKey, r.StartKey) <= 0 {
+		return errors.Wrap(ErrInvalidLabelRule, "end key should be greater than start key")
+	}
+	if r.TTL != "" {
+		ttl, err := time.ParseDuration(r.TTL)
+		if err != nil || ttl <= 0 {
+			return errors.Wrapf(ErrInvalidLabelRule, "invalid ttl %s", r.TTL)
+		}
+	}
+	return nil
+}
+
+func (l *RegionLabel) validate() error {
+	if l.Key == "" {
+		return errors.Wrap(ErrInvalidLabelRule, "label key is empty")
+	}
+	switch l.Key {
+	case ScheduleKey, MergeKey:
+		if l.Value != DenyValue && l.Value != AllowValue {
+			return errors.Wrapf(ErrInvalidLabelRule, "the value of %s should be %s or %s", l.Key, DenyValue, AllowValue)
+		}
+	case LeaderStoreLabelKey:
+		if _, _, ok := parseStoreLabel(l.Value); !ok {
+			return errors.Wrapf(ErrInvalidLabelRule, "the value of %s should be in the format of key=value", l.Key)
+		}
+	}
+	return nil
+}
+
+func parseStoreLabel(s string) (key, value string, ok bool) {
+	kv := strings.SplitN(s, "=", 2)
+	if len(kv) != 2 || kv[0] == "" {
+		return "", "", false
+	}
+	return kv[0], kv[1], true
+}
@@ -0,0 +1,379 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"bytes"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/typeutil"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/checker"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	// MergeJobTickInterval is the interval to plan and run the merges of the
+	// merge jobs.
+	MergeJobTickInterval = time.Second
+
+	mergeJobDesc               = "bulk-merge-region"
+	defaultMergeJobConcurrency = 8
+	maxMergeJobConcurrency     = 256
+	mergeJobScanLimit          = 1024
+	// maxEndedMergeJobs limits the ended jobs kept for querying.
+	maxEndedMergeJobs = 16
+)
+
+var (
+	// ErrInvalidMergeJob is returned when the merge job is invalid.
+	ErrInvalidMergeJob = errors.New("invalid merge job")
+	// ErrMergeJobNotFound is returned when the merge job does not exist.
+	ErrMergeJobNotFound = errors.New("merge job not found")
+)
+
+// MergeJobState is the state of a merge job.
+type MergeJobState string
+
+// The states of merge jobs.
+const (
+	MergeJobRunning  MergeJobState = "running"
+	MergeJobFinished MergeJobState = "finished"
+	MergeJobCanceled MergeJobState = "canceled"
+)
+
+// MergeJobStatus is the progress of a merge job.
+type MergeJobStatus struct {
+	ID          uint64        `json:"id"`
+	StartKey    string        `json:"start_key"`
+	EndKey      string        `json:"end_key"`
+	Concurrency int           `json:"concurrency"`
+	State       MergeJobState `json:"state"`
+	CreateTime  time.Time     `json:"create_time"`
+	EndTime     *time.Time    `json:"end_time,omitempty"`
+	// Total is the number of the merges needed when the job is created. It
+	// grows if the regions in the range split during the job.
+	Total int `json:"total"`
+	// Remaining is the number of the merges still needed, including the
+	// running ones. The merges are planned again in each round.
+	Remaining int `json:"remaining"`
+	Running   int `json:"running"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	// Progress is the ratio of the finished merges, from 0 to 1.
+	Progress float64 `json:"progress"`
+	// ETA is the estimated time to finish the job according to the speed so
+	// far. It is empty before any merge finishes.
+	ETA *typeutil.Duration `json:"eta,omitempty"`
+}
+
+type mergeJob struct {
+	status           MergeJobStatus
+	startKey, endKey []byte
+	// running are the passive operators of the running merges, which succeed
+	// when the target regions take over the ranges of the source regions.
+	running []*operator.Operator
+}
+
+// MergeJobController runs the jobs to merge the empty or small regions in key
+// ranges in bulk, which is much faster than merging them one by one by the
+// merge checker. The jobs are kept in memory, so they are lost when the
+// leader changes.
+type MergeJobController struct {
+	sync.RWMutex
+	cluster      opt.Cluster
+	opController *OperatorController
+	nextID       uint64
+	jobs         map[uint64]*mergeJob
+}
+
+// NewMergeJobController creates a MergeJobController.
+func NewMergeJobController(cluster opt.Cluster, opController *OperatorController) *MergeJobController {
+	return &MergeJobController{
+		cluster:      cluster,
+		opController: opController,
+		nextID:       1,
+		jobs:         make(map[uint64]*mergeJob),
+	}
+}
+
+// AddJob creates a job to merge the regions in [startKey, endKey) with at most
+// concurrency merges running at the same time. The default concurrency is used
+// if it is 0.
+func (c *MergeJobController) AddJob(startKey, endKey []byte, concurrency int) (*MergeJobStatus, error) {
+	if concurrency == 0 {
+		concurrency = defaultMergeJobConcurrency
+	}
+	if concurrency < 0 || concurrency > maxMergeJobConcurrency {
+		return nil, errors.Wrapf(ErrInvalidMergeJob, "concurrency should be in [1, %d]", maxMergeJobConcurrency)
+	}
+	if len(endKey) > 0 && bytes.Compare(endKey, startKey) <= 0 {
+		return nil, errors.Wrap(ErrInvalidMergeJob, "end key should be greater than start key")
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	for _, job := range c.jobs {
+		if job.status.State == MergeJobRunning && overlapRange(startKey, endKey, job.startKey, job.endKey) {
+			return nil, errors.Wrapf(ErrInvalidMergeJob, "the range overlaps with the running job %d", job.status.ID)
+		}
+	}
+	job := &mergeJob{
+		status: MergeJobStatus{
+			ID:          c.nextID,
+			StartKey:    hex.EncodeToString(startKey),
+			EndKey:      hex.EncodeToString(endKey),
+			Concurrency: concurrency,
+			State:       MergeJobRunning,
+			CreateTime:  time.Now(),
+		},
+		startKey: startKey,
+		endKey:   endKey,
+	}
+	c.nextID++
+	job.status.Total = countMerges(c.planMergeChains(startKey, endKey))
+	job.status.Remaining = job.status.Total
+	if job.status.Total == 0 {
+		job.end(MergeJobFinished)
+	}
+	c.jobs[job.status.ID] = job
+	c.pruneEndedJobs()
+	log.Info("merge job is added", zap.Uint64("job-id", job.status.ID), zap.String("start-key", job.status.StartKey),
+		zap.String("end-key", job.status.EndKey), zap.Int("merges", job.status.Total))
+	status := job.status
+	return &status, nil
+}
+
+// GetJob returns the status of the job.
+func (c *MergeJobController) GetJob(id uint64) (*MergeJobStatus, error) {
+	c.RLock()
+	defer c.RUnlock()
+	job, ok := c.jobs[id]
+	if !ok {
+		return nil, errors.Wrapf(ErrMergeJobNotFound, "job %d", id)
+	}
+	status := job.status
+	return &status, nil
+}
+
+// GetJobs returns the status of all the jobs, sorted by ID.
+func (c *MergeJobController) GetJobs() []*MergeJobStatus {
+	c.RLock()
+	defer c.RUnlock()
+	jobs := make([]*MergeJobStatus, 0, len(c.jobs))
+	for _, job := range c.jobs {
+		status := job.status
+		jobs = append(jobs, &status)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
+	return jobs
+}
+
+// CancelJob stops the job from starting new merges, the running merges are
+// not canceled.
+func (c *MergeJobController) CancelJob(id uint64) error {
+	c.Lock()
+	defer c.Unlock()
+	job, ok := c.jobs[id]
+	if !ok {
+		return errors.Wrapf(ErrMergeJobNotFound, "job %d", id)
+	}
+	if job.status.State == MergeJobRunning {
+		job.end(MergeJobCanceled)
+		log.Info("merge job is canceled", zap.Uint64("job-id", id))
+	}
+	return nil
+}
+
+// Tick checks the running merges and starts new ones for the running jobs.
+func (c *MergeJobController) Tick() {
+	c.Lock()
+	defer c.Unlock()
+	for _, job := range c.jobs {
+		if job.status.State == MergeJobRunning {
+			c.tickJob(job)
+		}
+	}
+	c.pruneEndedJobs()
+}
+
+func (c *MergeJobController) tickJob(job *mergeJob) {
+	s := &job.status
+	running := job.running[:0]
+	for _, op := range job.running {
+		switch {
+		case op.Status() == operator.SUCCESS:
+			s.Succeeded++
+		case op.IsEnd():
+			s.Failed++
+		default:
+			running = append(running, op)
+		}
+	}
+	job.running = running
+
+	chains := c.planMergeChains(job.startKey, job.endKey)
+	s.Remaining = countMerges(chains)
+	if s.Remaining > s.Total {
+		s.Total = s.Remaining
+	}
+	if s.Remaining == 0 && len(job.running) == 0 {
+		s.Running = 0
+		job.end(MergeJobFinished)
+		log.Info("merge job is finished", zap.Uint64("job-id", s.ID), zap.Int("succeeded", s.Succeeded), zap.Int("failed", s.Failed))
+		return
+	}
+
+	// Merges the disjoint pairs of adjacent regions in each chain, the merged
+	// regions are merged again in the following rounds.
+	budget := s.Concurrency - len(job.running)
+	for _, chain := range chains {
+		for i := 0; i+1 < len(chain) && budget > 0; i++ {
+			source, target := chain[i], chain[i+1]
+			if c.opController.GetOperator(source.GetID()) != nil || c.opController.GetOperator(target.GetID()) != nil {
+				continue
+			}
+			ops, err := operator.CreateMergeRegionOperator(mergeJobDesc, c.cluster, source, target, operator.OpMerge)
+			if err != nil {
+				log.Debug("failed to create merge operator", zap.Uint64("job-id", s.ID), zap.Error(err))
+				continue
+			}
+			if !c.opController.AddOperator(ops...) {
+				continue
+			}
+			job.running = append(job.running, ops[1])
+			budget--
+			i++
+		}
+	}
+	s.Running = len(job.running)
+
+	done := s.Total - s.Remaining
+	if s.Total > 0 {
+		s.Progress = float64(done) / float64(s.Total)
+	}
+	if done > 0 {
+		elapsed := time.Since(s.CreateTime)
+		eta := typeutil.NewDuration(elapsed * time.Duration(s.Remaining) / time.Duration(done))
+		s.ETA = &eta
+	}
+}
+
+func (job *mergeJob) end(state MergeJobState) {
+	now := time.Now()
+	job.status.State = state
+	job.status.EndTime = &now
+	job.status.ETA = nil
+	if state == MergeJobFinished {
+		job.status.Progress = 1
+	}
+	job.running = nil
+}
+
+func (c *MergeJobController) pruneEndedJobs() {
+	var ended []uint64
+	for id, job := range c.jobs {
+		if job.status.State != MergeJobRunning {
+			ended = append(ended, id)
+		}
+	}
+	if len(ended) <= maxEndedMergeJobs {
+		return
+	}
+	sort.Slice(ended, func(i, j int) bool { return ended[i] < ended[j] })
+	for _, id := range ended[:len(ended)-maxEndedMergeJobs] {
+		delete(c.jobs, id)
+	}
+}
+
+// planMergeChains returns the chains of the adjacent regions in the range
+// which can be merged with each other.
+func (c *MergeJobController) planMergeChains(startKey, endKey []byte) [][]*core.RegionInfo {
+	var (
+		chains [][]*core.RegionInfo
+		chain  []*core.RegionInfo
+	)
+	flush := func() {
+		if len(chain) > 1 {
+			chains = append(chains, chain)
+		}
+		chain = nil
+	}
+	key := startKey
+	for {
+		regions := c.cluster.ScanRegions(key, endKey, mergeJobScanLimit)
+		for _, region := range regions {
+			if !c.isMergeCandidate(region, startKey, endKey) {
+				flush()
+				continue
+			}
+			if len(chain) > 0 && !checker.AllowMerge(c.cluster, chain[len(chain)-1], region) {
+				flush()
+			}
+			chain = append(chain, region)
+		}
+		if len(regions) < mergeJobScanLimit {
+			break
+		}
+		key = regions[len(regions)-1].GetEndKey()
+		if len(key) == 0 {
+			break
+		}
+	}
+	flush()
+	return chains
+}
+
+// isMergeCandidate returns true if the region is in the range and is small
+// enough to be merged as the merge checker requires.
+func (c *MergeJobController) isMergeCandidate(region *core.RegionInfo, startKey, endKey []byte) bool {
+	if bytes.Compare(region.GetStartKey(), startKey) < 0 {
+		return false
+	}
+	if len(endKey) > 0 && (len(region.GetEndKey()) == 0 || bytes.Compare(region.GetEndKey(), endKey) > 0) {
+		return false
+	}
+	// The size of the regions loaded from storage is unknown before their
+	// first heartbeats.
+	if region.GetApproximateSize() == 0 ||
+		region.GetApproximateSize() > int64(c.cluster.GetMaxMergeRegionSize()) ||
+		region.GetApproximateKeys() > int64(c.cluster.GetMaxMergeRegionKeys()) {
+		return false
+	}
+	return opt.IsRegionHealthy(c.cluster, region) && opt.IsRegionReplicated(c.cluster, region) &&
+		!c.cluster.IsRegionHot(region) && opt.IsRegionMergeAllowed(c.cluster, region)
+}
+
+func countMerges(chains [][]*core.RegionInfo) int {
+	var n int
+	for _, chain := range chains {
+		n += len(chain) - 1
+	}
+	return n
+}
+
+// overlapRange returns true if [start1, end1) and [start2, end2) overlap, an
+// empty end key means the end of the key space.
+func overlapRange(start1, end1, start2, end2 []byte) bool {
+	return (len(end2) == 0 || bytes.Compare(start1, end2) < 0) &&
+		(len(end1) == 0 || bytes.Compare(start2, end1) < 0)
+}
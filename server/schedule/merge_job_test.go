@@ -0,0 +1,196 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"context"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/pkg/mock/mockcluster"
+	"github.com/pingcap/pd/v4/pkg/mock/mockhbstream"
+	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/placement"
+	"github.com/pkg/errors"
+)
+
+var _ = Suite(&testMergeJobSuite{})
+
+type testMergeJobSuite struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	tc     *mockcluster.Cluster
+	oc     *OperatorController
+	mjc    *MergeJobController
+}
+
+func (s *testMergeJobSuite) SetUpTest(c *C) {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	opt := mockoption.NewScheduleOptions()
+	opt.MaxMergeRegionSize = 2
+	opt.MaxMergeRegionKeys = 2
+	s.tc = mockcluster.NewCluster(opt)
+	for id := uint64(1); id <= 3; id++ {
+		s.tc.AddRegionStore(id, 10)
+	}
+	s.oc = NewOperatorController(s.ctx, s.tc, mockhbstream.NewHeartbeatStream())
+	s.mjc = NewMergeJobController(s.tc, s.oc)
+
+	// Regions: ["", a), [a, b), [b, c), [c, d), [d, e), [e, f), [f, g), [g, "")
+	// region 5 [d, e) is too large to merge.
+	keys := []string{"", "a", "b", "c", "d", "e", "f", "g", ""}
+	for i := 0; i+1 < len(keys); i++ {
+		id := uint64(i + 1)
+		size := int64(1)
+		if id == 5 {
+			size = 100
+		}
+		s.tc.PutRegion(newMergeJobTestRegion(id, keys[i], keys[i+1], size))
+	}
+}
+
+func (s *testMergeJobSuite) TearDownTest(c *C) {
+	s.cancel()
+}
+
+func newMergeJobTestRegion(id uint64, start, end string, size int64) *core.RegionInfo {
+	peers := []*metapb.Peer{
+		{Id: id*10 + 1, StoreId: 1},
+		{Id: id*10 + 2, StoreId: 2},
+		{Id: id*10 + 3, StoreId: 3},
+	}
+	return core.NewRegionInfo(
+		&metapb.Region{
+			Id:          id,
+			StartKey:    []byte(start),
+			EndKey:      []byte(end),
+			Peers:       peers,
+			RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+		},
+		peers[0],
+		core.SetApproximateSize(size),
+		core.SetApproximateKeys(size),
+	)
+}
+
+// finishMerges finishes the running merges by merging the source regions into
+// the target regions.
+func (s *testMergeJobSuite) finishMerges() int {
+	var n int
+	for _, op := range s.oc.GetOperators() {
+		step, ok := op.Step(op.Len() - 1).(operator.MergeRegion)
+		if !ok || !step.IsPassive {
+			continue
+		}
+		target := s.tc.GetRegion(step.ToRegion.GetId())
+		source := s.tc.GetRegion(step.FromRegion.GetId())
+		merged := target.Clone(core.WithStartKey(source.GetStartKey()), core.WithIncVersion())
+		s.tc.PutRegion(merged)
+		s.oc.Dispatch(merged, DispatchFromHeartBeat)
+		s.oc.RemoveOperator(s.oc.GetOperator(source.GetID()))
+		n++
+	}
+	return n
+}
+
+func (s *testMergeJobSuite) TestMergeJob(c *C) {
+	job, err := s.mjc.AddJob([]byte("a"), []byte("g"), 1)
+	c.Assert(err, IsNil)
+	c.Assert(job.State, Equals, MergeJobRunning)
+	// [a, b), [b, c), [c, d) and [e, f), [f, g) can be merged.
+	c.Assert(job.Total, Equals, 3)
+
+	// The range overlaps with the running job.
+	_, err = s.mjc.AddJob([]byte("f"), []byte(""), 1)
+	c.Assert(errors.Cause(err), Equals, ErrInvalidMergeJob)
+
+	// Only one merge runs at the same time.
+	s.mjc.Tick()
+	job, err = s.mjc.GetJob(job.ID)
+	c.Assert(err, IsNil)
+	c.Assert(job.Running, Equals, 1)
+	c.Assert(job.Remaining, Equals, 3)
+	c.Assert(s.finishMerges(), Equals, 1)
+
+	s.mjc.Tick()
+	job, _ = s.mjc.GetJob(job.ID)
+	c.Assert(job.Succeeded, Equals, 1)
+	c.Assert(job.Remaining, Equals, 2)
+	c.Assert(job.Progress, Equals, 1.0/3)
+	c.Assert(job.ETA, NotNil)
+
+	for i := 0; i < 5 && job.State == MergeJobRunning; i++ {
+		s.finishMerges()
+		s.mjc.Tick()
+		job, _ = s.mjc.GetJob(job.ID)
+	}
+	c.Assert(job.State, Equals, MergeJobFinished)
+	c.Assert(job.Succeeded, Equals, 3)
+	c.Assert(job.Progress, Equals, 1.0)
+	c.Assert(job.EndTime, NotNil)
+	// The region out of the range and the large region are not merged.
+	c.Assert(s.tc.GetRegion(1), NotNil)
+	c.Assert(s.tc.GetRegion(5), NotNil)
+	c.Assert(s.tc.GetRegion(8), NotNil)
+	c.Assert(s.tc.ScanRegions([]byte(""), nil, 0), HasLen, 5)
+}
+
+func (s *testMergeJobSuite) TestMergeJobConcurrency(c *C) {
+	job, err := s.mjc.AddJob(nil, nil, 0)
+	c.Assert(err, IsNil)
+	c.Assert(job.Concurrency, Equals, defaultMergeJobConcurrency)
+	// ["", a) ... [c, d) and [e, f) ... [g, "") can be merged.
+	c.Assert(job.Total, Equals, 5)
+
+	// The disjoint pairs are merged at the same time.
+	s.mjc.Tick()
+	job, _ = s.mjc.GetJob(job.ID)
+	c.Assert(job.Running, Equals, 3)
+
+	c.Assert(s.mjc.CancelJob(job.ID), IsNil)
+	job, _ = s.mjc.GetJob(job.ID)
+	c.Assert(job.State, Equals, MergeJobCanceled)
+	s.finishMerges()
+	s.mjc.Tick()
+	c.Assert(s.oc.GetOperators(), HasLen, 0)
+
+	_, err = s.mjc.GetJob(job.ID + 1)
+	c.Assert(errors.Cause(err), Equals, ErrMergeJobNotFound)
+	_, err = s.mjc.AddJob(nil, nil, maxMergeJobConcurrency+1)
+	c.Assert(errors.Cause(err), Equals, ErrInvalidMergeJob)
+	_, err = s.mjc.AddJob([]byte("b"), []byte("a"), 1)
+	c.Assert(errors.Cause(err), Equals, ErrInvalidMergeJob)
+}
+
+func (s *testMergeJobSuite) TestPlacementRuleBoundary(c *C) {
+	s.tc.EnablePlacementRules = true
+	c.Assert(s.tc.RuleManager.SetRule(&placement.Rule{
+		GroupID:     "pd",
+		ID:          "test",
+		Index:       1,
+		Override:    true,
+		StartKeyHex: "62", // b
+		EndKeyHex:   "63", // c
+		Role:        placement.Voter,
+		Count:       3,
+	}), IsNil)
+	// [a, b), [b, c) and [c, d) can not be merged across the rule boundaries.
+	job, err := s.mjc.AddJob([]byte("a"), []byte("d"), 1)
+	c.Assert(err, IsNil)
+	c.Assert(job.State, Equals, MergeJobFinished)
+	c.Assert(job.Total, Equals, 0)
+}
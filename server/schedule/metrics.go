@@ -50,13 +50,30 @@ var (
 			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 16),
 		}, []string{"type"})
 
+	waitingQueueDepthGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "schedule",
+			Name:      "waiting_queue_depth",
+			Help:      "Number of operators in each waiting queue.",
+		}, []string{"queue"})
+
+	waitingQueueWaitDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "pd",
+			Subsystem: "schedule",
+			Name:      "waiting_queue_wait_duration_seconds",
+			Help:      "Bucketed histogram of waiting time (s) of operator in each waiting queue.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 16),
+		}, []string{"queue"})
+
 	storeLimitGauge = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: "pd",
 			Subsystem: "schedule",
 			Name:      "store_limit",
 			Help:      "Limit of store.",
-		}, []string{"store", "type"})
+		}, []string{"store", "type", "limit_type"})
 )
 
 func init() {
@@ -65,4 +82,6 @@ func init() {
 	prometheus.MustRegister(operatorWaitDuration)
 	prometheus.MustRegister(storeLimitGauge)
 	prometheus.MustRegister(operatorWaitCounter)
+	prometheus.MustRegister(waitingQueueDepthGauge)
+	prometheus.MustRegister(waitingQueueWaitDuration)
 }
@@ -147,15 +147,16 @@ func isRegionMatch(a, b *core.RegionInfo) bool {
 }
 
 // CreateScatterRegionOperator creates an operator that scatters the specified region.
-func CreateScatterRegionOperator(desc string, cluster Cluster, origin *core.RegionInfo, targetPeers map[uint64]*metapb.Peer) (*Operator, error) {
-	// randomly pick a leader.
-	var ids []uint64
-	for id := range targetPeers {
-		ids = append(ids, id)
-	}
-	var leader uint64
-	if len(ids) > 0 {
-		leader = ids[rand.Intn(len(ids))]
+// A leader is picked randomly from the target peers if it is 0.
+func CreateScatterRegionOperator(desc string, cluster Cluster, origin *core.RegionInfo, targetPeers map[uint64]*metapb.Peer, leader uint64) (*Operator, error) {
+	if leader == 0 {
+		var ids []uint64
+		for id := range targetPeers {
+			ids = append(ids, id)
+		}
+		if len(ids) > 0 {
+			leader = ids[rand.Intn(len(ids))]
+		}
 	}
 	return NewBuilder(desc, cluster, origin).
 		SetPeers(targetPeers).
@@ -13,7 +13,10 @@
 
 package operator
 
-import "github.com/pingcap/pd/v4/server/core"
+import (
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/storelimit"
+)
 
 // OpInfluence records the influence of the cluster.
 type OpInfluence struct {
@@ -36,7 +39,20 @@ type StoreInfluence struct {
 	RegionCount int64
 	LeaderSize  int64
 	LeaderCount int64
-	StepCost    int64
+	StepCost    map[storelimit.Type]int64
+}
+
+// AddStepCost adds the step cost of the given store limit type.
+func (s *StoreInfluence) AddStepCost(limitType storelimit.Type, cost int64) {
+	if s.StepCost == nil {
+		s.StepCost = make(map[storelimit.Type]int64)
+	}
+	s.StepCost[limitType] += cost
+}
+
+// GetStepCost returns the step cost of the given store limit type.
+func (s *StoreInfluence) GetStepCost(limitType storelimit.Type) int64 {
+	return s.StepCost[limitType]
 }
 
 // ResourceProperty returns delta size of leader/region by influence.
@@ -51,6 +51,7 @@ type Cluster interface {
 type Operator struct {
 	desc        string
 	brief       string
+	source      string
 	regionID    uint64
 	regionEpoch *metapb.RegionEpoch
 	kind        OpKind
@@ -105,11 +106,27 @@ func (o *Operator) Desc() string {
 	return o.desc
 }
 
+// Brief returns the operator's short brief.
+func (o *Operator) Brief() string {
+	return o.brief
+}
+
 // SetDesc sets the description for the operator.
 func (o *Operator) SetDesc(desc string) {
 	o.desc = desc
 }
 
+// Source returns the name of the scheduler which creates the operator, it is
+// empty if the operator is not created by a scheduler.
+func (o *Operator) Source() string {
+	return o.source
+}
+
+// SetSource sets the name of the scheduler which creates the operator.
+func (o *Operator) SetSource(source string) {
+	o.source = source
+}
+
 // AttachKind attaches an operator kind for the operator.
 func (o *Operator) AttachKind(kind OpKind) {
 	o.kind |= kind
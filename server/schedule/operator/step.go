@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/log"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/storelimit"
 	"go.uber.org/zap"
 )
 
@@ -107,11 +108,7 @@ func (ap AddPeer) Influence(opInfluence OpInfluence, region *core.RegionInfo) {
 	regionSize := region.GetApproximateSize()
 	to.RegionSize += regionSize
 	to.RegionCount++
-	if regionSize > smallRegionThreshold {
-		to.StepCost += RegionInfluence
-	} else if regionSize <= smallRegionThreshold && regionSize > core.EmptyRegionApproximateSize {
-		to.StepCost += smallRegionInfluence
-	}
+	to.AddStepCost(storelimit.AddPeer, stepCost(regionSize))
 }
 
 // AddLearner is an OpStep that adds a region learner peer.
@@ -150,11 +147,7 @@ func (al AddLearner) Influence(opInfluence OpInfluence, region *core.RegionInfo)
 	regionSize := region.GetApproximateSize()
 	to.RegionSize += regionSize
 	to.RegionCount++
-	if regionSize > smallRegionThreshold {
-		to.StepCost += RegionInfluence
-	} else if regionSize <= smallRegionThreshold && regionSize > core.EmptyRegionApproximateSize {
-		to.StepCost += smallRegionInfluence
-	}
+	to.AddStepCost(storelimit.AddPeer, stepCost(regionSize))
 }
 
 // PromoteLearner is an OpStep that promotes a region learner peer to normal voter.
@@ -211,8 +204,10 @@ func (rp RemovePeer) IsFinish(region *core.RegionInfo) bool {
 func (rp RemovePeer) Influence(opInfluence OpInfluence, region *core.RegionInfo) {
 	from := opInfluence.GetStoreInfluence(rp.FromStore)
 
-	from.RegionSize -= region.GetApproximateSize()
+	regionSize := region.GetApproximateSize()
+	from.RegionSize -= regionSize
 	from.RegionCount--
+	from.AddStepCost(storelimit.RemovePeer, stepCost(regionSize))
 }
 
 // MergeRegion is an OpStep that merge two regions.
@@ -363,3 +358,15 @@ func (al AddLightLearner) Influence(opInfluence OpInfluence, region *core.Region
 	to.RegionSize += region.GetApproximateSize()
 	to.RegionCount++
 }
+
+// stepCost returns the cost of adding or removing a peer of the region with
+// the given size, which is taken from the store limit.
+func stepCost(regionSize int64) int64 {
+	if regionSize > smallRegionThreshold {
+		return RegionInfluence
+	}
+	if regionSize > core.EmptyRegionApproximateSize {
+		return smallRegionInfluence
+	}
+	return 0
+}
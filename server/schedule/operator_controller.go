@@ -31,6 +31,7 @@ import (
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pingcap/pd/v4/server/schedule/storelimit"
 	"go.uber.org/zap"
 )
 
@@ -51,6 +52,11 @@ var (
 	StoreBalanceBaseTime float64 = 60
 )
 
+// OperatorRecorder records the operators added to the OperatorController.
+type OperatorRecorder interface {
+	RecordOperator(op *operator.Operator)
+}
+
 // OperatorController is used to limit the speed of scheduling.
 type OperatorController struct {
 	sync.RWMutex
@@ -60,28 +66,37 @@ type OperatorController struct {
 	hbStreams       opt.HeartbeatStreams
 	histories       *list.List
 	counts          map[operator.OpKind]uint64
+	queueCounts     map[string]uint64
 	opRecords       *OperatorRecords
-	storesLimit     map[uint64]*StoreLimit
-	wop             WaitingOperator
+	storesLimit     map[uint64]map[storelimit.Type]*StoreLimit
+	wop             *WeightedQueues
 	wopStatus       *WaitingOperatorStatus
 	opNotifierQueue operatorQueue
+	recorder        OperatorRecorder
+	history         *OperatorHistory
+	// defaultLimits is the rate set by the user for the stores which have
+	// no limit of their own, e.g. the stores added later.
+	defaultLimits map[storelimit.Type]float64
 }
 
 // NewOperatorController creates a OperatorController.
 func NewOperatorController(ctx context.Context, cluster opt.Cluster, hbStreams opt.HeartbeatStreams) *OperatorController {
-	return &OperatorController{
+	oc := &OperatorController{
 		ctx:             ctx,
 		cluster:         cluster,
 		operators:       make(map[uint64]*operator.Operator),
 		hbStreams:       hbStreams,
 		histories:       list.New(),
 		counts:          make(map[operator.OpKind]uint64),
+		queueCounts:     make(map[string]uint64),
 		opRecords:       NewOperatorRecords(ctx),
-		storesLimit:     make(map[uint64]*StoreLimit),
-		wop:             NewRandBuckets(),
+		storesLimit:     make(map[uint64]map[storelimit.Type]*StoreLimit),
+		defaultLimits:   make(map[storelimit.Type]float64),
 		wopStatus:       NewWaitingOperatorStatus(),
 		opNotifierQueue: make(operatorQueue, 0),
 	}
+	oc.wop = NewWeightedQueues(oc.getQueueWeight, oc.withinInflightQuota)
+	return oc
 }
 
 // Ctx returns a context which will be canceled once RaftCluster is stopped.
@@ -90,6 +105,20 @@ func (oc *OperatorController) Ctx() context.Context {
 	return oc.ctx
 }
 
+// SetRecorder sets the recorder of the added operators.
+func (oc *OperatorController) SetRecorder(recorder OperatorRecorder) {
+	oc.Lock()
+	defer oc.Unlock()
+	oc.recorder = recorder
+}
+
+// SetHistory sets the history to save the finished operators.
+func (oc *OperatorController) SetHistory(history *OperatorHistory) {
+	oc.Lock()
+	defer oc.Unlock()
+	oc.history = history
+}
+
 // GetCluster exports cluster to evict-scheduler for check sctore status.
 func (oc *OperatorController) GetCluster() opt.Cluster {
 	oc.RLock()
@@ -139,7 +168,9 @@ func (oc *OperatorController) Dispatch(region *core.RegionInfo, source string) {
 					panic(op)
 				})
 				_ = op.Cancel()
+				oc.Lock()
 				oc.buryOperator(op)
+				oc.Unlock()
 				oc.PromoteWaitingOperator()
 			}
 		}
@@ -381,6 +412,13 @@ func (oc *OperatorController) checkAddOperator(ops ...*operator.Operator) bool {
 			operatorWaitCounter.WithLabelValues(op.Desc(), "add_canceled").Inc()
 			return false
 		}
+		if storeID := oc.fullTargetStore(op); storeID != 0 {
+			log.Debug("the disk of the target store is full, cancel add operator",
+				zap.Uint64("region-id", op.RegionID()),
+				zap.Uint64("store-id", storeID))
+			operatorWaitCounter.WithLabelValues(op.Desc(), "disk_full").Inc()
+			return false
+		}
 		if op.Status() != operator.CREATED {
 			log.Error("trying to add operator with unexpected status",
 				zap.Uint64("region-id", op.RegionID()),
@@ -408,6 +446,31 @@ func (oc *OperatorController) checkAddOperator(ops ...*operator.Operator) bool {
 	return !expired
 }
 
+// fullTargetStore returns the ID of the store whose disk is full and the
+// operator adds a peer to, or 0 if there is no such store.
+func (oc *OperatorController) fullTargetStore(op *operator.Operator) uint64 {
+	for i := 0; i < op.Len(); i++ {
+		var storeID uint64
+		switch step := op.Step(i).(type) {
+		case operator.AddPeer:
+			storeID = step.ToStore
+		case operator.AddLearner:
+			storeID = step.ToStore
+		case operator.AddLightPeer:
+			storeID = step.ToStore
+		case operator.AddLightLearner:
+			storeID = step.ToStore
+		default:
+			continue
+		}
+		store := oc.cluster.GetStore(storeID)
+		if store != nil && store.DiskState(oc.cluster.GetDiskAlmostFullRatio(), oc.cluster.GetDiskFullRatio()) == core.DiskStateFull {
+			return storeID
+		}
+	}
+	return 0
+}
+
 func isHigherPriorityOperator(new, old *operator.Operator) bool {
 	return new.GetPriorityLevel() > old.GetPriorityLevel()
 }
@@ -443,12 +506,14 @@ func (oc *OperatorController) addOperatorLocked(op *operator.Operator) bool {
 	operatorWaitDuration.WithLabelValues(op.Desc()).Observe(op.ElapsedTime().Seconds())
 	opInfluence := NewTotalOpInfluence([]*operator.Operator{op}, oc.cluster)
 	for storeID := range opInfluence.StoresInfluence {
-		stepCost := opInfluence.GetStoreInfluence(storeID).StepCost
-		if stepCost == 0 {
-			continue
+		for _, limitType := range storelimit.Types() {
+			stepCost := opInfluence.GetStoreInfluence(storeID).GetStepCost(limitType)
+			if stepCost == 0 {
+				continue
+			}
+			storeLimitGauge.WithLabelValues(strconv.FormatUint(storeID, 10), "take", limitType.String()).Set(float64(stepCost) / float64(operator.RegionInfluence))
+			oc.getOrCreateStoreLimit(storeID, limitType).Take(stepCost)
 		}
-		storeLimitGauge.WithLabelValues(strconv.FormatUint(storeID, 10), "take").Set(float64(stepCost) / float64(operator.RegionInfluence))
-		oc.storesLimit[storeID].Take(stepCost)
 	}
 	oc.updateCounts(oc.operators)
 
@@ -464,14 +529,17 @@ func (oc *OperatorController) addOperatorLocked(op *operator.Operator) bool {
 	for _, counter := range op.Counters {
 		counter.Inc()
 	}
+	if oc.recorder != nil {
+		oc.recorder.RecordOperator(op)
+	}
 	return true
 }
 
 // RemoveOperator removes a operator from the running operators.
 func (oc *OperatorController) RemoveOperator(op *operator.Operator) bool {
 	oc.Lock()
+	defer oc.Unlock()
 	removed := oc.removeOperatorLocked(op)
-	oc.Unlock()
 	if removed {
 		if op.Cancel() {
 			log.Info("operator removed",
@@ -501,6 +569,7 @@ func (oc *OperatorController) removeOperatorLocked(op *operator.Operator) bool {
 	return false
 }
 
+// buryOperator records the finished operator. The caller should hold the lock.
 func (oc *OperatorController) buryOperator(op *operator.Operator) {
 	st := op.Status()
 
@@ -545,6 +614,9 @@ func (oc *OperatorController) buryOperator(op *operator.Operator) {
 	}
 
 	oc.opRecords.Put(op)
+	if oc.history != nil {
+		oc.history.Record(op)
+	}
 }
 
 // GetOperatorStatus gets the operator and its status with the specify id.
@@ -743,8 +815,58 @@ func (oc *OperatorController) updateCounts(operators map[uint64]*operator.Operat
 	for k := range oc.counts {
 		delete(oc.counts, k)
 	}
+	for k := range oc.queueCounts {
+		delete(oc.queueCounts, k)
+	}
 	for _, op := range operators {
 		oc.counts[op.Kind()]++
+		oc.queueCounts[QueueName(op)]++
+	}
+}
+
+func (oc *OperatorController) getQueueWeight(queue string) float64 {
+	return oc.cluster.GetSchedulerWeight(queue)
+}
+
+// withinInflightQuota returns whether the running operators of the waiting
+// queue are fewer than its in-flight quota. The caller should hold the lock.
+func (oc *OperatorController) withinInflightQuota(queue string) bool {
+	quota := oc.cluster.GetSchedulerInflightQuota(queue)
+	return quota == 0 || oc.queueCounts[queue] < quota
+}
+
+// GetWaitingQueues gets the status of the waiting queues.
+func (oc *OperatorController) GetWaitingQueues() []*WaitingQueueStatus {
+	oc.RLock()
+	defer oc.RUnlock()
+	status := oc.wop.getStatus()
+	for _, s := range status {
+		s.Weight = oc.cluster.GetSchedulerWeight(s.Name)
+		s.Quota = oc.cluster.GetSchedulerInflightQuota(s.Name)
+		s.Running = oc.queueCounts[s.Name]
+	}
+	return status
+}
+
+// RemoveWaitingQueue removes the waiting queue of a scheduler and cancels the
+// operators in it. It is called when the scheduler is removed.
+func (oc *OperatorController) RemoveWaitingQueue(name string) {
+	oc.Lock()
+	defer oc.Unlock()
+	ops := oc.wop.removeQueue(name)
+	for i := 0; i < len(ops); i++ {
+		op := ops[i]
+		desc := op.Desc()
+		operatorWaitCounter.WithLabelValues(desc, "remove").Inc()
+		_ = op.Cancel()
+		oc.buryOperator(op)
+		// Merge operation has two operators which are counted as one.
+		if op.Kind()&operator.OpMerge != 0 && i+1 < len(ops) {
+			i++
+			_ = ops[i].Cancel()
+			oc.buryOperator(ops[i])
+		}
+		oc.wopStatus.ops[desc]--
 	}
 }
 
@@ -855,81 +977,109 @@ func (o *OperatorRecords) Put(op *operator.Operator) {
 func (oc *OperatorController) exceedStoreLimit(ops ...*operator.Operator) bool {
 	opInfluence := NewTotalOpInfluence(ops, oc.cluster)
 	for storeID := range opInfluence.StoresInfluence {
-		stepCost := opInfluence.GetStoreInfluence(storeID).StepCost
-		if stepCost == 0 {
-			continue
-		}
+		for _, limitType := range storelimit.Types() {
+			stepCost := opInfluence.GetStoreInfluence(storeID).GetStepCost(limitType)
+			if stepCost == 0 {
+				continue
+			}
 
-		available := oc.getOrCreateStoreLimit(storeID).bucket.Available()
-		storeLimitGauge.WithLabelValues(strconv.FormatUint(storeID, 10), "available").Set(float64(available) / float64(operator.RegionInfluence))
-		if available < stepCost {
-			return true
+			available := oc.getOrCreateStoreLimit(storeID, limitType).Available()
+			storeLimitGauge.WithLabelValues(strconv.FormatUint(storeID, 10), "available", limitType.String()).Set(float64(available) / float64(operator.RegionInfluence))
+			if available < stepCost {
+				return true
+			}
 		}
 	}
 	return false
 }
 
 // SetAllStoresLimit is used to set limit of all stores.
-func (oc *OperatorController) SetAllStoresLimit(rate float64, mode StoreLimitMode) {
+func (oc *OperatorController) SetAllStoresLimit(rate float64, mode StoreLimitMode, limitType storelimit.Type) {
 	oc.Lock()
 	defer oc.Unlock()
 	stores := oc.cluster.GetStores()
 	for _, s := range stores {
-		oc.newStoreLimit(s.GetID(), rate, mode)
+		oc.newStoreLimit(s.GetID(), rate, mode, limitType)
+	}
+	if mode == StoreLimitManual {
+		oc.defaultLimits[limitType] = rate
 	}
 }
 
+// SetDefaultStoreLimit sets the limit of the stores which have no limit of
+// their own.
+func (oc *OperatorController) SetDefaultStoreLimit(rate float64, limitType storelimit.Type) {
+	oc.Lock()
+	defer oc.Unlock()
+	oc.defaultLimits[limitType] = rate
+}
+
 // SetAllStoresLimitAuto updates the store limit in StoreLimitAuto mode
-func (oc *OperatorController) SetAllStoresLimitAuto(rate float64) {
+func (oc *OperatorController) SetAllStoresLimitAuto(rate float64, limitType storelimit.Type) {
 	oc.Lock()
 	defer oc.Unlock()
 	stores := oc.cluster.GetStores()
 	for _, s := range stores {
 		sid := s.GetID()
-		if old, ok := oc.storesLimit[sid]; ok {
+		if old, ok := oc.storesLimit[sid][limitType]; ok {
 			if old.Mode() == StoreLimitManual {
 				continue
 			}
 		}
-		oc.storesLimit[sid] = NewStoreLimit(rate, StoreLimitAuto)
+		oc.newStoreLimit(sid, rate, StoreLimitAuto, limitType)
 	}
 }
 
 // SetStoreLimit is used to set the limit of a store.
-func (oc *OperatorController) SetStoreLimit(storeID uint64, rate float64, mode StoreLimitMode) {
+func (oc *OperatorController) SetStoreLimit(storeID uint64, rate float64, mode StoreLimitMode, limitType storelimit.Type) {
 	oc.Lock()
 	defer oc.Unlock()
-	oc.newStoreLimit(storeID, rate, mode)
+	oc.newStoreLimit(storeID, rate, mode, limitType)
 }
 
 // newStoreLimit is used to create the limit of a store.
-func (oc *OperatorController) newStoreLimit(storeID uint64, rate float64, mode StoreLimitMode) {
-	oc.storesLimit[storeID] = NewStoreLimit(rate, mode)
-}
-
-// getOrCreateStoreLimit is used to get or create the limit of a store.
-func (oc *OperatorController) getOrCreateStoreLimit(storeID uint64) *StoreLimit {
-	if oc.storesLimit[storeID] == nil {
-		rate := oc.cluster.GetStoreBalanceRate() / StoreBalanceBaseTime
-		oc.newStoreLimit(storeID, rate, StoreLimitAuto)
-		oc.cluster.AttachAvailableFunc(storeID, func() bool {
+func (oc *OperatorController) newStoreLimit(storeID uint64, rate float64, mode StoreLimitMode, limitType storelimit.Type) {
+	limits, ok := oc.storesLimit[storeID]
+	if !ok {
+		limits = make(map[storelimit.Type]*StoreLimit)
+		oc.storesLimit[storeID] = limits
+	}
+	if _, ok := limits[limitType]; !ok {
+		oc.cluster.AttachAvailableFunc(storeID, limitType, func() bool {
 			oc.RLock()
 			defer oc.RUnlock()
-			return oc.storesLimit[storeID].Available() >= operator.RegionInfluence
+			limit, ok := oc.storesLimit[storeID][limitType]
+			return !ok || limit.Available() >= operator.RegionInfluence
 		})
 	}
-	return oc.storesLimit[storeID]
+	limits[limitType] = NewStoreLimit(rate, mode)
+}
+
+// getOrCreateStoreLimit is used to get or create the limit of a store.
+func (oc *OperatorController) getOrCreateStoreLimit(storeID uint64, limitType storelimit.Type) *StoreLimit {
+	if oc.storesLimit[storeID][limitType] == nil {
+		if rate, ok := oc.defaultLimits[limitType]; ok {
+			oc.newStoreLimit(storeID, rate, StoreLimitManual, limitType)
+		} else {
+			rate := oc.cluster.GetStoreBalanceRate() / StoreBalanceBaseTime
+			oc.newStoreLimit(storeID, rate, StoreLimitAuto, limitType)
+		}
+	}
+	return oc.storesLimit[storeID][limitType]
 }
 
 // GetAllStoresLimit is used to get limit of all stores.
-func (oc *OperatorController) GetAllStoresLimit() map[uint64]*StoreLimit {
+func (oc *OperatorController) GetAllStoresLimit() map[uint64]map[storelimit.Type]*StoreLimit {
 	oc.RLock()
 	defer oc.RUnlock()
-	limits := make(map[uint64]*StoreLimit)
+	limits := make(map[uint64]map[storelimit.Type]*StoreLimit)
 	for storeID, limit := range oc.storesLimit {
 		store := oc.cluster.GetStore(storeID)
 		if !store.IsTombstone() {
-			limits[storeID] = limit
+			limits[storeID] = make(map[storelimit.Type]*StoreLimit, len(limit))
+			for limitType, l := range limit {
+				limits[storeID][limitType] = l
+			}
 		}
 	}
 	return limits
@@ -947,6 +1097,8 @@ func (oc *OperatorController) GetLeaderSchedulePolicy() core.SchedulePolicy {
 func (oc *OperatorController) RemoveStoreLimit(storeID uint64) {
 	oc.Lock()
 	defer oc.Unlock()
-	oc.cluster.AttachAvailableFunc(storeID, nil)
+	for _, limitType := range storelimit.Types() {
+		oc.cluster.AttachAvailableFunc(storeID, limitType, nil)
+	}
 	delete(oc.storesLimit, storeID)
 }
@@ -31,6 +31,7 @@ import (
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/schedule/checker"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/storelimit"
 )
 
 func Test(t *testing.T) {
@@ -314,7 +315,7 @@ func (t *testOperatorControllerSuite) TestStoreLimit(c *C) {
 	for i := uint64(1); i <= 1000; i++ {
 		tc.AddLeaderRegion(i, i)
 	}
-	oc.SetStoreLimit(2, 1, StoreLimitManual)
+	oc.SetStoreLimit(2, 1, StoreLimitManual, storelimit.AddPeer)
 	for i := uint64(1); i <= 5; i++ {
 		op := operator.NewOperator("test", "test", 1, &metapb.RegionEpoch{}, operator.OpRegion, operator.AddPeer{ToStore: 2, PeerID: i})
 		c.Assert(oc.AddOperator(op), IsTrue)
@@ -324,13 +325,13 @@ func (t *testOperatorControllerSuite) TestStoreLimit(c *C) {
 	c.Assert(oc.AddOperator(op), IsFalse)
 	c.Assert(oc.RemoveOperator(op), IsFalse)
 
-	oc.SetStoreLimit(2, 2, StoreLimitManual)
+	oc.SetStoreLimit(2, 2, StoreLimitManual, storelimit.AddPeer)
 	for i := uint64(1); i <= 10; i++ {
 		op = operator.NewOperator("test", "test", i, &metapb.RegionEpoch{}, operator.OpRegion, operator.AddPeer{ToStore: 2, PeerID: i})
 		c.Assert(oc.AddOperator(op), IsTrue)
 		checkRemoveOperatorSuccess(c, oc, op)
 	}
-	oc.SetAllStoresLimit(1, StoreLimitManual)
+	oc.SetAllStoresLimit(1, StoreLimitManual, storelimit.AddPeer)
 	for i := uint64(1); i <= 5; i++ {
 		op = operator.NewOperator("test", "test", i, &metapb.RegionEpoch{}, operator.OpRegion, operator.AddPeer{ToStore: 2, PeerID: i})
 		c.Assert(oc.AddOperator(op), IsTrue)
@@ -339,6 +340,20 @@ func (t *testOperatorControllerSuite) TestStoreLimit(c *C) {
 	op = operator.NewOperator("test", "test", 1, &metapb.RegionEpoch{}, operator.OpRegion, operator.AddPeer{ToStore: 2, PeerID: 1})
 	c.Assert(oc.AddOperator(op), IsFalse)
 	c.Assert(oc.RemoveOperator(op), IsFalse)
+
+	// Removing peers is limited separately.
+	oc.SetStoreLimit(2, 1, StoreLimitManual, storelimit.RemovePeer)
+	for i := uint64(1); i <= 5; i++ {
+		op = operator.NewOperator("test", "test", i, &metapb.RegionEpoch{}, operator.OpRegion, operator.RemovePeer{FromStore: 2})
+		c.Assert(oc.AddOperator(op), IsTrue)
+		checkRemoveOperatorSuccess(c, oc, op)
+	}
+	op = operator.NewOperator("test", "test", 1, &metapb.RegionEpoch{}, operator.OpRegion, operator.RemovePeer{FromStore: 2})
+	c.Assert(oc.AddOperator(op), IsFalse)
+	c.Assert(oc.RemoveOperator(op), IsFalse)
+	limits := oc.GetAllStoresLimit()[2]
+	c.Assert(limits[storelimit.AddPeer].Rate(), Equals, 1.0)
+	c.Assert(limits[storelimit.RemovePeer].Mode(), Equals, StoreLimitManual)
 }
 
 // #1652
@@ -677,3 +692,93 @@ func (t *testOperatorControllerSuite) TestAddWaitingOperator(c *C) {
 	// no space left, new operator can not be added.
 	c.Assert(controller.AddWaitingOperator(addPeerOp(0)), Equals, 0)
 }
+
+func (t *testOperatorControllerSuite) TestAddPeerToFullStore(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	oc := NewOperatorController(t.ctx, tc, mockhbstream.NewHeartbeatStream())
+	tc.AddLeaderStore(1, 1)
+	tc.AddLeaderStore(2, 0)
+	tc.AddLeaderStore(3, 0)
+	tc.UpdateStorageRatio(2, 0.92, 0.08)
+	tc.UpdateStorageRatio(3, 0.97, 0.03)
+	tc.AddLeaderRegion(1, 1)
+	region := tc.GetRegion(1)
+
+	// Adding a peer to a full store is rejected.
+	op := operator.NewOperator("test", "test", 1, region.GetRegionEpoch(), operator.OpRegion, operator.AddLearner{ToStore: 3, PeerID: 3})
+	c.Assert(oc.AddOperator(op), IsFalse)
+	op = operator.NewOperator("test", "test", 1, region.GetRegionEpoch(), operator.OpRegion, operator.AddPeer{ToStore: 3, PeerID: 3})
+	c.Assert(oc.AddOperator(op), IsFalse)
+	// Adding a peer to an almost full store is allowed.
+	op = operator.NewOperator("test", "test", 1, region.GetRegionEpoch(), operator.OpRegion, operator.AddLearner{ToStore: 2, PeerID: 2})
+	c.Assert(oc.AddOperator(op), IsTrue)
+}
+
+func (t *testOperatorControllerSuite) TestWaitingQueueQuota(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	opt.SchedulerInflightQuotas = map[string]uint64{"evict-leader-scheduler": 2}
+	tc := mockcluster.NewCluster(opt)
+	oc := NewOperatorController(t.ctx, tc, mockhbstream.NewHeartbeatStream())
+	tc.AddLeaderStore(1, 3)
+	tc.AddLeaderStore(2, 0)
+	var ops []*operator.Operator
+	for i := uint64(1); i <= 3; i++ {
+		tc.AddLeaderRegion(i, 1, 2)
+		op := operator.NewOperator("evict-leader", "test", i, tc.GetRegion(i).GetRegionEpoch(), operator.OpLeader, operator.TransferLeader{FromStore: 1, ToStore: 2})
+		op.SetSource("evict-leader-scheduler")
+		c.Assert(oc.AddWaitingOperator(op), Equals, 1)
+		ops = append(ops, op)
+	}
+	// The third operator waits for the quota.
+	c.Assert(oc.GetOperator(3), IsNil)
+	queues := oc.GetWaitingQueues()
+	c.Assert(queues, HasLen, 1)
+	c.Assert(queues[0].Name, Equals, "evict-leader-scheduler")
+	c.Assert(queues[0].Weight, Equals, 1.0)
+	c.Assert(queues[0].Quota, Equals, uint64(2))
+	c.Assert(queues[0].Running, Equals, uint64(2))
+	c.Assert(queues[0].Depth, Equals, 1)
+
+	// It is promoted after a running operator is finished.
+	c.Assert(oc.RemoveOperator(ops[0]), IsTrue)
+	oc.PromoteWaitingOperator()
+	c.Assert(oc.GetOperator(3), Equals, ops[2])
+	queues = oc.GetWaitingQueues()
+	c.Assert(queues[0].Running, Equals, uint64(2))
+	c.Assert(queues[0].Depth, Equals, 0)
+
+	// The queue is removed with the scheduler, and its waiting operators are
+	// canceled.
+	tc.AddLeaderRegion(4, 1, 2)
+	op := operator.NewOperator("evict-leader", "test", 4, tc.GetRegion(4).GetRegionEpoch(), operator.OpLeader, operator.TransferLeader{FromStore: 1, ToStore: 2})
+	op.SetSource("evict-leader-scheduler")
+	c.Assert(oc.AddWaitingOperator(op), Equals, 1)
+	c.Assert(oc.wopStatus.ops["evict-leader"], Equals, uint64(1))
+	oc.RemoveWaitingQueue("evict-leader-scheduler")
+	c.Assert(oc.GetWaitingQueues(), HasLen, 0)
+	c.Assert(op.Status(), Equals, operator.CANCELED)
+	c.Assert(oc.wopStatus.ops["evict-leader"], Equals, uint64(0))
+}
+
+func (t *testOperatorControllerSuite) TestOperatorHistory(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	oc := NewOperatorController(t.ctx, tc, mockhbstream.NewHeartbeatStream())
+	history, err := NewOperatorHistory(c.MkDir(), time.Hour)
+	c.Assert(err, IsNil)
+	defer history.Close()
+	oc.SetHistory(history)
+	tc.AddLeaderStore(1, 1)
+	tc.AddLeaderStore(2, 0)
+	tc.AddLeaderRegion(1, 1, 2)
+	op := operator.NewOperator("balance-leader", "test", 1, tc.GetRegion(1).GetRegionEpoch(), operator.OpLeader, operator.TransferLeader{FromStore: 1, ToStore: 2})
+	op.SetSource("balance-leader-scheduler")
+	c.Assert(oc.AddWaitingOperator(op), Equals, 1)
+	c.Assert(oc.RemoveOperator(op), IsTrue)
+
+	records := waitRecords(c, history, 1)
+	c.Assert(records[0].RegionID, Equals, uint64(1))
+	c.Assert(records[0].Scheduler, Equals, "balance-leader-scheduler")
+	c.Assert(records[0].Status, Equals, "Canceled")
+}
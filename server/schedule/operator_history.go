@@ -0,0 +1,282 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/slice"
+	"github.com/pingcap/pd/v4/server/kv"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"go.uber.org/zap"
+)
+
+// OperatorHistoryRecord is a finished operator saved in the operator history.
+type OperatorHistoryRecord struct {
+	RegionID uint64 `json:"region-id"`
+	Desc     string `json:"desc"`
+	Brief    string `json:"brief"`
+	Kind     string `json:"kind"`
+	// Scheduler is the scheduler which creates the operator, it is empty if
+	// the operator is created by a checker or the API.
+	Scheduler string    `json:"scheduler,omitempty"`
+	Steps     []string  `json:"steps"`
+	Stores    []uint64  `json:"stores"`
+	StartTime time.Time `json:"start-time"`
+	EndTime   time.Time `json:"end-time"`
+	Status    string    `json:"status"`
+}
+
+// NewOperatorHistoryRecord creates the history record of a finished operator.
+func NewOperatorHistoryRecord(op *operator.Operator) *OperatorHistoryRecord {
+	steps := make([]string, 0, op.Len())
+	for i := 0; i < op.Len(); i++ {
+		steps = append(steps, op.Step(i).String())
+	}
+	// The records are keyed by the end time, so it must be set.
+	endTime := op.GetReachTimeOf(op.Status())
+	if endTime.IsZero() {
+		endTime = time.Now()
+	}
+	return &OperatorHistoryRecord{
+		RegionID:  op.RegionID(),
+		Desc:      op.Desc(),
+		Brief:     op.Brief(),
+		Kind:      op.Kind().String(),
+		Scheduler: op.Source(),
+		Steps:     steps,
+		Stores:    relatedStores(op),
+		StartTime: op.GetStartTime(),
+		EndTime:   endTime,
+		Status:    operator.OpStatusToString(op.Status()),
+	}
+}
+
+// relatedStores returns the stores which the steps of the operator involve.
+func relatedStores(op *operator.Operator) []uint64 {
+	var stores []uint64
+	add := func(storeIDs ...uint64) {
+		for _, id := range storeIDs {
+			if slice.NoneOf(stores, func(i int) bool { return stores[i] == id }) {
+				stores = append(stores, id)
+			}
+		}
+	}
+	for i := 0; i < op.Len(); i++ {
+		switch s := op.Step(i).(type) {
+		case operator.TransferLeader:
+			add(s.FromStore, s.ToStore)
+		case operator.AddPeer:
+			add(s.ToStore)
+		case operator.AddLearner:
+			add(s.ToStore)
+		case operator.AddLightPeer:
+			add(s.ToStore)
+		case operator.AddLightLearner:
+			add(s.ToStore)
+		case operator.PromoteLearner:
+			add(s.ToStore)
+		case operator.RemovePeer:
+			add(s.FromStore)
+		}
+	}
+	return stores
+}
+
+// OperatorHistoryFilter selects the records of the operator history. The zero
+// value of a field matches all the records.
+type OperatorHistoryFilter struct {
+	RegionID  uint64
+	StoreID   uint64
+	Kind      operator.OpKind
+	Scheduler string
+	// Start and End limit the end time of the operators to [Start, End).
+	Start time.Time
+	End   time.Time
+	// Limit is the max number of the records to return.
+	Limit int
+}
+
+func (f *OperatorHistoryFilter) match(r *OperatorHistoryRecord) bool {
+	if f.RegionID != 0 && r.RegionID != f.RegionID {
+		return false
+	}
+	if f.StoreID != 0 && slice.NoneOf(r.Stores, func(i int) bool { return r.Stores[i] == f.StoreID }) {
+		return false
+	}
+	if f.Kind != 0 {
+		kind, err := operator.ParseOperatorKind(r.Kind)
+		if err != nil || kind&f.Kind == 0 {
+			return false
+		}
+	}
+	return f.Scheduler == "" || r.Scheduler == f.Scheduler
+}
+
+// operatorHistoryChanSize is the number of records which can be queued before
+// they are saved. The records are dropped when the queue is full.
+const operatorHistoryChanSize = 1024
+
+// OperatorHistory saves the finished operators in a local leveldb, so that
+// they can be queried after they are removed from the memory. The records are
+// saved by a background goroutine. The records whose end time is older than
+// the retention are purged by Purge.
+type OperatorHistory struct {
+	db        *kv.LeveldbKV
+	retention time.Duration
+
+	records chan *OperatorHistoryRecord
+	quit    chan struct{}
+	once    sync.Once
+	wg      sync.WaitGroup
+}
+
+// NewOperatorHistory opens the operator history at path.
+func NewOperatorHistory(path string, retention time.Duration) (*OperatorHistory, error) {
+	db, err := kv.NewLeveldbKV(path)
+	if err != nil {
+		return nil, err
+	}
+	h := &OperatorHistory{
+		db:        db,
+		retention: retention,
+		records:   make(chan *OperatorHistoryRecord, operatorHistoryChanSize),
+		quit:      make(chan struct{}),
+	}
+	h.wg.Add(1)
+	go h.run()
+	return h, nil
+}
+
+// The records are keyed by the end time so that the queries and the purge can
+// seek to the time range, the region ID makes the key unique because a region
+// has at most one operator at a time.
+func operatorHistoryKey(endTime time.Time, regionID uint64) string {
+	return fmt.Sprintf("%020d/%020d", endTime.UnixNano(), regionID)
+}
+
+// Record queues the finished operator to be saved, it never blocks. The
+// operators which have never started are not saved.
+func (h *OperatorHistory) Record(op *operator.Operator) {
+	if !op.HasStarted() || !op.IsEnd() {
+		return
+	}
+	select {
+	case <-h.quit:
+		return
+	default:
+	}
+	r := NewOperatorHistoryRecord(op)
+	select {
+	case h.records <- r:
+	default:
+		log.Warn("drop the operator history since the queue is full", zap.Uint64("region-id", r.RegionID))
+	}
+}
+
+func (h *OperatorHistory) run() {
+	defer h.wg.Done()
+	for {
+		select {
+		case r := <-h.records:
+			h.save(r)
+		case <-h.quit:
+			// Save the queued records before closing.
+			for {
+				select {
+				case r := <-h.records:
+					h.save(r)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (h *OperatorHistory) save(r *OperatorHistoryRecord) {
+	value, err := json.Marshal(r)
+	if err != nil {
+		log.Error("failed to encode operator history", zap.Uint64("region-id", r.RegionID), zap.Error(err))
+		return
+	}
+	if err := h.db.Save(operatorHistoryKey(r.EndTime, r.RegionID), string(value)); err != nil {
+		log.Error("failed to save operator history", zap.Uint64("region-id", r.RegionID), zap.Error(err))
+	}
+}
+
+// Query returns the records selected by the filter, the latest first.
+func (h *OperatorHistory) Query(filter *OperatorHistoryFilter) ([]*OperatorHistoryRecord, error) {
+	rng := &util.Range{}
+	if !filter.Start.IsZero() {
+		rng.Start = []byte(operatorHistoryKey(filter.Start, 0))
+	}
+	if !filter.End.IsZero() {
+		rng.Limit = []byte(operatorHistoryKey(filter.End, 0))
+	}
+	iter := h.db.NewIterator(rng, nil)
+	defer iter.Release()
+	var records []*OperatorHistoryRecord
+	for ok := iter.Last(); ok; ok = iter.Prev() {
+		r := &OperatorHistoryRecord{}
+		if err := json.Unmarshal(iter.Value(), r); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if !filter.match(r) {
+			continue
+		}
+		records = append(records, r)
+		if filter.Limit > 0 && len(records) >= filter.Limit {
+			break
+		}
+	}
+	return records, errors.WithStack(iter.Error())
+}
+
+// Purge removes the records whose end time is older than the retention.
+func (h *OperatorHistory) Purge(now time.Time) error {
+	limit := operatorHistoryKey(now.Add(-h.retention), 0)
+	iter := h.db.NewIterator(&util.Range{Limit: []byte(limit)}, nil)
+	batch := new(leveldb.Batch)
+	for iter.Next() {
+		batch.Delete(append([]byte(nil), iter.Key()...))
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return errors.WithStack(err)
+	}
+	if batch.Len() == 0 {
+		return nil
+	}
+	return errors.WithStack(h.db.Write(batch, nil))
+}
+
+// Close saves the queued records and closes the operator history.
+func (h *OperatorHistory) Close() error {
+	var err error
+	h.once.Do(func() {
+		close(h.quit)
+		h.wg.Wait()
+		err = errors.WithStack(h.db.Close())
+	})
+	return err
+}
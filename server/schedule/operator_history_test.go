@@ -0,0 +1,141 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/pkg/testutil"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+)
+
+var _ = Suite(&testOperatorHistorySuite{})
+
+type testOperatorHistorySuite struct{}
+
+// waitRecords waits until the queued records are saved.
+func waitRecords(c *C, h *OperatorHistory, n int) []*OperatorHistoryRecord {
+	var records []*OperatorHistoryRecord
+	testutil.WaitUntil(c, func(c *C) bool {
+		var err error
+		records, err = h.Query(&OperatorHistoryFilter{})
+		c.Assert(err, IsNil)
+		return len(records) == n
+	})
+	return records
+}
+
+func (s *testOperatorHistorySuite) TestRecordAndQuery(c *C) {
+	h, err := NewOperatorHistory(c.MkDir(), time.Hour)
+	c.Assert(err, IsNil)
+	defer h.Close()
+
+	epoch := &metapb.RegionEpoch{}
+	op1 := operator.NewOperator("balance-leader", "test", 1, epoch, operator.OpLeader, operator.TransferLeader{FromStore: 1, ToStore: 2})
+	op1.SetSource("balance-leader-scheduler")
+	op2 := operator.NewOperator("replica-checker", "test", 2, epoch, operator.OpRegion|operator.OpReplica,
+		operator.AddLearner{ToStore: 3, PeerID: 10}, operator.PromoteLearner{ToStore: 3, PeerID: 10}, operator.RemovePeer{FromStore: 1})
+	// The operator which has never started is not recorded.
+	op3 := operator.NewOperator("balance-leader", "test", 3, epoch, operator.OpLeader, operator.TransferLeader{FromStore: 1, ToStore: 2})
+	c.Assert(op3.Cancel(), IsTrue)
+	h.Record(op3)
+	for _, op := range []*operator.Operator{op1, op2} {
+		c.Assert(op.Start(), IsTrue)
+		c.Assert(op.Cancel(), IsTrue)
+		h.Record(op)
+	}
+
+	records := waitRecords(c, h, 2)
+	// The latest first.
+	r := records[0]
+	c.Assert(r.RegionID, Equals, uint64(2))
+	c.Assert(r.Kind, Equals, "region,replica")
+	c.Assert(r.Scheduler, Equals, "")
+	c.Assert(r.Steps, HasLen, 3)
+	c.Assert(r.Stores, DeepEquals, []uint64{3, 1})
+	c.Assert(r.Status, Equals, "Canceled")
+	c.Assert(r.EndTime.Before(r.StartTime), IsFalse)
+	c.Assert(records[1].Scheduler, Equals, "balance-leader-scheduler")
+
+	for _, t := range []struct {
+		filter  *OperatorHistoryFilter
+		regions []uint64
+	}{
+		{&OperatorHistoryFilter{RegionID: 1}, []uint64{1}},
+		{&OperatorHistoryFilter{StoreID: 1}, []uint64{2, 1}},
+		{&OperatorHistoryFilter{StoreID: 3}, []uint64{2}},
+		{&OperatorHistoryFilter{Kind: operator.OpLeader}, []uint64{1}},
+		{&OperatorHistoryFilter{Kind: operator.OpReplica}, []uint64{2}},
+		{&OperatorHistoryFilter{Scheduler: "balance-leader-scheduler"}, []uint64{1}},
+		{&OperatorHistoryFilter{Limit: 1}, []uint64{2}},
+		{&OperatorHistoryFilter{Start: time.Now().Add(time.Minute)}, nil},
+		{&OperatorHistoryFilter{End: time.Now().Add(-time.Minute)}, nil},
+		{&OperatorHistoryFilter{Start: time.Now().Add(-time.Minute), End: time.Now().Add(time.Minute)}, []uint64{2, 1}},
+	} {
+		records, err := h.Query(t.filter)
+		c.Assert(err, IsNil)
+		var regions []uint64
+		for _, r := range records {
+			regions = append(regions, r.RegionID)
+		}
+		c.Assert(regions, DeepEquals, t.regions, Commentf("%+v", t.filter))
+	}
+}
+
+func (s *testOperatorHistorySuite) TestPurge(c *C) {
+	h, err := NewOperatorHistory(c.MkDir(), time.Hour)
+	c.Assert(err, IsNil)
+	defer h.Close()
+
+	op := operator.NewOperator("balance-leader", "test", 1, &metapb.RegionEpoch{}, operator.OpLeader, operator.TransferLeader{FromStore: 1, ToStore: 2})
+	c.Assert(op.Start(), IsTrue)
+	c.Assert(op.Cancel(), IsTrue)
+	h.Record(op)
+	waitRecords(c, h, 1)
+
+	c.Assert(h.Purge(time.Now()), IsNil)
+	records, err := h.Query(&OperatorHistoryFilter{})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 1)
+
+	c.Assert(h.Purge(time.Now().Add(time.Hour+time.Minute)), IsNil)
+	records, err = h.Query(&OperatorHistoryFilter{})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 0)
+}
+
+func (s *testOperatorHistorySuite) TestClose(c *C) {
+	dir := c.MkDir()
+	h, err := NewOperatorHistory(dir, time.Hour)
+	c.Assert(err, IsNil)
+	for i := uint64(1); i <= 10; i++ {
+		op := operator.NewOperator("balance-leader", "test", i, &metapb.RegionEpoch{}, operator.OpLeader, operator.TransferLeader{FromStore: 1, ToStore: 2})
+		c.Assert(op.Start(), IsTrue)
+		c.Assert(op.Cancel(), IsTrue)
+		h.Record(op)
+	}
+	// The queued records are saved before closed.
+	c.Assert(h.Close(), IsNil)
+	c.Assert(h.Close(), IsNil)
+
+	h, err = NewOperatorHistory(dir, time.Hour)
+	c.Assert(err, IsNil)
+	defer h.Close()
+	records, err := h.Query(&OperatorHistoryFilter{})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 10)
+}
@@ -13,7 +13,10 @@
 
 package opt
 
-import "github.com/pingcap/pd/v4/server/core"
+import (
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
+)
 
 // IsRegionHealthy checks if a region is healthy for scheduling. It requires the
 // region does not have any down or pending peers. And when placement rules
@@ -59,3 +62,42 @@ func IsRegionReplicated(cluster Cluster, region *core.RegionInfo) bool {
 func ReplicatedRegion(cluster Cluster) func(*core.RegionInfo) bool {
 	return func(region *core.RegionInfo) bool { return IsRegionReplicated(cluster, region) }
 }
+
+// GetRegionLabeler returns the region labeler of the cluster, or nil if the
+// cluster does not support region labels.
+func GetRegionLabeler(cluster Cluster) *labeler.RegionLabeler {
+	type withRegionLabeler interface {
+		GetRegionLabeler() *labeler.RegionLabeler
+	}
+	if cl, ok := cluster.(withRegionLabeler); ok {
+		return cl.GetRegionLabeler()
+	}
+	return nil
+}
+
+// IsRegionScheduleAllowed checks if the region is not labeled to deny the
+// balance schedulers and the region scatterer to move it.
+func IsRegionScheduleAllowed(cluster Cluster, region *core.RegionInfo) bool {
+	return GetRegionLabeler(cluster).GetRegionLabel(region, labeler.ScheduleKey) != labeler.DenyValue
+}
+
+// ScheduleAllowedRegion returns a function that checks if a region is not
+// labeled to deny scheduling.
+func ScheduleAllowedRegion(cluster Cluster) func(*core.RegionInfo) bool {
+	return func(region *core.RegionInfo) bool { return IsRegionScheduleAllowed(cluster, region) }
+}
+
+// IsRegionMergeAllowed checks if the region is not labeled to deny merging or
+// scheduling.
+func IsRegionMergeAllowed(cluster Cluster, region *core.RegionInfo) bool {
+	l := GetRegionLabeler(cluster)
+	return l.GetRegionLabel(region, labeler.MergeKey) != labeler.DenyValue &&
+		l.GetRegionLabel(region, labeler.ScheduleKey) != labeler.DenyValue
+}
+
+// IsLeaderStoreAllowed checks if the leader of the region is allowed to be on
+// the store. It is false if the region is labeled to keep the leader on the
+// stores with a store label and the store does not have it.
+func IsLeaderStoreAllowed(cluster Cluster, region *core.RegionInfo, store *core.StoreInfo) bool {
+	return GetRegionLabeler(cluster).IsLeaderStoreAllowed(region, store)
+}
@@ -52,7 +52,11 @@ type Options interface {
 	GetTolerantSizeRatio() float64
 	GetLowSpaceRatio() float64
 	GetHighSpaceRatio() float64
+	GetDiskAlmostFullRatio() float64
+	GetDiskFullRatio() float64
 	GetSchedulerMaxWaitingOperator() uint64
+	GetSchedulerWeight(name string) float64
+	GetSchedulerInflightQuota(name string) uint64
 
 	IsRemoveDownReplicaEnabled() bool
 	IsReplaceOfflineReplicaEnabled() bool
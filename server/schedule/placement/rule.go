@@ -17,6 +17,8 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"sort"
+
+	"github.com/pkg/errors"
 )
 
 // PeerRoleType is the expected peer type of the placement rule.
@@ -53,6 +55,8 @@ type Rule struct {
 	Count            int               `json:"count"`                       // expected count of the peers
 	LabelConstraints []LabelConstraint `json:"label_constraints,omitempty"` // used to select stores to place peers
 	LocationLabels   []string          `json:"location_labels,omitempty"`   // used to make peers isolated physically
+
+	group *RuleGroup // only set when loaded or updated by RuleManager, nil means the default group.
 }
 
 func (r Rule) String() string {
@@ -70,9 +74,83 @@ func (r Rule) StoreKey() string {
 	return hex.EncodeToString([]byte(r.GroupID)) + "-" + hex.EncodeToString([]byte(r.ID))
 }
 
-// Rules are ordered by (GroupID, Index, ID).
+func (r *Rule) groupIndex() int {
+	if r.group != nil {
+		return r.group.Index
+	}
+	return 0
+}
+
+func (r *Rule) groupOverride() bool {
+	return r.group != nil && r.group.Override
+}
+
+// RuleGroup defines properties of a rule group. Groups are applied in order of
+// (Index, ID), and a group with Override set disables all rules of previous
+// groups on overlapping ranges.
+type RuleGroup struct {
+	ID       string `json:"id"`
+	Index    int    `json:"index,omitempty"`
+	Override bool   `json:"override,omitempty"`
+}
+
+func (g *RuleGroup) validate() error {
+	if g.ID == "" {
+		return errors.New("group ID should not be empty")
+	}
+	if g.Index < 0 {
+		return errors.Errorf("group index should not be negative: %d", g.Index)
+	}
+	return nil
+}
+
+func (g RuleGroup) String() string {
+	b, _ := json.Marshal(g)
+	return string(b)
+}
+
+// RuleOpType indicates the operation type of a RuleOp.
+type RuleOpType string
+
+const (
+	// RuleOpAdd a placement rule, only need to specify the field *Rule
+	RuleOpAdd RuleOpType = "add"
+	// RuleOpDel a placement rule, only need to specify the field `GroupID`, `ID`, `DeleteByIDPrefix`
+	RuleOpDel RuleOpType = "del"
+)
+
+// RuleOp is for batching placement rule actions.
+type RuleOp struct {
+	*Rule                       // information of the placement rule to add/delete
+	Action           RuleOpType `json:"action"`              // the operation type
+	DeleteByIDPrefix bool       `json:"delete_by_id_prefix"` // if action == delete, delete by the prefix of id
+}
+
+func (r RuleOp) String() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+// GroupBundle represents a rule group and all rules belong to the group.
+type GroupBundle struct {
+	ID       string  `json:"group_id"`
+	Index    int     `json:"group_index"`
+	Override bool    `json:"group_override"`
+	Rules    []*Rule `json:"rules"`
+}
+
+func (g GroupBundle) String() string {
+	b, _ := json.Marshal(g)
+	return string(b)
+}
+
+// Rules are ordered by (GroupIndex, GroupID, Index, ID).
 func compareRule(a, b *Rule) int {
 	switch {
+	case a.groupIndex() < b.groupIndex():
+		return -1
+	case a.groupIndex() > b.groupIndex():
+		return 1
 	case a.GroupID < b.GroupID:
 		return -1
 	case a.GroupID > b.GroupID:
@@ -94,13 +172,35 @@ func sortRules(rules []*Rule) {
 	sort.Slice(rules, func(i, j int) bool { return compareRule(rules[i], rules[j]) < 0 })
 }
 
+func sortRuleGroups(groups []*RuleGroup) {
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Index != groups[j].Index {
+			return groups[i].Index < groups[j].Index
+		}
+		return groups[i].ID < groups[j].ID
+	})
+}
+
+func containsGroup(groups []*RuleGroup, id string) bool {
+	for _, g := range groups {
+		if g.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
 // Sort Rules, trim concealed rules.
 func prepareRulesForApply(rules []*Rule) []*Rule {
 	var res []*Rule
 	var i, j int
 	for i = 1; i < len(rules); i++ {
 		if rules[j].GroupID != rules[i].GroupID {
-			res = append(res, rules[j:i]...)
+			if rules[i].groupOverride() {
+				res = res[:0] // rules of all previous groups are overridden.
+			} else {
+				res = append(res, rules[j:i]...)
+			}
 			j = i
 		}
 		if rules[i].Override {
@@ -16,6 +16,8 @@ package placement
 import (
 	"bytes"
 	"sort"
+
+	"github.com/pkg/errors"
 )
 
 type splitPointType int
@@ -118,6 +120,31 @@ func buildRuleList(rules map[[2]string]*Rule) ruleList {
 	return rl
 }
 
+// validate checks that every key is covered by rules that place at least one
+// voter.
+func (rl ruleList) validate() error {
+	if len(rl.ranges) == 0 || len(rl.ranges[0].startKey) > 0 {
+		return errors.New("rules should cover the whole key space")
+	}
+	for i, rr := range rl.ranges {
+		var hasVoter bool
+		for _, r := range rr.applyRules {
+			if r.Role == Voter || r.Role == Leader {
+				hasVoter = true
+				break
+			}
+		}
+		if !hasVoter {
+			var endKey []byte
+			if i+1 < len(rl.ranges) {
+				endKey = rl.ranges[i+1].startKey
+			}
+			return errors.Errorf("needs at least one leader or voter in range [%x, %x)", rr.startKey, endKey)
+		}
+	}
+	return nil
+}
+
 func (rl ruleList) getSplitKeys(start, end []byte) [][]byte {
 	var keys [][]byte
 	i := sort.Search(len(rl.ranges), func(i int) bool {
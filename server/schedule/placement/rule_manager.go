@@ -17,6 +17,7 @@ import (
 	"bytes"
 	"encoding/hex"
 	"encoding/json"
+	"strings"
 	"sync"
 
 	"github.com/pingcap/log"
@@ -32,14 +33,16 @@ type RuleManager struct {
 	sync.RWMutex
 	initialized bool
 	rules       map[[2]string]*Rule
+	groups      map[string]*RuleGroup
 	ruleList    ruleList
 }
 
 // NewRuleManager creates a RuleManager instance.
 func NewRuleManager(store *core.Storage) *RuleManager {
 	return &RuleManager{
-		store: store,
-		rules: make(map[[2]string]*Rule),
+		store:  store,
+		rules:  make(map[[2]string]*Rule),
+		groups: make(map[string]*RuleGroup),
 	}
 }
 
@@ -52,6 +55,9 @@ func (m *RuleManager) Initialize(maxReplica int, locationLabels []string) error
 		return nil
 	}
 
+	if err := m.loadGroups(); err != nil {
+		return err
+	}
 	if err := m.loadRules(); err != nil {
 		return err
 	}
@@ -99,6 +105,7 @@ func (m *RuleManager) loadRules() error {
 			toDelete = append(toDelete, k)
 			toSave = append(toSave, &r)
 		}
+		r.group = m.groups[r.GroupID]
 		m.rules[r.Key()] = &r
 	})
 	if err != nil {
@@ -117,6 +124,33 @@ func (m *RuleManager) loadRules() error {
 	return nil
 }
 
+func (m *RuleManager) loadGroups() error {
+	var toDelete []string
+	_, err := m.store.LoadRuleGroups(func(k, v string) {
+		var g RuleGroup
+		if err := json.Unmarshal([]byte(v), &g); err != nil {
+			log.Error("failed to unmarshal rule group value", zap.String("group-id", k), zap.String("group-value", v))
+			toDelete = append(toDelete, k)
+			return
+		}
+		if g.ID != k {
+			log.Error("mismatch rule group id", zap.String("group-id", k), zap.String("group-value", v))
+			toDelete = append(toDelete, k)
+			return
+		}
+		m.groups[g.ID] = &g
+	})
+	if err != nil {
+		return err
+	}
+	for _, d := range toDelete {
+		if err = m.store.DeleteRuleGroup(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // check and adjust rule from client or storage.
 func (m *RuleManager) adjustRule(r *Rule) error {
 	var err error
@@ -166,6 +200,7 @@ func (m *RuleManager) SetRule(rule *Rule) error {
 	}
 	m.Lock()
 	defer m.Unlock()
+	rule.group = m.groups[rule.GroupID]
 	old := m.rules[rule.Key()]
 	m.rules[rule.Key()] = rule
 
@@ -204,6 +239,306 @@ func (m *RuleManager) DeleteRule(group, id string) error {
 	return nil
 }
 
+// GetRuleGroup returns the RuleGroup with the id. A group that has rules but is
+// not configured is returned with default properties. It returns nil if the
+// group neither is configured nor has any rule.
+func (m *RuleManager) GetRuleGroup(id string) *RuleGroup {
+	m.RLock()
+	defer m.RUnlock()
+	if g, ok := m.groups[id]; ok {
+		return g
+	}
+	for _, r := range m.rules {
+		if r.GroupID == id {
+			return &RuleGroup{ID: id}
+		}
+	}
+	return nil
+}
+
+// GetRuleGroups returns all rule groups sorted by (Index, ID), including the
+// ones that only have rules.
+func (m *RuleManager) GetRuleGroups() []*RuleGroup {
+	m.RLock()
+	defer m.RUnlock()
+	return m.getRuleGroupsLocked()
+}
+
+func (m *RuleManager) getRuleGroupsLocked() []*RuleGroup {
+	groups := make([]*RuleGroup, 0, len(m.groups))
+	for _, g := range m.groups {
+		groups = append(groups, g)
+	}
+	for _, r := range m.rules {
+		if !containsGroup(groups, r.GroupID) {
+			groups = append(groups, &RuleGroup{ID: r.GroupID})
+		}
+	}
+	sortRuleGroups(groups)
+	return groups
+}
+
+// SetRuleGroup inserts or updates a RuleGroup. The rules with the new group
+// must place at least one voter on the whole key space.
+func (m *RuleManager) SetRuleGroup(group *RuleGroup) error {
+	if err := group.validate(); err != nil {
+		return err
+	}
+	m.Lock()
+	defer m.Unlock()
+	rules, ruleList, err := m.rulesWithGroup(group.ID, group)
+	if err != nil {
+		return err
+	}
+	if err := m.store.SaveRuleGroup(group.ID, group); err != nil {
+		return err
+	}
+	m.groups[group.ID] = group
+	m.rules, m.ruleList = rules, ruleList
+	log.Info("placement rule group updated", zap.Stringer("group", group))
+	return nil
+}
+
+// DeleteRuleGroup removes a RuleGroup. Rules of the group are kept and use
+// default group properties, which must place at least one voter on the whole
+// key space.
+func (m *RuleManager) DeleteRuleGroup(id string) error {
+	m.Lock()
+	defer m.Unlock()
+	old, ok := m.groups[id]
+	if !ok {
+		return nil
+	}
+	rules, ruleList, err := m.rulesWithGroup(id, nil)
+	if err != nil {
+		return err
+	}
+	if err := m.store.DeleteRuleGroup(id); err != nil {
+		return err
+	}
+	delete(m.groups, id)
+	m.rules, m.ruleList = rules, ruleList
+	log.Info("placement rule group removed", zap.Stringer("group", old))
+	return nil
+}
+
+// rulesWithGroup returns the rules in which the rules of the group refer to
+// the new group, and the validated rule list built from them. The rules of
+// the group are replaced with copies, so that rules returned earlier are not
+// mutated. It must be called with the lock held.
+func (m *RuleManager) rulesWithGroup(id string, group *RuleGroup) (map[[2]string]*Rule, ruleList, error) {
+	rules := make(map[[2]string]*Rule, len(m.rules))
+	for key, r := range m.rules {
+		if r.GroupID == id {
+			nr := *r
+			nr.group = group
+			r = &nr
+		}
+		rules[key] = r
+	}
+	ruleList := buildRuleList(rules)
+	if err := ruleList.validate(); err != nil {
+		return nil, ruleList, err
+	}
+	return rules, ruleList, nil
+}
+
+// Batch executes a series of rule operations. All operations are validated
+// first, and the rules after the operations must place at least one voter on
+// the whole key space. Changes are saved to storage in one transaction.
+func (m *RuleManager) Batch(todo []RuleOp) error {
+	for _, t := range todo {
+		if t.Rule == nil {
+			return errors.New("rule should not be empty")
+		}
+		switch t.Action {
+		case RuleOpAdd:
+			if err := m.adjustRule(t.Rule); err != nil {
+				return err
+			}
+		case RuleOpDel:
+		default:
+			return errors.Errorf("invalid rule op action %s", t.Action)
+		}
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	rules := make(map[[2]string]*Rule, len(m.rules))
+	for key, r := range m.rules {
+		rules[key] = r
+	}
+	for _, t := range todo {
+		switch t.Action {
+		case RuleOpAdd:
+			t.Rule.group = m.groups[t.GroupID]
+			rules[t.Key()] = t.Rule
+		case RuleOpDel:
+			if !t.DeleteByIDPrefix {
+				delete(rules, t.Key())
+				continue
+			}
+			for key, r := range rules {
+				if r.GroupID == t.GroupID && strings.HasPrefix(r.ID, t.ID) {
+					delete(rules, key)
+				}
+			}
+		}
+	}
+	if err := m.commit(rules, m.groups); err != nil {
+		return err
+	}
+	for _, t := range todo {
+		log.Info("placement rule batch operation applied", zap.Stringer("op", t))
+	}
+	return nil
+}
+
+// GetAllGroupBundles returns all rules and groups configuration. Rules are
+// grouped by groups.
+func (m *RuleManager) GetAllGroupBundles() []GroupBundle {
+	m.RLock()
+	defer m.RUnlock()
+	var bundles []GroupBundle
+	for _, g := range m.getRuleGroupsLocked() {
+		bundles = append(bundles, m.getGroupBundleLocked(g))
+	}
+	return bundles
+}
+
+// GetGroupBundle returns a group and all rules belong to it.
+func (m *RuleManager) GetGroupBundle(id string) GroupBundle {
+	m.RLock()
+	defer m.RUnlock()
+	g, ok := m.groups[id]
+	if !ok {
+		g = &RuleGroup{ID: id}
+	}
+	return m.getGroupBundleLocked(g)
+}
+
+func (m *RuleManager) getGroupBundleLocked(g *RuleGroup) GroupBundle {
+	bundle := GroupBundle{
+		ID:       g.ID,
+		Index:    g.Index,
+		Override: g.Override,
+		Rules:    []*Rule{},
+	}
+	for _, r := range m.rules {
+		if r.GroupID == g.ID {
+			bundle.Rules = append(bundle.Rules, r)
+		}
+	}
+	sortRules(bundle.Rules)
+	return bundle
+}
+
+// SetGroupBundles replaces groups and all rules of the groups with the
+// bundles. If override is true, groups and rules not in the bundles are
+// removed. The result is validated and saved in one transaction.
+func (m *RuleManager) SetGroupBundles(bundles []GroupBundle, override bool) error {
+	ids := make(map[string]struct{}, len(bundles))
+	for _, b := range bundles {
+		if err := (&RuleGroup{ID: b.ID, Index: b.Index}).validate(); err != nil {
+			return err
+		}
+		if _, ok := ids[b.ID]; ok {
+			return errors.Errorf("duplicated group %s", b.ID)
+		}
+		ids[b.ID] = struct{}{}
+		for _, r := range b.Rules {
+			if r.GroupID == "" {
+				r.GroupID = b.ID
+			}
+			if r.GroupID != b.ID {
+				return errors.Errorf("rule %s/%s does not belong to group %s", r.GroupID, r.ID, b.ID)
+			}
+			if err := m.adjustRule(r); err != nil {
+				return err
+			}
+		}
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	rules := make(map[[2]string]*Rule, len(m.rules))
+	groups := make(map[string]*RuleGroup, len(m.groups))
+	for key, r := range m.rules {
+		if _, ok := ids[r.GroupID]; !ok && !override {
+			rules[key] = r
+		}
+	}
+	for id, g := range m.groups {
+		if _, ok := ids[id]; !ok && !override {
+			groups[id] = g
+		}
+	}
+	for _, b := range bundles {
+		g := &RuleGroup{ID: b.ID, Index: b.Index, Override: b.Override}
+		if g.Index != 0 || g.Override {
+			groups[b.ID] = g
+		}
+		for _, r := range b.Rules {
+			r.group = groups[b.ID]
+			if _, ok := rules[r.Key()]; ok {
+				return errors.Errorf("duplicated rule %s/%s", r.GroupID, r.ID)
+			}
+			rules[r.Key()] = r
+		}
+	}
+	if err := m.commit(rules, groups); err != nil {
+		return err
+	}
+	for _, b := range bundles {
+		log.Info("placement rule group bundle updated", zap.Stringer("bundle", b))
+	}
+	return nil
+}
+
+// commit validates the new rules and groups, saves the difference to storage
+// in one transaction and replaces the current ones. It must be called with
+// the lock held.
+func (m *RuleManager) commit(rules map[[2]string]*Rule, groups map[string]*RuleGroup) error {
+	ruleList := buildRuleList(rules)
+	if err := ruleList.validate(); err != nil {
+		return err
+	}
+
+	var batch core.RuleBatch
+	for key, r := range rules {
+		if old, ok := m.rules[key]; !ok || old != r {
+			if err := batch.SaveRule(r.StoreKey(), r); err != nil {
+				return err
+			}
+		}
+	}
+	for key, old := range m.rules {
+		if _, ok := rules[key]; !ok {
+			batch.DeleteRule(old.StoreKey())
+		}
+	}
+	for id, g := range groups {
+		if old, ok := m.groups[id]; !ok || old != g {
+			if err := batch.SaveRuleGroup(id, g); err != nil {
+				return err
+			}
+		}
+	}
+	for id := range m.groups {
+		if _, ok := groups[id]; !ok {
+			batch.DeleteRuleGroup(id)
+		}
+	}
+	if batch.Len() > core.MaxRuleBatchSize {
+		return errors.Errorf("too many changes of rules and groups in one batch: %d, at most %d", batch.Len(), core.MaxRuleBatchSize)
+	}
+	if err := m.store.SaveRuleBatch(&batch); err != nil {
+		return err
+	}
+	m.rules, m.groups, m.ruleList = rules, groups, ruleList
+	return nil
+}
+
 // GetSplitKeys returns all split keys in the range (start, end).
 func (m *RuleManager) GetSplitKeys(start, end []byte) [][]byte {
 	m.RLock()
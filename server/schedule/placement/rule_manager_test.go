@@ -15,6 +15,7 @@ package placement
 
 import (
 	"encoding/hex"
+	"fmt"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
@@ -168,3 +169,189 @@ func (s *testManagerSuite) dhex(hk string) []byte {
 	}
 	return k
 }
+
+func (s *testManagerSuite) TestGroupConfig(c *C) {
+	pd1 := &RuleGroup{ID: "pd"}
+	c.Assert(s.manager.GetRuleGroup("pd"), DeepEquals, pd1)
+	c.Assert(s.manager.GetRuleGroup("foo"), IsNil)
+
+	// update group pd
+	pd2 := &RuleGroup{ID: "pd", Index: 100, Override: true}
+	err := s.manager.SetRuleGroup(pd2)
+	c.Assert(err, IsNil)
+	c.Assert(s.manager.GetRuleGroup("pd"), DeepEquals, pd2)
+
+	// invalid groups are rejected.
+	c.Assert(s.manager.SetRuleGroup(&RuleGroup{ID: ""}), NotNil)
+	c.Assert(s.manager.SetRuleGroup(&RuleGroup{ID: "pd", Index: -1}), NotNil)
+	c.Assert(s.manager.SetGroupBundles([]GroupBundle{{ID: "pd", Index: -1}}, false), NotNil)
+	c.Assert(s.manager.GetRuleGroup("pd"), DeepEquals, pd2)
+
+	// new group g without config
+	err = s.manager.SetRule(&Rule{GroupID: "g", ID: "1", Role: "voter", Count: 1})
+	c.Assert(err, IsNil)
+	g1 := &RuleGroup{ID: "g"}
+	c.Assert(s.manager.GetRuleGroup("g"), DeepEquals, g1)
+	c.Assert(s.manager.GetRuleGroups(), DeepEquals, []*RuleGroup{g1, pd2})
+
+	// group pd overrides group g because of the larger index.
+	region := core.NewRegionInfo(&metapb.Region{}, nil)
+	rules := s.manager.GetRulesForApplyRegion(region)
+	c.Assert(rules, HasLen, 1)
+	c.Assert(rules[0].Key(), Equals, [2]string{"pd", "default"})
+
+	// reload from storage.
+	m2 := NewRuleManager(s.store)
+	c.Assert(m2.Initialize(3, nil), IsNil)
+	c.Assert(m2.GetRuleGroups(), DeepEquals, []*RuleGroup{g1, pd2})
+	c.Assert(m2.GetRulesForApplyRegion(region), HasLen, 1)
+
+	// remove group config of pd, rules of both groups apply again.
+	err = s.manager.DeleteRuleGroup("pd")
+	c.Assert(err, IsNil)
+	c.Assert(s.manager.GetRuleGroups(), DeepEquals, []*RuleGroup{g1, pd1})
+	c.Assert(s.manager.GetRulesForApplyRegion(region), HasLen, 2)
+}
+
+func (s *testManagerSuite) TestGroupValidate(c *C) {
+	// the learner rule of group l covers the whole key space.
+	c.Assert(s.manager.SetRule(&Rule{GroupID: "l", ID: "1", Role: "learner", Count: 1}), IsNil)
+	region := core.NewRegionInfo(&metapb.Region{}, nil)
+
+	// group l cannot override the only voter rule.
+	c.Assert(s.manager.SetRuleGroup(&RuleGroup{ID: "l", Index: 200, Override: true}), NotNil)
+	c.Assert(s.manager.GetRuleGroups(), DeepEquals, []*RuleGroup{{ID: "l"}, {ID: "pd"}})
+	c.Assert(s.manager.GetRulesForApplyRegion(region), HasLen, 2)
+	m2 := NewRuleManager(s.store)
+	c.Assert(m2.Initialize(3, nil), IsNil)
+	c.Assert(m2.GetRuleGroups(), DeepEquals, []*RuleGroup{{ID: "l"}, {ID: "pd"}})
+
+	// group pd overrides group l with a larger index.
+	pd := &RuleGroup{ID: "pd", Index: 300, Override: true}
+	c.Assert(s.manager.SetRuleGroup(pd), IsNil)
+	l := &RuleGroup{ID: "l", Index: 200, Override: true}
+	c.Assert(s.manager.SetRuleGroup(l), IsNil)
+
+	// the config of group pd cannot be removed, group l would override it.
+	c.Assert(s.manager.DeleteRuleGroup("pd"), NotNil)
+	c.Assert(s.manager.GetRuleGroups(), DeepEquals, []*RuleGroup{l, pd})
+	rules := s.manager.GetRulesForApplyRegion(region)
+	c.Assert(rules, HasLen, 1)
+	c.Assert(rules[0].Key(), Equals, [2]string{"pd", "default"})
+	m2 = NewRuleManager(s.store)
+	c.Assert(m2.Initialize(3, nil), IsNil)
+	c.Assert(m2.GetRuleGroups(), DeepEquals, []*RuleGroup{l, pd})
+}
+
+func (s *testManagerSuite) TestRuleGroupOverride(c *C) {
+	rules := []*Rule{
+		{GroupID: "a", ID: "1", Role: "voter", Count: 1, group: &RuleGroup{ID: "a", Index: 1}},
+		{GroupID: "b", ID: "1", Role: "voter", Count: 1, group: &RuleGroup{ID: "b", Index: 2}},
+		{GroupID: "c", ID: "1", Role: "voter", Count: 1, group: &RuleGroup{ID: "c", Index: 3, Override: true}},
+		{GroupID: "d", ID: "1", Role: "voter", Count: 1, group: &RuleGroup{ID: "d", Index: 4}},
+		{GroupID: "d", ID: "2", Role: "voter", Count: 1, Index: 1, Override: true, group: &RuleGroup{ID: "d", Index: 4}},
+	}
+	applied := prepareRulesForApply(rules)
+	c.Assert(applied, HasLen, 2)
+	c.Assert(applied[0].Key(), Equals, [2]string{"c", "1"})
+	c.Assert(applied[1].Key(), Equals, [2]string{"d", "2"})
+}
+
+func (s *testManagerSuite) TestBatch(c *C) {
+	err := s.manager.Batch([]RuleOp{
+		{Rule: &Rule{GroupID: "pd", ID: "a1", Role: "learner", Count: 1}, Action: RuleOpAdd},
+		{Rule: &Rule{GroupID: "pd", ID: "a2", Role: "learner", Count: 1}, Action: RuleOpAdd},
+		{Rule: &Rule{GroupID: "pd", ID: "b1", Role: "learner", Count: 1}, Action: RuleOpAdd},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(s.manager.GetAllRules(), HasLen, 4)
+
+	err = s.manager.Batch([]RuleOp{
+		{Rule: &Rule{GroupID: "pd", ID: "a"}, Action: RuleOpDel, DeleteByIDPrefix: true},
+		{Rule: &Rule{GroupID: "pd", ID: "b1"}, Action: RuleOpDel},
+		{Rule: &Rule{GroupID: "pd", ID: "c1", Role: "learner", Count: 1}, Action: RuleOpAdd},
+	})
+	c.Assert(err, IsNil)
+	rules := s.manager.GetAllRules()
+	c.Assert(rules, HasLen, 2)
+	c.Assert(rules[0].ID, Equals, "c1")
+	c.Assert(rules[1].ID, Equals, "default")
+
+	// invalid operations are rejected as a whole.
+	err = s.manager.Batch([]RuleOp{
+		{Rule: &Rule{GroupID: "pd", ID: "c1"}, Action: RuleOpDel},
+		{Rule: &Rule{GroupID: "pd", ID: "d1", Role: "foo", Count: 1}, Action: RuleOpAdd},
+	})
+	c.Assert(err, NotNil)
+	// no voter is left.
+	err = s.manager.Batch([]RuleOp{
+		{Rule: &Rule{GroupID: "pd", ID: "c1"}, Action: RuleOpDel},
+		{Rule: &Rule{GroupID: "pd", ID: "default"}, Action: RuleOpDel},
+	})
+	c.Assert(err, NotNil)
+	// key space is not fully covered.
+	err = s.manager.Batch([]RuleOp{
+		{Rule: &Rule{GroupID: "pd", ID: "default"}, Action: RuleOpDel},
+		{Rule: &Rule{GroupID: "pd", ID: "d1", Role: "voter", Count: 3, StartKeyHex: "11"}, Action: RuleOpAdd},
+	})
+	c.Assert(err, NotNil)
+	c.Assert(s.manager.GetAllRules(), HasLen, 2)
+
+	// too many changes to save in one transaction.
+	var ops []RuleOp
+	for i := 0; i <= core.MaxRuleBatchSize; i++ {
+		ops = append(ops, RuleOp{Rule: &Rule{GroupID: "pd", ID: fmt.Sprintf("e%d", i), Role: "learner", Count: 1}, Action: RuleOpAdd})
+	}
+	err = s.manager.Batch(ops)
+	c.Assert(err, ErrorMatches, "too many changes.*")
+	c.Assert(s.manager.GetAllRules(), HasLen, 2)
+
+	m2 := NewRuleManager(s.store)
+	c.Assert(m2.Initialize(3, nil), IsNil)
+	rules2 := m2.GetAllRules()
+	c.Assert(rules2, HasLen, 2)
+	for i := range rules2 {
+		c.Assert(rules2[i].String(), Equals, rules[i].String())
+	}
+}
+
+func (s *testManagerSuite) TestGroupBundle(c *C) {
+	bundles := s.manager.GetAllGroupBundles()
+	c.Assert(bundles, HasLen, 1)
+	c.Assert(bundles[0].ID, Equals, "pd")
+	c.Assert(bundles[0].Rules, HasLen, 1)
+
+	// add a new group without touching group pd.
+	err := s.manager.SetGroupBundles([]GroupBundle{{
+		ID: "foo", Index: 1, Override: true,
+		Rules: []*Rule{{ID: "f1", Role: "voter", Count: 3}},
+	}}, false)
+	c.Assert(err, IsNil)
+	c.Assert(s.manager.GetAllGroupBundles(), HasLen, 2)
+	c.Assert(s.manager.GetRuleGroup("foo"), DeepEquals, &RuleGroup{ID: "foo", Index: 1, Override: true})
+	bundle := s.manager.GetGroupBundle("foo")
+	c.Assert(bundle.Rules, HasLen, 1)
+	c.Assert(bundle.Rules[0].GroupID, Equals, "foo")
+
+	// rule of another group is rejected.
+	err = s.manager.SetGroupBundles([]GroupBundle{{
+		ID:    "foo",
+		Rules: []*Rule{{GroupID: "bar", ID: "f1", Role: "voter", Count: 3}},
+	}}, false)
+	c.Assert(err, NotNil)
+
+	// replace all.
+	err = s.manager.SetGroupBundles([]GroupBundle{{
+		ID:    "bar",
+		Rules: []*Rule{{ID: "b1", Role: "voter", Count: 3}},
+	}}, true)
+	c.Assert(err, IsNil)
+	bundles = s.manager.GetAllGroupBundles()
+	c.Assert(bundles, HasLen, 1)
+	c.Assert(bundles[0].ID, Equals, "bar")
+	c.Assert(s.manager.GetRuleGroup("foo"), IsNil)
+
+	m2 := NewRuleManager(s.store)
+	c.Assert(m2.Initialize(3, nil), IsNil)
+	c.Assert(m2.GetAllGroupBundles(), DeepEquals, bundles)
+}
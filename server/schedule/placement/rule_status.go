@@ -0,0 +1,166 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"sort"
+
+	"github.com/pingcap/pd/v4/server/core"
+)
+
+// RuleViolation is the kind of a region not satisfying a rule.
+type RuleViolation string
+
+const (
+	// UnderReplicated means the rule has less peers than expected.
+	UnderReplicated RuleViolation = "under-replicated"
+	// MismatchedRole means some peers of the rule have different role from the rule.
+	MismatchedRole RuleViolation = "mismatched-role"
+	// OrphanPeer means the region has peers not belonging to any rule.
+	OrphanPeer RuleViolation = "orphan-peer"
+)
+
+// ValidateRuleViolation checks if the violation kind is known.
+func ValidateRuleViolation(v RuleViolation) bool {
+	return v == UnderReplicated || v == MismatchedRole || v == OrphanPeer
+}
+
+// storeReplicaTolerantRatio is used to decide whether a store has too many or
+// too few replicas of a rule compared with the average of candidate stores.
+const storeReplicaTolerantRatio = 0.1
+
+// Violations returns all violations of the rule in the region fit. The rule
+// must be one of the rules the region is fitted with.
+func (f *RegionFit) Violations(rf *RuleFit) []RuleViolation {
+	var violations []RuleViolation
+	if len(rf.Peers) < rf.Rule.Count {
+		violations = append(violations, UnderReplicated)
+	}
+	if len(rf.PeersWithDifferentRole) > 0 {
+		violations = append(violations, MismatchedRole)
+	}
+	if len(f.OrphanPeers) > 0 {
+		violations = append(violations, OrphanPeer)
+	}
+	return violations
+}
+
+// RuleStatus is the placement status of a rule aggregated over regions.
+type RuleStatus struct {
+	GroupID               string         `json:"group_id"`
+	ID                    string         `json:"id"`
+	RegionCount           int            `json:"region_count"`
+	SatisfiedCount        int            `json:"satisfied_count"`
+	UnderReplicatedCount  int            `json:"under_replicated_count"`
+	MismatchedRoleCount   int            `json:"mismatched_role_count"`
+	OrphanPeerRegionCount int            `json:"orphan_peer_region_count"`
+	StoreReplicaCounts    map[uint64]int `json:"store_replica_counts"`
+	OverloadedStores      []uint64       `json:"overloaded_stores"`
+	UnderloadedStores     []uint64       `json:"underloaded_stores"`
+
+	rule *Rule
+}
+
+// RuleStatusCollector aggregates region fits into status of rules.
+type RuleStatusCollector struct {
+	stores   core.StoreSetInformer
+	statuses map[[2]string]*RuleStatus
+}
+
+// NewRuleStatusCollector creates a collector for the rules.
+func NewRuleStatusCollector(stores core.StoreSetInformer, rules []*Rule) *RuleStatusCollector {
+	c := &RuleStatusCollector{
+		stores:   stores,
+		statuses: make(map[[2]string]*RuleStatus, len(rules)),
+	}
+	for _, r := range rules {
+		c.statuses[r.Key()] = &RuleStatus{
+			GroupID:            r.GroupID,
+			ID:                 r.ID,
+			StoreReplicaCounts: make(map[uint64]int),
+			rule:               r,
+		}
+	}
+	return c
+}
+
+// Collect adds the fit result of a region.
+func (c *RuleStatusCollector) Collect(fit *RegionFit) {
+	for _, rf := range fit.RuleFits {
+		s, ok := c.statuses[rf.Rule.Key()]
+		if !ok {
+			continue
+		}
+		s.RegionCount++
+		if rf.IsSatisfied() {
+			s.SatisfiedCount++
+		}
+		for _, v := range fit.Violations(rf) {
+			switch v {
+			case UnderReplicated:
+				s.UnderReplicatedCount++
+			case MismatchedRole:
+				s.MismatchedRoleCount++
+			case OrphanPeer:
+				s.OrphanPeerRegionCount++
+			}
+		}
+		for _, p := range rf.Peers {
+			s.StoreReplicaCounts[p.GetStoreId()]++
+		}
+	}
+}
+
+// GetStatuses returns the status of all rules, sorted by rule order.
+func (c *RuleStatusCollector) GetStatuses() []*RuleStatus {
+	statuses := make([]*RuleStatus, 0, len(c.statuses))
+	for _, s := range c.statuses {
+		c.checkStoreBalance(s)
+		statuses = append(statuses, s)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return compareRule(statuses[i].rule, statuses[j].rule) < 0 })
+	return statuses
+}
+
+// checkStoreBalance finds stores that have too many or too few replicas of
+// the rule compared with the average count of the stores the rule can use.
+func (c *RuleStatusCollector) checkStoreBalance(s *RuleStatus) {
+	var candidates []uint64
+	var total int
+	for _, store := range c.stores.GetStores() {
+		if store.IsTombstone() || !MatchLabelConstraints(store, s.rule.LabelConstraints) {
+			continue
+		}
+		candidates = append(candidates, store.GetID())
+		total += s.StoreReplicaCounts[store.GetID()]
+	}
+	if len(candidates) == 0 || total == 0 {
+		return
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i] < candidates[j] })
+	avg := float64(total) / float64(len(candidates))
+	tolerance := avg * storeReplicaTolerantRatio
+	if tolerance < 1 {
+		tolerance = 1
+	}
+	for _, id := range candidates {
+		count := float64(s.StoreReplicaCounts[id])
+		if count > avg+tolerance {
+			s.OverloadedStores = append(s.OverloadedStores, id)
+		} else if count < avg-tolerance {
+			s.UnderloadedStores = append(s.UnderloadedStores, id)
+		}
+	}
+}
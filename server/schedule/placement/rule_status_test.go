@@ -0,0 +1,72 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/server/core"
+)
+
+var _ = Suite(&testRuleStatusSuite{})
+
+type testRuleStatusSuite struct{}
+
+func (s *testRuleStatusSuite) newRegion(id uint64, storeIDs ...uint64) *core.RegionInfo {
+	var peers []*metapb.Peer
+	for _, storeID := range storeIDs {
+		peers = append(peers, &metapb.Peer{Id: id*10 + storeID, StoreId: storeID})
+	}
+	return core.NewRegionInfo(&metapb.Region{Id: id, Peers: peers}, peers[0])
+}
+
+func (s *testRuleStatusSuite) TestRuleStatus(c *C) {
+	stores := core.NewBasicCluster()
+	for id := uint64(1); id <= 4; id++ {
+		stores.PutStore(core.NewStoreInfoWithLabel(id, 0, nil))
+	}
+	rule := &Rule{GroupID: "pd", ID: "default", Role: Voter, Count: 3}
+	rules := []*Rule{rule}
+
+	regions := []*core.RegionInfo{
+		s.newRegion(1, 1, 2, 3),
+		s.newRegion(2, 1, 2, 3),
+		s.newRegion(3, 1, 2),
+		s.newRegion(4, 1, 2, 3, 4),
+	}
+	collector := NewRuleStatusCollector(stores, rules)
+	for _, region := range regions {
+		collector.Collect(FitRegion(stores, region, rules))
+	}
+	fit := FitRegion(stores, regions[0], rules)
+	c.Assert(fit.Violations(fit.RuleFits[0]), HasLen, 0)
+	fit = FitRegion(stores, regions[2], rules)
+	c.Assert(fit.Violations(fit.RuleFits[0]), DeepEquals, []RuleViolation{UnderReplicated})
+	fit = FitRegion(stores, regions[3], rules)
+	c.Assert(fit.Violations(fit.RuleFits[0]), DeepEquals, []RuleViolation{OrphanPeer})
+
+	statuses := collector.GetStatuses()
+	c.Assert(statuses, HasLen, 1)
+	status := statuses[0]
+	c.Assert(status.RegionCount, Equals, 4)
+	c.Assert(status.SatisfiedCount, Equals, 3)
+	c.Assert(status.UnderReplicatedCount, Equals, 1)
+	c.Assert(status.MismatchedRoleCount, Equals, 0)
+	c.Assert(status.OrphanPeerRegionCount, Equals, 1)
+	c.Assert(status.StoreReplicaCounts, DeepEquals, map[uint64]int{1: 4, 2: 4, 3: 3})
+	// the average is 11/4, the tolerance is 1.
+	c.Assert(status.OverloadedStores, DeepEquals, []uint64{1, 2})
+	c.Assert(status.UnderloadedStores, DeepEquals, []uint64{4})
+}
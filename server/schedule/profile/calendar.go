@@ -0,0 +1,143 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidProfile is returned when the profile or the calendar is invalid.
+var ErrInvalidProfile = errors.New("invalid schedule profile")
+
+const timeOfDayLayout = "15:04"
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Window is a weekly time window in which a profile is active.
+type Window struct {
+	Profile string `json:"profile"`
+	// Days are the days of the week such as "mon", the window is on every day
+	// if it is empty.
+	Days []string `json:"days,omitempty"`
+	// Start and End are the time of the day in the format of "15:04". If End
+	// is not after Start, the window crosses midnight and ends on the next
+	// day, and it lasts the whole day if they are equal.
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// Priority decides which window is used when the windows overlap. The
+	// higher one is used, and the one listed first in the calendar is used if
+	// they are the same.
+	Priority int `json:"priority"`
+
+	days       [7]bool
+	start, end int // minutes of the day
+}
+
+func (w *Window) adjust() error {
+	if w.Profile == "" {
+		return errors.Wrap(ErrInvalidProfile, "window profile is empty")
+	}
+	for i := range w.days {
+		w.days[i] = len(w.Days) == 0
+	}
+	for _, d := range w.Days {
+		wd, ok := weekdays[strings.ToLower(d)]
+		if !ok {
+			return errors.Wrapf(ErrInvalidProfile, "invalid day %s", d)
+		}
+		w.days[wd] = true
+	}
+	var err error
+	if w.start, err = parseTimeOfDay(w.Start); err != nil {
+		return err
+	}
+	w.end, err = parseTimeOfDay(w.End)
+	return err
+}
+
+func parseTimeOfDay(s string) (int, error) {
+	t, err := time.Parse(timeOfDayLayout, s)
+	if err != nil {
+		return 0, errors.Wrapf(ErrInvalidProfile, "invalid time %s, it should be in the format of %s", s, timeOfDayLayout)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// contains returns if the time in the time zone of the calendar is in the
+// window.
+func (w *Window) contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	today, yesterday := t.Weekday(), (t.Weekday()+6)%7
+	if w.start < w.end {
+		return w.days[today] && m >= w.start && m < w.end
+	}
+	// The window crosses midnight, the part after midnight belongs to the
+	// day before.
+	return (w.days[today] && m >= w.start) || (w.days[yesterday] && m < w.end)
+}
+
+// Calendar decides the active profile by the weekly time windows.
+type Calendar struct {
+	// TimeZone is the IANA name of the time zone of the windows such as
+	// "Asia/Shanghai", the local time zone of PD is used if it is empty.
+	TimeZone string    `json:"time-zone,omitempty"`
+	Windows  []*Window `json:"windows"`
+
+	location *time.Location
+}
+
+func (c *Calendar) adjust() error {
+	c.location = time.Local
+	if c.TimeZone != "" {
+		loc, err := time.LoadLocation(c.TimeZone)
+		if err != nil {
+			return errors.Wrapf(ErrInvalidProfile, "invalid time zone %s", c.TimeZone)
+		}
+		c.location = loc
+	}
+	for _, w := range c.Windows {
+		if w == nil {
+			return errors.Wrap(ErrInvalidProfile, "window is null")
+		}
+		if err := w.adjust(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// match returns the window used at the time, nil if the time is not in any
+// window.
+func (c *Calendar) match(now time.Time) *Window {
+	t := now.In(c.location)
+	var matched *Window
+	for _, w := range c.Windows {
+		if w.contains(t) && (matched == nil || w.Priority > matched.Priority) {
+			matched = w
+		}
+	}
+	return matched
+}
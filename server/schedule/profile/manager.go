@@ -0,0 +1,253 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/server/config"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Status is the status of the schedule profiles.
+type Status struct {
+	// Profile is the name of the active profile, it is empty if no profile is
+	// active.
+	Profile string  `json:"profile,omitempty"`
+	Window  *Window `json:"window,omitempty"`
+	// Config is the schedule config with the active profile applied, which is
+	// used to schedule.
+	Config           *config.ScheduleConfig `json:"config"`
+	PausedSchedulers []string               `json:"paused-schedulers,omitempty"`
+	// ApplyError is why the active profile fails to be applied, in which case
+	// the schedule config is used as it is.
+	ApplyError string `json:"apply-error,omitempty"`
+}
+
+// Manager is responsible for the lifecycle of the schedule profiles and the
+// calendar, and activates the profiles according to the calendar. It is
+// threadsafe.
+type Manager struct {
+	sync.RWMutex
+	storage  *core.Storage
+	opt      *config.ScheduleOption
+	profiles map[string]*config.ScheduleProfile
+	calendar *Calendar
+	// active is the window of the active profile.
+	active *Window
+}
+
+// NewManager creates a Manager and loads the profiles and the calendar from
+// storage.
+func NewManager(storage *core.Storage, opt *config.ScheduleOption) (*Manager, error) {
+	m := &Manager{
+		storage:  storage,
+		opt:      opt,
+		profiles: make(map[string]*config.ScheduleProfile),
+		calendar: &Calendar{},
+	}
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *Manager) load() error {
+	_, err := m.storage.LoadScheduleProfiles(func(k, v string) {
+		var p config.ScheduleProfile
+		if err := json.Unmarshal([]byte(v), &p); err != nil {
+			log.Error("failed to unmarshal schedule profile", zap.String("profile-key", k), zap.String("profile-value", v))
+			return
+		}
+		m.profiles[p.Name] = &p
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := m.storage.LoadScheduleProfileCalendar(m.calendar); err != nil {
+		return err
+	}
+	if err := m.calendar.adjust(); err != nil {
+		log.Error("schedule profile calendar is in bad format", zap.Error(err))
+		m.calendar = &Calendar{location: time.Local}
+	}
+	return nil
+}
+
+// GetProfiles returns all the profiles sorted by name.
+func (m *Manager) GetProfiles() []*config.ScheduleProfile {
+	m.RLock()
+	defer m.RUnlock()
+	profiles := make([]*config.ScheduleProfile, 0, len(m.profiles))
+	for _, p := range m.profiles {
+		profiles = append(profiles, p)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles
+}
+
+// GetProfile returns the profile with the name, nil if it does not exist.
+func (m *Manager) GetProfile(name string) *config.ScheduleProfile {
+	m.RLock()
+	defer m.RUnlock()
+	return m.profiles[name]
+}
+
+// SetProfile inserts or updates a profile. It takes effect at once if the
+// profile is active.
+func (m *Manager) SetProfile(p *config.ScheduleProfile) error {
+	if err := p.Validate(m.opt.Load()); err != nil {
+		return errors.Wrap(ErrInvalidProfile, err.Error())
+	}
+	m.Lock()
+	defer m.Unlock()
+	if err := m.storage.SaveScheduleProfile(profileKey(p.Name), p); err != nil {
+		return err
+	}
+	m.profiles[p.Name] = p
+	m.update(time.Now())
+	return nil
+}
+
+// DeleteProfile removes a profile, the profiles used by the calendar can not
+// be removed.
+func (m *Manager) DeleteProfile(name string) error {
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.profiles[name]; !ok {
+		return nil
+	}
+	for _, w := range m.calendar.Windows {
+		if w.Profile == name {
+			return errors.Wrapf(ErrInvalidProfile, "profile %s is used by the calendar", name)
+		}
+	}
+	if err := m.storage.DeleteScheduleProfile(profileKey(name)); err != nil {
+		return err
+	}
+	delete(m.profiles, name)
+	return nil
+}
+
+// GetCalendar returns the calendar.
+func (m *Manager) GetCalendar() *Calendar {
+	m.RLock()
+	defer m.RUnlock()
+	return m.calendar
+}
+
+// SetCalendar replaces the calendar, the profiles used by it must exist. It
+// takes effect at once.
+func (m *Manager) SetCalendar(c *Calendar) error {
+	if err := c.adjust(); err != nil {
+		return err
+	}
+	m.Lock()
+	defer m.Unlock()
+	for _, w := range c.Windows {
+		if _, ok := m.profiles[w.Profile]; !ok {
+			return errors.Wrapf(ErrInvalidProfile, "profile %s does not exist", w.Profile)
+		}
+	}
+	if err := m.storage.SaveScheduleProfileCalendar(c); err != nil {
+		return err
+	}
+	m.calendar = c
+	m.update(time.Now())
+	return nil
+}
+
+// GetStatus returns the active profile and the schedule config used to
+// schedule.
+func (m *Manager) GetStatus() *Status {
+	m.RLock()
+	defer m.RUnlock()
+	status := &Status{Config: m.opt.LoadEffective()}
+	if p := m.opt.GetActiveProfile(); p != nil {
+		status.Profile = p.Name
+		status.Window = m.active
+		status.PausedSchedulers = p.PausedSchedulers
+		status.ApplyError = m.opt.GetProfileApplyError()
+	}
+	return status
+}
+
+// Update activates the profile of the window matching the time, it is called
+// periodically.
+func (m *Manager) Update(now time.Time) {
+	m.Lock()
+	defer m.Unlock()
+	m.update(now)
+}
+
+func (m *Manager) update(now time.Time) {
+	w := m.calendar.match(now)
+	var p *config.ScheduleProfile
+	if w != nil {
+		p = m.profiles[w.Profile]
+	}
+	m.active = w
+	// The schedule config may be changed after the profile is applied, so the
+	// apply error is reported every time.
+	defer m.updateApplyError()
+	old := m.opt.GetActiveProfile()
+	if old == p {
+		return
+	}
+	m.opt.SetActiveProfile(p)
+	if old == nil || p == nil || old.Name != p.Name {
+		log.Info("schedule profile is changed", zap.String("old-profile", profileName(old)), zap.String("new-profile", profileName(p)))
+	}
+	activeProfileGauge.Reset()
+	if p != nil {
+		activeProfileGauge.WithLabelValues(p.Name).Set(1)
+	}
+}
+
+func (m *Manager) updateApplyError() {
+	profileApplyErrorGauge.Reset()
+	if p := m.opt.GetActiveProfile(); p != nil && m.opt.GetProfileApplyError() != "" {
+		profileApplyErrorGauge.WithLabelValues(p.Name).Set(1)
+	}
+}
+
+// Deactivate deactivates the active profile, it is called when the cluster
+// is stopped.
+func (m *Manager) Deactivate() {
+	m.Lock()
+	defer m.Unlock()
+	m.active = nil
+	m.opt.SetActiveProfile(nil)
+	activeProfileGauge.Reset()
+	profileApplyErrorGauge.Reset()
+}
+
+func profileKey(name string) string {
+	return hex.EncodeToString([]byte(name))
+}
+
+func profileName(p *config.ScheduleProfile) string {
+	if p == nil {
+		return ""
+	}
+	return p.Name
+}
@@ -0,0 +1,203 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/v4/server/config"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/kv"
+	"github.com/pkg/errors"
+
+	// Register schedulers.
+	_ "github.com/pingcap/pd/v4/server/schedulers"
+)
+
+func TestProfile(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testProfileSuite{})
+
+type testProfileSuite struct {
+	store   *core.Storage
+	opt     *config.ScheduleOption
+	manager *Manager
+}
+
+func (s *testProfileSuite) SetUpTest(c *C) {
+	cfg := config.NewConfig()
+	c.Assert(cfg.Adjust(nil), IsNil)
+	s.store = core.NewStorage(kv.NewMemoryKV())
+	s.opt = config.NewScheduleOption(cfg)
+	var err error
+	s.manager, err = NewManager(s.store, s.opt)
+	c.Assert(err, IsNil)
+}
+
+// date returns the time of the day in the week of 2020-06-01, which is a
+// Monday.
+func date(day time.Weekday, hour, min int) time.Time {
+	return time.Date(2020, 6, 1+(int(day)+6)%7, hour, min, 0, 0, time.Local)
+}
+
+func (s *testProfileSuite) TestWindow(c *C) {
+	calendar := &Calendar{Windows: []*Window{
+		{Profile: "day", Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "09:00", End: "18:00"},
+		{Profile: "night", Start: "22:00", End: "06:00"},
+		{Profile: "weekend", Days: []string{"Sat", "sun"}, Start: "00:00", End: "00:00"},
+		{Profile: "release", Days: []string{"wed"}, Start: "14:00", End: "15:00", Priority: 1},
+		{Profile: "night-tie", Start: "23:00", End: "01:00"},
+	}}
+	c.Assert(calendar.adjust(), IsNil)
+	testCases := []struct {
+		t       time.Time
+		profile string
+	}{
+		{date(time.Monday, 8, 59), ""},
+		{date(time.Monday, 9, 0), "day"},
+		{date(time.Monday, 17, 59), "day"},
+		{date(time.Monday, 18, 0), ""},
+		{date(time.Wednesday, 14, 30), "release"},
+		// The window crossing midnight belongs to the day it starts.
+		{date(time.Monday, 22, 0), "night"},
+		{date(time.Tuesday, 5, 59), "night"},
+		{date(time.Tuesday, 6, 0), ""},
+		// The window listed first wins a tie.
+		{date(time.Monday, 23, 30), "night"},
+		// The whole-day window.
+		{date(time.Saturday, 12, 0), "weekend"},
+		{date(time.Sunday, 23, 59), "night"},
+		{date(time.Saturday, 0, 0), "night"},
+	}
+	for _, t := range testCases {
+		w := calendar.match(t.t)
+		if t.profile == "" {
+			c.Assert(w, IsNil, Commentf("%v", t.t))
+		} else {
+			c.Assert(w, NotNil, Commentf("%v", t.t))
+			c.Assert(w.Profile, Equals, t.profile, Commentf("%v", t.t))
+		}
+	}
+
+	for _, w := range []*Window{
+		{Start: "09:00", End: "18:00"},
+		{Profile: "a", Days: []string{"monday"}, Start: "09:00", End: "18:00"},
+		{Profile: "a", Start: "9", End: "18:00"},
+		{Profile: "a", Start: "09:00", End: "24:00"},
+	} {
+		c.Assert(errors.Cause((&Calendar{Windows: []*Window{w}}).adjust()), Equals, ErrInvalidProfile)
+	}
+	c.Assert(errors.Cause((&Calendar{TimeZone: "Mars/Olympus"}).adjust()), Equals, ErrInvalidProfile)
+}
+
+func (s *testProfileSuite) TestTimeZone(c *C) {
+	calendar := &Calendar{TimeZone: "Asia/Shanghai", Windows: []*Window{{Profile: "day", Start: "09:00", End: "18:00"}}}
+	c.Assert(calendar.adjust(), IsNil)
+	c.Assert(calendar.match(time.Date(2020, 6, 1, 1, 0, 0, 0, time.UTC)), NotNil)
+	c.Assert(calendar.match(time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)), IsNil)
+}
+
+func (s *testProfileSuite) TestManager(c *C) {
+	day := &config.ScheduleProfile{
+		Name:             "day",
+		Config:           map[string]interface{}{"region-schedule-limit": 1},
+		PausedSchedulers: []string{"balance-region-scheduler"},
+	}
+	c.Assert(s.manager.SetProfile(day), IsNil)
+	c.Assert(s.manager.SetProfile(&config.ScheduleProfile{Name: "night"}), IsNil)
+	err := s.manager.SetProfile(&config.ScheduleProfile{Name: "bad", Config: map[string]interface{}{"unknown": 1}})
+	c.Assert(errors.Cause(err), Equals, ErrInvalidProfile)
+	c.Assert(s.manager.GetProfiles(), HasLen, 2)
+	c.Assert(s.manager.GetProfile("day"), DeepEquals, day)
+
+	// The calendar can only use the existing profiles.
+	calendar := &Calendar{Windows: []*Window{{Profile: "unknown", Start: "09:00", End: "18:00"}}}
+	c.Assert(errors.Cause(s.manager.SetCalendar(calendar)), Equals, ErrInvalidProfile)
+	calendar = &Calendar{Windows: []*Window{
+		{Profile: "day", Start: "09:00", End: "18:00"},
+		{Profile: "night", Start: "18:00", End: "09:00"},
+	}}
+	c.Assert(s.manager.SetCalendar(calendar), IsNil)
+	c.Assert(errors.Cause(s.manager.DeleteProfile("day")), Equals, ErrInvalidProfile)
+
+	regionLimit := s.opt.Load().RegionScheduleLimit
+	s.manager.Update(date(time.Monday, 10, 0))
+	status := s.manager.GetStatus()
+	c.Assert(status.Profile, Equals, "day")
+	c.Assert(status.Window.Start, Equals, "09:00")
+	c.Assert(status.Config.RegionScheduleLimit, Equals, uint64(1))
+	c.Assert(status.PausedSchedulers, DeepEquals, []string{"balance-region-scheduler"})
+	c.Assert(s.opt.GetRegionScheduleLimit(), Equals, uint64(1))
+	c.Assert(s.opt.IsSchedulerPausedByProfile("balance-region-scheduler"), IsTrue)
+
+	// The change of the active profile takes effect at once.
+	day = &config.ScheduleProfile{Name: "day", Config: map[string]interface{}{"region-schedule-limit": 2}}
+	c.Assert(s.manager.SetProfile(day), IsNil)
+	s.manager.Update(date(time.Monday, 10, 0))
+	c.Assert(s.opt.GetRegionScheduleLimit(), Equals, uint64(2))
+	c.Assert(s.opt.IsSchedulerPausedByProfile("balance-region-scheduler"), IsFalse)
+
+	s.manager.Update(date(time.Monday, 20, 0))
+	c.Assert(s.manager.GetStatus().Profile, Equals, "night")
+	c.Assert(s.opt.GetRegionScheduleLimit(), Equals, regionLimit)
+
+	// The profiles and the calendar are persisted.
+	m, err := NewManager(s.store, s.opt)
+	c.Assert(err, IsNil)
+	c.Assert(m.GetProfiles(), HasLen, 2)
+	c.Assert(m.GetProfile("day").Config["region-schedule-limit"], Equals, float64(2))
+	c.Assert(m.GetCalendar().Windows, HasLen, 2)
+	m.Update(date(time.Monday, 10, 0))
+	c.Assert(s.opt.GetRegionScheduleLimit(), Equals, uint64(2))
+
+	m.Deactivate()
+	c.Assert(s.opt.GetActiveProfile(), IsNil)
+	c.Assert(s.opt.GetRegionScheduleLimit(), Equals, regionLimit)
+	c.Assert(m.SetCalendar(&Calendar{}), IsNil)
+	c.Assert(m.DeleteProfile("day"), IsNil)
+	c.Assert(m.GetProfiles(), HasLen, 1)
+}
+
+func (s *testProfileSuite) TestApplyError(c *C) {
+	low := &config.ScheduleProfile{Name: "low", Config: map[string]interface{}{"low-space-ratio": 0.75}}
+	c.Assert(s.manager.SetProfile(low), IsNil)
+	c.Assert(s.manager.SetCalendar(&Calendar{Windows: []*Window{{Profile: "low", Start: "00:00", End: "00:00"}}}), IsNil)
+	s.manager.Update(date(time.Monday, 10, 0))
+	status := s.manager.GetStatus()
+	c.Assert(status.Profile, Equals, "low")
+	c.Assert(status.Config.LowSpaceRatio, Equals, 0.75)
+	c.Assert(status.ApplyError, Equals, "")
+
+	// The schedule config is changed and the profile can not be applied.
+	cfg := s.opt.Load().Clone()
+	cfg.HighSpaceRatio = 0.78
+	s.opt.Store(cfg)
+	s.manager.Update(date(time.Monday, 10, 0))
+	status = s.manager.GetStatus()
+	c.Assert(status.Profile, Equals, "low")
+	c.Assert(status.Config.LowSpaceRatio, Equals, cfg.LowSpaceRatio)
+	c.Assert(status.ApplyError, Matches, ".*low-space-ratio should be larger than high-space-ratio.*")
+
+	cfg = cfg.Clone()
+	cfg.HighSpaceRatio = 0.7
+	s.opt.Store(cfg)
+	c.Assert(s.manager.GetStatus().ApplyError, Equals, "")
+	s.manager.Deactivate()
+	c.Assert(s.opt.GetProfileApplyError(), Equals, "")
+}
@@ -0,0 +1,38 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var activeProfileGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "pd",
+		Subsystem: "schedule",
+		Name:      "active_profile",
+		Help:      "The active schedule profile, it is 1 for the active one.",
+	}, []string{"profile"})
+
+var profileApplyErrorGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "pd",
+		Subsystem: "schedule",
+		Name:      "profile_apply_error",
+		Help:      "It is 1 if the active schedule profile fails to be applied to the schedule config.",
+	}, []string{"profile"})
+
+func init() {
+	prometheus.MustRegister(activeProfileGauge)
+	prometheus.MustRegister(profileApplyErrorGauge)
+}
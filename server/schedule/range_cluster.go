@@ -17,6 +17,7 @@ import (
 	"github.com/gogo/protobuf/proto"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
 )
 
@@ -27,6 +28,11 @@ type RangeCluster struct {
 	tolerantSizeRatio float64
 }
 
+// GetRegionLabeler returns the region labeler of the underlying cluster.
+func (r *RangeCluster) GetRegionLabeler() *labeler.RegionLabeler {
+	return opt.GetRegionLabeler(r.Cluster)
+}
+
 // GenRangeCluster gets a range cluster by specifying start key and end key.
 // The cluster can only know the regions within [startKey, endKey].
 func GenRangeCluster(cluster opt.Cluster, startKey, endKey []byte) *RangeCluster {
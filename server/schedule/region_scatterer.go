@@ -14,8 +14,8 @@
 package schedule
 
 import (
-	"math/rand"
 	"sync"
+	"time"
 
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/log"
@@ -27,68 +27,124 @@ import (
 	"go.uber.org/zap"
 )
 
-const regionScatterName = "region-scatter"
+const (
+	regionScatterName = "region-scatter"
+	// scatterGroupTTL is how long the counts of an idle scatter group are kept.
+	scatterGroupTTL = time.Hour
+)
+
+// ErrAddScatterOperator is returned when the operator to scatter a region
+// fails to be added.
+var ErrAddScatterOperator = errors.New("failed to add the scatter region operator")
+
+// scatterGroup records how many peers and leaders of the regions in a group
+// are scattered to each store.
+type scatterGroup struct {
+	Peers      map[uint64]uint64 `json:"peers"`
+	Leaders    map[uint64]uint64 `json:"leaders"`
+	LastUpdate time.Time         `json:"last-update"`
+}
 
+// selectedStores tracks the scatter groups. The regions scattered without a
+// group belong to the default group whose name is empty. The groups are saved
+// to storage, so that they survive PD restarts and leader changes.
 type selectedStores struct {
-	mu     sync.Mutex
-	stores map[uint64]struct{}
+	mu      sync.Mutex
+	storage *core.Storage
+	groups  map[string]*scatterGroup
 }
 
-func newSelectedStores() *selectedStores {
-	return &selectedStores{
-		stores: make(map[uint64]struct{}),
+func newSelectedStores(storage *core.Storage) *selectedStores {
+	s := &selectedStores{
+		storage: storage,
+		groups:  make(map[string]*scatterGroup),
+	}
+	if _, err := storage.LoadScatterGroups(&s.groups); err != nil {
+		log.Error("failed to load scatter groups", zap.Error(err))
 	}
+	return s
 }
 
-func (s *selectedStores) put(id uint64) bool {
+func (s *selectedStores) peerCount(group string, storeID uint64) uint64 {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if _, ok := s.stores[id]; ok {
-		return false
+	if g, ok := s.groups[group]; ok {
+		return g.Peers[storeID]
 	}
-	s.stores[id] = struct{}{}
-	return true
+	return 0
 }
 
-func (s *selectedStores) reset() {
+func (s *selectedStores) leaderCount(group string, storeID uint64) uint64 {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.stores = make(map[uint64]struct{})
+	if g, ok := s.groups[group]; ok {
+		return g.Leaders[storeID]
+	}
+	return 0
 }
 
-func (s *selectedStores) newFilter(scope string) filter.Filter {
+// put records the peers and the leader of a scattered region, drops the
+// groups which have been idle for scatterGroupTTL, and saves the groups.
+func (s *selectedStores) put(group string, peers map[uint64]*metapb.Peer, leader uint64) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	cloned := make(map[uint64]struct{})
-	for id := range s.stores {
-		cloned[id] = struct{}{}
+	now := time.Now()
+	for name, g := range s.groups {
+		if now.Sub(g.LastUpdate) > scatterGroupTTL {
+			delete(s.groups, name)
+		}
+	}
+	g, ok := s.groups[group]
+	if !ok {
+		g = &scatterGroup{
+			Peers:   make(map[uint64]uint64),
+			Leaders: make(map[uint64]uint64),
+		}
+		s.groups[group] = g
+	}
+	for id := range peers {
+		g.Peers[id]++
+	}
+	if leader != 0 {
+		g.Leaders[leader]++
+	}
+	g.LastUpdate = now
+	if err := s.storage.SaveScatterGroups(s.groups); err != nil {
+		log.Error("failed to save scatter groups", zap.String("group", group), zap.Error(err))
 	}
-	return filter.NewExcludedFilter(scope, nil, cloned)
 }
 
 // RegionScatterer scatters regions.
 type RegionScatterer struct {
-	name     string
-	cluster  opt.Cluster
-	filters  []filter.Filter
-	selected *selectedStores
+	name         string
+	cluster      opt.Cluster
+	opController *OperatorController
+	filters      []filter.Filter
+	selected     *selectedStores
 }
 
-// NewRegionScatterer creates a region scatterer.
+// NewRegionScatterer creates a region scatterer and loads the scatter groups
+// from storage.
 // RegionScatter is used for the `Lightning`, it will scatter the specified regions before import data.
-func NewRegionScatterer(cluster opt.Cluster) *RegionScatterer {
+func NewRegionScatterer(cluster opt.Cluster, opController *OperatorController, storage *core.Storage) *RegionScatterer {
 	return &RegionScatterer{
-		name:    regionScatterName,
-		cluster: cluster,
+		name:         regionScatterName,
+		cluster:      cluster,
+		opController: opController,
 		filters: []filter.Filter{
 			filter.StoreStateFilter{ActionScope: regionScatterName},
+			filter.NewDiskStateFilter(regionScatterName, true),
 		},
-		selected: newSelectedStores(),
+		selected: newSelectedStores(storage),
 	}
 }
 
-// Scatter relocates the region.
-func (r *RegionScatterer) Scatter(region *core.RegionInfo) (*operator.Operator, error) {
+// Scatter relocates the region and adds the operator to the operator
+// controller. The peers and the leaders of the regions in the same group are
+// balanced among the stores, ties are broken by the region and leader counts
+// of the stores. It returns a nil operator if the region does not need to be
+// scattered.
+func (r *RegionScatterer) Scatter(region *core.RegionInfo, group string) (*operator.Operator, error) {
 	if !opt.IsRegionReplicated(r.cluster, region) {
 		return nil, errors.Errorf("region %d is not fully replicated", region.GetID())
 	}
@@ -97,50 +153,69 @@ func (r *RegionScatterer) Scatter(region *core.RegionInfo) (*operator.Operator,
 		return nil, errors.Errorf("region %d has no leader", region.GetID())
 	}
 
-	return r.scatterRegion(region), nil
+	if !opt.IsRegionScheduleAllowed(r.cluster, region) {
+		return nil, errors.Errorf("region %d is labeled to deny scheduling", region.GetID())
+	}
+
+	targetPeers, leader := r.selectTargets(region, group)
+	if r.inPlace(region, targetPeers, leader) {
+		r.selected.put(group, targetPeers, leader)
+		return nil, nil
+	}
+	op, err := operator.CreateScatterRegionOperator("scatter-region", r.cluster, region, targetPeers, leader)
+	if err != nil {
+		log.Debug("fail to create scatter region operator", zap.Error(err))
+		return nil, nil
+	}
+	op.SetPriorityLevel(core.HighPriority)
+	if !r.opController.AddOperator(op) {
+		return nil, errors.Wrapf(ErrAddScatterOperator, "region %d", region.GetID())
+	}
+	// The group only counts the regions which are already in place or whose
+	// operators are added, so a failed scatter does not skew the group.
+	r.selected.put(group, targetPeers, leader)
+	return op, nil
 }
 
-func (r *RegionScatterer) scatterRegion(region *core.RegionInfo) *operator.Operator {
+// selectTargets returns the stores of the peers and the leader of the region
+// after it is scattered.
+func (r *RegionScatterer) selectTargets(region *core.RegionInfo, group string) (map[uint64]*metapb.Peer, uint64) {
 	stores := r.collectAvailableStores(region)
 	targetPeers := make(map[uint64]*metapb.Peer)
 	for _, peer := range region.GetPeers() {
-		if len(stores) == 0 {
-			// Reset selected stores if we have no available stores.
-			r.selected.reset()
-			stores = r.collectAvailableStores(region)
-		}
-
-		if r.selected.put(peer.GetStoreId()) {
-			delete(stores, peer.GetStoreId())
-			targetPeers[peer.GetStoreId()] = peer
-			continue
-		}
-		newPeer := r.selectPeerToReplace(stores, region, peer)
+		newPeer := r.selectPeerToReplace(group, stores, region, peer)
 		if newPeer == nil {
 			targetPeers[peer.GetStoreId()] = peer
 			continue
 		}
-		// Remove it from stores and mark it as selected.
+		// Remove it from stores so that it is not selected twice.
 		delete(stores, newPeer.GetStoreId())
-		r.selected.put(newPeer.GetStoreId())
 		targetPeers[newPeer.GetStoreId()] = newPeer
 	}
-	op, err := operator.CreateScatterRegionOperator("scatter-region", r.cluster, region, targetPeers)
-	if err != nil {
-		log.Debug("fail to create scatter region operator", zap.Error(err))
-		return nil
+	return targetPeers, r.selectLeader(group, region, targetPeers)
+}
+
+// inPlace checks if the region already has the target peers and leader.
+func (r *RegionScatterer) inPlace(region *core.RegionInfo, targetPeers map[uint64]*metapb.Peer, leader uint64) bool {
+	for _, peer := range targetPeers {
+		// The new peers have not been allocated IDs yet.
+		if peer.GetId() == 0 {
+			return false
+		}
 	}
-	op.SetPriorityLevel(core.HighPriority)
-	return op
+	return leader == region.GetLeader().GetStoreId()
 }
 
-func (r *RegionScatterer) selectPeerToReplace(stores map[uint64]*core.StoreInfo, region *core.RegionInfo, oldPeer *metapb.Peer) *metapb.Peer {
+// selectPeerToReplace returns the peer on the store with the fewest peers of
+// the group, or nil if the store of the old peer already has the fewest.
+func (r *RegionScatterer) selectPeerToReplace(group string, stores map[uint64]*core.StoreInfo, region *core.RegionInfo, oldPeer *metapb.Peer) *metapb.Peer {
 	// scoreGuard guarantees that the distinct score will not decrease.
 	regionStores := r.cluster.GetRegionStores(region)
 	storeID := oldPeer.GetStoreId()
 	sourceStore := r.cluster.GetStore(storeID)
 	if sourceStore == nil {
 		log.Error("failed to get the store", zap.Uint64("store-id", storeID))
+		return nil
 	}
 	var scoreGuard filter.Filter
 	if r.cluster.IsPlacementRulesEnabled() {
@@ -149,28 +224,59 @@ func (r *RegionScatterer) selectPeerToReplace(stores map[uint64]*core.StoreInfo,
 		scoreGuard = filter.NewDistinctScoreFilter(r.name, r.cluster.GetLocationLabels(), regionStores, sourceStore)
 	}
 
-	candidates := make([]*core.StoreInfo, 0, len(stores))
+	minCount := r.selected.peerCount(group, storeID)
+	var target *core.StoreInfo
 	for _, store := range stores {
 		if !scoreGuard.Target(r.cluster, store) {
 			continue
 		}
-		candidates = append(candidates, store)
+		count := r.selected.peerCount(group, store.GetID())
+		if count > minCount || (count == minCount && target == nil) {
+			continue
+		}
+		if count < minCount || store.GetRegionCount() < target.GetRegionCount() ||
+			(store.GetRegionCount() == target.GetRegionCount() && store.GetID() < target.GetID()) {
+			minCount, target = count, store
+		}
 	}
-
-	if len(candidates) == 0 {
+	if target == nil {
 		return nil
 	}
-
-	target := candidates[rand.Intn(len(candidates))]
 	return &metapb.Peer{
 		StoreId:   target.GetID(),
 		IsLearner: oldPeer.GetIsLearner(),
 	}
 }
 
+// selectLeader returns the voter on the store with the fewest leaders of the
+// group, ties are broken by the leader counts of the stores. The stores which
+// the leader of the region is allowed to be on by the region labels are
+// preferred.
+func (r *RegionScatterer) selectLeader(group string, region *core.RegionInfo, peers map[uint64]*metapb.Peer) uint64 {
+	var (
+		leader        uint64
+		minCount      uint64
+		target        *core.StoreInfo
+		targetAllowed bool
+	)
+	for id, peer := range peers {
+		store := r.cluster.GetStore(id)
+		if peer.GetIsLearner() || store == nil || r.cluster.CheckLabelProperty(opt.RejectLeader, store.GetLabels()) {
+			continue
+		}
+		allowed := opt.IsLeaderStoreAllowed(r.cluster, region, store)
+		count := r.selected.leaderCount(group, id)
+		if target == nil || (allowed && !targetAllowed) || (allowed == targetAllowed && (count < minCount ||
+			(count == minCount && (store.GetLeaderCount() < target.GetLeaderCount() ||
+				(store.GetLeaderCount() == target.GetLeaderCount() && id < leader))))) {
+			leader, minCount, target, targetAllowed = id, count, store, allowed
+		}
+	}
+	return leader
+}
+
 func (r *RegionScatterer) collectAvailableStores(region *core.RegionInfo) map[uint64]*core.StoreInfo {
 	filters := []filter.Filter{
-		r.selected.newFilter(r.name),
 		filter.NewExcludedFilter(r.name, nil, region.GetStoreIds()),
 	}
 	filters = append(filters, r.filters...)
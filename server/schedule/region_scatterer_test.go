@@ -0,0 +1,58 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/kv"
+)
+
+var _ = Suite(&testSelectedStoresSuite{})
+
+type testSelectedStoresSuite struct{}
+
+func (s *testSelectedStoresSuite) TestPersist(c *C) {
+	storage := core.NewStorage(kv.NewMemoryKV())
+	selected := newSelectedStores(storage)
+	peers := map[uint64]*metapb.Peer{
+		1: {Id: 1, StoreId: 1},
+		2: {Id: 2, StoreId: 2},
+	}
+	selected.put("", peers, 1)
+	selected.put("a", peers, 2)
+	selected.put("b", peers, 2)
+
+	// The groups are loaded after PD restarts or the leader changes.
+	selected = newSelectedStores(storage)
+	c.Assert(selected.peerCount("", 1), Equals, uint64(1))
+	c.Assert(selected.leaderCount("", 1), Equals, uint64(1))
+	c.Assert(selected.peerCount("a", 2), Equals, uint64(1))
+	c.Assert(selected.leaderCount("a", 1), Equals, uint64(0))
+	c.Assert(selected.leaderCount("a", 2), Equals, uint64(1))
+	c.Assert(selected.peerCount("c", 1), Equals, uint64(0))
+
+	// The idle groups are dropped from storage too.
+	selected.groups["b"].LastUpdate = time.Now().Add(-scatterGroupTTL - time.Minute)
+	selected.put("a", peers, 1)
+	selected = newSelectedStores(storage)
+	c.Assert(selected.peerCount("a", 1), Equals, uint64(2))
+	c.Assert(selected.leaderCount("a", 1), Equals, uint64(1))
+	c.Assert(selected.peerCount("b", 1), Equals, uint64(0))
+	c.Assert(selected.groups, HasLen, 2)
+}
@@ -0,0 +1,242 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/pd/v4/pkg/slice"
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidSchedulerConfig is error info for the scheduler config update
+// which is rejected by the schema.
+var ErrInvalidSchedulerConfig = errors.New("invalid scheduler config")
+
+// ConfigurableScheduler is a scheduler whose config is described by a schema,
+// the config can be read and updated through the uniform scheduler config API.
+type ConfigurableScheduler interface {
+	Scheduler
+	// GetConfigSchema returns the schema of the config encoded by EncodeConfig.
+	GetConfigSchema() *ConfigSchema
+	// UpdateConfig validates the partial update in JSON with the schema and
+	// applies it. The config is not changed if the update is rejected.
+	UpdateConfig(data []byte) error
+}
+
+// ConfigSchema is a subset of JSON schema which describes a scheduler config
+// or one of its items.
+type ConfigSchema struct {
+	Type       string                   `json:"type"`
+	Properties map[string]*ConfigSchema `json:"properties,omitempty"`
+	Items      *ConfigSchema            `json:"items,omitempty"`
+	Enum       []string                 `json:"enum,omitempty"`
+	Minimum    *float64                 `json:"minimum,omitempty"`
+	Maximum    *float64                 `json:"maximum,omitempty"`
+	ReadOnly   bool                     `json:"readOnly,omitempty"`
+}
+
+// NewConfigSchema generates the schema from the config struct. The items are
+// the fields with json tags, and the constraints are declared by the schema
+// tags, e.g. `schema:"min=0,max=1"`, `schema:"enum=leader|follower"` or
+// `schema:"readonly"`. For an array, the enum applies to its elements.
+func NewConfigSchema(conf interface{}) *ConfigSchema {
+	return typeSchema(reflect.TypeOf(conf))
+}
+
+func typeSchema(t reflect.Type) *ConfigSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &ConfigSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &ConfigSchema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		return &ConfigSchema{Type: "integer", Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &ConfigSchema{Type: "number"}
+	case reflect.String:
+		return &ConfigSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		// []byte is encoded as a base64 string.
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &ConfigSchema{Type: "string"}
+		}
+		return &ConfigSchema{Type: "array", Items: typeSchema(t.Elem())}
+	case reflect.Struct:
+		schema := &ConfigSchema{Type: "object", Properties: make(map[string]*ConfigSchema)}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := jsonName(field)
+			if name == "" {
+				continue
+			}
+			item := typeSchema(field.Type)
+			item.parseTag(field.Tag.Get("schema"))
+			schema.Properties[name] = item
+		}
+		return schema
+	default:
+		return &ConfigSchema{Type: "object"}
+	}
+}
+
+func jsonName(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		return ""
+	}
+	tag := field.Tag.Get("json")
+	if i := strings.Index(tag, ","); i != -1 {
+		tag = tag[:i]
+	}
+	if tag == "-" {
+		return ""
+	}
+	return tag
+}
+
+func (s *ConfigSchema) parseTag(tag string) {
+	for _, opt := range strings.Split(tag, ",") {
+		kv := strings.SplitN(opt, "=", 2)
+		switch kv[0] {
+		case "readonly":
+			s.ReadOnly = true
+		case "min", "max":
+			if len(kv) != 2 {
+				continue
+			}
+			v, err := strconv.ParseFloat(kv[1], 64)
+			if err != nil {
+				continue
+			}
+			if kv[0] == "min" {
+				s.Minimum = &v
+			} else {
+				s.Maximum = &v
+			}
+		case "enum":
+			if len(kv) != 2 {
+				continue
+			}
+			enum := strings.Split(kv[1], "|")
+			if s.Items != nil {
+				s.Items.Enum = enum
+			} else {
+				s.Enum = enum
+			}
+		}
+	}
+}
+
+// Validate checks whether the value decoded from JSON satisfies the schema.
+func (s *ConfigSchema) Validate(v interface{}) error {
+	switch s.Type {
+	case "integer", "number":
+		n, ok := v.(float64)
+		if !ok {
+			return errors.Errorf("%v is not a %s", v, s.Type)
+		}
+		if s.Type == "integer" && n != float64(int64(n)) {
+			return errors.Errorf("%v is not an integer", v)
+		}
+		if s.Minimum != nil && n < *s.Minimum {
+			return errors.Errorf("%v is less than %v", v, *s.Minimum)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			return errors.Errorf("%v is greater than %v", v, *s.Maximum)
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return errors.Errorf("%v is not a string", v)
+		}
+		if len(s.Enum) > 0 && slice.NoneOf(s.Enum, func(i int) bool { return s.Enum[i] == str }) {
+			return errors.Errorf("%s is not one of %s", str, strings.Join(s.Enum, ", "))
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return errors.Errorf("%v is not a boolean", v)
+		}
+	case "array":
+		items, ok := v.([]interface{})
+		if !ok {
+			return errors.Errorf("%v is not an array", v)
+		}
+		for _, item := range items {
+			if err := s.Items.Validate(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyConfigUpdate applies the partial update in JSON to the config struct
+// after validating it with the schema generated by NewConfigSchema. Either all
+// the items are updated or none of them. The caller should hold the lock of
+// the config if it is shared.
+func ApplyConfigUpdate(conf interface{}, data []byte) error {
+	var update map[string]json.RawMessage
+	if err := json.Unmarshal(data, &update); err != nil {
+		return errors.Wrap(ErrInvalidSchedulerConfig, err.Error())
+	}
+	keys := make([]string, 0, len(update))
+	for key := range update {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	schema := NewConfigSchema(conf)
+	v := reflect.ValueOf(conf).Elem()
+	fields := make(map[string]reflect.Value)
+	for i := 0; i < v.NumField(); i++ {
+		if name := jsonName(v.Type().Field(i)); name != "" {
+			fields[name] = v.Field(i)
+		}
+	}
+	values := make([]reflect.Value, 0, len(keys))
+	for _, key := range keys {
+		item, ok := schema.Properties[key]
+		if !ok {
+			return errors.Wrapf(ErrInvalidSchedulerConfig, "unknown item %s", key)
+		}
+		if item.ReadOnly {
+			return errors.Wrapf(ErrInvalidSchedulerConfig, "item %s is read-only", key)
+		}
+		var raw interface{}
+		if err := json.Unmarshal(update[key], &raw); err != nil {
+			return errors.Wrapf(ErrInvalidSchedulerConfig, "item %s: %v", key, err)
+		}
+		if err := item.Validate(raw); err != nil {
+			return errors.Wrapf(ErrInvalidSchedulerConfig, "item %s: %v", key, err)
+		}
+		value := reflect.New(fields[key].Type())
+		if err := json.Unmarshal(update[key], value.Interface()); err != nil {
+			return errors.Wrapf(ErrInvalidSchedulerConfig, "item %s: %v", key, err)
+		}
+		values = append(values, value.Elem())
+	}
+	for i, key := range keys {
+		fields[key].Set(values[i])
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"sync"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pkg/errors"
+)
+
+var _ = Suite(&testSchedulerConfigSuite{})
+
+type testSchedulerConfigSuite struct{}
+
+type testSchedulerConfig struct {
+	sync.RWMutex
+
+	Name    string          `json:"name" schema:"readonly"`
+	Ranges  []core.KeyRange `json:"ranges" schema:"readonly"`
+	Limit   uint64          `json:"limit"`
+	Ratio   float64         `json:"ratio" schema:"min=0,max=1"`
+	Enabled bool            `json:"enabled"`
+	Roles   []string        `json:"roles" schema:"enum=leader|follower"`
+	Ignored int             `json:"-"`
+}
+
+func (s *testSchedulerConfigSuite) TestSchema(c *C) {
+	schema := NewConfigSchema(&testSchedulerConfig{})
+	c.Assert(schema.Type, Equals, "object")
+	c.Assert(schema.Properties, HasLen, 6)
+	c.Assert(schema.Properties["name"].Type, Equals, "string")
+	c.Assert(schema.Properties["name"].ReadOnly, IsTrue)
+	ranges := schema.Properties["ranges"]
+	c.Assert(ranges.Type, Equals, "array")
+	c.Assert(ranges.Items.Properties["start-key"].Type, Equals, "string")
+	c.Assert(schema.Properties["limit"].Type, Equals, "integer")
+	c.Assert(*schema.Properties["limit"].Minimum, Equals, 0.0)
+	c.Assert(schema.Properties["ratio"].Type, Equals, "number")
+	c.Assert(*schema.Properties["ratio"].Maximum, Equals, 1.0)
+	c.Assert(schema.Properties["enabled"].Type, Equals, "boolean")
+	c.Assert(schema.Properties["roles"].Items.Enum, DeepEquals, []string{"leader", "follower"})
+}
+
+func (s *testSchedulerConfigSuite) TestApplyUpdate(c *C) {
+	conf := &testSchedulerConfig{Name: "test", Limit: 1, Ratio: 0.5}
+	c.Assert(ApplyConfigUpdate(conf, []byte(`{"limit":4,"enabled":true,"roles":["leader"]}`)), IsNil)
+	c.Assert(conf.Limit, Equals, uint64(4))
+	c.Assert(conf.Enabled, IsTrue)
+	c.Assert(conf.Roles, DeepEquals, []string{"leader"})
+
+	for _, update := range []string{
+		`{"name":"foo"}`,
+		`{"unknown":1}`,
+		`{"limit":-1}`,
+		`{"limit":1.5}`,
+		`{"ratio":2}`,
+		`{"enabled":"true"}`,
+		`{"roles":["learner"]}`,
+		`[]`,
+		// The update is applied entirely or not at all.
+		`{"limit":8,"ratio":2}`,
+	} {
+		err := ApplyConfigUpdate(conf, []byte(update))
+		c.Assert(errors.Cause(err), Equals, ErrInvalidSchedulerConfig, Commentf(update))
+	}
+	c.Assert(conf.Name, Equals, "test")
+	c.Assert(conf.Limit, Equals, uint64(4))
+	c.Assert(conf.Ratio, Equals, 0.5)
+}
@@ -0,0 +1,52 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storelimit
+
+// Type indicates the type of store limit
+type Type int
+
+const (
+	// AddPeer indicates the type of store limit that limits the adding peer rate
+	AddPeer Type = iota
+	// RemovePeer indicates the type of store limit that limits the removing peer rate
+	RemovePeer
+)
+
+// TypeNameValue indicates the name of store limit type and the enum value
+var TypeNameValue = map[string]Type{
+	"add-peer":    AddPeer,
+	"remove-peer": RemovePeer,
+}
+
+// Types returns all the store limit types.
+func Types() []Type {
+	return []Type{AddPeer, RemovePeer}
+}
+
+// String returns the representation of the Type
+func (t Type) String() string {
+	for n, v := range TypeNameValue {
+		if v == t {
+			return n
+		}
+	}
+	return ""
+}
+
+// ParseType parses the name of a store limit type.
+func ParseType(name string) (Type, bool) {
+	t, ok := TypeNameValue[name]
+	return t, ok
+}
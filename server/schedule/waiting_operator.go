@@ -14,98 +14,192 @@
 package schedule
 
 import (
-	"math/rand"
+	"sort"
 	"time"
 
+	"github.com/pingcap/pd/v4/pkg/typeutil"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 )
 
 // PriorityWeight is used to represent the weight of different priorities of operators.
 var PriorityWeight = []float64{1.0, 4.0, 9.0}
 
-// WaitingOperator is an interface of waiting operators.
-type WaitingOperator interface {
-	PutOperator(op *operator.Operator)
-	GetOperator() []*operator.Operator
-	ListOperator() []*operator.Operator
+// QueueName returns the name of the waiting queue of the operator. It is the
+// scheduler which creates the operator, or the description of the operator if
+// it is created by a checker.
+func QueueName(op *operator.Operator) string {
+	if source := op.Source(); source != "" {
+		return source
+	}
+	return op.Desc()
+}
+
+// waitingQueue holds the waiting operators of a source by priority.
+type waitingQueue struct {
+	name string
+	// pass is the virtual time of the queue. The queue with the smallest pass
+	// is served first, and its pass advances inversely to its weight.
+	pass float64
+	ops  [][]*operator.Operator
+}
+
+func (q *waitingQueue) len() int {
+	var n int
+	for _, ops := range q.ops {
+		n += len(ops)
+	}
+	return n
+}
+
+// head returns the index of the highest priority which has operators.
+func (q *waitingQueue) head() int {
+	for i := len(q.ops) - 1; i >= 0; i-- {
+		if len(q.ops[i]) > 0 {
+			return i
+		}
+	}
+	return -1
 }
 
-// Bucket is used to maintain the operators created by a specific scheduler.
-type Bucket struct {
-	weight float64
-	ops    []*operator.Operator
+// oldest returns the create time of the operator which has waited the longest.
+func (q *waitingQueue) oldest() time.Time {
+	var t time.Time
+	for _, ops := range q.ops {
+		if len(ops) > 0 && (t.IsZero() || ops[0].GetCreateTime().Before(t)) {
+			t = ops[0].GetCreateTime()
+		}
+	}
+	return t
 }
 
-// RandBuckets is an implementation of waiting operators
-type RandBuckets struct {
-	totalWeight float64
-	buckets     []*Bucket
+// WeightedQueues is an implementation of waiting operators which queues the
+// operators by their sources and serves the queues in proportion to their
+// weights, so that a busy scheduler cannot starve the others. A queue is
+// skipped when its source runs out of the in-flight quota.
+type WeightedQueues struct {
+	// weight returns the weight of the source.
+	weight func(source string) float64
+	// allow returns whether an operator of the source can be promoted now.
+	allow  func(source string) bool
+	vtime  float64
+	queues map[string]*waitingQueue
 }
 
-// NewRandBuckets creates a random buckets.
-func NewRandBuckets() *RandBuckets {
-	var buckets []*Bucket
-	for i := 0; i < len(PriorityWeight); i++ {
-		buckets = append(buckets, &Bucket{
-			weight: PriorityWeight[i],
-		})
+// NewWeightedQueues creates weighted queues.
+func NewWeightedQueues(weight func(source string) float64, allow func(source string) bool) *WeightedQueues {
+	return &WeightedQueues{
+		weight: weight,
+		allow:  allow,
+		queues: make(map[string]*waitingQueue),
 	}
-	return &RandBuckets{buckets: buckets}
 }
 
-// PutOperator puts an operator into the random buckets.
-func (b *RandBuckets) PutOperator(op *operator.Operator) {
+// PutOperator puts an operator into the queue of its source.
+func (w *WeightedQueues) PutOperator(op *operator.Operator) {
+	name := QueueName(op)
+	q, ok := w.queues[name]
+	if !ok {
+		q = &waitingQueue{name: name, ops: make([][]*operator.Operator, len(PriorityWeight))}
+		w.queues[name] = q
+	}
+	// An idle queue does not save up its share.
+	if q.len() == 0 && q.pass < w.vtime {
+		q.pass = w.vtime
+	}
 	priority := op.GetPriorityLevel()
-	bucket := b.buckets[priority]
-	if len(bucket.ops) == 0 {
-		b.totalWeight += bucket.weight
+	q.ops[priority] = append(q.ops[priority], op)
+	waitingQueueDepthGauge.WithLabelValues(name).Set(float64(q.len()))
+}
+
+// removeQueue removes the queue of the source and returns its operators,
+// the merge operators are kept in pairs.
+func (w *WeightedQueues) removeQueue(name string) []*operator.Operator {
+	q, ok := w.queues[name]
+	if !ok {
+		return nil
 	}
-	bucket.ops = append(bucket.ops, op)
+	delete(w.queues, name)
+	waitingQueueDepthGauge.DeleteLabelValues(name)
+	waitingQueueWaitDuration.DeleteLabelValues(name)
+	var ops []*operator.Operator
+	for _, bucket := range q.ops {
+		ops = append(ops, bucket...)
+	}
+	return ops
 }
 
-// ListOperator lists all operator in the random buckets.
-func (b *RandBuckets) ListOperator() []*operator.Operator {
+// ListOperator lists all operators in the queues.
+func (w *WeightedQueues) ListOperator() []*operator.Operator {
 	var ops []*operator.Operator
-	for i := range b.buckets {
-		bucket := b.buckets[i]
-		for j := range bucket.ops {
-			ops = append(ops, bucket.ops[j])
+	for _, q := range w.queues {
+		for _, bucket := range q.ops {
+			ops = append(ops, bucket...)
 		}
 	}
 	return ops
 }
 
-// GetOperator gets an operator from the random buckets.
-func (b *RandBuckets) GetOperator() []*operator.Operator {
-	if b.totalWeight == 0 {
-		return nil
-	}
-	r := rand.Float64()
-	var sum float64
-	for i := range b.buckets {
-		bucket := b.buckets[i]
-		if len(bucket.ops) == 0 {
+// GetOperator gets the operator with the highest priority from the queue with
+// the smallest virtual time among the queues which are allowed.
+func (w *WeightedQueues) GetOperator() []*operator.Operator {
+	var selected *waitingQueue
+	for _, q := range w.queues {
+		if q.len() == 0 || !w.allow(q.name) {
 			continue
 		}
-		proportion := bucket.weight / b.totalWeight
-		if r >= sum && r < sum+proportion {
-			var res []*operator.Operator
-			res = append(res, bucket.ops[0])
-			// Merge operation has two operators, and thus it should be handled specifically.
-			if bucket.ops[0].Kind()&operator.OpMerge != 0 {
-				res = append(res, bucket.ops[1])
-				bucket.ops = bucket.ops[2:]
-			} else {
-				bucket.ops = bucket.ops[1:]
-			}
-			if len(bucket.ops) == 0 {
-				b.totalWeight -= bucket.weight
-			}
-			return res
+		if selected == nil || q.pass < selected.pass || (q.pass == selected.pass && q.name < selected.name) {
+			selected = q
 		}
-		sum += proportion
 	}
-	return nil
+	if selected == nil {
+		return nil
+	}
+	priority := selected.head()
+	bucket := selected.ops[priority]
+	res := []*operator.Operator{bucket[0]}
+	// Merge operation has two operators, and thus it should be handled specifically.
+	if bucket[0].Kind()&operator.OpMerge != 0 && len(bucket) > 1 {
+		res = append(res, bucket[1])
+	}
+	selected.ops[priority] = bucket[len(res):]
+
+	w.vtime = selected.pass
+	weight := w.weight(selected.name)
+	if weight <= 0 {
+		weight = 1
+	}
+	selected.pass += 1 / (weight * PriorityWeight[priority])
+	waitingQueueDepthGauge.WithLabelValues(selected.name).Set(float64(selected.len()))
+	waitingQueueWaitDuration.WithLabelValues(selected.name).Observe(res[0].ElapsedTime().Seconds())
+	return res
+}
+
+// WaitingQueueStatus is the status of a waiting queue.
+type WaitingQueueStatus struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+	// Quota is the max number of running operators of the source, 0 means
+	// no limit.
+	Quota   uint64 `json:"quota"`
+	Running uint64 `json:"running"`
+	Depth   int    `json:"depth"`
+	// WaitTime is how long the oldest operator in the queue has waited.
+	WaitTime typeutil.Duration `json:"wait-time"`
+}
+
+// getStatus returns the status of the queues sorted by name, the weights,
+// quotas and running counts are filled by the caller.
+func (w *WeightedQueues) getStatus() []*WaitingQueueStatus {
+	status := make([]*WaitingQueueStatus, 0, len(w.queues))
+	for _, q := range w.queues {
+		s := &WaitingQueueStatus{Name: q.name, Depth: q.len()}
+		if oldest := q.oldest(); !oldest.IsZero() {
+			s.WaitTime = typeutil.NewDuration(time.Since(oldest))
+		}
+		status = append(status, s)
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Name < status[j].Name })
+	return status
 }
 
 // WaitingOperatorStatus is used to limit the count of each kind of operators.
@@ -119,7 +213,3 @@ func NewWaitingOperatorStatus() *WaitingOperatorStatus {
 		make(map[string]uint64),
 	}
 }
-
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
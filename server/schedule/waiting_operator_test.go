@@ -24,17 +24,21 @@ var _ = Suite(&testWaitingOperatorSuite{})
 
 type testWaitingOperatorSuite struct{}
 
-func (s *testWaitingOperatorSuite) TestRandBuckets(c *C) {
-	rb := NewRandBuckets()
-	addOperators(rb)
+func newTestWeightedQueues() *WeightedQueues {
+	return NewWeightedQueues(func(string) float64 { return 1 }, func(string) bool { return true })
+}
+
+func (s *testWaitingOperatorSuite) TestGetOperator(c *C) {
+	wq := newTestWeightedQueues()
+	addOperators(wq)
 	for i := 0; i < 3; i++ {
-		op := rb.GetOperator()
+		op := wq.GetOperator()
 		c.Assert(op, NotNil)
 	}
-	c.Assert(rb.GetOperator(), IsNil)
+	c.Assert(wq.GetOperator(), IsNil)
 }
 
-func addOperators(wop WaitingOperator) {
+func addOperators(wop *WeightedQueues) {
 	op := operator.NewOperator("testOperatorNormal", "test", uint64(1), &metapb.RegionEpoch{}, operator.OpRegion, []operator.OpStep{
 		operator.RemovePeer{FromStore: uint64(1)},
 	}...)
@@ -52,13 +56,13 @@ func addOperators(wop WaitingOperator) {
 }
 
 func (s *testWaitingOperatorSuite) TestListOperator(c *C) {
-	rb := NewRandBuckets()
-	addOperators(rb)
-	c.Assert(len(rb.ListOperator()), Equals, 3)
+	wq := newTestWeightedQueues()
+	addOperators(wq)
+	c.Assert(len(wq.ListOperator()), Equals, 3)
 }
 
-func (s *testWaitingOperatorSuite) TestRandomBucketsWithMergeRegion(c *C) {
-	rb := NewRandBuckets()
+func (s *testWaitingOperatorSuite) TestGetOperatorWithMergeRegion(c *C) {
+	wq := newTestWeightedQueues()
 	descs := []string{"merge-region", "admin-merge-region", "random-merge"}
 	for j := 0; j < 100; j++ {
 		// adds operators
@@ -77,7 +81,7 @@ func (s *testWaitingOperatorSuite) TestRandomBucketsWithMergeRegion(c *C) {
 				IsPassive: false,
 			},
 		}...)
-		rb.PutOperator(op)
+		wq.PutOperator(op)
 		op = operator.NewOperator(desc, "test", uint64(2), &metapb.RegionEpoch{}, operator.OpRegion|operator.OpMerge, []operator.OpStep{
 			operator.MergeRegion{
 				FromRegion: &metapb.Region{
@@ -92,17 +96,121 @@ func (s *testWaitingOperatorSuite) TestRandomBucketsWithMergeRegion(c *C) {
 				IsPassive: true,
 			},
 		}...)
-		rb.PutOperator(op)
+		wq.PutOperator(op)
 		op = operator.NewOperator("testOperatorHigh", "test", uint64(3), &metapb.RegionEpoch{}, operator.OpRegion, []operator.OpStep{
 			operator.RemovePeer{FromStore: uint64(3)},
 		}...)
 		op.SetPriorityLevel(core.HighPriority)
-		rb.PutOperator(op)
+		wq.PutOperator(op)
 
 		for i := 0; i < 2; i++ {
-			op := rb.GetOperator()
+			op := wq.GetOperator()
 			c.Assert(op, NotNil)
 		}
-		c.Assert(rb.GetOperator(), IsNil)
+		c.Assert(wq.GetOperator(), IsNil)
+	}
+}
+
+func newSourceOperator(source string, regionID uint64, kind operator.OpKind) *operator.Operator {
+	op := operator.NewOperator("test", "test", regionID, &metapb.RegionEpoch{}, kind, operator.RemovePeer{FromStore: 1})
+	op.SetSource(source)
+	return op
+}
+
+func (s *testWaitingOperatorSuite) TestWeightedQueues(c *C) {
+	weights := map[string]float64{"hot": 3}
+	allowed := map[string]bool{"hot": true, "shuffle": true}
+	wq := NewWeightedQueues(func(source string) float64 {
+		if weight, ok := weights[source]; ok {
+			return weight
+		}
+		return 1
+	}, func(source string) bool { return allowed[source] })
+
+	for i := uint64(0); i < 20; i++ {
+		wq.PutOperator(newSourceOperator("shuffle", i, operator.OpRegion))
+		wq.PutOperator(newSourceOperator("hot", 100+i, operator.OpRegion))
+	}
+	c.Assert(wq.ListOperator(), HasLen, 40)
+	// The operators are promoted in proportion to the weights.
+	counts := make(map[string]int)
+	for i := 0; i < 8; i++ {
+		ops := wq.GetOperator()
+		c.Assert(ops, HasLen, 1)
+		counts[ops[0].Source()]++
+	}
+	c.Assert(counts, DeepEquals, map[string]int{"hot": 6, "shuffle": 2})
+
+	// The queue out of quota is skipped.
+	allowed["hot"] = false
+	for i := 0; i < 3; i++ {
+		c.Assert(wq.GetOperator()[0].Source(), Equals, "shuffle")
+	}
+	allowed["shuffle"] = false
+	c.Assert(wq.GetOperator(), IsNil)
+
+	status := wq.getStatus()
+	c.Assert(status, HasLen, 2)
+	c.Assert(status[0].Name, Equals, "hot")
+	c.Assert(status[0].Depth, Equals, 14)
+	c.Assert(status[1].Name, Equals, "shuffle")
+	c.Assert(status[1].Depth, Equals, 15)
+}
+
+func (s *testWaitingOperatorSuite) TestWeightedQueuesIdle(c *C) {
+	wq := newTestWeightedQueues()
+	for i := uint64(0); i < 10; i++ {
+		wq.PutOperator(newSourceOperator("balance", i, operator.OpRegion))
+	}
+	for i := 0; i < 5; i++ {
+		c.Assert(wq.GetOperator()[0].Source(), Equals, "balance")
+	}
+	// An idle queue does not save up its share, the queues take turns.
+	for i := uint64(0); i < 5; i++ {
+		wq.PutOperator(newSourceOperator("evict", 100+i, operator.OpLeader))
+	}
+	counts := make(map[string]int)
+	for i := 0; i < 6; i++ {
+		counts[wq.GetOperator()[0].Source()]++
+	}
+	c.Assert(counts, DeepEquals, map[string]int{"balance": 3, "evict": 3})
+}
+
+func (s *testWaitingOperatorSuite) TestWeightedQueuesPriority(c *C) {
+	wq := newTestWeightedQueues()
+	op := newSourceOperator("balance", 1, operator.OpRegion)
+	wq.PutOperator(op)
+	high := newSourceOperator("balance", 2, operator.OpRegion)
+	high.SetPriorityLevel(core.HighPriority)
+	wq.PutOperator(high)
+	// The merge operators are promoted together.
+	merge := []*operator.Operator{
+		newSourceOperator("merge", 3, operator.OpMerge),
+		newSourceOperator("merge", 4, operator.OpMerge),
 	}
+	wq.PutOperator(merge[0])
+	wq.PutOperator(merge[1])
+
+	c.Assert(wq.GetOperator(), DeepEquals, []*operator.Operator{high})
+	c.Assert(wq.GetOperator(), DeepEquals, merge)
+	c.Assert(wq.GetOperator(), DeepEquals, []*operator.Operator{op})
+	c.Assert(wq.GetOperator(), IsNil)
+}
+
+func (s *testWaitingOperatorSuite) TestRemoveQueue(c *C) {
+	wq := newTestWeightedQueues()
+	op := newSourceOperator("balance", 1, operator.OpRegion)
+	wq.PutOperator(op)
+	merge := []*operator.Operator{
+		newSourceOperator("merge", 2, operator.OpMerge),
+		newSourceOperator("merge", 3, operator.OpMerge),
+	}
+	wq.PutOperator(merge[0])
+	wq.PutOperator(merge[1])
+
+	c.Assert(wq.removeQueue("merge"), DeepEquals, merge)
+	c.Assert(wq.removeQueue("merge"), IsNil)
+	c.Assert(wq.getStatus(), HasLen, 1)
+	c.Assert(wq.GetOperator(), DeepEquals, []*operator.Operator{op})
+	c.Assert(wq.GetOperator(), IsNil)
 }
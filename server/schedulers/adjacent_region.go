@@ -16,6 +16,7 @@ package schedulers
 import (
 	"bytes"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/pingcap/kvproto/pkg/metapb"
@@ -82,11 +83,18 @@ func init() {
 }
 
 type balanceAdjacentRegionConfig struct {
-	Name        string `json:"name"`
+	mu          sync.RWMutex
+	Name        string `json:"name" schema:"readonly"`
 	LeaderLimit uint64 `json:"leader-limit"`
 	PeerLimit   uint64 `json:"peer-limit"`
 }
 
+func (conf *balanceAdjacentRegionConfig) getLimits() (leaderLimit, peerLimit uint64) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	return conf.LeaderLimit, conf.PeerLimit
+}
+
 // balanceAdjacentRegionScheduler will disperse adjacent regions.
 // we will scan a part regions order by key, then select the longest
 // adjacent regions and disperse them. finally, we will guarantee
@@ -143,9 +151,21 @@ func (l *balanceAdjacentRegionScheduler) GetType() string {
 }
 
 func (l *balanceAdjacentRegionScheduler) EncodeConfig() ([]byte, error) {
+	l.conf.mu.RLock()
+	defer l.conf.mu.RUnlock()
 	return schedule.EncodeConfig(l.conf)
 }
 
+func (l *balanceAdjacentRegionScheduler) GetConfigSchema() *schedule.ConfigSchema {
+	return schedule.NewConfigSchema(l.conf)
+}
+
+func (l *balanceAdjacentRegionScheduler) UpdateConfig(data []byte) error {
+	l.conf.mu.Lock()
+	defer l.conf.mu.Unlock()
+	return schedule.ApplyConfigUpdate(l.conf, data)
+}
+
 func (l *balanceAdjacentRegionScheduler) GetMinInterval() time.Duration {
 	return minAdjacentSchedulerInterval
 }
@@ -159,11 +179,13 @@ func (l *balanceAdjacentRegionScheduler) IsScheduleAllowed(cluster opt.Cluster)
 }
 
 func (l *balanceAdjacentRegionScheduler) allowBalanceLeader() bool {
-	return l.OpController.OperatorCount(operator.OpAdjacent|operator.OpLeader) < l.conf.LeaderLimit
+	leaderLimit, _ := l.conf.getLimits()
+	return l.OpController.OperatorCount(operator.OpAdjacent|operator.OpLeader) < leaderLimit
 }
 
 func (l *balanceAdjacentRegionScheduler) allowBalancePeer() bool {
-	return l.OpController.OperatorCount(operator.OpAdjacent|operator.OpRegion) < l.conf.PeerLimit
+	_, peerLimit := l.conf.getLimits()
+	return l.OpController.OperatorCount(operator.OpAdjacent|operator.OpRegion) < peerLimit
 }
 
 func (l *balanceAdjacentRegionScheduler) Schedule(cluster opt.Cluster) []*operator.Operator {
@@ -294,7 +316,7 @@ func (l *balanceAdjacentRegionScheduler) disperseLeader(cluster opt.Cluster, bef
 			storesInfo = append(storesInfo, store)
 		}
 	}
-	target := l.selector.SelectTarget(cluster, storesInfo)
+	target := l.selector.SelectTarget(cluster, storesInfo, filter.NewLeaderStoreFilter(l.GetName(), cluster, before))
 	if target == nil {
 		return nil
 	}
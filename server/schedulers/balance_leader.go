@@ -64,8 +64,8 @@ func init() {
 }
 
 type balanceLeaderSchedulerConfig struct {
-	Name   string          `json:"name"`
-	Ranges []core.KeyRange `json:"ranges"`
+	Name   string          `json:"name" schema:"readonly"`
+	Ranges []core.KeyRange `json:"ranges" schema:"readonly"`
 }
 
 type balanceLeaderScheduler struct {
@@ -126,6 +126,14 @@ func (l *balanceLeaderScheduler) EncodeConfig() ([]byte, error) {
 	return schedule.EncodeConfig(l.conf)
 }
 
+func (l *balanceLeaderScheduler) GetConfigSchema() *schedule.ConfigSchema {
+	return schedule.NewConfigSchema(l.conf)
+}
+
+func (l *balanceLeaderScheduler) UpdateConfig(data []byte) error {
+	return schedule.ApplyConfigUpdate(l.conf, data)
+}
+
 func (l *balanceLeaderScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
 	return l.opController.OperatorCount(operator.OpLeader) < cluster.GetLeaderScheduleLimit()
 }
@@ -193,7 +201,7 @@ func (l *balanceLeaderScheduler) Schedule(cluster opt.Cluster) []*operator.Opera
 // the best follower peer and transfers the leader.
 func (l *balanceLeaderScheduler) transferLeaderOut(cluster opt.Cluster, source *core.StoreInfo) []*operator.Operator {
 	sourceID := source.GetID()
-	region := cluster.RandLeaderRegion(sourceID, l.conf.Ranges, opt.HealthRegion(cluster))
+	region := cluster.RandLeaderRegion(sourceID, l.conf.Ranges, opt.HealthRegion(cluster), opt.ScheduleAllowedRegion(cluster))
 	if region == nil {
 		log.Debug("store has no leader", zap.String("scheduler", l.GetName()), zap.Uint64("store-id", sourceID))
 		schedulerCounter.WithLabelValues(l.GetName(), "no-leader-region").Inc()
@@ -220,7 +228,7 @@ func (l *balanceLeaderScheduler) transferLeaderOut(cluster opt.Cluster, source *
 // the worst follower peer and transfers the leader.
 func (l *balanceLeaderScheduler) transferLeaderIn(cluster opt.Cluster, target *core.StoreInfo) []*operator.Operator {
 	targetID := target.GetID()
-	region := cluster.RandFollowerRegion(targetID, l.conf.Ranges, opt.HealthRegion(cluster))
+	region := cluster.RandFollowerRegion(targetID, l.conf.Ranges, opt.HealthRegion(cluster), opt.ScheduleAllowedRegion(cluster))
 	if region == nil {
 		log.Debug("store has no follower", zap.String("scheduler", l.GetName()), zap.Uint64("store-id", targetID))
 		schedulerCounter.WithLabelValues(l.GetName(), "no-follower-region").Inc()
@@ -251,6 +259,12 @@ func (l *balanceLeaderScheduler) createOperator(cluster opt.Cluster, region *cor
 		return nil
 	}
 
+	if !filter.Target(cluster, target, []filter.Filter{filter.NewLeaderStoreFilter(l.GetName(), cluster, region)}) {
+		log.Debug("region is labeled to keep the leader away from the target store", zap.String("scheduler", l.GetName()), zap.Uint64("region-id", region.GetID()))
+		schedulerCounter.WithLabelValues(l.GetName(), "leader-store-deny").Inc()
+		return nil
+	}
+
 	sourceID := source.GetID()
 	targetID := target.GetID()
 
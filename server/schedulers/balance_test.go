@@ -30,6 +30,7 @@ import (
 	"github.com/pingcap/pd/v4/server/kv"
 	"github.com/pingcap/pd/v4/server/schedule"
 	"github.com/pingcap/pd/v4/server/schedule/checker"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 )
 
@@ -337,6 +338,37 @@ func (s *testBalanceLeaderSchedulerSuite) TestBalanceFilter(c *C) {
 	c.Assert(s.schedule(), HasLen, 0)
 }
 
+func (s *testBalanceLeaderSchedulerSuite) TestRegionLabel(c *C) {
+	// Stores:     1    2    3    4
+	// Leaders:    1    2    3   16
+	// Region1:    F    F    F    L
+	s.tc.AddLeaderStore(1, 1)
+	s.tc.AddLeaderStore(2, 2)
+	s.tc.AddLeaderStore(3, 3)
+	s.tc.AddLeaderStore(4, 16)
+	s.tc.AddLeaderRegion(1, 4, 1, 2, 3)
+	testutil.CheckTransferLeader(c, s.schedule()[0], operator.OpBalance, 4, 1)
+
+	// The leader of region 1 should stay in zone z2, store 1 is filtered.
+	store := s.tc.GetStore(2)
+	s.tc.PutStore(store.Clone(core.SetStoreLabels([]*metapb.StoreLabel{{Key: "zone", Value: "z2"}})))
+	regionLabeler := s.tc.GetRegionLabeler()
+	rule := &labeler.LabelRule{
+		ID:     "test",
+		Labels: []*labeler.RegionLabel{{Key: labeler.LeaderStoreLabelKey, Value: "zone=z2"}},
+	}
+	c.Assert(regionLabeler.SetLabelRule(rule), IsNil)
+	testutil.CheckTransferLeader(c, s.schedule()[0], operator.OpBalance, 4, 2)
+
+	// Region 1 is not scheduled if it is labeled to deny scheduling.
+	rule = &labeler.LabelRule{
+		ID:     "test",
+		Labels: []*labeler.RegionLabel{{Key: labeler.ScheduleKey, Value: labeler.DenyValue}},
+	}
+	c.Assert(regionLabeler.SetLabelRule(rule), IsNil)
+	c.Assert(s.schedule(), HasLen, 0)
+}
+
 func (s *testBalanceLeaderSchedulerSuite) TestLeaderWeight(c *C) {
 	// Stores:     1       2       3       4
 	// Leaders:    10      10      10      10
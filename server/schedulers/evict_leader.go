@@ -81,7 +81,7 @@ func init() {
 type evictLeaderSchedulerConfig struct {
 	mu                sync.RWMutex
 	storage           *core.Storage
-	StoreIDWithRanges map[uint64][]core.KeyRange `json:"store-id-ranges"`
+	StoreIDWithRanges map[uint64][]core.KeyRange `json:"store-id-ranges" schema:"readonly"`
 	cluster           opt.Cluster
 }
 
@@ -196,6 +196,16 @@ func (s *evictLeaderScheduler) EncodeConfig() ([]byte, error) {
 	return schedule.EncodeConfig(s.conf)
 }
 
+func (s *evictLeaderScheduler) GetConfigSchema() *schedule.ConfigSchema {
+	return schedule.NewConfigSchema(s.conf)
+}
+
+func (s *evictLeaderScheduler) UpdateConfig(data []byte) error {
+	s.conf.mu.Lock()
+	defer s.conf.mu.Unlock()
+	return schedule.ApplyConfigUpdate(s.conf, data)
+}
+
 func (s *evictLeaderScheduler) Prepare(cluster opt.Cluster) error {
 	s.conf.mu.RLock()
 	defer s.conf.mu.RUnlock()
@@ -228,7 +238,7 @@ func (s *evictLeaderScheduler) scheduleOnce(cluster opt.Cluster) []*operator.Ope
 			schedulerCounter.WithLabelValues(s.GetName(), "no-leader").Inc()
 			continue
 		}
-		target := s.selector.SelectTarget(cluster, cluster.GetFollowerStores(region))
+		target := s.selector.SelectTarget(cluster, cluster.GetFollowerStores(region), filter.NewLeaderStoreFilter(s.GetName(), cluster, region))
 		if target == nil {
 			schedulerCounter.WithLabelValues(s.GetName(), "no-target-store").Inc()
 			continue
@@ -23,6 +23,7 @@ import (
 	"github.com/pingcap/pd/v4/pkg/apiutil"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/filter"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
 	"github.com/pkg/errors"
@@ -75,7 +76,7 @@ func init() {
 type grantLeaderSchedulerConfig struct {
 	mu                sync.RWMutex
 	storage           *core.Storage
-	StoreIDWithRanges map[uint64][]core.KeyRange `json:"store-id-ranges"`
+	StoreIDWithRanges map[uint64][]core.KeyRange `json:"store-id-ranges" schema:"readonly"`
 	cluster           opt.Cluster
 }
 
@@ -183,6 +184,16 @@ func (s *grantLeaderScheduler) EncodeConfig() ([]byte, error) {
 	return schedule.EncodeConfig(s.conf)
 }
 
+func (s *grantLeaderScheduler) GetConfigSchema() *schedule.ConfigSchema {
+	return schedule.NewConfigSchema(s.conf)
+}
+
+func (s *grantLeaderScheduler) UpdateConfig(data []byte) error {
+	s.conf.mu.Lock()
+	defer s.conf.mu.Unlock()
+	return schedule.ApplyConfigUpdate(s.conf, data)
+}
+
 func (s *grantLeaderScheduler) Prepare(cluster opt.Cluster) error {
 	s.conf.mu.RLock()
 	defer s.conf.mu.RUnlock()
@@ -218,6 +229,10 @@ func (s *grantLeaderScheduler) Schedule(cluster opt.Cluster) []*operator.Operato
 			schedulerCounter.WithLabelValues(s.GetName(), "no-follower").Inc()
 			continue
 		}
+		if store := cluster.GetStore(id); store == nil || !filter.Target(cluster, store, []filter.Filter{filter.NewLeaderStoreFilter(s.GetName(), cluster, region)}) {
+			schedulerCounter.WithLabelValues(s.GetName(), "leader-store-deny").Inc()
+			continue
+		}
 
 		op, err := operator.CreateTransferLeaderOperator(GrantLeaderType, cluster, region, region.GetLeader().GetStoreId(), id, operator.OpLeader)
 		if err != nil {
@@ -143,6 +143,20 @@ func (h *hotScheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.conf.ServeHTTP(w, r)
 }
 
+func (h *hotScheduler) EncodeConfig() ([]byte, error) {
+	return h.conf.EncodeConfig()
+}
+
+func (h *hotScheduler) GetConfigSchema() *schedule.ConfigSchema {
+	return schedule.NewConfigSchema(h.conf)
+}
+
+func (h *hotScheduler) UpdateConfig(data []byte) error {
+	h.conf.Lock()
+	defer h.conf.Unlock()
+	return schedule.ApplyConfigUpdate(h.conf, data)
+}
+
 func (h *hotScheduler) GetMinInterval() time.Duration {
 	return minHotScheduleInterval
 }
@@ -189,14 +203,18 @@ func (h *hotScheduler) prepareForBalance(cluster opt.Cluster) {
 	storesStat := cluster.GetStoresStats()
 
 	minHotDegree := cluster.GetHotRegionCacheHitsThreshold()
+	storeCPU := storesStat.GetStoresCPUUsage()
 	{ // update read statistics
 		regionRead := cluster.RegionReadStats()
 		storeByte := storesStat.GetStoresBytesReadStat()
 		storeKey := storesStat.GetStoresKeysReadStat()
+		storeDisk := storesStat.GetStoresDiskReadRate()
 
 		h.stLoadInfos[readLeader] = summaryStoresLoad(
 			storeByte,
 			storeKey,
+			storeCPU,
+			storeDisk,
 			h.pendingSums[readLeader],
 			regionRead,
 			minHotDegree,
@@ -207,10 +225,13 @@ func (h *hotScheduler) prepareForBalance(cluster opt.Cluster) {
 		regionWrite := cluster.RegionWriteStats()
 		storeByte := storesStat.GetStoresBytesWriteStat()
 		storeKey := storesStat.GetStoresKeysWriteStat()
+		storeDisk := storesStat.GetStoresDiskWriteRate()
 
 		h.stLoadInfos[writeLeader] = summaryStoresLoad(
 			storeByte,
 			storeKey,
+			storeCPU,
+			storeDisk,
 			h.pendingSums[writeLeader],
 			regionWrite,
 			minHotDegree,
@@ -219,6 +240,8 @@ func (h *hotScheduler) prepareForBalance(cluster opt.Cluster) {
 		h.stLoadInfos[writePeer] = summaryStoresLoad(
 			storeByte,
 			storeKey,
+			storeCPU,
+			storeDisk,
 			h.pendingSums[writePeer],
 			regionWrite,
 			minHotDegree,
@@ -259,6 +282,8 @@ func (h *hotScheduler) gcRegionPendings() {
 func summaryStoresLoad(
 	storeByteRate map[uint64]float64,
 	storeKeyRate map[uint64]float64,
+	storeCPUUsage map[uint64]float64,
+	storeDiskRate map[uint64]float64,
 	pendings map[uint64]Influence,
 	storeHotPeers map[uint64][]*statistics.HotPeerStat,
 	minHotDegree int,
@@ -268,11 +293,25 @@ func summaryStoresLoad(
 	loadDetail := make(map[uint64]*storeLoadDetail, len(storeByteRate))
 	allByteSum := 0.0
 	allKeySum := 0.0
+	allCPUSum := 0.0
+	allDiskSum := 0.0
 	allCount := 0.0
 
 	// Stores without byte rate statistics is not available to schedule.
 	for id, byteRate := range storeByteRate {
 		keyRate := storeKeyRate[id]
+		cpuUsage := storeCPUUsage[id]
+		diskRate := storeDiskRate[id]
+
+		// The share of a hot peer in the CPU usage and the disk IO rate is
+		// estimated by its key rate and byte rate.
+		var cpuUsagePerKey, diskRatePerByte float64
+		if keyRate > 0 {
+			cpuUsagePerKey = cpuUsage / keyRate
+		}
+		if byteRate > 0 {
+			diskRatePerByte = diskRate / byteRate
+		}
 
 		// Find all hot peers first
 		hotPeers := make([]*statistics.HotPeerStat, 0)
@@ -302,19 +341,25 @@ func summaryStoresLoad(
 		}
 		allByteSum += byteRate
 		allKeySum += keyRate
+		allCPUSum += cpuUsage
+		allDiskSum += diskRate
 		allCount += float64(len(hotPeers))
 
 		// Build store load prediction from current load and pending influence.
 		stLoadPred := (&storeLoad{
 			ByteRate: byteRate,
 			KeyRate:  keyRate,
+			CPUUsage: cpuUsage,
+			DiskRate: diskRate,
 			Count:    float64(len(hotPeers)),
 		}).ToLoadPred(pendings[id])
 
 		// Construct store load info.
 		loadDetail[id] = &storeLoadDetail{
-			LoadPred: stLoadPred,
-			HotPeers: hotPeers,
+			LoadPred:        stLoadPred,
+			HotPeers:        hotPeers,
+			CPUUsagePerKey:  cpuUsagePerKey,
+			DiskRatePerByte: diskRatePerByte,
 		}
 	}
 	storeLen := float64(len(storeByteRate))
@@ -322,9 +367,13 @@ func summaryStoresLoad(
 	for id, detail := range loadDetail {
 		byteExp := allByteSum / storeLen
 		keyExp := allKeySum / storeLen
+		cpuExp := allCPUSum / storeLen
+		diskExp := allDiskSum / storeLen
 		countExp := allCount / storeLen
 		detail.LoadPred.Future.ExpByteRate = byteExp
 		detail.LoadPred.Future.ExpKeyRate = keyExp
+		detail.LoadPred.Future.ExpCPUUsage = cpuExp
+		detail.LoadPred.Future.ExpDiskRate = diskExp
 		detail.LoadPred.Future.ExpCount = countExp
 		// Debug
 		{
@@ -429,6 +478,8 @@ type balanceSolver struct {
 	stLoadDetail map[uint64]*storeLoadDetail
 	rwTy         rwType
 	opTy         opType
+	// the dimensions to balance in priority order
+	priorities []string
 
 	cur *solution
 
@@ -461,11 +512,14 @@ func (bs *balanceSolver) init() {
 	for _, id := range getUnhealthyStores(bs.cluster) {
 		delete(bs.stLoadDetail, id)
 	}
+	bs.priorities = bs.sche.conf.GetPriorities(bs.rwTy)
 
 	bs.maxSrc = &storeLoad{}
 	bs.minDst = &storeLoad{
 		ByteRate: math.MaxFloat64,
 		KeyRate:  math.MaxFloat64,
+		CPUUsage: math.MaxFloat64,
+		DiskRate: math.MaxFloat64,
 		Count:    math.MaxFloat64,
 	}
 	maxCur := &storeLoad{}
@@ -479,10 +533,30 @@ func (bs *balanceSolver) init() {
 	bs.rankStep = &storeLoad{
 		ByteRate: maxCur.ByteRate * bs.sche.conf.GetByteRankStepRatio(),
 		KeyRate:  maxCur.KeyRate * bs.sche.conf.GetKeyRankStepRatio(),
+		CPUUsage: maxCur.CPUUsage * bs.sche.conf.GetCPURankStepRatio(),
+		DiskRate: maxCur.DiskRate * bs.sche.conf.GetDiskRateRankStepRatio(),
 		Count:    maxCur.Count * bs.sche.conf.GetCountRankStepRatio(),
 	}
 }
 
+// balancedDims returns the first two dimensions in priority order, which are
+// balanced by the solver. The other dimensions are only kept from being worsened.
+func (bs *balanceSolver) balancedDims() []string {
+	if len(bs.priorities) > 2 {
+		return bs.priorities[:2]
+	}
+	return bs.priorities
+}
+
+// pickingDims returns the dimensions in which the source stores should be
+// hotter, and the destination stores should be cooler, than the expectation.
+func (bs *balanceSolver) pickingDims() []string {
+	if bs.sche.conf.IsStrictPickingStoreEnabled() {
+		return bs.balancedDims()
+	}
+	return bs.priorities[:1]
+}
+
 func getUnhealthyStores(cluster opt.Cluster) []uint64 {
 	ret := make([]uint64, 0)
 	stores := cluster.GetStores()
@@ -588,8 +662,7 @@ func (bs *balanceSolver) filterSrcStores() map[uint64]*storeLoadDetail {
 		if len(detail.HotPeers) == 0 {
 			continue
 		}
-		if detail.LoadPred.min().ByteRate > bs.sche.conf.GetSrcToleranceRatio()*detail.LoadPred.Future.ExpByteRate &&
-			detail.LoadPred.min().KeyRate > bs.sche.conf.GetSrcToleranceRatio()*detail.LoadPred.Future.ExpKeyRate {
+		if bs.isSrcStoreHot(detail) {
 			ret[id] = detail
 			balanceHotRegionCounter.WithLabelValues("src-store-succ", strconv.FormatUint(id, 10)).Inc()
 		}
@@ -598,8 +671,29 @@ func (bs *balanceSolver) filterSrcStores() map[uint64]*storeLoadDetail {
 	return ret
 }
 
+func (bs *balanceSolver) isSrcStoreHot(detail *storeLoadDetail) bool {
+	minLoad, tolerance := detail.LoadPred.min(), bs.sche.conf.GetSrcToleranceRatio()
+	for _, dim := range bs.pickingDims() {
+		if stLdDim(dim)(minLoad) <= tolerance*stLdExpDim(dim)(&detail.LoadPred.Future) {
+			return false
+		}
+	}
+	return true
+}
+
+func (bs *balanceSolver) isDstStoreCold(detail *storeLoadDetail) bool {
+	maxLoad, tolerance := detail.LoadPred.max(), bs.sche.conf.GetDstToleranceRatio()
+	for _, dim := range bs.pickingDims() {
+		if stLdDim(dim)(maxLoad)*tolerance >= stLdExpDim(dim)(&detail.LoadPred.Future) {
+			return false
+		}
+	}
+	return true
+}
+
 func (bs *balanceSolver) filterHotPeers() []*statistics.HotPeerStat {
-	ret := bs.stLoadDetail[bs.cur.srcStoreID].HotPeers
+	srcDetail := bs.stLoadDetail[bs.cur.srcStoreID]
+	ret := srcDetail.HotPeers
 	// Return at most MaxPeerNum peers, to prevent balanceSolver.solve() too slow.
 	maxPeerNum := bs.sche.conf.GetMaxPeerNumber()
 
@@ -618,33 +712,29 @@ func (bs *balanceSolver) filterHotPeers() []*statistics.HotPeerStat {
 		return nret
 	}
 
-	byteSort := make([]*statistics.HotPeerStat, len(ret))
-	copy(byteSort, ret)
-	sort.Slice(byteSort, func(i, j int) bool {
-		return byteSort[i].GetByteRate() > byteSort[j].GetByteRate()
-	})
-	keySort := make([]*statistics.HotPeerStat, len(ret))
-	copy(keySort, ret)
-	sort.Slice(keySort, func(i, j int) bool {
-		return keySort[i].GetKeyRate() > keySort[j].GetKeyRate()
-	})
+	// Pick the hottest peers in each balanced dimension in turn.
+	dims := bs.balancedDims()
+	sorted := make([][]*statistics.HotPeerStat, len(dims))
+	for i, dim := range dims {
+		dim := dim
+		peers := make([]*statistics.HotPeerStat, len(ret))
+		copy(peers, ret)
+		sort.Slice(peers, func(i, j int) bool {
+			return srcDetail.peerLoad(peers[i], dim) > srcDetail.peerLoad(peers[j], dim)
+		})
+		sorted[i] = peers
+	}
 
 	union := make(map[*statistics.HotPeerStat]struct{}, maxPeerNum)
 	for len(union) < maxPeerNum {
-		for len(byteSort) > 0 {
-			peer := byteSort[0]
-			byteSort = byteSort[1:]
-			if _, ok := union[peer]; !ok {
-				union[peer] = struct{}{}
-				break
-			}
-		}
-		for len(keySort) > 0 {
-			peer := keySort[0]
-			keySort = keySort[1:]
-			if _, ok := union[peer]; !ok {
-				union[peer] = struct{}{}
-				break
+		for i := range sorted {
+			for len(sorted[i]) > 0 {
+				peer := sorted[i][0]
+				sorted[i] = sorted[i][1:]
+				if _, ok := union[peer]; !ok {
+					union[peer] = struct{}{}
+					break
+				}
 			}
 		}
 	}
@@ -683,6 +773,11 @@ func (bs *balanceSolver) isRegionAvailable(region *core.RegionInfo) bool {
 		return false
 	}
 
+	if !opt.IsRegionScheduleAllowed(bs.cluster, region) {
+		schedulerCounter.WithLabelValues(bs.sche.GetName(), "label-deny").Inc()
+		return false
+	}
+
 	return true
 }
 
@@ -732,6 +827,7 @@ func (bs *balanceSolver) filterDstStores() map[uint64]*storeLoadDetail {
 
 		filters = []filter.Filter{
 			filter.StoreStateFilter{ActionScope: bs.sche.GetName(), MoveRegion: true},
+			filter.NewDiskStateFilter(bs.sche.GetName(), true),
 			filter.NewExcludedFilter(bs.sche.GetName(), bs.cur.region.GetStoreIds(), bs.cur.region.GetStoreIds()),
 			filter.NewHealthFilter(bs.sche.GetName()),
 			filter.NewSpecialUseFilter(bs.sche.GetName(), filter.SpecialUseHotRegion),
@@ -745,6 +841,7 @@ func (bs *balanceSolver) filterDstStores() map[uint64]*storeLoadDetail {
 			filter.StoreStateFilter{ActionScope: bs.sche.GetName(), TransferLeader: true},
 			filter.NewHealthFilter(bs.sche.GetName()),
 			filter.NewSpecialUseFilter(bs.sche.GetName(), filter.SpecialUseHotRegion),
+			filter.NewLeaderStoreFilter(bs.sche.GetName(), bs.cluster, bs.cur.region),
 		}
 
 		candidates = bs.cluster.GetFollowerStores(bs.cur.region)
@@ -757,8 +854,7 @@ func (bs *balanceSolver) filterDstStores() map[uint64]*storeLoadDetail {
 	for _, store := range candidates {
 		if filter.Target(bs.cluster, store, filters) {
 			detail := bs.stLoadDetail[store.GetID()]
-			if detail.LoadPred.max().ByteRate*bs.sche.conf.GetDstToleranceRatio() < detail.LoadPred.Future.ExpByteRate &&
-				detail.LoadPred.max().KeyRate*bs.sche.conf.GetDstToleranceRatio() < detail.LoadPred.Future.ExpKeyRate {
+			if bs.isDstStoreCold(detail) {
 				ret[store.GetID()] = bs.stLoadDetail[store.GetID()]
 				balanceHotRegionCounter.WithLabelValues("dst-store-succ", strconv.FormatUint(store.GetID(), 10)).Inc()
 			}
@@ -772,7 +868,8 @@ func (bs *balanceSolver) filterDstStores() map[uint64]*storeLoadDetail {
 // calcProgressiveRank calculates `bs.cur.progressiveRank`.
 // See the comments of `solution.progressiveRank` for more about progressive rank.
 func (bs *balanceSolver) calcProgressiveRank() {
-	srcLd := bs.stLoadDetail[bs.cur.srcStoreID].LoadPred.min()
+	srcDetail := bs.stLoadDetail[bs.cur.srcStoreID]
+	srcLd := srcDetail.LoadPred.min()
 	dstLd := bs.stLoadDetail[bs.cur.dstStoreID].LoadPred.max()
 	peer := bs.cur.srcPeerStat
 	rank := int64(0)
@@ -789,26 +886,61 @@ func (bs *balanceSolver) calcProgressiveRank() {
 			}
 			return a - b
 		}
-		keyDecRatio := (dstLd.KeyRate + peer.GetKeyRate()) / getSrcDecRate(srcLd.KeyRate, peer.GetKeyRate())
-		keyHot := peer.GetKeyRate() >= bs.sche.conf.GetMinHotKeyRate()
-		byteDecRatio := (dstLd.ByteRate + peer.GetByteRate()) / getSrcDecRate(srcLd.ByteRate, peer.GetByteRate())
-		byteHot := peer.GetByteRate() > bs.sche.conf.GetMinHotByteRate()
+		decRatio := func(dim string) float64 {
+			ld, peerLd := stLdDim(dim), srcDetail.peerLoad(peer, dim)
+			return (ld(dstLd) + peerLd) / getSrcDecRate(ld(srcLd), peerLd)
+		}
 		greatDecRatio, minorDecRatio := bs.sche.conf.GetGreatDecRatio(), bs.sche.conf.GetMinorGreatDecRatio()
+		isBalanced := func(dim string) bool {
+			return bs.isHotPeer(peer, dim) && decRatio(dim) <= greatDecRatio
+		}
+		first := bs.priorities[0]
+		firstBalanced := isBalanced(first)
+		secondBalanced := len(bs.priorities) > 1 && isBalanced(bs.priorities[1])
 		switch {
-		case byteHot && byteDecRatio <= greatDecRatio && keyHot && keyDecRatio <= greatDecRatio:
-			// Both byte rate and key rate are balanced, the best choice.
+		case firstBalanced && secondBalanced:
+			// Both of the first two dimensions are balanced, the best choice.
 			rank = -3
-		case byteDecRatio <= minorDecRatio && keyHot && keyDecRatio <= greatDecRatio:
-			// Byte rate is not worsened, key rate is balanced.
+		case decRatio(first) <= minorDecRatio && secondBalanced:
+			// The first dimension is not worsened, the second one is balanced.
 			rank = -2
-		case byteHot && byteDecRatio <= greatDecRatio:
-			// Byte rate is balanced, ignore the key rate.
+		case firstBalanced:
+			// The first dimension is balanced, ignore the second one.
 			rank = -1
 		}
+		// The dimensions of lower priorities should not be worsened.
+		for _, dim := range bs.priorities[len(bs.balancedDims()):] {
+			if decRatio(dim) > minorDecRatio {
+				rank = 0
+			}
+		}
 	}
 	bs.cur.progressiveRank = rank
 }
 
+// isHotPeer checks if the peer is hot enough in the dimension to be moved. The
+// CPU usage and the disk IO rate are estimated by the key rate and byte rate.
+func (bs *balanceSolver) isHotPeer(peer *statistics.HotPeerStat, dim string) bool {
+	switch dim {
+	case byteDim, diskDim:
+		return peer.GetByteRate() > bs.sche.conf.GetMinHotByteRate()
+	default:
+		return peer.GetKeyRate() >= bs.sche.conf.GetMinHotKeyRate()
+	}
+}
+
+// peerRankStep returns the step to rank the peers in the dimension.
+func peerRankStep(dim string) float64 {
+	switch dim {
+	case keyDim:
+		return 10
+	case cpuDim:
+		return 1
+	default:
+		return 100
+	}
+}
+
 // betterThan checks if `bs.cur` is a better solution than `old`.
 func (bs *balanceSolver) betterThan(old *solution) bool {
 	if old == nil {
@@ -845,27 +977,35 @@ func (bs *balanceSolver) betterThan(old *solution) bool {
 				return false
 			}
 		} else {
-			byteRkCmp := rankCmp(bs.cur.srcPeerStat.GetByteRate(), old.srcPeerStat.GetByteRate(), stepRank(0, 100))
-			keyRkCmp := rankCmp(bs.cur.srcPeerStat.GetKeyRate(), old.srcPeerStat.GetKeyRate(), stepRank(0, 10))
+			peerRkCmp := func(dim string) int {
+				srcDetail := bs.stLoadDetail[bs.cur.srcStoreID]
+				oldSrcDetail := bs.stLoadDetail[old.srcStoreID]
+				return rankCmp(srcDetail.peerLoad(bs.cur.srcPeerStat, dim), oldSrcDetail.peerLoad(old.srcPeerStat, dim), stepRank(0, peerRankStep(dim)))
+			}
+			firstRkCmp := peerRkCmp(bs.priorities[0])
+			secondRkCmp := 0
+			if len(bs.priorities) > 1 {
+				secondRkCmp = peerRkCmp(bs.priorities[1])
+			}
 
 			switch bs.cur.progressiveRank {
-			case -2: // greatDecRatio < byteDecRatio <= minorDecRatio && keyDecRatio <= greatDecRatio
-				if keyRkCmp != 0 {
-					return keyRkCmp > 0
+			case -2: // greatDecRatio < firstDecRatio <= minorDecRatio && secondDecRatio <= greatDecRatio
+				if secondRkCmp != 0 {
+					return secondRkCmp > 0
 				}
-				if byteRkCmp != 0 {
-					// prefer smaller byte rate, to reduce oscillation
-					return byteRkCmp < 0
+				if firstRkCmp != 0 {
+					// prefer smaller first dimension, to reduce oscillation
+					return firstRkCmp < 0
 				}
-			case -3: // byteDecRatio <= greatDecRatio && keyDecRatio <= greatDecRatio
-				if keyRkCmp != 0 {
-					return keyRkCmp > 0
+			case -3: // firstDecRatio <= greatDecRatio && secondDecRatio <= greatDecRatio
+				if secondRkCmp != 0 {
+					return secondRkCmp > 0
 				}
 				fallthrough
-			case -1: // byteDecRatio <= greatDecRatio
-				if byteRkCmp != 0 {
-					// prefer region with larger byte rate, to converge faster
-					return byteRkCmp > 0
+			case -1: // firstDecRatio <= greatDecRatio
+				if firstRkCmp != 0 {
+					// prefer region with larger first dimension, to converge faster
+					return firstRkCmp > 0
 				}
 			}
 		}
@@ -892,13 +1032,11 @@ func (bs *balanceSolver) compareSrcStore(st1, st2 uint64) int {
 				)),
 			)
 		} else {
+			first := stLdDim(bs.priorities[0])
 			lpCmp = sliceLPCmp(
-				minLPCmp(negLoadCmp(sliceLoadCmp(
-					stLdRankCmp(stLdByteRate, stepRank(bs.maxSrc.ByteRate, bs.rankStep.ByteRate)),
-					stLdRankCmp(stLdKeyRate, stepRank(bs.maxSrc.KeyRate, bs.rankStep.KeyRate)),
-				))),
+				minLPCmp(negLoadCmp(sliceLoadCmp(bs.stLdRankCmps(bs.maxSrc)...))),
 				diffCmp(
-					stLdRankCmp(stLdByteRate, stepRank(0, bs.rankStep.ByteRate)),
+					stLdRankCmp(first, stepRank(0, first(bs.rankStep))),
 				),
 			)
 		}
@@ -927,13 +1065,11 @@ func (bs *balanceSolver) compareDstStore(st1, st2 uint64) int {
 					stLdRankCmp(stLdByteRate, stepRank(0, bs.rankStep.ByteRate)),
 				)))
 		} else {
+			first := stLdDim(bs.priorities[0])
 			lpCmp = sliceLPCmp(
-				maxLPCmp(sliceLoadCmp(
-					stLdRankCmp(stLdByteRate, stepRank(bs.minDst.ByteRate, bs.rankStep.ByteRate)),
-					stLdRankCmp(stLdKeyRate, stepRank(bs.minDst.KeyRate, bs.rankStep.KeyRate)),
-				)),
+				maxLPCmp(sliceLoadCmp(bs.stLdRankCmps(bs.minDst)...)),
 				diffCmp(
-					stLdRankCmp(stLdByteRate, stepRank(0, bs.rankStep.ByteRate)),
+					stLdRankCmp(first, stepRank(0, first(bs.rankStep))),
 				),
 			)
 		}
@@ -945,6 +1081,18 @@ func (bs *balanceSolver) compareDstStore(st1, st2 uint64) int {
 	return 0
 }
 
+// stLdRankCmps returns the comparators of the store loads in the balanced
+// dimensions, which are ranked from the base load.
+func (bs *balanceSolver) stLdRankCmps(base *storeLoad) []storeLoadCmp {
+	dims := bs.balancedDims()
+	cmps := make([]storeLoadCmp, 0, len(dims))
+	for _, dim := range dims {
+		ld := stLdDim(dim)
+		cmps = append(cmps, stLdRankCmp(ld, stepRank(ld(base), ld(bs.rankStep))))
+	}
+	return cmps
+}
+
 func stepRank(rk0 float64, step float64) func(float64) int64 {
 	return func(rate float64) int64 {
 		return int64((rate - rk0) / step)
@@ -1012,9 +1160,12 @@ func (bs *balanceSolver) buildOperators() ([]*operator.Operator, []Influence) {
 		schedulerCounter.WithLabelValues(bs.sche.GetName(), "new-operator"),
 		schedulerCounter.WithLabelValues(bs.sche.GetName(), bs.opTy.String()))
 
+	srcDetail := bs.stLoadDetail[bs.cur.srcStoreID]
 	infl := Influence{
 		ByteRate: bs.cur.srcPeerStat.GetByteRate(),
 		KeyRate:  bs.cur.srcPeerStat.GetKeyRate(),
+		CPUUsage: srcDetail.peerLoad(bs.cur.srcPeerStat, cpuDim),
+		DiskRate: srcDetail.peerLoad(bs.cur.srcPeerStat, diskDim),
 		Count:    1,
 	}
 
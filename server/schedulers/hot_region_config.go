@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/pingcap/pd/v4/pkg/slice"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/schedule"
 	"github.com/pingcap/pd/v4/server/statistics"
@@ -38,33 +39,50 @@ func initHotRegionScheduleConfig() *hotRegionSchedulerConfig {
 		MaxZombieRounds:       3,
 		ByteRateRankStepRatio: 0.05,
 		KeyRateRankStepRatio:  0.05,
+		CPURankStepRatio:      0.05,
+		DiskRateRankStepRatio: 0.05,
 		CountRankStepRatio:    0.01,
 		GreatDecRatio:         0.95,
 		MinorDecRatio:         0.99,
 		MaxPeerNum:            1000,
 		SrcToleranceRatio:     1.02, // Tolerate 2% difference
 		DstToleranceRatio:     1.02, // Tolerate 2% difference
+		ReadPriorities:        []string{byteDim, keyDim},
+		WritePriorities:       []string{byteDim, keyDim},
+		StrictPickingStore:    true,
 	}
 }
 
+var defaultHotPriorities = []string{byteDim, keyDim}
+
 type hotRegionSchedulerConfig struct {
 	sync.RWMutex
 	storage *core.Storage
 
-	MinHotByteRate  float64 `json:"min-hot-byte-rate"`
-	MinHotKeyRate   float64 `json:"min-hot-key-rate"`
-	MaxZombieRounds int     `json:"max-zombie-rounds"`
-	MaxPeerNum      int     `json:"max-peer-number"`
+	MinHotByteRate  float64 `json:"min-hot-byte-rate" schema:"min=0"`
+	MinHotKeyRate   float64 `json:"min-hot-key-rate" schema:"min=0"`
+	MaxZombieRounds int     `json:"max-zombie-rounds" schema:"min=0"`
+	MaxPeerNum      int     `json:"max-peer-number" schema:"min=0"`
 
 	// rank step ratio decide the step when calculate rank
 	// step = max current * rank step ratio
-	ByteRateRankStepRatio float64 `json:"byte-rate-rank-step-ratio"`
-	KeyRateRankStepRatio  float64 `json:"key-rate-rank-step-ratio"`
-	CountRankStepRatio    float64 `json:"count-rank-step-ratio"`
-	GreatDecRatio         float64 `json:"great-dec-ratio"`
-	MinorDecRatio         float64 `json:"minor-dec-ratio"`
-	SrcToleranceRatio     float64 `json:"src-tolerance-ratio"`
-	DstToleranceRatio     float64 `json:"dst-tolerance-ratio"`
+	ByteRateRankStepRatio float64 `json:"byte-rate-rank-step-ratio" schema:"min=0,max=1"`
+	KeyRateRankStepRatio  float64 `json:"key-rate-rank-step-ratio" schema:"min=0,max=1"`
+	CPURankStepRatio      float64 `json:"cpu-rank-step-ratio" schema:"min=0,max=1"`
+	DiskRateRankStepRatio float64 `json:"disk-rate-rank-step-ratio" schema:"min=0,max=1"`
+	CountRankStepRatio    float64 `json:"count-rank-step-ratio" schema:"min=0,max=1"`
+	GreatDecRatio         float64 `json:"great-dec-ratio" schema:"min=0,max=1"`
+	MinorDecRatio         float64 `json:"minor-dec-ratio" schema:"min=0,max=1"`
+	SrcToleranceRatio     float64 `json:"src-tolerance-ratio" schema:"min=0"`
+	DstToleranceRatio     float64 `json:"dst-tolerance-ratio" schema:"min=0"`
+
+	// The dimensions to balance in priority order. The first two are balanced
+	// and the others are only kept from being worsened.
+	ReadPriorities  []string `json:"read-priorities" schema:"enum=byte|key|cpu|disk"`
+	WritePriorities []string `json:"write-priorities" schema:"enum=byte|key|cpu|disk"`
+	// StrictPickingStore requires the source stores to be hotter than the
+	// expectation in the first two dimensions, otherwise only in the first one.
+	StrictPickingStore bool `json:"strict-picking-store"`
 }
 
 func (conf *hotRegionSchedulerConfig) EncodeConfig() ([]byte, error) {
@@ -121,6 +139,18 @@ func (conf *hotRegionSchedulerConfig) GetKeyRankStepRatio() float64 {
 	return conf.KeyRateRankStepRatio
 }
 
+func (conf *hotRegionSchedulerConfig) GetCPURankStepRatio() float64 {
+	conf.RLock()
+	defer conf.RUnlock()
+	return conf.CPURankStepRatio
+}
+
+func (conf *hotRegionSchedulerConfig) GetDiskRateRankStepRatio() float64 {
+	conf.RLock()
+	defer conf.RUnlock()
+	return conf.DiskRateRankStepRatio
+}
+
 func (conf *hotRegionSchedulerConfig) GetCountRankStepRatio() float64 {
 	conf.RLock()
 	defer conf.RUnlock()
@@ -151,6 +181,38 @@ func (conf *hotRegionSchedulerConfig) GetMinHotByteRate() float64 {
 	return conf.MinHotByteRate
 }
 
+// GetPriorities returns the dimensions to balance for the read or write flow
+// in priority order, the duplicated and unknown dimensions are ignored.
+func (conf *hotRegionSchedulerConfig) GetPriorities(rwTy rwType) []string {
+	conf.RLock()
+	defer conf.RUnlock()
+	priorities := conf.WritePriorities
+	if rwTy == read {
+		priorities = conf.ReadPriorities
+	}
+	ret := make([]string, 0, len(priorities))
+	for _, dim := range priorities {
+		switch dim {
+		case byteDim, keyDim, cpuDim, diskDim:
+		default:
+			continue
+		}
+		if slice.NoneOf(ret, func(i int) bool { return ret[i] == dim }) {
+			ret = append(ret, dim)
+		}
+	}
+	if len(ret) == 0 {
+		return append(ret, defaultHotPriorities...)
+	}
+	return ret
+}
+
+func (conf *hotRegionSchedulerConfig) IsStrictPickingStoreEnabled() bool {
+	conf.RLock()
+	defer conf.RUnlock()
+	return conf.StrictPickingStore
+}
+
 func (conf *hotRegionSchedulerConfig) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	router := mux.NewRouter()
 	router.HandleFunc("/list", conf.handleGetConfig).Methods("GET")
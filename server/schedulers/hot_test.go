@@ -15,11 +15,14 @@ package schedulers
 
 import (
 	"context"
+	"math"
 	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/pd/v4/pkg/mock/mockcluster"
+	"github.com/pingcap/pd/v4/pkg/mock/mockhbstream"
 	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
 	"github.com/pingcap/pd/v4/pkg/testutil"
 	"github.com/pingcap/pd/v4/server/core"
@@ -27,6 +30,7 @@ import (
 	"github.com/pingcap/pd/v4/server/schedule"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/statistics"
+	"github.com/pkg/errors"
 )
 
 func init() {
@@ -579,6 +583,48 @@ func (s *testHotReadRegionSchedulerSuite) TestByteRateOnly(c *C) {
 	hb.(*hotScheduler).clearPendingInfluence()
 }
 
+func (s *testHotReadRegionSchedulerSuite) TestRegionSplit(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opt := mockoption.NewScheduleOptions()
+	opt.HotRegionCacheHitsThreshold = 2
+	hb, err := schedule.CreateScheduler(HotReadRegionType, schedule.NewOperatorController(ctx, nil, nil), core.NewStorage(kv.NewMemoryKV()), nil)
+	c.Assert(err, IsNil)
+
+	tc := mockcluster.NewCluster(opt)
+	tc.AddRegionStore(1, 3)
+	tc.AddRegionStore(2, 2)
+	tc.AddRegionStore(3, 2)
+	tc.AddRegionStore(4, 0)
+	tc.UpdateStorageReadBytes(1, 7.5*MB*statistics.StoreHeartBeatReportInterval)
+	tc.UpdateStorageReadBytes(2, 4.9*MB*statistics.StoreHeartBeatReportInterval)
+	tc.UpdateStorageReadBytes(3, 4.5*MB*statistics.StoreHeartBeatReportInterval)
+	tc.UpdateStorageReadBytes(4, 0)
+
+	for i := 0; i < 3; i++ {
+		addRegionInfo(tc, read, []testRegionInfo{
+			{1, []uint64{1, 2, 3}, 512 * KB, 0},
+		})
+	}
+	c.Assert(hb.Schedule(tc), HasLen, 1)
+	hb.(*hotScheduler).clearPendingInfluence()
+
+	// Region 1 is split, so it is not hot until it stays hot for the hit
+	// threshold again.
+	split := func() {
+		region := tc.GetRegion(1).Clone(core.SetRegionVersion(1))
+		for _, item := range tc.HotCache.CheckRead(region, tc.StoresStats) {
+			tc.HotCache.Update(item)
+		}
+		tc.PutRegion(region)
+	}
+	split()
+	c.Assert(hb.Schedule(tc), HasLen, 0)
+	split()
+	split()
+	c.Assert(hb.Schedule(tc), HasLen, 1)
+}
+
 func (s *testHotReadRegionSchedulerSuite) TestWithKeyRate(c *C) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -632,6 +678,59 @@ func (s *testHotReadRegionSchedulerSuite) TestWithKeyRate(c *C) {
 	}
 }
 
+func (s *testHotReadRegionSchedulerSuite) TestWithCPUPriority(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	statistics.Denoising = false
+	opt := mockoption.NewScheduleOptions()
+	hb, err := schedule.CreateScheduler(HotReadRegionType, schedule.NewOperatorController(ctx, nil, nil), core.NewStorage(kv.NewMemoryKV()), nil)
+	c.Assert(err, IsNil)
+	opt.HotRegionCacheHitsThreshold = 0
+
+	tc := mockcluster.NewCluster(opt)
+	for id := uint64(1); id <= 4; id++ {
+		tc.AddRegionStore(id, 20)
+		// The byte rate and key rate are balanced.
+		tc.UpdateStorageReadStats(id, 9.5*MB*statistics.StoreHeartBeatReportInterval, 9.5*MB*statistics.StoreHeartBeatReportInterval)
+	}
+	// The CPU usage of store 1 is much higher than the others.
+	tc.UpdateStorageCPUUsage(1, 400)
+	tc.UpdateStorageCPUUsage(2, 100)
+	tc.UpdateStorageCPUUsage(3, 150)
+	tc.UpdateStorageCPUUsage(4, 200)
+
+	addRegionInfo(tc, read, []testRegionInfo{
+		{1, []uint64{1, 2, 3}, 0.5 * MB, 0.5 * MB},
+		{2, []uint64{1, 3, 4}, 0.5 * MB, 0.5 * MB},
+		{3, []uint64{2, 3, 4}, 0.5 * MB, 0.5 * MB},
+	})
+
+	// Nothing to do by the byte rate and key rate.
+	c.Assert(hb.Schedule(tc), HasLen, 0)
+
+	sche := hb.(schedule.ConfigurableScheduler)
+	c.Assert(errors.Cause(sche.UpdateConfig([]byte(`{"read-priorities": ["cpu", "foo"]}`))), Equals, schedule.ErrInvalidSchedulerConfig)
+	c.Assert(sche.UpdateConfig([]byte(`{"read-priorities": ["cpu", "byte"]}`)), IsNil)
+	c.Assert(hb.(*hotScheduler).conf.GetPriorities(read), DeepEquals, []string{cpuDim, byteDim})
+	c.Assert(hb.(*hotScheduler).conf.GetPriorities(write), DeepEquals, []string{byteDim, keyDim})
+	// The byte rate of store 1 is not higher than the expectation.
+	hb.(*hotScheduler).clearPendingInfluence()
+	c.Assert(hb.Schedule(tc), HasLen, 0)
+
+	c.Assert(sche.UpdateConfig([]byte(`{"strict-picking-store": false}`)), IsNil)
+	for i := 0; i < 20; i++ {
+		hb.(*hotScheduler).clearPendingInfluence()
+		ops := hb.Schedule(tc)
+		c.Assert(ops, HasLen, 1)
+		// Transfer the leader to store 2 with the lowest CPU usage.
+		testutil.CheckTransferLeader(c, ops[0], operator.OpHotRegion, 1, 2)
+		// The CPU usage of the region is estimated by its share of the key rate.
+		for p := range hb.(*hotScheduler).pendings[readLeader] {
+			c.Assert(math.Abs(p.origin.CPUUsage-400*0.5/9.5) < 1e-6, IsTrue)
+		}
+	}
+}
+
 func (s *testHotReadRegionSchedulerSuite) TestWithPendingInfluence(c *C) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -730,6 +829,65 @@ func (s *testHotReadRegionSchedulerSuite) TestWithPendingInfluence(c *C) {
 	}
 }
 
+var _ = Suite(&testSplitHotRegionSchedulerSuite{})
+
+type testSplitHotRegionSchedulerSuite struct{}
+
+func (s *testSplitHotRegionSchedulerSuite) TestSplit(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	oc := schedule.NewOperatorController(ctx, tc, mockhbstream.NewHeartbeatStream())
+	sche, err := schedule.CreateScheduler(SplitHotRegionType, oc, core.NewStorage(kv.NewMemoryKV()), schedule.ConfigSliceDecoder(SplitHotRegionType, nil))
+	c.Assert(err, IsNil)
+	c.Assert(sche.GetName(), Equals, SplitHotRegionName)
+	c.Assert(sche.(schedule.ConfigurableScheduler).UpdateConfig([]byte(`{"min-hot-degree": 2}`)), IsNil)
+
+	tc.AddRegionStore(1, 3)
+	tc.AddRegionStore(2, 2)
+	tc.AddRegionStore(3, 2)
+	//| store_id | read_bytes_rate |
+	//|----------|-----------------|
+	//|    1     |       7MB       |
+	//|    2     |       1MB       |
+	//|    3     |       1MB       |
+	tc.UpdateStorageReadBytes(1, 7*MB*statistics.StoreHeartBeatReportInterval)
+	tc.UpdateStorageReadBytes(2, 1*MB*statistics.StoreHeartBeatReportInterval)
+	tc.UpdateStorageReadBytes(3, 1*MB*statistics.StoreHeartBeatReportInterval)
+
+	// Region 1 alone is hotter than the average of the stores, so it is
+	// split after it stays hot for 2 rounds.
+	for i := 0; i < 3; i++ {
+		c.Assert(sche.Schedule(tc), HasLen, 0)
+		addRegionInfo(tc, read, []testRegionInfo{
+			{1, []uint64{1, 2, 3}, 6 * MB, 0},
+			{2, []uint64{2, 1, 3}, 512 * KB, 0},
+		})
+	}
+	ops := sche.Schedule(tc)
+	c.Assert(ops, HasLen, 1)
+	c.Assert(ops[0].RegionID(), Equals, uint64(1))
+	c.Assert(ops[0].Kind(), Equals, operator.OpSplit)
+	c.Assert(ops[0].Step(0).(operator.SplitRegion).Policy, Equals, pdpb.CheckPolicy_APPROXIMATE)
+	c.Assert(oc.AddOperator(ops[0]), IsTrue)
+	c.Assert(sche.IsScheduleAllowed(tc), IsFalse)
+	oc.RemoveOperator(ops[0])
+	c.Assert(sche.IsScheduleAllowed(tc), IsTrue)
+
+	// The key range of region 1 is cooling down.
+	c.Assert(sche.Schedule(tc), HasLen, 0)
+	c.Assert(sche.(schedule.ConfigurableScheduler).UpdateConfig([]byte(`{"cool-down-seconds": 0, "split-policy": "scan"}`)), IsNil)
+	ops = sche.Schedule(tc)
+	c.Assert(ops, HasLen, 1)
+	c.Assert(ops[0].Step(0).(operator.SplitRegion).Policy, Equals, pdpb.CheckPolicy_SCAN)
+
+	// Moving region 1 is enough when the other stores are hot too.
+	tc.UpdateStorageReadBytes(2, 6*MB*statistics.StoreHeartBeatReportInterval)
+	tc.UpdateStorageReadBytes(3, 6*MB*statistics.StoreHeartBeatReportInterval)
+	c.Assert(sche.Schedule(tc), HasLen, 0)
+}
+
 var _ = Suite(&testHotCacheSuite{})
 
 type testHotCacheSuite struct{}
@@ -59,8 +59,8 @@ func init() {
 }
 
 type labelSchedulerConfig struct {
-	Name   string          `json:"name"`
-	Ranges []core.KeyRange `json:"ranges"`
+	Name   string          `json:"name" schema:"readonly"`
+	Ranges []core.KeyRange `json:"ranges" schema:"readonly"`
 }
 
 type labelScheduler struct {
@@ -96,6 +96,14 @@ func (s *labelScheduler) EncodeConfig() ([]byte, error) {
 	return schedule.EncodeConfig(s.conf)
 }
 
+func (s *labelScheduler) GetConfigSchema() *schedule.ConfigSchema {
+	return schedule.NewConfigSchema(s.conf)
+}
+
+func (s *labelScheduler) UpdateConfig(data []byte) error {
+	return schedule.ApplyConfigUpdate(s.conf, data)
+}
+
 func (s *labelScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
 	return s.OpController.OperatorCount(operator.OpLeader) < cluster.GetLeaderScheduleLimit()
 }
@@ -125,7 +133,7 @@ func (s *labelScheduler) Schedule(cluster opt.Cluster) []*operator.Operator {
 				excludeStores[p.GetStoreId()] = struct{}{}
 			}
 			f := filter.NewExcludedFilter(s.GetName(), nil, excludeStores)
-			target := s.selector.SelectTarget(cluster, cluster.GetFollowerStores(region), f)
+			target := s.selector.SelectTarget(cluster, cluster.GetFollowerStores(region), f, filter.NewLeaderStoreFilter(s.GetName(), cluster, region))
 			if target == nil {
 				log.Debug("label scheduler no target found for region", zap.Uint64("region-id", region.GetID()))
 				schedulerCounter.WithLabelValues(s.GetName(), "no-target").Inc()
@@ -61,8 +61,8 @@ func init() {
 }
 
 type randomMergeSchedulerConfig struct {
-	Name   string          `json:"name"`
-	Ranges []core.KeyRange `json:"ranges"`
+	Name   string          `json:"name" schema:"readonly"`
+	Ranges []core.KeyRange `json:"ranges" schema:"readonly"`
 }
 
 type randomMergeScheduler struct {
@@ -97,6 +97,14 @@ func (s *randomMergeScheduler) EncodeConfig() ([]byte, error) {
 	return schedule.EncodeConfig(s.conf)
 }
 
+func (s *randomMergeScheduler) GetConfigSchema() *schedule.ConfigSchema {
+	return schedule.NewConfigSchema(s.conf)
+}
+
+func (s *randomMergeScheduler) UpdateConfig(data []byte) error {
+	return schedule.ApplyConfigUpdate(s.conf, data)
+}
+
 func (s *randomMergeScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
 	return s.OpController.OperatorCount(operator.OpMerge) < cluster.GetMergeScheduleLimit()
 }
@@ -74,9 +74,9 @@ const (
 type scatterRangeSchedulerConfig struct {
 	mu        sync.RWMutex
 	storage   *core.Storage
-	RangeName string `json:"range-name"`
-	StartKey  string `json:"start-key"`
-	EndKey    string `json:"end-key"`
+	RangeName string `json:"range-name" schema:"readonly"`
+	StartKey  string `json:"start-key" schema:"readonly"`
+	EndKey    string `json:"end-key" schema:"readonly"`
 }
 
 func (conf *scatterRangeSchedulerConfig) BuildWithArgs(args []string) error {
@@ -192,6 +192,16 @@ func (l *scatterRangeScheduler) EncodeConfig() ([]byte, error) {
 	return schedule.EncodeConfig(l.config)
 }
 
+func (l *scatterRangeScheduler) GetConfigSchema() *schedule.ConfigSchema {
+	return schedule.NewConfigSchema(l.config)
+}
+
+func (l *scatterRangeScheduler) UpdateConfig(data []byte) error {
+	l.config.mu.Lock()
+	defer l.config.mu.Unlock()
+	return schedule.ApplyConfigUpdate(l.config, data)
+}
+
 func (l *scatterRangeScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
 	return l.OpController.OperatorCount(operator.OpRange) < cluster.GetRegionScheduleLimit()
 }
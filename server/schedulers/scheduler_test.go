@@ -15,6 +15,7 @@ package schedulers
 
 import (
 	"context"
+	"sort"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
@@ -25,10 +26,12 @@ import (
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/kv"
 	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
 	"github.com/pingcap/pd/v4/server/schedule/placement"
 	"github.com/pingcap/pd/v4/server/statistics"
+	"github.com/pkg/errors"
 )
 
 var _ = Suite(&testShuffleLeaderSuite{})
@@ -63,6 +66,33 @@ func (s *testShuffleLeaderSuite) TestShuffle(c *C) {
 	}
 }
 
+func (s *testShuffleLeaderSuite) TestLeaderStoreLabel(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+
+	sl, err := schedule.CreateScheduler(ShuffleLeaderType, schedule.NewOperatorController(ctx, nil, nil), core.NewStorage(kv.NewMemoryKV()), schedule.ConfigSliceDecoder(ShuffleLeaderType, []string{"", ""}))
+	c.Assert(err, IsNil)
+
+	tc.AddLabelsStore(1, 1, map[string]string{"zone": "z1"})
+	tc.AddLabelsStore(2, 1, map[string]string{"zone": "z2"})
+	tc.AddLabelsStore(3, 1, map[string]string{"zone": "z2"})
+	tc.AddLeaderRegion(1, 1, 2, 3)
+	tc.AddLeaderRegion(2, 2, 1, 3)
+	c.Assert(tc.GetRegionLabeler().SetLabelRule(&labeler.LabelRule{
+		ID:     "rule1",
+		Labels: []*labeler.RegionLabel{{Key: labeler.LeaderStoreLabelKey, Value: "zone=z1"}},
+	}), IsNil)
+
+	// The leaders are only shuffled to store 1.
+	for i := 0; i < 10; i++ {
+		if ops := sl.Schedule(tc); ops != nil {
+			testutil.CheckTransferLeader(c, ops[0], operator.OpAdmin, 2, 1)
+		}
+	}
+}
+
 var _ = Suite(&testBalanceAdjacentRegionSuite{})
 
 type testBalanceAdjacentRegionSuite struct {
@@ -210,8 +240,11 @@ func (s *testScatterRegionSuite) checkOperator(op *operator.Operator, c *C) {
 }
 
 func (s *testScatterRegionSuite) scatter(c *C, numStores, numRegions uint64) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 	opt := mockoption.NewScheduleOptions()
 	tc := mockcluster.NewCluster(opt)
+	oc := schedule.NewOperatorController(ctx, tc, mockhbstream.NewHeartbeatStream())
 
 	// Add stores 1~6.
 	for i := uint64(1); i <= numStores; i++ {
@@ -226,11 +259,11 @@ func (s *testScatterRegionSuite) scatter(c *C, numStores, numRegions uint64) {
 		tc.AddLeaderRegion(i, seq.next(), seq.next(), seq.next())
 	}
 
-	scatterer := schedule.NewRegionScatterer(tc)
+	scatterer := schedule.NewRegionScatterer(tc, oc, core.NewStorage(kv.NewMemoryKV()))
 
 	for i := uint64(1); i <= numRegions; i++ {
 		region := tc.GetRegion(i)
-		if op, _ := scatterer.Scatter(region); op != nil {
+		if op, _ := scatterer.Scatter(region, ""); op != nil {
 			s.checkOperator(op, c)
 			schedule.ApplyOperator(tc, op)
 		}
@@ -270,16 +303,167 @@ func (s *testScatterRegionSuite) TestStoreLimit(c *C) {
 		tc.AddLeaderRegion(i, seq.next(), seq.next(), seq.next())
 	}
 
-	scatterer := schedule.NewRegionScatterer(tc)
+	scatterer := schedule.NewRegionScatterer(tc, oc, core.NewStorage(kv.NewMemoryKV()))
 
 	for i := uint64(1); i <= 5; i++ {
 		region := tc.GetRegion(i)
-		if op, _ := scatterer.Scatter(region); op != nil {
-			c.Assert(oc.AddWaitingOperator(op), Equals, 1)
+		op, err := scatterer.Scatter(region, "")
+		c.Assert(err, IsNil)
+		if op != nil {
+			c.Assert(oc.GetOperator(i), Equals, op)
 		}
 	}
 }
 
+func (s *testScatterRegionSuite) TestRegionLabel(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	oc := schedule.NewOperatorController(ctx, tc, mockhbstream.NewHeartbeatStream())
+	for i := uint64(1); i <= 6; i++ {
+		tc.AddRegionStore(i, 0)
+	}
+	tc.AddLeaderRegion(1, 1, 2, 3)
+	tc.GetRegionLabeler().SetLabelRule(&labeler.LabelRule{
+		ID:     "test",
+		Labels: []*labeler.RegionLabel{{Key: labeler.ScheduleKey, Value: labeler.DenyValue}},
+	})
+
+	scatterer := schedule.NewRegionScatterer(tc, oc, core.NewStorage(kv.NewMemoryKV()))
+	op, err := scatterer.Scatter(tc.GetRegion(1), "")
+	c.Assert(op, IsNil)
+	c.Assert(err, ErrorMatches, ".*labeled to deny scheduling.*")
+}
+
+func (s *testScatterRegionSuite) TestLeaderStoreLabel(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	oc := schedule.NewOperatorController(ctx, tc, mockhbstream.NewHeartbeatStream())
+	tc.AddLabelsStore(1, 0, map[string]string{"zone": "z1"})
+	tc.AddLabelsStore(2, 0, map[string]string{"zone": "z1"})
+	tc.AddLabelsStore(3, 0, map[string]string{"zone": "z2"})
+	for i := uint64(1); i <= 6; i++ {
+		tc.AddLeaderRegion(i, 1, 2, 3)
+	}
+	tc.GetRegionLabeler().SetLabelRule(&labeler.LabelRule{
+		ID:     "test",
+		Labels: []*labeler.RegionLabel{{Key: labeler.LeaderStoreLabelKey, Value: "zone=z1"}},
+	})
+
+	// The leaders are balanced among the stores which they are allowed to
+	// be on.
+	scatterer := schedule.NewRegionScatterer(tc, oc, core.NewStorage(kv.NewMemoryKV()))
+	leaders := make(map[uint64]int)
+	for i := uint64(1); i <= 6; i++ {
+		op, err := scatterer.Scatter(tc.GetRegion(i), "")
+		c.Assert(err, IsNil)
+		if op != nil {
+			schedule.ApplyOperator(tc, op)
+		}
+		leaders[tc.GetRegion(i).GetLeader().GetStoreId()]++
+	}
+	c.Assert(leaders, DeepEquals, map[uint64]int{1: 3, 2: 3})
+}
+
+func (s *testScatterRegionSuite) TestScatterGroup(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opt := mockoption.NewScheduleOptions()
+	// All the operators are added at once, so the store limit should not be
+	// reached.
+	opt.StoreBalanceRate = 1000
+	tc := mockcluster.NewCluster(opt)
+	oc := schedule.NewOperatorController(ctx, tc, mockhbstream.NewHeartbeatStream())
+	for i := uint64(1); i <= 5; i++ {
+		tc.AddRegionStore(i, 0)
+	}
+	// All the regions are on stores 1, 2 and 3 with the leaders on store 1.
+	for i := uint64(1); i <= 20; i++ {
+		tc.AddLeaderRegion(i, 1, 2, 3)
+	}
+
+	scatterer := schedule.NewRegionScatterer(tc, oc, core.NewStorage(kv.NewMemoryKV()))
+	groups := map[string][]uint64{"a": {}, "b": {}}
+	for i := uint64(1); i <= 20; i++ {
+		group := "a"
+		if i > 10 {
+			group = "b"
+		}
+		groups[group] = append(groups[group], i)
+		op, err := scatterer.Scatter(tc.GetRegion(i), group)
+		c.Assert(err, IsNil)
+		if op != nil {
+			s.checkOperator(op, c)
+			schedule.ApplyOperator(tc, op)
+		}
+	}
+
+	// The peers and the leaders of each group are balanced.
+	for _, ids := range groups {
+		peers, leaders := make(map[uint64]int), make(map[uint64]int)
+		for _, id := range ids {
+			region := tc.GetRegion(id)
+			for _, peer := range region.GetPeers() {
+				peers[peer.GetStoreId()]++
+			}
+			leaders[region.GetLeader().GetStoreId()]++
+		}
+		for i := uint64(1); i <= 5; i++ {
+			c.Assert(peers[i], Equals, 6)
+			c.Assert(leaders[i], Equals, 2)
+		}
+	}
+}
+
+func (s *testScatterRegionSuite) TestAddOperatorFailed(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	oc := schedule.NewOperatorController(ctx, tc, mockhbstream.NewHeartbeatStream())
+	for i := uint64(1); i <= 6; i++ {
+		tc.AddRegionStore(i, 0)
+	}
+	for i := uint64(1); i <= 3; i++ {
+		tc.AddLeaderRegion(i, 1, 2, 3)
+	}
+	addedStores := func(op *operator.Operator) []uint64 {
+		var stores []uint64
+		for i := 0; i < op.Len(); i++ {
+			if step, ok := op.Step(i).(operator.AddLightLearner); ok {
+				stores = append(stores, step.ToStore)
+			}
+		}
+		sort.Slice(stores, func(i, j int) bool { return stores[i] < stores[j] })
+		return stores
+	}
+
+	// Region 1 stays in place.
+	scatterer := schedule.NewRegionScatterer(tc, oc, core.NewStorage(kv.NewMemoryKV()))
+	op, err := scatterer.Scatter(tc.GetRegion(1), "a")
+	c.Assert(err, IsNil)
+	c.Assert(op, IsNil)
+
+	// Region 2 already has an operator, so its scatter operator fails to be
+	// added and is not counted in the group.
+	region := tc.GetRegion(2)
+	existing := operator.NewOperator("test", "test", 2, region.GetRegionEpoch(), operator.OpRegion, operator.TransferLeader{FromStore: 1, ToStore: 2})
+	existing.SetPriorityLevel(core.HighPriority)
+	c.Assert(oc.AddOperator(existing), IsTrue)
+	op, err = scatterer.Scatter(region, "a")
+	c.Assert(op, IsNil)
+	c.Assert(errors.Cause(err), Equals, schedule.ErrAddScatterOperator)
+
+	// Region 3 is scattered to the stores which region 2 would be.
+	op, err = scatterer.Scatter(tc.GetRegion(3), "a")
+	c.Assert(err, IsNil)
+	c.Assert(op, NotNil)
+	c.Assert(addedStores(op), DeepEquals, []uint64{4, 5, 6})
+}
+
 var _ = Suite(&testRejectLeaderSuite{})
 
 type testRejectLeaderSuite struct{}
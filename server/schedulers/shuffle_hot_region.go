@@ -16,6 +16,7 @@ package schedulers
 import (
 	"math/rand"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/pingcap/kvproto/pkg/metapb"
@@ -65,10 +66,17 @@ func init() {
 }
 
 type shuffleHotRegionSchedulerConfig struct {
-	Name  string `json:"name"`
+	mu    sync.RWMutex
+	Name  string `json:"name" schema:"readonly"`
 	Limit uint64 `json:"limit"`
 }
 
+func (conf *shuffleHotRegionSchedulerConfig) getLimit() uint64 {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	return conf.Limit
+}
+
 // ShuffleHotRegionScheduler mainly used to test.
 // It will randomly pick a hot peer, and move the peer
 // to a random store, and then transfer the leader to
@@ -105,11 +113,23 @@ func (s *shuffleHotRegionScheduler) GetType() string {
 }
 
 func (s *shuffleHotRegionScheduler) EncodeConfig() ([]byte, error) {
+	s.conf.mu.RLock()
+	defer s.conf.mu.RUnlock()
 	return schedule.EncodeConfig(s.conf)
 }
 
+func (s *shuffleHotRegionScheduler) GetConfigSchema() *schedule.ConfigSchema {
+	return schedule.NewConfigSchema(s.conf)
+}
+
+func (s *shuffleHotRegionScheduler) UpdateConfig(data []byte) error {
+	s.conf.mu.Lock()
+	defer s.conf.mu.Unlock()
+	return schedule.ApplyConfigUpdate(s.conf, data)
+}
+
 func (s *shuffleHotRegionScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
-	return s.OpController.OperatorCount(operator.OpHotRegion) < s.conf.Limit &&
+	return s.OpController.OperatorCount(operator.OpHotRegion) < s.conf.getLimit() &&
 		s.OpController.OperatorCount(operator.OpRegion) < cluster.GetRegionScheduleLimit() &&
 		s.OpController.OperatorCount(operator.OpLeader) < cluster.GetLeaderScheduleLimit()
 }
@@ -128,6 +148,8 @@ func (s *shuffleHotRegionScheduler) dispatch(typ rwType, cluster opt.Cluster) []
 		s.stLoadInfos[readLeader] = summaryStoresLoad(
 			storesStats.GetStoresBytesReadStat(),
 			storesStats.GetStoresKeysReadStat(),
+			storesStats.GetStoresCPUUsage(),
+			storesStats.GetStoresDiskReadRate(),
 			map[uint64]Influence{},
 			cluster.RegionReadStats(),
 			minHotDegree,
@@ -137,6 +159,8 @@ func (s *shuffleHotRegionScheduler) dispatch(typ rwType, cluster opt.Cluster) []
 		s.stLoadInfos[writeLeader] = summaryStoresLoad(
 			storesStats.GetStoresBytesWriteStat(),
 			storesStats.GetStoresKeysWriteStat(),
+			storesStats.GetStoresCPUUsage(),
+			storesStats.GetStoresDiskWriteRate(),
 			map[uint64]Influence{},
 			cluster.RegionWriteStats(),
 			minHotDegree,
@@ -173,6 +197,7 @@ func (s *shuffleHotRegionScheduler) randomSchedule(cluster opt.Cluster, loadDeta
 
 		filters := []filter.Filter{
 			filter.StoreStateFilter{ActionScope: s.GetName(), MoveRegion: true},
+			filter.NewDiskStateFilter(s.GetName(), true),
 			filter.NewExcludedFilter(s.GetName(), srcRegion.GetStoreIds(), srcRegion.GetStoreIds()),
 			scoreGuard,
 		}
@@ -59,8 +59,8 @@ func init() {
 }
 
 type shuffleLeaderSchedulerConfig struct {
-	Name   string          `json:"name"`
-	Ranges []core.KeyRange `json:"ranges"`
+	Name   string          `json:"name" schema:"readonly"`
+	Ranges []core.KeyRange `json:"ranges" schema:"readonly"`
 }
 
 type shuffleLeaderScheduler struct {
@@ -96,6 +96,14 @@ func (s *shuffleLeaderScheduler) EncodeConfig() ([]byte, error) {
 	return schedule.EncodeConfig(s.conf)
 }
 
+func (s *shuffleLeaderScheduler) GetConfigSchema() *schedule.ConfigSchema {
+	return schedule.NewConfigSchema(s.conf)
+}
+
+func (s *shuffleLeaderScheduler) UpdateConfig(data []byte) error {
+	return schedule.ApplyConfigUpdate(s.conf, data)
+}
+
 func (s *shuffleLeaderScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
 	return s.OpController.OperatorCount(operator.OpLeader) < cluster.GetLeaderScheduleLimit()
 }
@@ -116,6 +124,10 @@ func (s *shuffleLeaderScheduler) Schedule(cluster opt.Cluster) []*operator.Opera
 		schedulerCounter.WithLabelValues(s.GetName(), "no-follower").Inc()
 		return nil
 	}
+	if !filter.Target(cluster, targetStore, []filter.Filter{filter.NewLeaderStoreFilter(s.GetName(), cluster, region)}) {
+		schedulerCounter.WithLabelValues(s.GetName(), "leader-store-deny").Inc()
+		return nil
+	}
 	op, err := operator.CreateTransferLeaderOperator(ShuffleLeaderType, cluster, region, region.GetLeader().GetId(), targetStore.GetID(), operator.OpAdmin)
 	if err != nil {
 		log.Debug("fail to create shuffle leader operator", zap.Error(err))
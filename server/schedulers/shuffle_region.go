@@ -69,6 +69,7 @@ type shuffleRegionScheduler struct {
 func newShuffleRegionScheduler(opController *schedule.OperatorController, conf *shuffleRegionSchedulerConfig) schedule.Scheduler {
 	filters := []filter.Filter{
 		filter.StoreStateFilter{ActionScope: ShuffleRegionName, MoveRegion: true},
+		filter.NewDiskStateFilter(ShuffleRegionName, true),
 		filter.NewSpecialUseFilter(ShuffleRegionName),
 	}
 	base := NewBaseScheduler(opController)
@@ -95,6 +96,16 @@ func (s *shuffleRegionScheduler) EncodeConfig() ([]byte, error) {
 	return s.conf.EncodeConfig()
 }
 
+func (s *shuffleRegionScheduler) GetConfigSchema() *schedule.ConfigSchema {
+	return schedule.NewConfigSchema(s.conf)
+}
+
+func (s *shuffleRegionScheduler) UpdateConfig(data []byte) error {
+	s.conf.Lock()
+	defer s.conf.Unlock()
+	return schedule.ApplyConfigUpdate(s.conf, data)
+}
+
 func (s *shuffleRegionScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
 	return s.OpController.OperatorCount(operator.OpRegion) < cluster.GetRegionScheduleLimit()
 }
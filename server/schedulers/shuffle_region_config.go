@@ -38,8 +38,8 @@ type shuffleRegionSchedulerConfig struct {
 	sync.RWMutex
 	storage *core.Storage
 
-	Ranges []core.KeyRange `json:"ranges"`
-	Roles  []string        `json:"roles"` // can include `leader`, `follower`, `learner`.
+	Ranges []core.KeyRange `json:"ranges" schema:"readonly"`
+	Roles  []string        `json:"roles" schema:"enum=leader|follower|learner"`
 }
 
 func (conf *shuffleRegionSchedulerConfig) EncodeConfig() ([]byte, error) {
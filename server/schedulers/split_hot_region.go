@@ -0,0 +1,285 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedulers
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pingcap/pd/v4/server/statistics"
+	"go.uber.org/zap"
+)
+
+const (
+	// SplitHotRegionName is split hot region scheduler name.
+	SplitHotRegionName = "split-hot-region-scheduler"
+	// SplitHotRegionType is split hot region scheduler type.
+	SplitHotRegionType = "split-hot-region"
+
+	splitPolicyApproximate = "approximate"
+	splitPolicyScan        = "scan"
+)
+
+func init() {
+	schedule.RegisterSliceDecoderBuilder(SplitHotRegionType, func(args []string) schedule.ConfigDecoder {
+		return func(v interface{}) error {
+			conf, ok := v.(*splitHotRegionSchedulerConfig)
+			if !ok {
+				return ErrScheduleConfigNotExist
+			}
+			if len(args) == 1 {
+				limit, err := strconv.ParseUint(args[0], 10, 64)
+				if err != nil {
+					return err
+				}
+				conf.SplitLimit = limit
+			}
+			return nil
+		}
+	})
+
+	schedule.RegisterScheduler(SplitHotRegionType, func(opController *schedule.OperatorController, storage *core.Storage, decoder schedule.ConfigDecoder) (schedule.Scheduler, error) {
+		conf := initSplitHotRegionSchedulerConfig()
+		if err := decoder(conf); err != nil {
+			return nil, err
+		}
+		conf.Name = SplitHotRegionName
+		return newSplitHotRegionScheduler(opController, conf), nil
+	})
+}
+
+func initSplitHotRegionSchedulerConfig() *splitHotRegionSchedulerConfig {
+	return &splitHotRegionSchedulerConfig{
+		MinHotDegree:    10,
+		LoadRatio:       1,
+		SplitPolicy:     splitPolicyApproximate,
+		SplitLimit:      1,
+		CoolDownSeconds: 300,
+	}
+}
+
+type splitHotRegionSchedulerConfig struct {
+	mu   sync.RWMutex
+	Name string `json:"name" schema:"readonly"`
+	// MinHotDegree is how many rounds a region must stay hot before it is split.
+	MinHotDegree int `json:"min-hot-degree" schema:"min=1"`
+	// A region is too hot to move if its byte rate or key rate exceeds
+	// LoadRatio times the average of the stores, since any store it is moved to
+	// is hotter than the average.
+	LoadRatio   float64 `json:"load-ratio" schema:"min=0"`
+	SplitPolicy string  `json:"split-policy" schema:"enum=approximate|scan"`
+	// SplitLimit is the max number of the split operators running at the same time.
+	SplitLimit uint64 `json:"split-limit"`
+	// CoolDownSeconds is how long the key range of a split region is not split
+	// again, so that the halves are balanced by the hot region scheduler first.
+	CoolDownSeconds uint64 `json:"cool-down-seconds"`
+}
+
+func (conf *splitHotRegionSchedulerConfig) clone() *splitHotRegionSchedulerConfig {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	return &splitHotRegionSchedulerConfig{
+		Name:            conf.Name,
+		MinHotDegree:    conf.MinHotDegree,
+		LoadRatio:       conf.LoadRatio,
+		SplitPolicy:     conf.SplitPolicy,
+		SplitLimit:      conf.SplitLimit,
+		CoolDownSeconds: conf.CoolDownSeconds,
+	}
+}
+
+func (conf *splitHotRegionSchedulerConfig) getSplitLimit() uint64 {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	return conf.SplitLimit
+}
+
+// splitRecord is the key range of a region split by the scheduler.
+type splitRecord struct {
+	startKey, endKey []byte
+	splitTime        time.Time
+}
+
+// splitHotRegionScheduler splits the regions which stay hot for a long time
+// and are too hot to be balanced by moving them. The halves are left to the
+// hot region scheduler.
+type splitHotRegionScheduler struct {
+	*BaseScheduler
+	conf *splitHotRegionSchedulerConfig
+
+	mu     sync.Mutex
+	splits []*splitRecord
+}
+
+func newSplitHotRegionScheduler(opController *schedule.OperatorController, conf *splitHotRegionSchedulerConfig) schedule.Scheduler {
+	return &splitHotRegionScheduler{
+		BaseScheduler: NewBaseScheduler(opController),
+		conf:          conf,
+	}
+}
+
+func (s *splitHotRegionScheduler) GetName() string {
+	return s.conf.Name
+}
+
+func (s *splitHotRegionScheduler) GetType() string {
+	return SplitHotRegionType
+}
+
+func (s *splitHotRegionScheduler) EncodeConfig() ([]byte, error) {
+	s.conf.mu.RLock()
+	defer s.conf.mu.RUnlock()
+	return schedule.EncodeConfig(s.conf)
+}
+
+func (s *splitHotRegionScheduler) GetConfigSchema() *schedule.ConfigSchema {
+	return schedule.NewConfigSchema(s.conf)
+}
+
+func (s *splitHotRegionScheduler) UpdateConfig(data []byte) error {
+	s.conf.mu.Lock()
+	defer s.conf.mu.Unlock()
+	return schedule.ApplyConfigUpdate(s.conf, data)
+}
+
+func (s *splitHotRegionScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
+	return s.OpController.OperatorCount(operator.OpSplit) < s.conf.getSplitLimit()
+}
+
+func (s *splitHotRegionScheduler) Schedule(cluster opt.Cluster) []*operator.Operator {
+	schedulerCounter.WithLabelValues(s.GetName(), "schedule").Inc()
+	conf := s.conf.clone()
+	s.expireSplits(time.Now(), time.Duration(conf.CoolDownSeconds)*time.Second)
+
+	storesStats := cluster.GetStoresStats()
+	var (
+		target   *statistics.HotPeerStat
+		maxRatio float64
+		kind     string
+	)
+	for _, typ := range []rwType{read, write} {
+		var byteRates, keyRates map[uint64]float64
+		var peers map[uint64][]*statistics.HotPeerStat
+		if typ == read {
+			byteRates, keyRates = storesStats.GetStoresBytesReadStat(), storesStats.GetStoresKeysReadStat()
+			peers = cluster.RegionReadStats()
+		} else {
+			byteRates, keyRates = storesStats.GetStoresBytesWriteStat(), storesStats.GetStoresKeysWriteStat()
+			peers = cluster.RegionWriteStats()
+		}
+		avgByteRate, avgKeyRate := averageLoad(byteRates), averageLoad(keyRates)
+		for _, storePeers := range peers {
+			for _, peer := range storePeers {
+				// The read flow is served by the leader.
+				if peer.HotDegree < conf.MinHotDegree || (typ == read && !peer.IsLeader()) {
+					continue
+				}
+				ratio := 0.0
+				if avgByteRate > 0 {
+					ratio = peer.GetByteRate() / avgByteRate
+				}
+				if avgKeyRate > 0 && peer.GetKeyRate()/avgKeyRate > ratio {
+					ratio = peer.GetKeyRate() / avgKeyRate
+				}
+				if ratio <= conf.LoadRatio || ratio <= maxRatio {
+					continue
+				}
+				if !s.isRegionSplittable(cluster, peer.RegionID) {
+					continue
+				}
+				target, maxRatio, kind = peer, ratio, typ.String()
+			}
+		}
+	}
+	if target == nil {
+		schedulerCounter.WithLabelValues(s.GetName(), "no-too-hot-region").Inc()
+		return nil
+	}
+
+	region := cluster.GetRegion(target.RegionID)
+	policy := pdpb.CheckPolicy_APPROXIMATE
+	if conf.SplitPolicy == splitPolicyScan {
+		policy = pdpb.CheckPolicy_SCAN
+	}
+	op := operator.CreateSplitRegionOperator("split-hot-region", region, 0, policy, nil)
+	op.Counters = append(op.Counters,
+		schedulerCounter.WithLabelValues(s.GetName(), "new-operator"),
+		splitHotRegionCounter.WithLabelValues(kind, strconv.FormatUint(target.StoreID, 10)),
+	)
+	s.mu.Lock()
+	s.splits = append(s.splits, &splitRecord{
+		startKey:  region.GetStartKey(),
+		endKey:    region.GetEndKey(),
+		splitTime: time.Now(),
+	})
+	s.mu.Unlock()
+	log.Info("split the region too hot to move",
+		zap.Uint64("region-id", region.GetID()),
+		zap.String("type", kind),
+		zap.Int("hot-degree", target.HotDegree),
+		zap.Float64("load-ratio", maxRatio))
+	return []*operator.Operator{op}
+}
+
+func (s *splitHotRegionScheduler) isRegionSplittable(cluster opt.Cluster, regionID uint64) bool {
+	region := cluster.GetRegion(regionID)
+	if region == nil || !opt.IsRegionHealthy(cluster, region) || !opt.IsRegionScheduleAllowed(cluster, region) {
+		return false
+	}
+	if s.OpController.GetOperator(regionID) != nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.splits {
+		if (len(r.endKey) == 0 || string(region.GetStartKey()) < string(r.endKey)) &&
+			(len(region.GetEndKey()) == 0 || string(r.startKey) < string(region.GetEndKey())) {
+			schedulerCounter.WithLabelValues(s.GetName(), "cool-down").Inc()
+			return false
+		}
+	}
+	return true
+}
+
+// expireSplits drops the split key ranges older than the cool down time.
+func (s *splitHotRegionScheduler) expireSplits(now time.Time, coolDown time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	splits := s.splits[:0]
+	for _, r := range s.splits {
+		if now.Sub(r.splitTime) < coolDown {
+			splits = append(splits, r)
+		}
+	}
+	s.splits = splits
+}
+
+func averageLoad(loads map[uint64]float64) float64 {
+	if len(loads) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, load := range loads {
+		sum += load
+	}
+	return sum / float64(len(loads))
+}
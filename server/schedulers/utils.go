@@ -22,6 +22,7 @@ import (
 	"github.com/montanaflynn/stats"
 	"github.com/pingcap/log"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/explain"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
 	"github.com/pingcap/pd/v4/server/statistics"
@@ -78,6 +79,15 @@ func shouldBalance(cluster opt.Cluster, source, target *core.StoreInfo, region *
 	}
 	// Make sure after move, source score is still greater than target score.
 	shouldBalance := sourceScore > targetScore
+	explain.RecorderOf(cluster, scheduleName).Compare(&explain.Comparison{
+		RegionID:    region.GetID(),
+		Kind:        kind.Resource.String(),
+		SourceID:    sourceID,
+		TargetID:    targetID,
+		SourceScore: sourceScore,
+		TargetScore: targetScore,
+		Balance:     shouldBalance,
+	})
 
 	if !shouldBalance {
 		log.Debug("skip balance "+kind.Resource.String(),
@@ -165,12 +175,16 @@ func getKeyRanges(args []string) ([]core.KeyRange, error) {
 type Influence struct {
 	ByteRate float64
 	KeyRate  float64
+	CPUUsage float64
+	DiskRate float64
 	Count    float64
 }
 
 func (infl Influence) add(rhs *Influence, w float64) Influence {
 	infl.ByteRate += rhs.ByteRate * w
 	infl.KeyRate += rhs.KeyRate * w
+	infl.CPUUsage += rhs.CPUUsage * w
+	infl.DiskRate += rhs.DiskRate * w
 	infl.Count += rhs.Count * w
 	return infl
 }
@@ -204,13 +218,28 @@ func summaryPendingInfluence(pendings map[*pendingInfluence]struct{}, f func(*op
 	return ret
 }
 
+// The dimensions of the store load which can be balanced by the hot region
+// scheduler. The CPU usage and the disk IO rate are reported by the store
+// heartbeats, the share of a hot peer is estimated by its key rate and byte
+// rate respectively.
+const (
+	byteDim = "byte"
+	keyDim  = "key"
+	cpuDim  = "cpu"
+	diskDim = "disk"
+)
+
 type storeLoad struct {
 	ByteRate float64
 	KeyRate  float64
+	CPUUsage float64
+	DiskRate float64
 	Count    float64
 
 	ExpByteRate float64
 	ExpKeyRate  float64
+	ExpCPUUsage float64
+	ExpDiskRate float64
 	ExpCount    float64
 }
 
@@ -218,6 +247,8 @@ func (load *storeLoad) ToLoadPred(infl Influence) *storeLoadPred {
 	future := *load
 	future.ByteRate += infl.ByteRate
 	future.KeyRate += infl.KeyRate
+	future.CPUUsage += infl.CPUUsage
+	future.DiskRate += infl.DiskRate
 	future.Count += infl.Count
 	return &storeLoadPred{
 		Current: *load,
@@ -233,10 +264,46 @@ func stLdKeyRate(ld *storeLoad) float64 {
 	return ld.KeyRate
 }
 
+func stLdCPUUsage(ld *storeLoad) float64 {
+	return ld.CPUUsage
+}
+
+func stLdDiskRate(ld *storeLoad) float64 {
+	return ld.DiskRate
+}
+
 func stLdCount(ld *storeLoad) float64 {
 	return ld.Count
 }
 
+// stLdDim returns the accessor of the load of the dimension.
+func stLdDim(dim string) func(ld *storeLoad) float64 {
+	switch dim {
+	case keyDim:
+		return stLdKeyRate
+	case cpuDim:
+		return stLdCPUUsage
+	case diskDim:
+		return stLdDiskRate
+	default:
+		return stLdByteRate
+	}
+}
+
+// stLdExpDim returns the accessor of the expected load of the dimension.
+func stLdExpDim(dim string) func(ld *storeLoad) float64 {
+	switch dim {
+	case keyDim:
+		return func(ld *storeLoad) float64 { return ld.ExpKeyRate }
+	case cpuDim:
+		return func(ld *storeLoad) float64 { return ld.ExpCPUUsage }
+	case diskDim:
+		return func(ld *storeLoad) float64 { return ld.ExpDiskRate }
+	default:
+		return func(ld *storeLoad) float64 { return ld.ExpByteRate }
+	}
+}
+
 type storeLoadCmp func(ld1, ld2 *storeLoad) int
 
 func negLoadCmp(cmp storeLoadCmp) storeLoadCmp {
@@ -291,6 +358,8 @@ func (lp *storeLoadPred) diff() *storeLoad {
 	return &storeLoad{
 		ByteRate: mx.ByteRate - mn.ByteRate,
 		KeyRate:  mx.KeyRate - mn.KeyRate,
+		CPUUsage: mx.CPUUsage - mn.CPUUsage,
+		DiskRate: mx.DiskRate - mn.DiskRate,
 		Count:    mx.Count - mn.Count,
 	}
 }
@@ -330,6 +399,8 @@ func minLoad(a, b *storeLoad) *storeLoad {
 	return &storeLoad{
 		ByteRate: math.Min(a.ByteRate, b.ByteRate),
 		KeyRate:  math.Min(a.KeyRate, b.KeyRate),
+		CPUUsage: math.Min(a.CPUUsage, b.CPUUsage),
+		DiskRate: math.Min(a.DiskRate, b.DiskRate),
 		Count:    math.Min(a.Count, b.Count),
 	}
 }
@@ -338,6 +409,8 @@ func maxLoad(a, b *storeLoad) *storeLoad {
 	return &storeLoad{
 		ByteRate: math.Max(a.ByteRate, b.ByteRate),
 		KeyRate:  math.Max(a.KeyRate, b.KeyRate),
+		CPUUsage: math.Max(a.CPUUsage, b.CPUUsage),
+		DiskRate: math.Max(a.DiskRate, b.DiskRate),
 		Count:    math.Max(a.Count, b.Count),
 	}
 }
@@ -345,6 +418,25 @@ func maxLoad(a, b *storeLoad) *storeLoad {
 type storeLoadDetail struct {
 	LoadPred *storeLoadPred
 	HotPeers []*statistics.HotPeerStat
+
+	// The CPU usage per key and the disk IO rate per byte of the store, which
+	// are used to estimate the share of a hot peer.
+	CPUUsagePerKey  float64
+	DiskRatePerByte float64
+}
+
+// peerLoad returns the estimated load of the hot peer in the dimension.
+func (li *storeLoadDetail) peerLoad(peer *statistics.HotPeerStat, dim string) float64 {
+	switch dim {
+	case keyDim:
+		return peer.GetKeyRate()
+	case cpuDim:
+		return peer.GetKeyRate() * li.CPUUsagePerKey
+	case diskDim:
+		return peer.GetByteRate() * li.DiskRatePerByte
+	default:
+		return peer.GetByteRate()
+	}
 }
 
 func (li *storeLoadDetail) toHotPeersStat() *statistics.HotPeersStat {
@@ -355,6 +447,8 @@ func (li *storeLoadDetail) toHotPeersStat() *statistics.HotPeersStat {
 	return &statistics.HotPeersStat{
 		TotalBytesRate: li.LoadPred.Current.ByteRate,
 		TotalKeysRate:  li.LoadPred.Current.KeyRate,
+		TotalCPUUsage:  li.LoadPred.Current.CPUUsage,
+		TotalDiskRate:  li.LoadPred.Current.DiskRate,
 		Count:          len(li.HotPeers),
 		Stats:          peers,
 	}
@@ -44,6 +44,8 @@ import (
 	"github.com/pingcap/pd/v4/pkg/grpcutil"
 	"github.com/pingcap/pd/v4/pkg/logutil"
 	"github.com/pingcap/pd/v4/pkg/typeutil"
+	"github.com/pingcap/pd/v4/server/audit"
+	"github.com/pingcap/pd/v4/server/auth"
 	"github.com/pingcap/pd/v4/server/cluster"
 	"github.com/pingcap/pd/v4/server/config"
 	configmanager "github.com/pingcap/pd/v4/server/config_manager"
@@ -102,6 +104,12 @@ type Server struct {
 	etcdCfg     *embed.Config
 	scheduleOpt *config.ScheduleOption
 	handler     *Handler
+	// authenticator authenticates the callers, nil if the authentication is
+	// disabled.
+	authenticator *auth.Authenticator
+	// auditLogger records the requests which change the cluster, nil if the
+	// audit log is disabled.
+	auditLogger *audit.Logger
 
 	ctx              context.Context
 	serverLoopCtx    context.Context
@@ -124,8 +132,13 @@ type Server struct {
 	basicCluster *core.BasicCluster
 	// for tso.
 	tso *tso.TimestampOracle
+	// tsoDispatcher merges tso requests across streams, nil if disabled.
+	tsoDispatcher *tso.Dispatcher
 	// for raft cluster
 	cluster *cluster.RaftCluster
+	// serviceSafePointLock is used to serialize the updates of the gc
+	// safepoint and the service safepoints.
+	serviceSafePointLock sync.Mutex
 	// For async region heartbeat.
 	hbStreams *heartbeatStreams
 	// Zap logger
@@ -229,6 +242,27 @@ func CreateServer(ctx context.Context, cfg *config.Config, serviceBuilders ...Ha
 		DiagnosticsServer: sysutil.NewDiagnosticsServer(cfg.Log.File.Filename),
 	}
 
+	if cfg.Auth.Enable {
+		// The members are expected to share the common name of the
+		// certificate, the requests redirected by them are trusted.
+		var memberNames []string
+		if cfg.Security.CertPath != "" {
+			cn, err := auth.CertCommonName(cfg.Security.CertPath)
+			if err != nil {
+				return nil, err
+			}
+			memberNames = append(memberNames, cn)
+		}
+		a, err := auth.NewAuthenticator(cfg.Auth.TokenFile, cfg.Auth.CertRoles, memberNames)
+		if err != nil {
+			return nil, err
+		}
+		s.authenticator = a
+	}
+	if cfg.Audit.Enable {
+		s.auditLogger = audit.NewLogger(&cfg.Audit)
+	}
+
 	s.cfgManager = configmanager.NewConfigManager(s)
 	s.handler = newHandler(s)
 
@@ -356,6 +390,9 @@ func (s *Server) startServer(ctx context.Context) error {
 		s.cfg.TsoSaveInterval.Duration,
 		func() time.Duration { return s.scheduleOpt.LoadPDServerConfig().MaxResetTSGap },
 	)
+	if s.cfg.TsoMaxBatchSize > 1 {
+		s.tsoDispatcher = tso.NewDispatcher(s.tso, s.cfg.TsoMaxBatchSize, s.cfg.TsoBatchWaitDuration.Duration)
+	}
 	kvBase := kv.NewEtcdKVBase(s.client, s.rootPath)
 	path := filepath.Join(s.cfg.DataDir, "region-meta")
 	regionStorage, err := core.NewRegionStorage(ctx, path)
@@ -426,6 +463,11 @@ func (s *Server) Close() {
 	if err := s.storage.Close(); err != nil {
 		log.Error("close storage meet error", zap.Error(err))
 	}
+	if s.auditLogger != nil {
+		if err := s.auditLogger.Close(); err != nil {
+			log.Error("close audit log meet error", zap.Error(err))
+		}
+	}
 
 	// Run callbacks
 	for _, cb := range s.closeCallbacks {
@@ -471,14 +513,19 @@ func (s *Server) LoopContext() context.Context {
 
 func (s *Server) startServerLoop(ctx context.Context) {
 	s.serverLoopCtx, s.serverLoopCancel = context.WithCancel(ctx)
-	s.serverLoopWg.Add(3)
+	s.serverLoopWg.Add(4)
 	go s.leaderLoop()
 	go s.etcdLeaderLoop()
 	go s.serverMetricsLoop()
+	go s.tsoPresyncLoop()
 	if s.cfg.EnableDynamicConfig {
 		s.serverLoopWg.Add(1)
 		go s.configCheckLoop()
 	}
+	if s.tsoDispatcher != nil {
+		s.serverLoopWg.Add(1)
+		go s.tsoDispatchLoop()
+	}
 }
 
 func (s *Server) stopServerLoop() {
@@ -715,7 +762,7 @@ func (s *Server) StartTimestamp() int64 {
 // GetConfig gets the config information.
 func (s *Server) GetConfig() *config.Config {
 	cfg := s.cfg.Clone()
-	cfg.Schedule = *s.scheduleOpt.Load()
+	cfg.Schedule = *s.scheduleOpt.Load().Clone()
 	cfg.Replication = *s.scheduleOpt.GetReplication().Load()
 	cfg.LabelProperty = s.scheduleOpt.LoadLabelPropertyConfig().Clone()
 	cfg.ClusterVersion = *s.scheduleOpt.LoadClusterVersion()
@@ -739,9 +786,7 @@ func (s *Server) GetConfig() *config.Config {
 
 // GetScheduleConfig gets the balance config information.
 func (s *Server) GetScheduleConfig() *config.ScheduleConfig {
-	cfg := &config.ScheduleConfig{}
-	*cfg = *s.scheduleOpt.Load()
-	return cfg
+	return s.scheduleOpt.Load().Clone()
 }
 
 // SetScheduleConfig sets the balance config information.
@@ -965,6 +1010,17 @@ func (s *Server) GetSecurityConfig() *grpcutil.SecurityConfig {
 	return &s.cfg.Security
 }
 
+// GetAuditLogger returns the audit logger, nil if the audit log is disabled.
+func (s *Server) GetAuditLogger() *audit.Logger {
+	return s.auditLogger
+}
+
+// GetAuthenticator returns the authenticator of the callers, nil if the
+// authentication is disabled.
+func (s *Server) GetAuthenticator() *auth.Authenticator {
+	return s.authenticator
+}
+
 // GetClusterRootPath returns the cluster root path.
 func (s *Server) GetClusterRootPath() string {
 	return path.Join(s.rootPath, "raft")
@@ -1080,6 +1136,7 @@ func (s *Server) leaderLoop() {
 
 func (s *Server) campaignLeader() {
 	log.Info("start to campaign leader", zap.String("campaign-leader-name", s.Name()))
+	start := time.Now()
 
 	lease := member.NewLeaderLease(s.client)
 	defer lease.Close()
@@ -1104,6 +1161,7 @@ func (s *Server) campaignLeader() {
 		return
 	}
 	defer s.tso.ResetTimestamp()
+	tsoTakeoverDuration.Observe(time.Since(start).Seconds())
 
 	err := s.reloadConfigFromKV()
 	if err != nil {
@@ -1139,6 +1197,13 @@ func (s *Server) campaignLeader() {
 			etcdLeader := s.member.GetEtcdLeader()
 			if etcdLeader != s.member.ID() {
 				log.Info("etcd leader changed, resigns leadership", zap.String("old-leader-name", s.Name()))
+				// Delete the leader key instead of waiting for the lease to be
+				// revoked, which may be slow right after the etcd leader changes.
+				// It is safe for tso because the next leader always starts from
+				// the timestamp window saved by this one.
+				if err = s.member.DeleteLeaderKey(); err != nil {
+					log.Warn("failed to delete leader key", zap.Error(err))
+				}
 				return
 			}
 		case <-tsTicker.C:
@@ -1154,6 +1219,40 @@ func (s *Server) campaignLeader() {
 	}
 }
 
+// tsoPresyncLoop keeps the timestamp window saved by the leader up to date
+// when the server is a follower, so that it can serve tso right after it
+// becomes leader.
+func (s *Server) tsoPresyncLoop() {
+	defer logutil.LogPanic()
+	defer s.serverLoopWg.Done()
+
+	ctx, cancel := context.WithCancel(s.serverLoopCtx)
+	defer cancel()
+	ticker := time.NewTicker(tso.UpdateTimestampStep)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if s.member.IsLeader() {
+				continue
+			}
+			if err := s.tso.Presync(); err != nil {
+				log.Warn("failed to presync timestamp", zap.Error(err))
+			}
+		case <-ctx.Done():
+			log.Info("server is closed, exit tso presync loop")
+			return
+		}
+	}
+}
+
+func (s *Server) tsoDispatchLoop() {
+	defer logutil.LogPanic()
+	defer s.serverLoopWg.Done()
+
+	s.tsoDispatcher.Run(s.serverLoopCtx)
+}
+
 func (s *Server) etcdLeaderLoop() {
 	defer logutil.LogPanic()
 	defer s.serverLoopWg.Done()
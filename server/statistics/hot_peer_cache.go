@@ -291,7 +291,14 @@ func (f *hotPeerCache) updateHotPeerStat(newItem, oldItem *HotPeerStat, storesSt
 		return newItem
 	}
 
-	if oldItem != nil {
+	if oldItem != nil && newItem.Version > oldItem.Version {
+		// The region is split or merged, so the stats before are stale. The
+		// region is measured again from scratch, and it is not taken as hot
+		// by the schedulers until it stays hot for the hit threshold again.
+		newItem.rollingByteRate = NewMedianFilter(rollingWindowsSize)
+		newItem.rollingKeyRate = NewMedianFilter(rollingWindowsSize)
+		newItem.AntiCount = hotRegionAntiCount
+	} else if oldItem != nil {
 		newItem.rollingByteRate = oldItem.rollingByteRate
 		newItem.rollingKeyRate = oldItem.rollingKeyRate
 		if isHot {
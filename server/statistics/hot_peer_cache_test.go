@@ -59,6 +59,39 @@ func (t *testHotPeerCache) TestStoreTimeUnsync(c *C) {
 	}
 }
 
+func (t *testHotPeerCache) TestRegionSplit(c *C) {
+	cache := NewHotStoresStats(WriteFlow)
+	stats := NewStoresStats()
+	peers := newPeers(3,
+		func(i int) uint64 { return uint64(10000 + i) },
+		func(i int) uint64 { return uint64(i) })
+	meta := &metapb.Region{
+		Id:          1000,
+		Peers:       peers,
+		RegionEpoch: &metapb.RegionEpoch{ConfVer: 6, Version: 6},
+	}
+	checkByteRate := func(writtenBytes uint64, expect float64, hotDegree int) {
+		region := core.NewRegionInfo(meta, peers[0],
+			core.SetReportInterval(RegionHeartBeatReportInterval),
+			core.SetWrittenBytes(writtenBytes*RegionHeartBeatReportInterval))
+		res := cache.CheckRegionFlow(region, stats)
+		c.Assert(res, HasLen, 3)
+		for _, p := range res {
+			cache.Update(p)
+			c.Assert(p.GetByteRate(), Equals, expect)
+			c.Assert(p.HotDegree, Equals, hotDegree)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		checkByteRate(100*1024, 100*1024, i)
+	}
+	// The rates and the hot degree before the split are dropped.
+	meta.RegionEpoch = &metapb.RegionEpoch{ConfVer: 6, Version: 7}
+	checkByteRate(50*1024, 50*1024, 0)
+	// The median of the rates after the split.
+	checkByteRate(60*1024, 55*1024, 1)
+}
+
 type genID func(i int) uint64
 
 func newPeers(n int, pid genID, sid genID) []*metapb.Peer {
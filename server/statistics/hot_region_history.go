@@ -0,0 +1,176 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/kv"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// HotRegionHistoryRecord is a hot peer saved in the hot region history.
+type HotRegionHistoryRecord struct {
+	UpdateTime time.Time `json:"update_time"`
+	RegionID   uint64    `json:"region_id"`
+	StoreID    uint64    `json:"store_id"`
+	IsLeader   bool      `json:"is_leader"`
+	HotType    string    `json:"hot_type"`
+	// StartKey and EndKey are in hex format.
+	StartKey  string  `json:"start_key"`
+	EndKey    string  `json:"end_key"`
+	ByteRate  float64 `json:"flow_bytes"`
+	KeyRate   float64 `json:"flow_keys"`
+	HotDegree int     `json:"hot_degree"`
+}
+
+// NewHotRegionHistoryRecord creates the history record of a hot peer.
+func NewHotRegionHistoryRecord(updateTime time.Time, peer *HotPeerStat, region *core.RegionInfo) *HotRegionHistoryRecord {
+	return &HotRegionHistoryRecord{
+		UpdateTime: updateTime,
+		RegionID:   peer.RegionID,
+		StoreID:    peer.StoreID,
+		IsLeader:   peer.IsLeader(),
+		HotType:    peer.Kind.String(),
+		StartKey:   core.HexRegionKeyStr(region.GetStartKey()),
+		EndKey:     core.HexRegionKeyStr(region.GetEndKey()),
+		ByteRate:   peer.GetByteRate(),
+		KeyRate:    peer.GetKeyRate(),
+		HotDegree:  peer.HotDegree,
+	}
+}
+
+// HotRegionHistoryFilter selects the records of the hot region history. The
+// zero value of a field matches all the records.
+type HotRegionHistoryFilter struct {
+	RegionID uint64
+	StoreID  uint64
+	// HotType is read or write.
+	HotType string
+	// Start and End limit the update time of the records to [Start, End).
+	Start time.Time
+	End   time.Time
+	// Limit is the max number of the records to return.
+	Limit int
+}
+
+func (f *HotRegionHistoryFilter) match(r *HotRegionHistoryRecord) bool {
+	if f.RegionID != 0 && r.RegionID != f.RegionID {
+		return false
+	}
+	if f.StoreID != 0 && r.StoreID != f.StoreID {
+		return false
+	}
+	return f.HotType == "" || r.HotType == f.HotType
+}
+
+// HotRegionHistory saves the snapshots of the hot peers in a local leveldb, so
+// that the hot regions in the past can be queried. The records whose update
+// time is older than the retention are purged by Purge.
+type HotRegionHistory struct {
+	db        *kv.LeveldbKV
+	retention time.Duration
+}
+
+// NewHotRegionHistory opens the hot region history at path.
+func NewHotRegionHistory(path string, retention time.Duration) (*HotRegionHistory, error) {
+	db, err := kv.NewLeveldbKV(path)
+	if err != nil {
+		return nil, err
+	}
+	return &HotRegionHistory{db: db, retention: retention}, nil
+}
+
+// The records are ordered by the update time, the hot type, the region ID and
+// the store ID make the key unique in a snapshot.
+func hotRegionHistoryKey(updateTime time.Time, hotType string, regionID, storeID uint64) string {
+	return fmt.Sprintf("%020d/%s/%020d/%020d", updateTime.UnixNano(), hotType, regionID, storeID)
+}
+
+func hotRegionHistoryTimeKey(t time.Time) string {
+	return fmt.Sprintf("%020d", t.UnixNano())
+}
+
+// Save saves a snapshot of the hot peers.
+func (h *HotRegionHistory) Save(records []*HotRegionHistoryRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	batch := new(leveldb.Batch)
+	for _, r := range records {
+		value, err := json.Marshal(r)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		batch.Put([]byte(hotRegionHistoryKey(r.UpdateTime, r.HotType, r.RegionID, r.StoreID)), value)
+	}
+	return errors.WithStack(h.db.Write(batch, nil))
+}
+
+// Query returns the records selected by the filter, the latest first.
+func (h *HotRegionHistory) Query(filter *HotRegionHistoryFilter) ([]*HotRegionHistoryRecord, error) {
+	rng := &util.Range{}
+	if !filter.Start.IsZero() {
+		rng.Start = []byte(hotRegionHistoryTimeKey(filter.Start))
+	}
+	if !filter.End.IsZero() {
+		rng.Limit = []byte(hotRegionHistoryTimeKey(filter.End))
+	}
+	iter := h.db.NewIterator(rng, nil)
+	defer iter.Release()
+	var records []*HotRegionHistoryRecord
+	for ok := iter.Last(); ok; ok = iter.Prev() {
+		r := &HotRegionHistoryRecord{}
+		if err := json.Unmarshal(iter.Value(), r); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if !filter.match(r) {
+			continue
+		}
+		records = append(records, r)
+		if filter.Limit > 0 && len(records) >= filter.Limit {
+			break
+		}
+	}
+	return records, errors.WithStack(iter.Error())
+}
+
+// Purge removes the records whose update time is older than the retention.
+func (h *HotRegionHistory) Purge(now time.Time) error {
+	limit := hotRegionHistoryTimeKey(now.Add(-h.retention))
+	iter := h.db.NewIterator(&util.Range{Limit: []byte(limit)}, nil)
+	batch := new(leveldb.Batch)
+	for iter.Next() {
+		batch.Delete(append([]byte(nil), iter.Key()...))
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return errors.WithStack(err)
+	}
+	if batch.Len() == 0 {
+		return nil
+	}
+	return errors.WithStack(h.db.Write(batch, nil))
+}
+
+// Close closes the hot region history.
+func (h *HotRegionHistory) Close() error {
+	return errors.WithStack(h.db.Close())
+}
@@ -0,0 +1,82 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/server/core"
+)
+
+var _ = Suite(&testHotRegionHistorySuite{})
+
+type testHotRegionHistorySuite struct{}
+
+func (s *testHotRegionHistorySuite) TestSaveAndQuery(c *C) {
+	h, err := NewHotRegionHistory(c.MkDir(), time.Hour)
+	c.Assert(err, IsNil)
+	defer h.Close()
+
+	region := core.NewRegionInfo(&metapb.Region{Id: 1, StartKey: []byte("a"), EndKey: []byte("b")}, nil)
+	now := time.Now()
+	snapshot := func(t time.Time) []*HotRegionHistoryRecord {
+		return []*HotRegionHistoryRecord{
+			NewHotRegionHistoryRecord(t, &HotPeerStat{RegionID: 1, StoreID: 1, Kind: WriteFlow, ByteRate: 100, KeyRate: 10, HotDegree: 3}, region),
+			NewHotRegionHistoryRecord(t, &HotPeerStat{RegionID: 1, StoreID: 2, Kind: WriteFlow, ByteRate: 100, KeyRate: 10, HotDegree: 3}, region),
+			NewHotRegionHistoryRecord(t, &HotPeerStat{RegionID: 2, StoreID: 1, Kind: ReadFlow, ByteRate: 200, KeyRate: 20, HotDegree: 5}, region),
+		}
+	}
+	c.Assert(h.Save(snapshot(now.Add(-2*time.Hour))), IsNil)
+	c.Assert(h.Save(snapshot(now.Add(-time.Minute))), IsNil)
+	c.Assert(h.Save(snapshot(now)), IsNil)
+
+	records, err := h.Query(&HotRegionHistoryFilter{})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 9)
+	// The latest first.
+	r := records[0]
+	c.Assert(r.UpdateTime.Equal(now), IsTrue)
+	c.Assert(r.HotType, Equals, "write")
+	c.Assert(r.StartKey, Equals, "61")
+	c.Assert(r.EndKey, Equals, "62")
+	c.Assert(r.ByteRate, Equals, 100.0)
+	c.Assert(r.HotDegree, Equals, 3)
+
+	for _, t := range []struct {
+		filter *HotRegionHistoryFilter
+		count  int
+	}{
+		{&HotRegionHistoryFilter{RegionID: 1}, 6},
+		{&HotRegionHistoryFilter{StoreID: 1}, 6},
+		{&HotRegionHistoryFilter{HotType: "read"}, 3},
+		{&HotRegionHistoryFilter{RegionID: 1, StoreID: 2, HotType: "write"}, 3},
+		{&HotRegionHistoryFilter{Start: now.Add(-time.Hour)}, 6},
+		{&HotRegionHistoryFilter{Start: now.Add(-time.Hour), End: now}, 3},
+		{&HotRegionHistoryFilter{HotType: "read", Limit: 2}, 2},
+	} {
+		records, err = h.Query(t.filter)
+		c.Assert(err, IsNil)
+		c.Assert(records, HasLen, t.count, Commentf("%+v", t.filter))
+	}
+
+	// The snapshot older than the retention is purged.
+	c.Assert(h.Purge(now), IsNil)
+	records, err = h.Query(&HotRegionHistoryFilter{})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 6)
+	c.Assert(records[5].UpdateTime.Equal(now.Add(-time.Minute)), IsTrue)
+}
@@ -17,6 +17,8 @@ package statistics
 type HotPeersStat struct {
 	TotalBytesRate float64       `json:"total_flow_bytes"`
 	TotalKeysRate  float64       `json:"total_flow_keys"`
+	TotalCPUUsage  float64       `json:"total_cpu_usage"`
+	TotalDiskRate  float64       `json:"total_disk_rate"`
 	Count          int           `json:"regions_count"`
 	Stats          []HotPeerStat `json:"statistics"`
 }
@@ -320,11 +320,14 @@ func (r *RollingStoreStats) Observe(stats *pdpb.StoreStats) {
 func (r *RollingStoreStats) Set(stats *pdpb.StoreStats) {
 	statInterval := stats.GetInterval()
 	interval := statInterval.GetEndTimestamp() - statInterval.GetStartTimestamp()
+	r.Lock()
+	defer r.Unlock()
+	r.totalCPUUsage.Set(collect(stats.GetCpuUsages()))
+	r.totalBytesDiskReadRate.Set(collect(stats.GetReadIoRates()))
+	r.totalBytesDiskWriteRate.Set(collect(stats.GetWriteIoRates()))
 	if interval == 0 {
 		return
 	}
-	r.Lock()
-	defer r.Unlock()
 	r.bytesWriteRate.Set(float64(stats.BytesWritten) / float64(interval))
 	r.bytesReadRate.Set(float64(stats.BytesRead) / float64(interval))
 	r.keysWriteRate.Set(float64(stats.KeysWritten) / float64(interval))
@@ -0,0 +1,163 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tso
+
+import (
+	"context"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/log"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const dispatcherChanSize = 10000
+
+type tsoResult struct {
+	ts  pdpb.Timestamp
+	err error
+}
+
+type tsoRequest struct {
+	count uint32
+	// resultCh is buffered so that the dispatcher never blocks on a
+	// requester which has gone away.
+	resultCh chan tsoResult
+}
+
+// Dispatcher merges the tso requests from concurrent streams into one
+// allocation per tick, and splits the allocated range back to each request.
+type Dispatcher struct {
+	oracle       *TimestampOracle
+	maxBatchSize int
+	maxWait      time.Duration
+	reqCh        chan *tsoRequest
+}
+
+// NewDispatcher creates a Dispatcher. maxBatchSize is the max number of
+// requests merged into one allocation, maxWait is how long the dispatcher
+// waits for more requests before allocating for a non-full batch.
+func NewDispatcher(oracle *TimestampOracle, maxBatchSize int, maxWait time.Duration) *Dispatcher {
+	return &Dispatcher{
+		oracle:       oracle,
+		maxBatchSize: maxBatchSize,
+		maxWait:      maxWait,
+		reqCh:        make(chan *tsoRequest, dispatcherChanSize),
+	}
+}
+
+// GetRespTS is used to get a timestamp through the dispatcher. It has the
+// same semantics as TimestampOracle.GetRespTS: the logical part of the
+// returned timestamp is the last one of the allocated range.
+func (d *Dispatcher) GetRespTS(ctx context.Context, count uint32) (pdpb.Timestamp, error) {
+	if count == 0 {
+		return pdpb.Timestamp{}, errors.New("tso count should be positive")
+	}
+	req := &tsoRequest{count: count, resultCh: make(chan tsoResult, 1)}
+	select {
+	case d.reqCh <- req:
+	case <-ctx.Done():
+		return pdpb.Timestamp{}, errors.WithStack(ctx.Err())
+	}
+	select {
+	case res := <-req.resultCh:
+		return res.ts, res.err
+	case <-ctx.Done():
+		return pdpb.Timestamp{}, errors.WithStack(ctx.Err())
+	}
+}
+
+// Run dispatches the requests until the context is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	batch := make([]*tsoRequest, 0, d.maxBatchSize)
+	var pending *tsoRequest
+	for {
+		batch = batch[:0]
+		if pending != nil {
+			batch = append(batch, pending)
+			pending = nil
+		} else {
+			select {
+			case req := <-d.reqCh:
+				batch = append(batch, req)
+			case <-ctx.Done():
+				log.Info("tso dispatcher is stopped")
+				return
+			}
+		}
+		batch, pending = d.collect(ctx, batch)
+		d.dispatch(batch)
+	}
+}
+
+// collect fills up the batch with the queued requests. The request which
+// does not fit in the batch is returned as pending.
+func (d *Dispatcher) collect(ctx context.Context, batch []*tsoRequest) ([]*tsoRequest, *tsoRequest) {
+	var timeout <-chan time.Time
+	if d.maxWait > 0 {
+		timer := time.NewTimer(d.maxWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	sum := int64(batch[0].count)
+	for len(batch) < d.maxBatchSize {
+		var req *tsoRequest
+		if timeout == nil {
+			select {
+			case req = <-d.reqCh:
+			default:
+				return batch, nil
+			}
+		} else {
+			select {
+			case req = <-d.reqCh:
+			case <-timeout:
+				return batch, nil
+			case <-ctx.Done():
+				return batch, nil
+			}
+		}
+		// Keep the merged count in the logical range, otherwise the
+		// allocation always fails.
+		if sum+int64(req.count) >= maxLogical {
+			return batch, req
+		}
+		sum += int64(req.count)
+		batch = append(batch, req)
+	}
+	return batch, nil
+}
+
+func (d *Dispatcher) dispatch(batch []*tsoRequest) {
+	var count uint32
+	for _, req := range batch {
+		count += req.count
+	}
+	tsoBatchSize.Observe(float64(len(batch)))
+	ts, err := d.oracle.GetRespTS(count)
+	if err != nil {
+		log.Debug("failed to dispatch tso", zap.Int("batch-size", len(batch)), zap.Error(err))
+		for _, req := range batch {
+			req.resultCh <- tsoResult{err: err}
+		}
+		return
+	}
+	logical := ts.GetLogical() - int64(count)
+	for _, req := range batch {
+		logical += int64(req.count)
+		req.resultCh <- tsoResult{ts: pdpb.Timestamp{Physical: ts.GetPhysical(), Logical: logical}}
+	}
+}
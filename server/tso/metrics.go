@@ -31,9 +31,29 @@ var (
 			Name:      "tso",
 			Help:      "Record of tso metadata.",
 		}, []string{"type"})
+
+	syncDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "pd",
+			Subsystem: "tso",
+			Name:      "sync_duration_seconds",
+			Help:      "Bucketed histogram of the time (s) a new leader takes to sync timestamp.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 13),
+		}, []string{"type"})
+
+	tsoBatchSize = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "pd",
+			Subsystem: "tso",
+			Name:      "dispatch_batch_size",
+			Help:      "Bucketed histogram of the number of tso requests merged into one allocation.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 14),
+		})
 )
 
 func init() {
 	prometheus.MustRegister(tsoCounter)
 	prometheus.MustRegister(tsoGauge)
+	prometheus.MustRegister(syncDuration)
+	prometheus.MustRegister(tsoBatchSize)
 }
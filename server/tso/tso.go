@@ -45,6 +45,13 @@ type TimestampOracle struct {
 	ts            unsafe.Pointer
 	lastSavedTime atomic.Value
 	lease         *member.LeaderLease
+	// syncedCh is closed once the timestamp is synced, so that requests
+	// waiting for the synchronization can be served immediately.
+	syncedCh atomic.Value
+	// For followers, the timestamp window saved by the leader and its etcd
+	// revision, refreshed by Presync. It lets a newly elected leader save its
+	// window without loading the timestamp from etcd again.
+	presynced unsafe.Pointer
 
 	rootPath      string
 	member        string
@@ -56,13 +63,15 @@ type TimestampOracle struct {
 // NewTimestampOracle creates a new TimestampOracle.
 // TODO: remove saveInterval
 func NewTimestampOracle(client *clientv3.Client, rootPath string, member string, saveInterval time.Duration, maxResetTsGap func() time.Duration) *TimestampOracle {
-	return &TimestampOracle{
+	t := &TimestampOracle{
 		rootPath:      rootPath,
 		client:        client,
 		saveInterval:  saveInterval,
 		maxResetTsGap: maxResetTsGap,
 		member:        member,
 	}
+	t.syncedCh.Store(make(chan struct{}))
+	return t
 }
 
 type atomicObject struct {
@@ -70,6 +79,11 @@ type atomicObject struct {
 	logical  int64
 }
 
+type presyncedObject struct {
+	last     time.Time
+	revision int64
+}
+
 func (t *TimestampOracle) getTimestampPath() string {
 	return path.Join(t.rootPath, "timestamp")
 }
@@ -85,6 +99,36 @@ func (t *TimestampOracle) loadTimestamp() (time.Time, error) {
 	return typeutil.ParseTimestamp(data)
 }
 
+// loadTimestampWithRevision loads the saved timestamp and the mod revision of
+// it. The revision is 0 if the timestamp does not exist.
+func (t *TimestampOracle) loadTimestampWithRevision() (time.Time, int64, error) {
+	resp, err := etcdutil.EtcdKVGet(t.client, t.getTimestampPath())
+	if err != nil {
+		return typeutil.ZeroTime, 0, err
+	}
+	if len(resp.Kvs) == 0 {
+		return typeutil.ZeroTime, 0, nil
+	}
+	last, err := typeutil.ParseTimestamp(resp.Kvs[0].Value)
+	if err != nil {
+		return typeutil.ZeroTime, 0, err
+	}
+	return last, resp.Kvs[0].ModRevision, nil
+}
+
+// Presync refreshes the timestamp window saved by the leader. It is called
+// periodically by followers.
+func (t *TimestampOracle) Presync() error {
+	last, revision, err := t.loadTimestampWithRevision()
+	if err != nil {
+		tsoCounter.WithLabelValues("err_presync").Inc()
+		return err
+	}
+	atomic.StorePointer(&t.presynced, unsafe.Pointer(&presyncedObject{last: last, revision: revision}))
+	tsoGauge.WithLabelValues("presynced").Set(float64(last.Unix()))
+	return nil
+}
+
 // save timestamp, if lastTs is 0, we think the timestamp doesn't exist, so create it,
 // otherwise, update it.
 func (t *TimestampOracle) saveTimestamp(ts time.Time) error {
@@ -109,13 +153,42 @@ func (t *TimestampOracle) saveTimestamp(ts time.Time) error {
 // SyncTimestamp is used to synchronize the timestamp.
 func (t *TimestampOracle) SyncTimestamp(lease *member.LeaderLease) error {
 	tsoCounter.WithLabelValues("sync").Inc()
+	start := time.Now()
 
-	last, err := t.loadTimestamp()
-	if err != nil {
-		return err
+	next, save, ok := t.syncWithPresynced()
+	if ok {
+		tsoCounter.WithLabelValues("sync_presynced").Inc()
+		syncDuration.WithLabelValues("presynced").Observe(time.Since(start).Seconds())
+	} else {
+		last, err := t.loadTimestamp()
+		if err != nil {
+			return err
+		}
+		next, save = t.nextWindow(last)
+		if err = t.saveTimestamp(save); err != nil {
+			tsoCounter.WithLabelValues("err_save_sync_ts").Inc()
+			return err
+		}
+		log.Info("sync and save timestamp", zap.Time("last", last), zap.Time("save", save), zap.Time("next", next))
+		syncDuration.WithLabelValues("loaded").Observe(time.Since(start).Seconds())
+	}
+
+	tsoCounter.WithLabelValues("sync_ok").Inc()
+
+	current := &atomicObject{
+		physical: next,
 	}
+	t.lease = lease
+	atomic.StorePointer(&t.ts, unsafe.Pointer(current))
+	close(t.syncedCh.Load().(chan struct{}))
+
+	return nil
+}
 
-	next := time.Now()
+// nextWindow returns the physical time to start with and the timestamp
+// window to save, given the last saved timestamp.
+func (t *TimestampOracle) nextWindow(last time.Time) (next, save time.Time) {
+	next = time.Now()
 	failpoint.Inject("fallBackSync", func() {
 		next = next.Add(time.Hour)
 	})
@@ -126,23 +199,37 @@ func (t *TimestampOracle) SyncTimestamp(lease *member.LeaderLease) error {
 		log.Error("system time may be incorrect", zap.Time("last", last), zap.Time("next", next))
 		next = last.Add(updateTimestampGuard)
 	}
+	return next, next.Add(t.saveInterval)
+}
 
-	save := next.Add(t.saveInterval)
-	if err = t.saveTimestamp(save); err != nil {
-		tsoCounter.WithLabelValues("err_save_sync_ts").Inc()
-		return err
+// syncWithPresynced saves the next timestamp window based on the presynced
+// one. The saving succeeds only if the timestamp has not been changed since
+// it was presynced, otherwise the caller should load it from etcd.
+func (t *TimestampOracle) syncWithPresynced() (next, save time.Time, ok bool) {
+	presynced := (*presyncedObject)(atomic.LoadPointer(&t.presynced))
+	if presynced == nil {
+		return
 	}
+	// The presynced window is out of date once it is used.
+	atomic.StorePointer(&t.presynced, nil)
+	next, save = t.nextWindow(presynced.last)
 
-	tsoCounter.WithLabelValues("sync_ok").Inc()
-	log.Info("sync and save timestamp", zap.Time("last", last), zap.Time("save", save), zap.Time("next", next))
-
-	current := &atomicObject{
-		physical: next,
+	data := typeutil.Uint64ToBytes(uint64(save.UnixNano()))
+	key := t.getTimestampPath()
+	leaderPath := path.Join(t.rootPath, "leader")
+	resp, err := kv.NewSlowLogTxn(t.client).
+		If(clientv3.Compare(clientv3.Value(leaderPath), "=", t.member),
+			clientv3.Compare(clientv3.ModRevision(key), "=", presynced.revision)).
+		Then(clientv3.OpPut(key, string(data))).
+		Commit()
+	if err != nil || !resp.Succeeded {
+		log.Warn("presynced timestamp is out of date, load it again", zap.Time("presynced", presynced.last), zap.Error(err))
+		tsoCounter.WithLabelValues("err_presynced_outdated").Inc()
+		return next, save, false
 	}
-	t.lease = lease
-	atomic.StorePointer(&t.ts, unsafe.Pointer(current))
-
-	return nil
+	t.lastSavedTime.Store(save)
+	log.Info("sync and save timestamp with presynced one", zap.Time("last", presynced.last), zap.Time("save", save), zap.Time("next", next))
+	return next, save, true
 }
 
 // ResetUserTimestamp update the physical part with specified tso.
@@ -253,6 +340,7 @@ func (t *TimestampOracle) ResetTimestamp() {
 		physical: typeutil.ZeroTime,
 	}
 	atomic.StorePointer(&t.ts, unsafe.Pointer(zero))
+	t.syncedCh.Store(make(chan struct{}))
 }
 
 var maxRetryCount = 100
@@ -273,7 +361,10 @@ func (t *TimestampOracle) GetRespTS(count uint32) (pdpb.Timestamp, error) {
 		current := (*atomicObject)(atomic.LoadPointer(&t.ts))
 		if current == nil || current.physical == typeutil.ZeroTime {
 			log.Error("we haven't synced timestamp ok, wait and retry", zap.Int("retry-count", i))
-			time.Sleep(200 * time.Millisecond)
+			select {
+			case <-t.syncedCh.Load().(chan struct{}):
+			case <-time.After(200 * time.Millisecond):
+			}
 			continue
 		}
 
@@ -0,0 +1,189 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"strconv"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/log"
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/mvcc/mvccpb"
+	"go.uber.org/zap"
+)
+
+// WatchEventType is the type of a watch event.
+type WatchEventType string
+
+// Watch event types.
+const (
+	WatchEventPut    WatchEventType = "put"
+	WatchEventDelete WatchEventType = "delete"
+	// WatchEventCompacted means that the requested revision has been
+	// compacted. It is the last event of the watch, the watcher should
+	// start over with revision 0 to get a new snapshot.
+	WatchEventCompacted WatchEventType = "compacted"
+)
+
+// Watchable key spaces.
+const (
+	WatchKeySpaceStores = "stores"
+	WatchKeySpaceConfig = "config"
+)
+
+// WatchEvent is a change of a key in a key space.
+type WatchEvent struct {
+	Type WatchEventType `json:"type"`
+	// Revision is the etcd revision of the change, or the revision of the
+	// snapshot for the events of the snapshot. To resume a broken watch, watch
+	// again from the last received revision plus one.
+	Revision int64 `json:"revision"`
+	// Key identifies the item in the key space, e.g. the store ID.
+	Key   string          `json:"key,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// watchKeySpace describes how a key space is stored in etcd.
+type watchKeySpace struct {
+	// key is the etcd key relative to the root path, it is a prefix if
+	// isPrefix is set.
+	key      string
+	isPrefix bool
+	// decode converts a kv in etcd to the key and value of an event. value
+	// is nil for deletions.
+	decode func(key string, value []byte) (string, json.RawMessage, error)
+}
+
+var watchKeySpaces = map[string]watchKeySpace{
+	WatchKeySpaceStores: {
+		key:      "raft/s",
+		isPrefix: true,
+		decode: func(key string, value []byte) (string, json.RawMessage, error) {
+			id, err := strconv.ParseUint(path.Base(key), 10, 64)
+			if err != nil {
+				return "", nil, errors.WithStack(err)
+			}
+			if value == nil {
+				return strconv.FormatUint(id, 10), nil, nil
+			}
+			store := &metapb.Store{}
+			if err := proto.Unmarshal(value, store); err != nil {
+				return "", nil, errors.WithStack(err)
+			}
+			data, err := json.Marshal(store)
+			return strconv.FormatUint(id, 10), data, errors.WithStack(err)
+		},
+	},
+	WatchKeySpaceConfig: {
+		key: "config",
+		decode: func(key string, value []byte) (string, json.RawMessage, error) {
+			return WatchKeySpaceConfig, value, nil
+		},
+	},
+}
+
+const watchChanSize = 16
+
+// Watch watches the changes of a key space since the revision. If revision
+// is not positive, the current items are sent as put events before the
+// changes. The returned channel is closed when the context is done, the
+// watch is broken, or after a WatchEventCompacted event.
+func (s *Server) Watch(ctx context.Context, keySpace string, revision int64) (<-chan []*WatchEvent, error) {
+	ks, ok := watchKeySpaces[keySpace]
+	if !ok {
+		return nil, errors.Errorf("unknown key space %s", keySpace)
+	}
+	key := path.Join(s.rootPath, ks.key)
+	var opts []clientv3.OpOption
+	if ks.isPrefix {
+		key += "/"
+		opts = append(opts, clientv3.WithPrefix())
+	}
+
+	ch := make(chan []*WatchEvent, watchChanSize)
+	go func() {
+		defer close(ch)
+		send := func(events []*WatchEvent) bool {
+			select {
+			case ch <- events:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		if revision <= 0 {
+			resp, err := s.client.Get(ctx, key, opts...)
+			if err != nil {
+				log.Error("failed to load watch snapshot", zap.String("key-space", keySpace), zap.Error(err))
+				return
+			}
+			events := make([]*WatchEvent, 0, len(resp.Kvs))
+			for _, kv := range resp.Kvs {
+				if e := newWatchEvent(ks, WatchEventPut, resp.Header.Revision, kv); e != nil {
+					events = append(events, e)
+				}
+			}
+			if len(events) > 0 && !send(events) {
+				return
+			}
+			revision = resp.Header.Revision + 1
+		}
+
+		watchCh := s.client.Watch(clientv3.WithRequireLeader(ctx), key, append(opts, clientv3.WithRev(revision))...)
+		for resp := range watchCh {
+			if resp.CompactRevision != 0 {
+				send([]*WatchEvent{{Type: WatchEventCompacted, Revision: resp.CompactRevision}})
+				return
+			}
+			if err := resp.Err(); err != nil {
+				log.Warn("watch is broken", zap.String("key-space", keySpace), zap.Error(err))
+				return
+			}
+			events := make([]*WatchEvent, 0, len(resp.Events))
+			for _, e := range resp.Events {
+				typ := WatchEventPut
+				if e.Type == mvccpb.DELETE {
+					typ = WatchEventDelete
+				}
+				if event := newWatchEvent(ks, typ, e.Kv.ModRevision, e.Kv); event != nil {
+					events = append(events, event)
+				}
+			}
+			if len(events) > 0 && !send(events) {
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func newWatchEvent(ks watchKeySpace, typ WatchEventType, revision int64, kv *mvccpb.KeyValue) *WatchEvent {
+	var value []byte
+	if typ == WatchEventPut {
+		value = kv.Value
+	}
+	key, data, err := ks.decode(string(kv.Key), value)
+	if err != nil {
+		log.Error("failed to decode watch event", zap.ByteString("key", kv.Key), zap.Error(err))
+		return nil
+	}
+	return &WatchEvent{Type: typ, Revision: revision, Key: key, Value: data}
+}
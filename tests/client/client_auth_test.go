@@ -0,0 +1,163 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	pd "github.com/pingcap/pd/v4/client"
+	"github.com/pingcap/pd/v4/pkg/grpcutil"
+	"github.com/pingcap/pd/v4/server/config"
+	"github.com/pingcap/pd/v4/tests"
+	"google.golang.org/grpc/metadata"
+)
+
+var _ = Suite(&clientAuthTestSuite{})
+
+type clientAuthTestSuite struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	cluster *tests.TestCluster
+	client  *http.Client
+}
+
+func (s *clientAuthTestSuite) SetUpSuite(c *C) {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	tokenFile := filepath.Join(c.MkDir(), "tokens.json")
+	c.Assert(ioutil.WriteFile(tokenFile, []byte(`[
+		{"token": "admin-token", "user": "alice", "role": "admin"},
+		{"token": "reader-token", "user": "carol", "role": "read-only"}
+	]`), 0600), IsNil)
+	var err error
+	s.cluster, err = tests.NewTestCluster(s.ctx, 2, func(conf *config.Config) {
+		conf.Security = grpcutil.SecurityConfig{
+			KeyPath:  testTLSInfo.KeyFile,
+			CertPath: testTLSInfo.CertFile,
+			CAPath:   testTLSInfo.TrustedCAFile,
+		}
+		conf.AdvertiseClientUrls = strings.ReplaceAll(conf.AdvertiseClientUrls, "http", "https")
+		conf.ClientUrls = strings.ReplaceAll(conf.ClientUrls, "http", "https")
+		conf.AdvertisePeerUrls = strings.ReplaceAll(conf.AdvertisePeerUrls, "http", "https")
+		conf.PeerUrls = strings.ReplaceAll(conf.PeerUrls, "http", "https")
+		conf.InitialCluster = strings.ReplaceAll(conf.InitialCluster, "http", "https")
+		conf.Auth.Enable = true
+		conf.Auth.TokenFile = tokenFile
+		// The certificate of PD itself has no role.
+		conf.Auth.CertRoles = map[string]string{"client": "read-only"}
+	})
+	c.Assert(err, IsNil)
+	c.Assert(s.cluster.RunInitialServers(), IsNil)
+	leader := s.cluster.GetServer(s.cluster.WaitLeader()).GetServer()
+	ctx := metadata.NewIncomingContext(s.ctx, metadata.Pairs("authorization", "Bearer admin-token"))
+	_, err = leader.Bootstrap(ctx, &pdpb.BootstrapRequest{
+		Header: &pdpb.RequestHeader{ClusterId: leader.ClusterID()},
+		Store:  &metapb.Store{Id: 1, Address: "mock://1"},
+		Region: &metapb.Region{Id: 2, Peers: []*metapb.Peer{{Id: 3, StoreId: 1}}},
+	})
+	c.Assert(err, IsNil)
+
+	tlsConfig, err := testClientTLSInfo.ClientConfig()
+	c.Assert(err, IsNil)
+	s.client = &http.Client{
+		Transport: &http.Transport{
+			DisableKeepAlives: true,
+			TLSClientConfig:   tlsConfig,
+		},
+	}
+}
+
+func (s *clientAuthTestSuite) TearDownSuite(c *C) {
+	s.cluster.Destroy()
+	s.cancel()
+}
+
+func (s *clientAuthTestSuite) follower() *tests.TestServer {
+	for name, svr := range s.cluster.GetServers() {
+		if name != s.cluster.GetLeader() {
+			return svr
+		}
+	}
+	return nil
+}
+
+func (s *clientAuthTestSuite) request(c *C, method, url, token, body string) int {
+	req, err := http.NewRequest(method, url, bytes.NewBufferString(body))
+	c.Assert(err, IsNil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := s.client.Do(req)
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+	_, err = ioutil.ReadAll(resp.Body)
+	c.Assert(err, IsNil)
+	return resp.StatusCode
+}
+
+func (s *clientAuthTestSuite) TestRedirect(c *C) {
+	// The requests to the follower are redirected to the leader, which checks
+	// the caller authenticated by the follower instead of the follower.
+	urlPrefix := s.follower().GetConfig().AdvertiseClientUrls + "/pd/api/v1"
+	testCases := []struct {
+		method, path, token, body string
+		code                      int
+	}{
+		{http.MethodGet, "/version", "", "", http.StatusOK},
+		{http.MethodPost, "/admin/log", "", `"info"`, http.StatusForbidden},
+		{http.MethodPost, "/admin/log", "reader-token", `"info"`, http.StatusForbidden},
+		{http.MethodPost, "/admin/log", "admin-token", `"info"`, http.StatusOK},
+	}
+	for _, t := range testCases {
+		code := s.request(c, t.method, urlPrefix+t.path, t.token, t.body)
+		c.Assert(code, Equals, t.code, Commentf("%s %s %s", t.method, t.path, t.token))
+	}
+}
+
+func (s *clientAuthTestSuite) TestToken(c *C) {
+	var endpoints []string
+	for _, svr := range s.cluster.GetServers() {
+		endpoints = append(endpoints, svr.GetConfig().AdvertiseClientUrls)
+	}
+	security := pd.SecurityOption{
+		CAPath:   testClientTLSInfo.TrustedCAFile,
+		CertPath: testClientTLSInfo.CertFile,
+		KeyPath:  testClientTLSInfo.KeyFile,
+	}
+	// The client certificate is read-only.
+	cli, err := pd.NewClientWithContext(s.ctx, endpoints, security)
+	c.Assert(err, IsNil)
+	defer cli.Close()
+	_, err = cli.UpdateGCSafePoint(s.ctx, 1)
+	c.Assert(err, ErrorMatches, ".*permission denied.*")
+	_, err = cli.UpdateServiceGCSafePoint(s.ctx, "br", 3600, 1)
+	c.Assert(err, ErrorMatches, ".*403 Forbidden.*")
+
+	// The token is used prior to the certificate by both gRPC and HTTP.
+	cli, err = pd.NewClientWithContext(s.ctx, endpoints, security, pd.WithToken("admin-token"))
+	c.Assert(err, IsNil)
+	defer cli.Close()
+	_, err = cli.UpdateGCSafePoint(s.ctx, 1)
+	c.Assert(err, IsNil)
+	_, err = cli.UpdateServiceGCSafePoint(s.ctx, "br", 3600, 1)
+	c.Assert(err, IsNil)
+}
@@ -15,6 +15,7 @@ package client_test
 
 import (
 	"context"
+	"encoding/hex"
 	"math"
 	"path/filepath"
 	"sort"
@@ -32,9 +33,9 @@ import (
 	"github.com/pingcap/pd/v4/pkg/testutil"
 	"github.com/pingcap/pd/v4/server"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/tests"
 	"go.etcd.io/etcd/clientv3"
-	"go.uber.org/goleak"
 )
 
 func Test(t *testing.T) {
@@ -42,7 +43,7 @@ func Test(t *testing.T) {
 }
 
 func TestMain(m *testing.M) {
-	goleak.VerifyTestMain(m, testutil.LeakOptions...)
+	testutil.VerifyTestMainWithEtcd(m)
 }
 
 var _ = Suite(&clientTestSuite{})
@@ -187,6 +188,176 @@ func (s *clientTestSuite) TestLeaderTransfer(c *C) {
 	wg.Wait()
 }
 
+func (s *clientTestSuite) TestWatchStores(c *C) {
+	cluster, err := tests.NewTestCluster(s.ctx, 3)
+	c.Assert(err, IsNil)
+	defer cluster.Destroy()
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	leader := cluster.GetServer(cluster.WaitLeader())
+	c.Assert(leader.BootstrapCluster(), IsNil)
+
+	var endpoints []string
+	for _, s := range cluster.GetServers() {
+		endpoints = append(endpoints, s.GetConfig().AdvertiseClientUrls)
+	}
+	cli, err := pd.NewClientWithContext(s.ctx, endpoints, pd.SecurityOption{})
+	c.Assert(err, IsNil)
+	defer cli.Close()
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	ch, err := cli.WatchStores(ctx, 0)
+	c.Assert(err, IsNil)
+	nextEvent := func() *pd.StoreEvent {
+		select {
+		case events := <-ch:
+			c.Assert(events, HasLen, 1)
+			return events[0]
+		case <-time.After(10 * time.Second):
+			c.Fatal("no store event")
+			return nil
+		}
+	}
+
+	e := nextEvent()
+	c.Assert(e.Type, Equals, pd.StoreEventPut)
+	c.Assert(e.Store.GetId(), Equals, uint64(1))
+
+	putStore := func(svr *tests.TestServer, store *metapb.Store) {
+		_, err := svr.GetServer().PutStore(context.Background(), &pdpb.PutStoreRequest{Header: newHeader(svr.GetServer()), Store: store})
+		c.Assert(err, IsNil)
+	}
+	putStore(leader, &metapb.Store{Id: 2, Address: "mock://2"})
+	e = nextEvent()
+	c.Assert(e.Type, Equals, pd.StoreEventPut)
+	c.Assert(e.Store.GetAddress(), Equals, "mock://2")
+
+	// The watch is resumed after the leader changes.
+	c.Assert(leader.Stop(), IsNil)
+	leader = cluster.GetServer(cluster.WaitLeader())
+	putStore(leader, &metapb.Store{Id: 3, Address: "mock://3"})
+	e = nextEvent()
+	c.Assert(e.Type, Equals, pd.StoreEventPut)
+	c.Assert(e.Store.GetId(), Equals, uint64(3))
+
+	cancel()
+	for range ch {
+	}
+}
+
+func (s *clientTestSuite) TestGetRegionLabels(c *C) {
+	cluster, err := tests.NewTestCluster(s.ctx, 1)
+	c.Assert(err, IsNil)
+	defer cluster.Destroy()
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	leader := cluster.GetServer(cluster.WaitLeader())
+	c.Assert(leader.BootstrapCluster(), IsNil)
+	rc := leader.GetRaftCluster()
+	region := core.NewRegionInfo(&metapb.Region{
+		Id:          10,
+		StartKey:    []byte("a"),
+		EndKey:      []byte("c"),
+		RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+		Peers:       []*metapb.Peer{{Id: 11, StoreId: 1}},
+	}, &metapb.Peer{Id: 11, StoreId: 1})
+	c.Assert(rc.HandleRegionHeartbeat(region), IsNil)
+	rule := &labeler.LabelRule{
+		ID:          "test",
+		Labels:      []*labeler.RegionLabel{{Key: "tag", Value: "foo"}},
+		StartKeyHex: hex.EncodeToString([]byte("a")),
+		EndKeyHex:   hex.EncodeToString([]byte("b")),
+	}
+	c.Assert(rc.GetRegionLabeler().SetLabelRule(rule), IsNil)
+
+	cli, err := pd.NewClientWithContext(s.ctx, []string{leader.GetAddr()}, pd.SecurityOption{})
+	c.Assert(err, IsNil)
+	defer cli.Close()
+
+	labels, err := cli.GetRegionLabels(context.Background(), 10)
+	c.Assert(err, IsNil)
+	c.Assert(labels, DeepEquals, []*pd.RegionLabel{{Key: "tag", Value: "foo"}})
+	_, err = cli.GetRegionLabels(context.Background(), 20)
+	c.Assert(err, ErrorMatches, ".*404.*")
+
+	labels, err = cli.GetKeyLabels(context.Background(), []byte("a"))
+	c.Assert(err, IsNil)
+	c.Assert(labels, DeepEquals, []*pd.RegionLabel{{Key: "tag", Value: "foo"}})
+	labels, err = cli.GetKeyLabels(context.Background(), []byte("b"))
+	c.Assert(err, IsNil)
+	c.Assert(labels, HasLen, 0)
+}
+
+func (s *clientTestSuite) TestScatterRegions(c *C) {
+	cluster, err := tests.NewTestCluster(s.ctx, 1)
+	c.Assert(err, IsNil)
+	defer cluster.Destroy()
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	leader := cluster.GetServer(cluster.WaitLeader())
+	c.Assert(leader.BootstrapCluster(), IsNil)
+	region := core.NewRegionInfo(&metapb.Region{
+		Id:          10,
+		RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+		Peers:       []*metapb.Peer{{Id: 11, StoreId: 1}},
+	}, &metapb.Peer{Id: 11, StoreId: 1})
+	c.Assert(leader.GetRaftCluster().HandleRegionHeartbeat(region), IsNil)
+
+	cli, err := pd.NewClientWithContext(s.ctx, []string{leader.GetAddr()}, pd.SecurityOption{})
+	c.Assert(err, IsNil)
+	defer cli.Close()
+
+	failures, err := cli.ScatterRegions(context.Background(), []uint64{10, 20}, pd.WithGroup("test"))
+	c.Assert(err, IsNil)
+	c.Assert(failures, HasLen, 2)
+	c.Assert(failures[10], Matches, ".*not fully replicated.*")
+	c.Assert(failures[20], Matches, ".*not found.*")
+
+	err = cli.ScatterRegion(context.Background(), 20, pd.WithGroup("test"))
+	c.Assert(err, ErrorMatches, ".*not found.*")
+}
+
+func (s *clientTestSuite) TestUpdateServiceGCSafePoint(c *C) {
+	cluster, err := tests.NewTestCluster(s.ctx, 1)
+	c.Assert(err, IsNil)
+	defer cluster.Destroy()
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	leader := cluster.GetServer(cluster.WaitLeader())
+	c.Assert(leader.BootstrapCluster(), IsNil)
+
+	cli, err := pd.NewClientWithContext(s.ctx, []string{leader.GetAddr()}, pd.SecurityOption{})
+	c.Assert(err, IsNil)
+	defer cli.Close()
+
+	min, err := cli.UpdateServiceGCSafePoint(context.Background(), "a", 1000, 10)
+	c.Assert(err, IsNil)
+	c.Assert(min, Equals, uint64(10))
+	min, err = cli.UpdateServiceGCSafePoint(context.Background(), "b", 1000, 5)
+	c.Assert(err, IsNil)
+	c.Assert(min, Equals, uint64(5))
+	// The safepoint of a service cannot be moved backwards.
+	_, err = cli.UpdateServiceGCSafePoint(context.Background(), "a", 1000, 8)
+	c.Assert(err, ErrorMatches, ".*cannot be moved backwards.*")
+
+	// The gc safepoint is blocked by the service safepoints.
+	safePoint, err := cli.UpdateGCSafePoint(context.Background(), 20)
+	c.Assert(err, IsNil)
+	c.Assert(safePoint, Equals, uint64(5))
+
+	min, err = cli.UpdateServiceGCSafePoint(context.Background(), "b", 0, 0)
+	c.Assert(err, IsNil)
+	c.Assert(min, Equals, uint64(10))
+	min, err = cli.UpdateServiceGCSafePoint(context.Background(), "a", 0, 0)
+	c.Assert(err, IsNil)
+	c.Assert(min, Equals, uint64(0))
+}
+
 func (s *clientTestSuite) waitLeader(c *C, cli client, leader string) {
 	testutil.WaitUntil(c, func(c *C) bool {
 		cli.ScheduleCheckLeader()
@@ -0,0 +1,90 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/audit"
+	"github.com/pingcap/pd/v4/server/config"
+	"github.com/pingcap/pd/v4/tests"
+	"github.com/pingcap/pd/v4/tests/pdctl"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&auditTestSuite{})
+
+type auditTestSuite struct{}
+
+func (s *auditTestSuite) SetUpSuite(c *C) {
+	server.EnableZap = true
+}
+
+func (s *auditTestSuite) TestAudit(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tc, err := tests.NewTestCluster(ctx, 1, func(conf *config.Config) {
+		conf.Audit.Enable = true
+	})
+	c.Assert(err, IsNil)
+	err = tc.RunInitialServers()
+	c.Assert(err, IsNil)
+	tc.WaitLeader()
+	leaderServer := tc.GetServer(tc.GetLeader())
+	c.Assert(leaderServer.BootstrapCluster(), IsNil)
+	pdAddr := tc.GetConfig().GetClientURL()
+	cmd := pdctl.InitCommand()
+	defer tc.Destroy()
+
+	args := []string{"-u", pdAddr, "config", "set", "leader-schedule-limit", "8"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	args = []string{"-u", pdAddr, "config", "set", "region-schedule-limit", "16"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+
+	// audit command
+	args = []string{"-u", pdAddr, "audit"}
+	_, output, err := pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	var records []*audit.Record
+	c.Assert(json.Unmarshal(output, &records), IsNil)
+	c.Assert(records, HasLen, 3)
+	for _, r := range records[:2] {
+		c.Assert(r.Protocol, Equals, audit.ProtocolHTTP)
+		c.Assert(r.Method, Equals, http.MethodPost)
+		c.Assert(r.Route, Equals, "/pd/api/v1/config")
+		c.Assert(r.Status, Equals, http.StatusOK)
+	}
+	c.Assert(records[0].Time.After(records[1].Time), IsTrue)
+	// The cluster is bootstrapped by gRPC.
+	c.Assert(records[2].Protocol, Equals, audit.ProtocolGRPC)
+	c.Assert(records[2].Route, Equals, "/pdpb.PD/Bootstrap")
+
+	// audit --limit=1
+	args = []string{"-u", pdAddr, "audit", "--limit=1"}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	c.Assert(json.Unmarshal(output, &records), IsNil)
+	c.Assert(records, HasLen, 1)
+}
@@ -17,6 +17,8 @@ import (
 	"context"
 	"encoding/json"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -160,6 +162,13 @@ func (s *configTestSuite) TestConfig(c *C) {
 	c.Assert(json.Unmarshal(output, &labelPropertyCfg), IsNil)
 	c.Assert(labelPropertyCfg, DeepEquals, svr.GetLabelProperty())
 
+	// config set scheduler-weights <json>
+	args1 = []string{"-u", pdAddr, "config", "set", "scheduler-weights", `{"balance-hot-region-scheduler":4}`}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args1...)
+	c.Assert(err, IsNil)
+	time.Sleep(20 * time.Millisecond)
+	c.Assert(svr.GetScheduleConfig().SchedulerWeights, DeepEquals, map[string]float64{"balance-hot-region-scheduler": 4})
+
 	// test config read and write
 	testItems := []testItem{
 		{"leader-schedule-limit", uint64(64), func(scheduleConfig *config.ScheduleConfig) interface{} {
@@ -301,4 +310,169 @@ func (s *configTestSuite) TestPlacementRules(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(rules, HasLen, 1)
 	c.Assert(rules[0].Key(), Equals, [2]string{"pd", "test1"})
+
+	// test rule group
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "placement-rules", "rule-group", "set", "test-group", "10", "true")
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "Success!"), IsTrue)
+	var group placement.RuleGroup
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "placement-rules", "rule-group", "show", "test-group")
+	c.Assert(err, IsNil)
+	c.Assert(json.Unmarshal(output, &group), IsNil)
+	c.Assert(group, DeepEquals, placement.RuleGroup{ID: "test-group", Index: 10, Override: true})
+	var groups []placement.RuleGroup
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "placement-rules", "rule-group", "show")
+	c.Assert(err, IsNil)
+	c.Assert(json.Unmarshal(output, &groups), IsNil)
+	c.Assert(groups, HasLen, 2)
+
+	// test rule bundle load and save
+	_, _, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "placement-rules", "rule-bundle", "load", "--out="+fname)
+	c.Assert(err, IsNil)
+	var bundles []placement.GroupBundle
+	b, _ = ioutil.ReadFile(fname)
+	c.Assert(json.Unmarshal(b, &bundles), IsNil)
+	c.Assert(bundles, HasLen, 2)
+	c.Assert(bundles[0].ID, Equals, "pd")
+	c.Assert(bundles[1].ID, Equals, "test-group")
+	c.Assert(bundles[1].Index, Equals, 10)
+	c.Assert(bundles[1].Rules, HasLen, 1)
+
+	bundles = bundles[1:]
+	bundles[0].Override = false
+	b, _ = json.Marshal(bundles)
+	ioutil.WriteFile(fname, b, 0644)
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "placement-rules", "rule-bundle", "save", "--in="+fname)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "Success!"), IsTrue)
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "placement-rules", "show", "--group=test-group")
+	c.Assert(err, IsNil)
+	c.Assert(json.Unmarshal(output, &rules), IsNil)
+	c.Assert(rules, HasLen, 1)
+	c.Assert(rules[0].Key(), Equals, [2]string{"test-group", "test2"})
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "placement-rules", "show", "--group=pd")
+	c.Assert(err, IsNil)
+	rules2 = nil
+	c.Assert(json.Unmarshal(output, &rules2), IsNil)
+	c.Assert(rules2, HasLen, 0)
+
+	// the last voter rule cannot be removed.
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "placement-rules", "rule-bundle", "delete", "test-group")
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "Success!"), IsFalse)
+}
+
+func (s *configTestSuite) TestScheduleProfile(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	c.Assert(err, IsNil)
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	cluster.WaitLeader()
+	pdAddr := cluster.GetConfig().GetClientURL()
+	cmd := pdctl.InitCommand()
+
+	leaderServer := cluster.GetServer(cluster.GetLeader())
+	c.Assert(leaderServer.BootstrapCluster(), IsNil)
+	pdctl.MustPutStore(c, leaderServer.GetServer(), 1, metapb.StoreState_Up, nil)
+	defer cluster.Destroy()
+
+	mustExec := func(args []string, v interface{}) string {
+		args = append([]string{"-u", pdAddr}, args...)
+		_, output, err := pdctl.ExecuteCommandC(cmd, args...)
+		c.Assert(err, IsNil)
+		if v == nil {
+			return string(output)
+		}
+		c.Assert(json.Unmarshal(output, v), IsNil)
+		return ""
+	}
+
+	// schedule-profile set command
+	c.Assert(mustExec([]string{"schedule-profile", "set", "day", "region-schedule-limit=1", "max-store-down-time=1h", "--paused-schedulers=balance-region-scheduler"}, nil), Equals, "Success!\n")
+	c.Assert(mustExec([]string{"schedule-profile", "set", "night"}, nil), Equals, "Success!\n")
+	c.Assert(mustExec([]string{"schedule-profile", "set", "bad", "region-schedule-limit"}, nil), Matches, "Invalid config.*\n")
+	c.Assert(mustExec([]string{"schedule-profile", "set", "bad", "unknown=1"}, nil), Matches, "(?s)Failed!.*invalid schedule profile.*")
+
+	// schedule-profile list and show command
+	var profiles []*config.ScheduleProfile
+	mustExec([]string{"schedule-profile", "list"}, &profiles)
+	c.Assert(profiles, HasLen, 2)
+	var p config.ScheduleProfile
+	mustExec([]string{"schedule-profile", "show", "day"}, &p)
+	c.Assert(p.Config, DeepEquals, map[string]interface{}{"region-schedule-limit": float64(1), "max-store-down-time": "1h"})
+	c.Assert(p.PausedSchedulers, DeepEquals, []string{"balance-region-scheduler"})
+
+	// schedule-profile calendar command
+	f, _ := ioutil.TempFile("/tmp", "pd_tests")
+	fname := f.Name()
+	f.Close()
+	calendar := `{"windows": [{"profile": "day", "start": "00:00", "end": "00:00"}, {"profile": "night", "start": "00:00", "end": "00:00"}]}`
+	c.Assert(ioutil.WriteFile(fname, []byte(calendar), 0644), IsNil)
+	c.Assert(mustExec([]string{"schedule-profile", "calendar", "set", "--in=" + fname}, nil), Equals, "Success!\n")
+	var windows struct {
+		Windows []map[string]interface{} `json:"windows"`
+	}
+	mustExec([]string{"schedule-profile", "calendar"}, &windows)
+	c.Assert(windows.Windows, HasLen, 2)
+
+	// schedule-profile status command
+	var status struct {
+		Profile string                 `json:"profile"`
+		Config  *config.ScheduleConfig `json:"config"`
+	}
+	mustExec([]string{"schedule-profile", "status"}, &status)
+	c.Assert(status.Profile, Equals, "day")
+	c.Assert(status.Config.RegionScheduleLimit, Equals, uint64(1))
+	c.Assert(status.Config.MaxStoreDownTime.Duration, Equals, time.Hour)
+
+	// schedule-profile delete command
+	c.Assert(mustExec([]string{"schedule-profile", "delete", "night"}, nil), Matches, "(?s)Failed.*used by the calendar.*")
+	c.Assert(ioutil.WriteFile(fname, []byte(`{"windows": []}`), 0644), IsNil)
+	c.Assert(mustExec([]string{"schedule-profile", "calendar", "set", "--in=" + fname}, nil), Equals, "Success!\n")
+	c.Assert(mustExec([]string{"schedule-profile", "delete", "night"}, nil), Equals, "Success!\n")
+	mustExec([]string{"schedule-profile", "list"}, &profiles)
+	c.Assert(profiles, HasLen, 1)
+}
+
+func (s *configTestSuite) TestAuth(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tokenFile := filepath.Join(c.MkDir(), "tokens.json")
+	c.Assert(ioutil.WriteFile(tokenFile, []byte(`[
+		{"token": "admin-token", "user": "alice", "role": "admin"},
+		{"token": "reader-token", "user": "bob", "role": "read-only"}
+	]`), 0600), IsNil)
+	cluster, err := tests.NewTestCluster(ctx, 1, func(conf *config.Config) {
+		conf.Auth.Enable = true
+		conf.Auth.TokenFile = tokenFile
+	})
+	c.Assert(err, IsNil)
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	cluster.WaitLeader()
+	pdAddr := cluster.GetConfig().GetClientURL()
+	cmd := pdctl.InitCommand()
+	defer cluster.Destroy()
+
+	mustExec := func(args ...string) string {
+		args = append([]string{"-u", pdAddr}, args...)
+		_, output, err := pdctl.ExecuteCommandC(cmd, args...)
+		c.Assert(err, IsNil)
+		return string(output)
+	}
+
+	c.Assert(mustExec("config", "show"), Matches, "(?s).*\\[401\\].*")
+	var cfg config.ScheduleConfig
+	c.Assert(json.Unmarshal([]byte(mustExec("config", "show", "schedule", "--token=reader-token")), &cfg), IsNil)
+	c.Assert(mustExec("config", "set", "leader-schedule-limit", "64", "--token=reader-token"), Matches, "(?s).*\\[403\\].*")
+	c.Assert(mustExec("config", "set", "leader-schedule-limit", "64", "--token=admin-token"), Equals, "Success!\n")
+
+	// The token is read from the environment variable if the flag is not set.
+	os.Setenv("PD_CTL_TOKEN", "reader-token")
+	defer os.Unsetenv("PD_CTL_TOKEN")
+	c.Assert(mustExec("config", "set", "leader-schedule-limit", "32", "--token="), Matches, "(?s).*\\[403\\].*")
+	c.Assert(json.Unmarshal([]byte(mustExec("config", "show", "schedule")), &cfg), IsNil)
+	c.Assert(cfg.LeaderScheduleLimit, Equals, uint64(64))
 }
@@ -42,6 +42,7 @@ func InitCommand() *cobra.Command {
 	commandFlags := pdctl.CommandFlags{}
 	rootCmd := &cobra.Command{}
 	rootCmd.PersistentFlags().StringVarP(&commandFlags.URL, "pd", "u", "", "")
+	rootCmd.PersistentFlags().StringVar(&commandFlags.Token, "token", "", "")
 	rootCmd.Flags().StringVar(&commandFlags.CAPath, "cacert", "", "")
 	rootCmd.Flags().StringVar(&commandFlags.CertPath, "cert", "", "")
 	rootCmd.Flags().StringVar(&commandFlags.KeyPath, "key", "", "")
@@ -64,6 +65,10 @@ func InitCommand() *cobra.Command {
 		command.NewPluginCommand(),
 		command.NewComponentCommand(),
 		command.NewCompletionCommand(),
+		command.NewServiceGCSafepointCommand(),
+		command.NewRegionLabelCommand(),
+		command.NewScheduleProfileCommand(),
+		command.NewAuditCommand(),
 	)
 	return rootCmd
 }
@@ -23,8 +23,10 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/v4/pkg/typeutil"
 	"github.com/pingcap/pd/v4/server"
 	"github.com/pingcap/pd/v4/server/api"
+	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/statistics"
 	"github.com/pingcap/pd/v4/tests"
@@ -47,7 +49,9 @@ func (s *hotTestSuite) SetUpSuite(c *C) {
 func (s *hotTestSuite) TestHot(c *C) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	cluster, err := tests.NewTestCluster(ctx, 1)
+	cluster, err := tests.NewTestCluster(ctx, 1, func(conf *config.Config) {
+		conf.HotRegionHistory.Interval = typeutil.NewDuration(100 * time.Millisecond)
+	})
 	c.Assert(err, IsNil)
 	err = cluster.RunInitialServers()
 	c.Assert(err, IsNil)
@@ -79,6 +83,9 @@ func (s *hotTestSuite) TestHot(c *C) {
 	newStats.BytesRead = bytesRead
 	newStats.KeysWritten = keysWritten
 	newStats.KeysRead = keysRead
+	newStats.CpuUsages = []*pdpb.RecordPair{{Key: "cop-0", Value: 60}, {Key: "cop-1", Value: 40}}
+	newStats.ReadIoRates = []*pdpb.RecordPair{{Key: "cop", Value: 2048}}
+	newStats.WriteIoRates = []*pdpb.RecordPair{{Key: "raftstore", Value: 1024}}
 	rc := leaderServer.GetRaftCluster()
 	for i := statistics.DefaultWriteMfSize; i > 0; i-- {
 		newStats.Interval = &pdpb.TimeInterval{StartTimestamp: uint64(now - 10*i), EndTimestamp: uint64(now - 10*i + 10)}
@@ -94,6 +101,9 @@ func (s *hotTestSuite) TestHot(c *C) {
 	c.Assert(hotStores.BytesReadStats[1], Equals, float64(bytesRead)/10)
 	c.Assert(hotStores.KeysWriteStats[1], Equals, float64(keysWritten)/10)
 	c.Assert(hotStores.KeysReadStats[1], Equals, float64(keysRead)/10)
+	c.Assert(hotStores.CPUUsageStats[1], Equals, float64(100))
+	c.Assert(hotStores.DiskReadStats[1], Equals, float64(2048))
+	c.Assert(hotStores.DiskWriteStats[1], Equals, float64(1024))
 
 	// test hot region
 	statistics.Denoising = false
@@ -119,4 +129,18 @@ func (s *hotTestSuite) TestHot(c *C) {
 	time.Sleep(5000 * time.Millisecond)
 	testHot(hotReadRegionID, hotStoreId, "read")
 	testHot(hotWriteRegionID, hotStoreId, "write")
+
+	// test hot region history
+	var records []*statistics.HotRegionHistoryRecord
+	args = []string{"-u", pdAddr, "hot", "history", "--type", "write", "--store", "1", "--limit", "1"}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	c.Assert(json.Unmarshal(output, &records), IsNil)
+	c.Assert(records, HasLen, 1)
+	c.Assert(records[0].RegionID, Equals, hotWriteRegionID)
+	c.Assert(records[0].HotType, Equals, "write")
+	args = []string{"-u", pdAddr, "hot", "history", "--type", "all"}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	c.Assert(string(output), Matches, "(?s).*invalid hot_type.*")
 }
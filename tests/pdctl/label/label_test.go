@@ -25,6 +25,7 @@ import (
 	"github.com/pingcap/pd/v4/server"
 	"github.com/pingcap/pd/v4/server/api"
 	"github.com/pingcap/pd/v4/server/config"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/tests"
 	"github.com/pingcap/pd/v4/tests/pdctl"
 )
@@ -133,3 +134,59 @@ func (s *labelTestSuite) TestLabel(c *C) {
 	ss = []*metapb.Store{stores[0], stores[2]}
 	pdctl.CheckStoresInfo(c, storesInfo.Stores, ss)
 }
+
+func (s *labelTestSuite) TestRegionLabel(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	c.Assert(err, IsNil)
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	cluster.WaitLeader()
+	pdAddr := cluster.GetConfig().GetClientURL()
+	cmd := pdctl.InitCommand()
+
+	leaderServer := cluster.GetServer(cluster.GetLeader())
+	c.Assert(leaderServer.BootstrapCluster(), IsNil)
+	pdctl.MustPutStore(c, leaderServer.GetServer(), 1, metapb.StoreState_Up, nil)
+	pdctl.MustPutRegion(c, cluster, 3, 1, []byte("a"), []byte("c"))
+	defer cluster.Destroy()
+
+	mustExec := func(args []string, v interface{}) string {
+		args = append([]string{"-u", pdAddr}, args...)
+		_, output, err := pdctl.ExecuteCommandC(cmd, args...)
+		c.Assert(err, IsNil)
+		if v == nil {
+			return string(output)
+		}
+		c.Assert(json.Unmarshal(output, v), IsNil)
+		return ""
+	}
+
+	// region-label set command
+	c.Assert(mustExec([]string{"region-label", "set", "rule1", "merge=deny", "tag=foo", "--start-key=61", "--end-key=62", "--ttl=1h"}, nil), Equals, "Success!\n")
+	c.Assert(mustExec([]string{"region-label", "set", "rule2", "merge"}, nil), Matches, "Invalid label.*\n")
+	c.Assert(mustExec([]string{"region-label", "set", "rule2", "schedule=foo"}, nil), Matches, "(?s)Failed!.*invalid label rule.*")
+
+	// region-label rules and show command
+	var rules []*labeler.LabelRule
+	mustExec([]string{"region-label", "rules"}, &rules)
+	c.Assert(rules, HasLen, 1)
+	c.Assert(rules[0].ID, Equals, "rule1")
+	c.Assert(rules[0].ExpireAt, NotNil)
+	var rule labeler.LabelRule
+	mustExec([]string{"region-label", "show", "rule1"}, &rule)
+	c.Assert(rule.Labels, DeepEquals, []*labeler.RegionLabel{{Key: "merge", Value: "deny"}, {Key: "tag", Value: "foo"}})
+
+	// region-label region and key command
+	var labels []*labeler.RegionLabel
+	mustExec([]string{"region-label", "region", "3"}, &labels)
+	c.Assert(labels, HasLen, 2)
+	mustExec([]string{"region-label", "key", "62"}, &labels)
+	c.Assert(labels, HasLen, 0)
+
+	// region-label delete command
+	c.Assert(mustExec([]string{"region-label", "delete", "rule1"}, nil), Equals, "Success!\n")
+	mustExec([]string{"region-label", "rules"}, &rules)
+	c.Assert(rules, HasLen, 0)
+}
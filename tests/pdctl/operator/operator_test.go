@@ -15,15 +15,19 @@ package operator_test
 
 import (
 	"context"
+	"encoding/json"
 	"strings"
 	"testing"
 	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/pkg/testutil"
 	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/api"
 	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule"
 	"github.com/pingcap/pd/v4/tests"
 	"github.com/pingcap/pd/v4/tests/pdctl"
 )
@@ -197,6 +201,38 @@ func (s *operatorTestSuite) TestOperator(c *C) {
 	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
 	c.Assert(err, IsNil)
 	c.Assert(strings.Contains(string(output), "scatter-region"), IsTrue)
+	// operator add scatter-region <region_id> [<region_id>...] --group=<group>
+	args = []string{"-u", pdAddr, "operator", "add", "scatter-region", "3", "100", "--group=test"}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	var scatterResult api.ScatterRegionsResult
+	c.Assert(json.Unmarshal(output, &scatterResult), IsNil)
+	c.Assert(scatterResult.FailedRegions, HasKey, uint64(100))
+
+	// operator queue
+	args = []string{"-u", pdAddr, "operator", "queue"}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	var queues []*schedule.WaitingQueueStatus
+	c.Assert(json.Unmarshal(output, &queues), IsNil)
+	c.Assert(queues, HasLen, 0)
+
+	// operator history --region=<region_id> --kind=<kind>
+	args = []string{"-u", pdAddr, "operator", "history", "--region=3", "--kind=merge"}
+	var records []*schedule.OperatorHistoryRecord
+	// The history is saved asynchronously.
+	testutil.WaitUntil(c, func(c *C) bool {
+		_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+		c.Assert(err, IsNil)
+		c.Assert(json.Unmarshal(output, &records), IsNil)
+		return len(records) == 1
+	})
+	c.Assert(records[0].Desc, Equals, "admin-merge-region")
+	c.Assert(records[0].Status, Equals, "Canceled")
+	args = []string{"-u", pdAddr, "operator", "history", "--limit=a"}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "invalid limit"), IsTrue)
 
 	// test echo
 	echo := pdctl.GetEcho([]string{"-u", pdAddr, "operator", "remove", "1"})
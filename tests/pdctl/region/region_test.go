@@ -16,6 +16,7 @@ package region_test
 import (
 	"context"
 	"encoding/json"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -24,7 +25,9 @@ import (
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/pd/v4/server"
 	"github.com/pingcap/pd/v4/server/api"
+	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule"
 	"github.com/pingcap/pd/v4/tests"
 	"github.com/pingcap/pd/v4/tests/pdctl"
 )
@@ -252,3 +255,52 @@ func (s *regionTestSuite) TestRegion(c *C) {
 	c.Assert(json.Unmarshal(output, &regionsInfo), IsNil)
 	pdctl.CheckRegionsInfo(c, regionsInfo, []*core.RegionInfo{r3, r4})
 }
+
+func (s *regionTestSuite) TestRegionMergeJob(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1, func(cfg *config.Config) { cfg.Replication.MaxReplicas = 1 })
+	c.Assert(err, IsNil)
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	cluster.WaitLeader()
+	pdAddr := cluster.GetConfig().GetClientURL()
+	cmd := pdctl.InitCommand()
+
+	leaderServer := cluster.GetServer(cluster.GetLeader())
+	c.Assert(leaderServer.BootstrapCluster(), IsNil)
+	pdctl.MustPutStore(c, leaderServer.GetServer(), 1, metapb.StoreState_Up, nil)
+	pdctl.MustPutRegion(c, cluster, 1, 1, []byte("a"), []byte("b"), core.SetApproximateSize(1), core.SetApproximateKeys(1))
+	pdctl.MustPutRegion(c, cluster, 2, 1, []byte("b"), []byte("c"), core.SetApproximateSize(1), core.SetApproximateKeys(1))
+	defer cluster.Destroy()
+
+	mustExec := func(args []string, v interface{}) string {
+		args = append([]string{"-u", pdAddr, "region", "merge-job"}, args...)
+		_, output, err := pdctl.ExecuteCommandC(cmd, args...)
+		c.Assert(err, IsNil)
+		if v == nil {
+			return string(output)
+		}
+		c.Assert(json.Unmarshal(output, v), IsNil)
+		return ""
+	}
+
+	// region merge-job add command
+	var job schedule.MergeJobStatus
+	mustExec([]string{"add", "--start-key=61", "--end-key=63", "--concurrency=4"}, &job)
+	c.Assert(job.Total, Equals, 1)
+	c.Assert(job.Concurrency, Equals, 4)
+	c.Assert(mustExec([]string{"add", "--start-key=zz"}, nil), Matches, "(?s)Failed to add merge job.*hex format.*")
+
+	// region merge-job show command
+	var jobs []*schedule.MergeJobStatus
+	mustExec([]string{"show"}, &jobs)
+	c.Assert(jobs, HasLen, 1)
+	c.Assert(jobs[0].ID, Equals, job.ID)
+
+	// region merge-job cancel command
+	c.Assert(mustExec([]string{"cancel", "100"}, nil), Matches, "(?s)Failed to cancel merge job.*not found.*")
+	c.Assert(mustExec([]string{"cancel", strconv.FormatUint(job.ID, 10)}, nil), Equals, "Success!\n")
+	mustExec([]string{"show", strconv.FormatUint(job.ID, 10)}, &job)
+	c.Assert(job.State, Equals, schedule.MergeJobCanceled)
+}
@@ -0,0 +1,82 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safepoint_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/tests"
+	"github.com/pingcap/pd/v4/tests/pdctl"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&safepointTestSuite{})
+
+type safepointTestSuite struct{}
+
+func (s *safepointTestSuite) SetUpSuite(c *C) {
+	server.EnableZap = true
+}
+
+func (s *safepointTestSuite) TestServiceGCSafepoint(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tc, err := tests.NewTestCluster(ctx, 1)
+	c.Assert(err, IsNil)
+	err = tc.RunInitialServers()
+	c.Assert(err, IsNil)
+	tc.WaitLeader()
+	leaderServer := tc.GetServer(tc.GetLeader())
+	c.Assert(leaderServer.BootstrapCluster(), IsNil)
+	pdAddr := tc.GetConfig().GetClientURL()
+	cmd := pdctl.InitCommand()
+	defer tc.Destroy()
+
+	svr := leaderServer.GetServer()
+	_, err = svr.UpdateServiceGCSafePoint("a", 3600, 1)
+	c.Assert(err, IsNil)
+	_, err = svr.UpdateServiceGCSafePoint("b", 3600, 2)
+	c.Assert(err, IsNil)
+
+	type list struct {
+		ServiceGCSafepoints []*core.ServiceSafePoint `json:"service_gc_safe_points"`
+		GCSafePoint         uint64                   `json:"gc_safe_point"`
+	}
+
+	// show service gc safepoints
+	args := []string{"-u", pdAddr, "service-gc-safepoint"}
+	_, output, err := pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	var l list
+	c.Assert(json.Unmarshal(output, &l), IsNil)
+	c.Assert(l.ServiceGCSafepoints, HasLen, 2)
+
+	// delete a service gc safepoint
+	args = []string{"-u", pdAddr, "service-gc-safepoint", "delete", "a"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	ssps, err := svr.GetServiceGCSafePoints()
+	c.Assert(err, IsNil)
+	c.Assert(ssps, HasLen, 1)
+	c.Assert(ssps[0].ServiceID, Equals, "b")
+}
@@ -23,6 +23,7 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/pd/v4/server"
+	pdcluster "github.com/pingcap/pd/v4/server/cluster"
 	"github.com/pingcap/pd/v4/tests"
 	"github.com/pingcap/pd/v4/tests/pdctl"
 )
@@ -82,10 +83,10 @@ func (s *schedulerTestSuite) TestScheduler(c *C) {
 		if args != nil {
 			mustExec(args, nil)
 		}
-		var schedulers []string
+		var schedulers []*server.SchedulerStatus
 		mustExec([]string{"-u", pdAddr, "scheduler", "show"}, &schedulers)
 		for _, scheduler := range schedulers {
-			c.Assert(expected[scheduler], Equals, true)
+			c.Assert(expected[scheduler.Name], Equals, true)
 		}
 	}
 
@@ -232,8 +233,90 @@ func (s *schedulerTestSuite) TestScheduler(c *C) {
 	mustExec([]string{"-u", pdAddr, "scheduler", "config", "shuffle-region-scheduler", "show-roles"}, &roles)
 	c.Assert(roles, DeepEquals, []string{"learner"})
 
+	// test the config commands generated from the schema
+	echo := pdctl.GetEcho([]string{"-u", pdAddr, "scheduler", "config", "shuffle-region-scheduler", "schema"})
+	c.Assert(strings.Contains(echo, "roles: array of string in [leader,follower,learner]"), IsTrue)
+	c.Assert(strings.Contains(echo, "ranges: array of object, read-only"), IsTrue)
+	echo = pdctl.GetEcho([]string{"-u", pdAddr, "scheduler", "config", "shuffle-region-scheduler", "set", "roles", "leader,follower"})
+	c.Assert(strings.Contains(echo, "Success!"), IsTrue, Commentf(echo))
+	mustExec([]string{"-u", pdAddr, "scheduler", "config", "shuffle-region-scheduler", "show-roles"}, &roles)
+	c.Assert(roles, DeepEquals, []string{"leader", "follower"})
+	echo = pdctl.GetEcho([]string{"-u", pdAddr, "scheduler", "config", "shuffle-region-scheduler", "set", "roles", "witness"})
+	c.Assert(strings.Contains(echo, "invalid scheduler config"), IsTrue)
+	labelConfig := make(map[string]interface{})
+	mustExec([]string{"-u", pdAddr, "scheduler", "config", "label-scheduler"}, &labelConfig)
+	c.Assert(labelConfig["name"], Equals, "label-scheduler")
+	echo = pdctl.GetEcho([]string{"-u", pdAddr, "scheduler", "config", "label-scheduler", "set", "name", "foo"})
+	c.Assert(strings.Contains(echo, "name is read-only"), IsTrue)
+	echo = pdctl.GetEcho([]string{"-u", pdAddr, "scheduler", "config", "balance-hot-region-scheduler", "set", "read-priorities", "cpu,byte"})
+	c.Assert(strings.Contains(echo, "Success!"), IsTrue, Commentf(echo))
+	echo = pdctl.GetEcho([]string{"-u", pdAddr, "scheduler", "config", "balance-hot-region-scheduler", "set", "strict-picking-store", "false"})
+	c.Assert(strings.Contains(echo, "Success!"), IsTrue, Commentf(echo))
+	hotConfig := make(map[string]interface{})
+	mustExec([]string{"-u", pdAddr, "scheduler", "config", "balance-hot-region-scheduler"}, &hotConfig)
+	c.Assert(hotConfig["read-priorities"], DeepEquals, []interface{}{"cpu", "byte"})
+	c.Assert(hotConfig["strict-picking-store"], Equals, false)
+	echo = pdctl.GetEcho([]string{"-u", pdAddr, "scheduler", "config", "balance-hot-region-scheduler", "set", "write-priorities", "memory"})
+	c.Assert(strings.Contains(echo, "invalid scheduler config"), IsTrue)
+
+	// test split hot region scheduler
+	echo = pdctl.GetEcho([]string{"-u", pdAddr, "scheduler", "add", "split-hot-region-scheduler"})
+	c.Assert(strings.Contains(echo, "Success!"), IsTrue, Commentf(echo))
+	echo = pdctl.GetEcho([]string{"-u", pdAddr, "scheduler", "config", "split-hot-region-scheduler", "set", "split-policy", "scan"})
+	c.Assert(strings.Contains(echo, "Success!"), IsTrue, Commentf(echo))
+	splitConfig := make(map[string]interface{})
+	mustExec([]string{"-u", pdAddr, "scheduler", "config", "split-hot-region-scheduler"}, &splitConfig)
+	c.Assert(splitConfig["split-policy"], Equals, "scan")
+	echo = pdctl.GetEcho([]string{"-u", pdAddr, "scheduler", "remove", "split-hot-region-scheduler"})
+	c.Assert(strings.Contains(echo, "Success!"), IsTrue, Commentf(echo))
+
+	// test pause and resume
+	echo = pdctl.GetEcho([]string{"-u", pdAddr, "scheduler", "pause", "label-scheduler", "60", "incident"})
+	c.Assert(strings.Contains(echo, "Success!"), IsTrue)
+	var status []*server.SchedulerStatus
+	mustExec([]string{"-u", pdAddr, "scheduler", "show"}, &status)
+	for _, s := range status {
+		c.Assert(s.Paused, Equals, s.Name == "label-scheduler")
+		if s.Paused {
+			c.Assert(s.Reason, Equals, "incident")
+			c.Assert(s.RemainingSeconds > 0, IsTrue)
+		}
+	}
+	echo = pdctl.GetEcho([]string{"-u", pdAddr, "scheduler", "resume", "label-scheduler"})
+	c.Assert(strings.Contains(echo, "Success!"), IsTrue)
+	var resumed []*server.SchedulerStatus
+	mustExec([]string{"-u", pdAddr, "scheduler", "show"}, &resumed)
+	for _, s := range resumed {
+		c.Assert(s.Paused, IsFalse)
+	}
+
+	// test simulate
+	var simulated struct {
+		Operators []string                     `json:"operators"`
+		Stores    []*pdcluster.StoreScoreDelta `json:"stores"`
+	}
+	mustExec([]string{"-u", pdAddr, "scheduler", "simulate", "evict-leader-scheduler", "1", "--schedule-config", `{"leader-schedule-limit":1}`}, &simulated)
+	c.Assert(simulated.Stores, HasLen, 4)
+	echo = pdctl.GetEcho([]string{"-u", pdAddr, "scheduler", "simulate", "foo-scheduler"})
+	c.Assert(strings.Contains(echo, "invalid simulation"), IsTrue)
+	echo = pdctl.GetEcho([]string{"-u", pdAddr, "scheduler", "simulate", "--store-weights", "foo"})
+	c.Assert(strings.Contains(echo, "Failed!"), IsTrue)
+
+	// test explain
+	echo = pdctl.GetEcho([]string{"-u", pdAddr, "scheduler", "explain", "balance-leader-scheduler", "enable", "5"})
+	c.Assert(strings.Contains(echo, "Success!"), IsTrue)
+	var explain server.SchedulerExplain
+	mustExec([]string{"-u", pdAddr, "scheduler", "explain", "balance-leader-scheduler"}, &explain)
+	c.Assert(explain.Enabled, IsTrue)
+	echo = pdctl.GetEcho([]string{"-u", pdAddr, "scheduler", "explain", "balance-leader-scheduler", "disable"})
+	c.Assert(strings.Contains(echo, "Success!"), IsTrue)
+	mustExec([]string{"-u", pdAddr, "scheduler", "explain", "balance-leader-scheduler"}, &explain)
+	c.Assert(explain.Enabled, IsFalse)
+	echo = pdctl.GetEcho([]string{"-u", pdAddr, "scheduler", "explain", "foo-scheduler"})
+	c.Assert(strings.Contains(echo, "scheduler not found"), IsTrue)
+
 	// test echo
-	echo := pdctl.GetEcho([]string{"-u", pdAddr, "scheduler", "add", "balance-region-scheduler"})
+	echo = pdctl.GetEcho([]string{"-u", pdAddr, "scheduler", "add", "balance-region-scheduler"})
 	c.Assert(strings.Contains(echo, "Success!"), IsTrue)
 	echo = pdctl.GetEcho([]string{"-u", pdAddr, "scheduler", "remove", "balance-region-scheduler"})
 	c.Assert(strings.Contains(echo, "Success!"), IsTrue)
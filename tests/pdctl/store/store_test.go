@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/pd/v4/server"
 	"github.com/pingcap/pd/v4/server/api"
 	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/storelimit"
 	"github.com/pingcap/pd/v4/tests"
 	"github.com/pingcap/pd/v4/tests/pdctl"
 )
@@ -155,28 +156,60 @@ func (s *storeTestSuite) TestStore(c *C) {
 	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
 	c.Assert(err, IsNil)
 	limits := leaderServer.GetRaftCluster().GetOperatorController().GetAllStoresLimit()
-	c.Assert(limits[1].Rate()*60, Equals, float64(10))
+	c.Assert(limits[1][storelimit.AddPeer].Rate()*60, Equals, float64(10))
+	c.Assert(limits[1][storelimit.RemovePeer].Rate()*60, Equals, float64(10))
+
+	// store limit <store_id> <rate> <type>
+	args = []string{"-u", pdAddr, "store", "limit", "1", "5", "remove-peer"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	limits = leaderServer.GetRaftCluster().GetOperatorController().GetAllStoresLimit()
+	c.Assert(limits[1][storelimit.AddPeer].Rate()*60, Equals, float64(10))
+	c.Assert(limits[1][storelimit.RemovePeer].Rate()*60, Equals, float64(5))
+	args = []string{"-u", pdAddr, "store", "limit", "1", "5", "unknown-type"}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "unknown type"), IsTrue)
 
 	// store limit all <rate>
 	args = []string{"-u", pdAddr, "store", "limit", "all", "20"}
 	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
 	c.Assert(err, IsNil)
 	limits = leaderServer.GetRaftCluster().GetOperatorController().GetAllStoresLimit()
-	c.Assert(limits[1].Rate()*60, Equals, float64(20))
-	c.Assert(limits[3].Rate()*60, Equals, float64(20))
+	c.Assert(limits[1][storelimit.AddPeer].Rate()*60, Equals, float64(20))
+	c.Assert(limits[3][storelimit.RemovePeer].Rate()*60, Equals, float64(20))
 	_, ok := limits[2]
 	c.Assert(ok, IsFalse)
 
+	// store limit all <rate> <type>
+	args = []string{"-u", pdAddr, "store", "limit", "all", "30", "add-peer"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+
 	// store limit
 	args = []string{"-u", pdAddr, "store", "limit"}
-	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
 	c.Assert(err, IsNil)
-	limits = leaderServer.GetRaftCluster().GetOperatorController().GetAllStoresLimit()
-	c.Assert(limits[1].Rate()*60, Equals, float64(20))
-	c.Assert(limits[3].Rate()*60, Equals, float64(20))
-	_, ok = limits[2]
+	shown := make(map[string]map[string]struct {
+		Rate float64 `json:"rate"`
+		Mode string  `json:"mode"`
+	})
+	c.Assert(json.Unmarshal(output, &shown), IsNil)
+	c.Assert(shown["1"]["add-peer"].Rate, Equals, float64(30))
+	c.Assert(shown["1"]["remove-peer"].Rate, Equals, float64(20))
+	c.Assert(shown["3"]["add-peer"].Mode, Equals, "manual")
+	_, ok = shown["2"]
 	c.Assert(ok, IsFalse)
 
+	// The limits are reloaded after the cluster restarts.
+	rc := leaderServer.GetRaftCluster()
+	rc.Stop()
+	c.Assert(rc.Start(leaderServer.GetServer()), IsNil)
+	limits = leaderServer.GetRaftCluster().GetOperatorController().GetAllStoresLimit()
+	c.Assert(limits[1][storelimit.AddPeer].Rate()*60, Equals, float64(30))
+	c.Assert(limits[1][storelimit.RemovePeer].Rate()*60, Equals, float64(20))
+	c.Assert(limits[3][storelimit.AddPeer].Mode(), Equals, schedule.StoreLimitManual)
+
 	// store delete <store_id> command
 	c.Assert(storeInfo.Store.State, Equals, metapb.StoreState_Up)
 	args = []string{"-u", pdAddr, "store", "delete", "1"}
@@ -27,7 +27,6 @@ import (
 	"github.com/pingcap/pd/v4/server"
 	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/tests"
-	"go.uber.org/goleak"
 )
 
 // dialClient used to dial http request.
@@ -42,7 +41,7 @@ func Test(t *testing.T) {
 }
 
 func TestMain(m *testing.M) {
-	goleak.VerifyTestMain(m, testutil.LeakOptions...)
+	testutil.VerifyTestMainWithEtcd(m)
 }
 
 var _ = Suite(&serverTestSuite{})
@@ -16,6 +16,8 @@ package cluster_test
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"sync"
 	"testing"
 	"time"
@@ -34,7 +36,10 @@ import (
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/kv"
 	syncer "github.com/pingcap/pd/v4/server/region_syncer"
+	"github.com/pingcap/pd/v4/server/replay"
 	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/storelimit"
 	"github.com/pingcap/pd/v4/tests"
 	"github.com/pingcap/pd/v4/tests/pdctl"
 	"github.com/pkg/errors"
@@ -272,7 +277,7 @@ func testStateAndLimit(c *C, clusterID uint64, rc *cluster.RaftCluster, grpcPDCl
 	// prepare
 	storeID := store.GetId()
 	oc := rc.GetOperatorController()
-	oc.SetAllStoresLimit(1.0, schedule.StoreLimitManual)
+	oc.SetAllStoresLimit(1.0, schedule.StoreLimitManual, storelimit.AddPeer)
 	resetStoreState(c, rc, store.GetId(), beforeState)
 	_, isOKBefore := oc.GetAllStoresLimit()[storeID]
 	// run
@@ -822,6 +827,57 @@ func (s *clusterTestSuite) TestReplicateModeStatus(c *C) {
 	c.Assert(hbRes.GetReplicateStatus().GetMode(), Equals, replicate_mode.ReplicateStatus_DR_AUTOSYNC) // check status in store heartbeat response
 }
 
+func (s *clusterTestSuite) TestHeartbeatRecord(c *C) {
+	dir, err := ioutil.TempDir("", "pd-heartbeat-record")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	tc, err := tests.NewTestCluster(s.ctx, 1, func(conf *config.Config) {
+		conf.HeartbeatRecord.Dir = dir
+	})
+	defer tc.Destroy()
+	c.Assert(err, IsNil)
+	err = tc.RunInitialServers()
+	c.Assert(err, IsNil)
+	tc.WaitLeader()
+	leaderServer := tc.GetServer(tc.GetLeader())
+	grpcPDClient := testutil.MustNewGrpcClient(c, leaderServer.GetAddr())
+	clusterID := leaderServer.GetClusterID()
+	bootstrapCluster(c, clusterID, grpcPDClient, "127.0.0.1:0")
+	rc := leaderServer.GetRaftCluster()
+	c.Assert(rc, NotNil)
+
+	_, err = grpcPDClient.StoreHeartbeat(context.Background(), &pdpb.StoreHeartbeatRequest{
+		Header: testutil.NewRequestHeader(clusterID),
+		Stats:  &pdpb.StoreStats{StoreId: 1, Capacity: 100},
+	})
+	c.Assert(err, IsNil)
+	region := core.NewRegionInfo(&metapb.Region{
+		Id:          2,
+		Peers:       []*metapb.Peer{{Id: 3, StoreId: 1}},
+		RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+	}, &metapb.Peer{Id: 3, StoreId: 1})
+	c.Assert(rc.HandleRegionHeartbeat(region), IsNil)
+	op := operator.NewOperator("test", "test", 2, region.GetRegionEpoch(), operator.OpAdmin, operator.RemovePeer{FromStore: 2})
+	c.Assert(rc.GetOperatorController().AddOperator(op), IsTrue)
+	// The recording is flushed when the cluster is stopped.
+	c.Assert(leaderServer.Stop(), IsNil)
+
+	files, err := replay.ListFiles(dir)
+	c.Assert(err, IsNil)
+	types := make(map[replay.RecordType]int)
+	c.Assert(replay.ReadFiles(files, func(r *replay.Record) error {
+		types[r.Type]++
+		if r.Type == replay.RecordOperator {
+			c.Assert(r.Operator.RegionID, Equals, uint64(2))
+			c.Assert(r.Operator.Steps, DeepEquals, []string{"remove peer on store 2"})
+		}
+		return nil
+	}), IsNil)
+	c.Assert(types[replay.RecordStoreHeartbeat], Equals, 1)
+	c.Assert(types[replay.RecordRegionHeartbeat], Equals, 1)
+	c.Assert(types[replay.RecordOperator], Equals, 1)
+}
+
 func newIsBootstrapRequest(clusterID uint64) *pdpb.IsBootstrappedRequest {
 	req := &pdpb.IsBootstrappedRequest{
 		Header: testutil.NewRequestHeader(clusterID),
@@ -26,7 +26,6 @@ import (
 	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/tests"
-	"go.uber.org/goleak"
 )
 
 func Test(t *testing.T) {
@@ -34,7 +33,7 @@ func Test(t *testing.T) {
 }
 
 func TestMain(m *testing.M) {
-	goleak.VerifyTestMain(m, testutil.LeakOptions...)
+	testutil.VerifyTestMainWithEtcd(m)
 }
 
 var _ = Suite(&serverTestSuite{})
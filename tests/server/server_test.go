@@ -22,7 +22,6 @@ import (
 	"github.com/pingcap/pd/v4/pkg/testutil"
 	"github.com/pingcap/pd/v4/server"
 	"github.com/pingcap/pd/v4/tests"
-	"go.uber.org/goleak"
 
 	// Register schedulers.
 	_ "github.com/pingcap/pd/v4/server/schedulers"
@@ -33,7 +32,7 @@ func Test(t *testing.T) {
 }
 
 func TestMain(m *testing.M) {
-	goleak.VerifyTestMain(m, testutil.LeakOptions...)
+	testutil.VerifyTestMainWithEtcd(m)
 }
 
 var _ = Suite(&serverTestSuite{})
@@ -24,7 +24,10 @@ import (
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/pd/v4/pkg/testutil"
+	"github.com/pingcap/pd/v4/pkg/typeutil"
 	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/config"
+	"github.com/pingcap/pd/v4/server/tso"
 	"github.com/pingcap/pd/v4/tests"
 	"go.uber.org/goleak"
 )
@@ -170,6 +173,59 @@ func (s *testTsoSuite) TestTsoCount0(c *C) {
 	c.Assert(err, NotNil)
 }
 
+func (s *testTsoSuite) TestDispatcher(c *C) {
+	cluster, err := tests.NewTestCluster(s.ctx, 1, func(conf *config.Config) {
+		conf.TsoMaxBatchSize = 4
+		conf.TsoBatchWaitDuration = typeutil.NewDuration(5 * time.Millisecond)
+	})
+	defer cluster.Destroy()
+	c.Assert(err, IsNil)
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	leaderServer := cluster.GetServer(cluster.WaitLeader())
+	grpcPDClient := testutil.MustNewGrpcClient(c, leaderServer.GetAddr())
+	clusterID := leaderServer.GetClusterID()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// Each stream gets a range ending at the returned logical, the ranges of
+	// all streams must not overlap.
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		ranges = make(map[int64]map[int64]struct{})
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(count uint32) {
+			defer wg.Done()
+			tsoClient, err := grpcPDClient.Tso(ctx)
+			c.Assert(err, IsNil)
+			defer tsoClient.CloseSend()
+			req := &pdpb.TsoRequest{Header: testutil.NewRequestHeader(clusterID), Count: count}
+			for j := 0; j < 50; j++ {
+				c.Assert(tsoClient.Send(req), IsNil)
+				resp, err := tsoClient.Recv()
+				c.Assert(err, IsNil)
+				c.Assert(resp.GetCount(), Equals, count)
+				ts := resp.GetTimestamp()
+				mu.Lock()
+				if ranges[ts.GetPhysical()] == nil {
+					ranges[ts.GetPhysical()] = make(map[int64]struct{})
+				}
+				for l := ts.GetLogical() - int64(count) + 1; l <= ts.GetLogical(); l++ {
+					_, ok := ranges[ts.GetPhysical()][l]
+					c.Assert(ok, IsFalse)
+					ranges[ts.GetPhysical()][l] = struct{}{}
+				}
+				mu.Unlock()
+			}
+		}(uint32(i + 1))
+	}
+	wg.Wait()
+}
+
 var _ = Suite(&testTimeFallBackSuite{})
 
 type testTimeFallBackSuite struct {
@@ -311,3 +367,80 @@ func (s *testFollowerTsoSuite) TestRequest(c *C) {
 	c.Assert(strings.Contains(err.Error(), "can not get timestamp"), IsTrue)
 	failpoint.Disable("github.com/pingcap/pd/v4/server/tso/skipRetryGetTS")
 }
+
+var _ = Suite(&testLeaderTakeoverSuite{})
+
+type testLeaderTakeoverSuite struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (s *testLeaderTakeoverSuite) SetUpSuite(c *C) {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	server.EnableZap = true
+}
+
+func (s *testLeaderTakeoverSuite) TearDownSuite(c *C) {
+	s.cancel()
+}
+
+// tryGetTimestamp gets a timestamp from the server, it fails if the server
+// cannot serve in the timeout.
+func (s *testLeaderTakeoverSuite) tryGetTimestamp(c *C, addr string, clusterID uint64, timeout time.Duration) (*pdpb.Timestamp, error) {
+	grpcPDClient := testutil.MustNewGrpcClient(c, addr)
+	ctx, cancel := context.WithTimeout(s.ctx, timeout)
+	defer cancel()
+	tsoClient, err := grpcPDClient.Tso(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tsoClient.CloseSend()
+	req := &pdpb.TsoRequest{Header: testutil.NewRequestHeader(clusterID), Count: 1}
+	if err = tsoClient.Send(req); err != nil {
+		return nil, err
+	}
+	resp, err := tsoClient.Recv()
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetTimestamp(), nil
+}
+
+func (s *testLeaderTakeoverSuite) TestResignLeader(c *C) {
+	cluster, err := tests.NewTestCluster(s.ctx, 3)
+	defer cluster.Destroy()
+	c.Assert(err, IsNil)
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	leader := cluster.GetServer(cluster.WaitLeader())
+	clusterID := leader.GetClusterID()
+	// wait for followers to presync the timestamp window.
+	time.Sleep(3 * tso.UpdateTimestampStep)
+
+	last, err := s.tryGetTimestamp(c, leader.GetAddr(), clusterID, time.Second)
+	c.Assert(err, IsNil)
+
+	start := time.Now()
+	// ResignLeader blocks until etcd finishes transferring leadership, poll
+	// the new leader at the same time.
+	resignCh := make(chan error, 1)
+	go func() { resignCh <- leader.ResignLeader() }()
+	var ts *pdpb.Timestamp
+	for ts == nil {
+		c.Assert(time.Since(start), Less, 5*time.Second)
+		for _, svr := range cluster.GetServers() {
+			if svr == leader {
+				continue
+			}
+			if ts, err = s.tryGetTimestamp(c, svr.GetAddr(), clusterID, 20*time.Millisecond); err == nil {
+				break
+			}
+		}
+	}
+	unavailable := time.Since(start)
+	c.Logf("tso is unavailable for %v during resigning leader", unavailable)
+	c.Assert(unavailable, Less, 500*time.Millisecond)
+	c.Assert(ts.GetPhysical(), Greater, last.GetPhysical())
+	c.Assert(<-resignCh, IsNil)
+}
@@ -24,7 +24,6 @@ import (
 	"github.com/pingcap/pd/v4/server"
 	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/tests"
-	"go.uber.org/goleak"
 )
 
 func Test(t *testing.T) {
@@ -32,7 +31,7 @@ func Test(t *testing.T) {
 }
 
 func TestMain(m *testing.M) {
-	goleak.VerifyTestMain(m, testutil.LeakOptions...)
+	testutil.VerifyTestMainWithEtcd(m)
 }
 
 var _ = Suite(&serverTestSuite{})
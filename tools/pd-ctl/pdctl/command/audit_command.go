@@ -0,0 +1,54 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditPrefix = "pd/api/v1/admin/audit"
+)
+
+// NewAuditCommand returns an audit subcommand of rootCmd
+func NewAuditCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "audit [--limit=<limit>]",
+		Short: "show the recent audit records of the requests which change the cluster, the latest first",
+		Run:   showAuditCommandFunc,
+	}
+	c.Flags().String("limit", "", "the max number of the records")
+	return c
+}
+
+func showAuditCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	path := auditPrefix
+	if limit, _ := cmd.Flags().GetString("limit"); limit != "" {
+		path += "?" + url.Values{"limit": {limit}}.Encode()
+	}
+	r, err := doRequest(cmd, path, http.MethodGet)
+	if err != nil {
+		cmd.Printf("Failed to get the audit records: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
@@ -16,11 +16,11 @@ package command
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 	"path"
 	"strconv"
+	"strings"
 
 	"github.com/pingcap/pd/v4/server/schedule/placement"
 	"github.com/spf13/cobra"
@@ -34,6 +34,9 @@ var (
 	clusterVersionPrefix = "pd/api/v1/config/cluster-version"
 	rulesPrefix          = "pd/api/v1/config/rules"
 	rulePrefix           = "pd/api/v1/config/rule"
+	ruleGroupPrefix      = "pd/api/v1/config/rule_group"
+	ruleGroupsPrefix     = "pd/api/v1/config/rule_groups"
+	ruleBundlePrefix     = "pd/api/v1/config/placement-rule"
 )
 
 // NewConfigCommand return a config subcommand of rootCmd
@@ -254,7 +257,13 @@ func postConfigDataWithPath(cmd *cobra.Command, key, value, path string) error {
 	data := make(map[string]interface{})
 	val, err := strconv.ParseFloat(value, 64)
 	if err != nil {
-		val = value
+		// The items of a map are set in JSON, e.g. {"hot-region":4}.
+		var items map[string]interface{}
+		if json.Unmarshal([]byte(value), &items) == nil {
+			val = items
+		} else {
+			val = value
+		}
 	}
 	data[key] = val
 	reqData, err := json.Marshal(data)
@@ -356,7 +365,61 @@ func NewPlacementRulesCommand() *cobra.Command {
 		Run:   putPlacementRulesFunc,
 	}
 	save.Flags().String("in", "rules.json", "the filename contains rules")
-	c.AddCommand(enable, disable, show, load, save)
+	ruleGroup := &cobra.Command{
+		Use:   "rule-group",
+		Short: "rule group configurations",
+	}
+	groupShow := &cobra.Command{
+		Use:   "show [id]",
+		Short: "show rule group configuration(s)",
+		Run:   showRuleGroupFunc,
+	}
+	groupSet := &cobra.Command{
+		Use:   "set <id> <index> <override>",
+		Short: "update rule group configuration",
+		Run:   updateRuleGroupFunc,
+	}
+	groupDelete := &cobra.Command{
+		Use:   "delete <id>",
+		Short: "delete rule group configuration",
+		Run:   delRuleGroupFunc,
+	}
+	ruleGroup.AddCommand(groupShow, groupSet, groupDelete)
+	ruleBundle := &cobra.Command{
+		Use:   "rule-bundle",
+		Short: "process rules in group(s), set/save perform in a replace fashion",
+	}
+	bundleGet := &cobra.Command{
+		Use:   "get <id>",
+		Short: "get rule group config and its rules by group id",
+		Run:   getRuleBundle,
+	}
+	bundleGet.Flags().String("out", "", "the output file")
+	bundleSet := &cobra.Command{
+		Use:   "set",
+		Short: "set rule group config and its rules from file",
+		Run:   setRuleBundle,
+	}
+	bundleSet.Flags().String("in", "group.json", "the file contains one group config and its rules")
+	bundleDelete := &cobra.Command{
+		Use:   "delete <id>",
+		Short: "delete rule group config and its rules by group id",
+		Run:   delRuleBundle,
+	}
+	bundleLoad := &cobra.Command{
+		Use:   "load",
+		Short: "load all group configs and rules to file",
+		Run:   loadRuleBundle,
+	}
+	bundleLoad.Flags().String("out", "rules.json", "the output file")
+	bundleSave := &cobra.Command{
+		Use:   "save",
+		Short: "save all group configs and rules from file, groups and rules not in the file are removed",
+		Run:   saveRuleBundle,
+	}
+	bundleSave.Flags().String("in", "rules.json", "the file contains all group configs and all rules")
+	ruleBundle.AddCommand(bundleGet, bundleSet, bundleDelete, bundleLoad, bundleSave)
+	c.AddCommand(enable, disable, show, load, save, ruleGroup, ruleBundle)
 	return c
 }
 
@@ -440,23 +503,194 @@ func putPlacementRulesFunc(cmd *cobra.Command, args []string) {
 		cmd.Println(err)
 		return
 	}
+	// rules with zero count are deleted, all changes are applied in one batch.
+	var opts []placement.RuleOp
 	for _, r := range rules {
 		if r.Count > 0 {
-			b, _ := json.Marshal(r)
-			_, err = doRequest(cmd, rulePrefix, http.MethodPost, WithBody("application/json", bytes.NewBuffer(b)))
-			if err != nil {
-				fmt.Printf("failed to save rule %s/%s: %v\n", r.GroupID, r.ID, err)
-				return
-			}
-			fmt.Printf("saved rule %s/%s\n", r.GroupID, r.ID)
+			opts = append(opts, placement.RuleOp{Rule: r, Action: placement.RuleOpAdd})
 		} else {
-			_, err = doRequest(cmd, path.Join(rulePrefix, r.GroupID, r.ID), http.MethodDelete)
-			if err != nil {
-				fmt.Printf("failed to delete rule %s/%s: %v\n", r.GroupID, r.ID, err)
-				return
-			}
-			fmt.Printf("deleted rule %s/%s\n", r.GroupID, r.ID)
+			opts = append(opts, placement.RuleOp{Rule: r, Action: placement.RuleOpDel})
 		}
 	}
+	b, _ := json.Marshal(opts)
+	_, err = doRequest(cmd, path.Join(rulesPrefix, "batch"), http.MethodPost, WithBody("application/json", bytes.NewBuffer(b)))
+	if err != nil {
+		cmd.Printf("failed to save rules: %v\n", err)
+		return
+	}
+	for _, opt := range opts {
+		if opt.Action == placement.RuleOpAdd {
+			cmd.Printf("saved rule %s/%s\n", opt.GroupID, opt.ID)
+		} else {
+			cmd.Printf("deleted rule %s/%s\n", opt.GroupID, opt.ID)
+		}
+	}
+	cmd.Println("Success!")
+}
+
+func showRuleGroupFunc(cmd *cobra.Command, args []string) {
+	if len(args) > 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+
+	reqPath := ruleGroupsPrefix
+	if len(args) > 0 {
+		reqPath = path.Join(ruleGroupPrefix, args[0])
+	}
+	res, err := doRequest(cmd, reqPath, http.MethodGet)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println(res)
+}
+
+func updateRuleGroupFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 3 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	index, err := strconv.ParseInt(args[1], 10, 32)
+	if err != nil {
+		cmd.Printf("index %s should be a number\n", args[1])
+		return
+	}
+	var override bool
+	switch strings.ToLower(args[2]) {
+	case "false":
+	case "true":
+		override = true
+	default:
+		cmd.Printf("override %s should be a boolean\n", args[2])
+		return
+	}
+	b, _ := json.Marshal(placement.RuleGroup{
+		ID:       args[0],
+		Index:    int(index),
+		Override: override,
+	})
+
+	_, err = doRequest(cmd, ruleGroupPrefix, http.MethodPost, WithBody("application/json", bytes.NewBuffer(b)))
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println("Success!")
+}
+
+func delRuleGroupFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+
+	_, err := doRequest(cmd, path.Join(ruleGroupPrefix, args[0]), http.MethodDelete)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println("Success!")
+}
+
+func getRuleBundle(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+
+	res, err := doRequest(cmd, path.Join(ruleBundlePrefix, args[0]), http.MethodGet)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+
+	file := ""
+	if f := cmd.Flag("out"); f != nil {
+		file = f.Value.String()
+	}
+	if file == "" {
+		cmd.Println(res)
+		return
+	}
+	if err = ioutil.WriteFile(file, []byte(res), 0644); err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Printf("rule group saved to file %s\n", file)
+}
+
+func setRuleBundle(cmd *cobra.Command, args []string) {
+	var file string
+	if f := cmd.Flag("in"); f != nil {
+		file = f.Value.String()
+	}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	var bundle placement.GroupBundle
+	if err = json.Unmarshal(content, &bundle); err != nil {
+		cmd.Println(err)
+		return
+	}
+
+	_, err = doRequest(cmd, path.Join(ruleBundlePrefix, bundle.ID), http.MethodPost, WithBody("application/json", bytes.NewReader(content)))
+	if err != nil {
+		cmd.Printf("failed to save rule bundle %s: %v\n", bundle.ID, err)
+		return
+	}
+	cmd.Printf("saved rule bundle %s\n", bundle.ID)
+}
+
+func delRuleBundle(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+
+	_, err := doRequest(cmd, path.Join(ruleBundlePrefix, args[0]), http.MethodDelete)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println("Success!")
+}
+
+func loadRuleBundle(cmd *cobra.Command, args []string) {
+	res, err := doRequest(cmd, ruleBundlePrefix, http.MethodGet)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+
+	file := "rules.json"
+	if f := cmd.Flag("out"); f != nil {
+		file = f.Value.String()
+	}
+	if err = ioutil.WriteFile(file, []byte(res), 0644); err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Printf("rules saved to file %s\n", file)
+}
+
+func saveRuleBundle(cmd *cobra.Command, args []string) {
+	var file string
+	if f := cmd.Flag("in"); f != nil {
+		file = f.Value.String()
+	}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+
+	_, err = doRequest(cmd, ruleBundlePrefix, http.MethodPost, WithBody("application/json", bytes.NewReader(content)))
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
 	cmd.Println("Success!")
 }
@@ -33,6 +33,12 @@ var (
 	pingPrefix = "pd/api/v1/ping"
 )
 
+const allowFollowerHandle = "PD-Allow-follower-handle"
+
+// tokenEnv is the environment variable of the bearer token, which is used if
+// the token is not set by the flag.
+const tokenEnv = "PD_CTL_TOKEN"
+
 // InitHTTPSClient creates https client with ca file
 func InitHTTPSClient(CAPath, CertPath, KeyPath string) error {
 	tlsInfo := transport.TLSInfo{
@@ -94,6 +100,7 @@ func doRequest(cmd *cobra.Command, prefix string, method string,
 		if b.contentType != "" {
 			req.Header.Set("Content-Type", b.contentType)
 		}
+		setToken(cmd, req)
 		// the resp would be returned by the outer function
 		resp, err = dial(req)
 		if err != nil {
@@ -104,6 +111,53 @@ func doRequest(cmd *cobra.Command, prefix string, method string,
 	return resp, err
 }
 
+// doStreamRequest sends a GET request for a streaming response, and calls f
+// to consume the response body. The request can be served by followers since
+// the leader does not redirect streams.
+func doStreamRequest(cmd *cobra.Command, prefix string, f func(decoder *json.Decoder) error) error {
+	endpoints := getEndpoints(cmd)
+	var streamErr error
+	err := tryURLs(cmd, endpoints, func(endpoint string) error {
+		req, err := http.NewRequest(http.MethodGet, endpoint+"/"+prefix, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(allowFollowerHandle, "true")
+		setToken(cmd, req)
+		resp, err := dialClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			var msg []byte
+			msg, err = ioutil.ReadAll(resp.Body)
+			if err != nil {
+				return err
+			}
+			return errors.Errorf("[%d] %s", resp.StatusCode, msg)
+		}
+		// Do not try other endpoints once the stream is started.
+		streamErr = f(json.NewDecoder(resp.Body))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return streamErr
+}
+
+// setToken sets the bearer token of the request if there is one.
+func setToken(cmd *cobra.Command, req *http.Request) {
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = os.Getenv(tokenEnv)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
 func dial(req *http.Request) (string, error) {
 	resp, err := dialClient.Do(req)
 	if err != nil {
@@ -186,8 +240,15 @@ func postJSON(cmd *cobra.Command, prefix string, input map[string]interface{}) {
 	err = tryURLs(cmd, endpoints, func(endpoint string) error {
 		var msg []byte
 		var r *http.Response
+		var req *http.Request
 		url := endpoint + "/" + prefix
-		r, err = dialClient.Post(url, "application/json", bytes.NewBuffer(data))
+		req, err = http.NewRequest(http.MethodPost, url, bytes.NewBuffer(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		setToken(cmd, req)
+		r, err = dialClient.Do(req)
 		if err != nil {
 			return err
 		}
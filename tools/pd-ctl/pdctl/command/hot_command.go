@@ -15,6 +15,7 @@ package command
 
 import (
 	"net/http"
+	"net/url"
 
 	"github.com/spf13/cobra"
 )
@@ -23,6 +24,7 @@ const (
 	hotReadRegionsPrefix  = "pd/api/v1/hotspot/regions/read"
 	hotWriteRegionsPrefix = "pd/api/v1/hotspot/regions/write"
 	hotStoresPrefix       = "pd/api/v1/hotspot/stores"
+	hotHistoryPrefix      = "pd/api/v1/hotspot/regions/history"
 )
 
 // NewHotSpotCommand return a hot subcommand of rootCmd
@@ -34,6 +36,7 @@ func NewHotSpotCommand() *cobra.Command {
 	cmd.AddCommand(NewHotWriteRegionCommand())
 	cmd.AddCommand(NewHotReadRegionCommand())
 	cmd.AddCommand(NewHotStoreCommand())
+	cmd.AddCommand(NewHotHistoryCommand())
 	return cmd
 }
 
@@ -93,3 +96,49 @@ func showHotStoresCommandFunc(cmd *cobra.Command, args []string) {
 	}
 	cmd.Println(r)
 }
+
+// NewHotHistoryCommand return a hot history subcommand of hotSpotCmd
+func NewHotHistoryCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history [--region=<region_id>] [--store=<store_id>] [--type=<read|write>] [--start=<unix_time>] [--end=<unix_time>] [--limit=<limit>]",
+		Short: "show the history of the hot regions, the latest first",
+		Run:   showHotHistoryCommandFunc,
+	}
+	cmd.Flags().String("region", "", "only the hot peers of the region")
+	cmd.Flags().String("store", "", "only the hot peers on the store")
+	cmd.Flags().String("type", "", "only the hot peers of the type, read or write")
+	cmd.Flags().String("start", "", "only the snapshots at or after the unix time")
+	cmd.Flags().String("end", "", "only the snapshots before the unix time")
+	cmd.Flags().String("limit", "", "the max number of the hot peers")
+	return cmd
+}
+
+func showHotHistoryCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	query := url.Values{}
+	for flag, param := range map[string]string{
+		"region": "region_id",
+		"store":  "store_id",
+		"type":   "hot_type",
+		"start":  "start",
+		"end":    "end",
+		"limit":  "limit",
+	} {
+		if value, _ := cmd.Flags().GetString(flag); value != "" {
+			query.Set(param, value)
+		}
+	}
+	path := hotHistoryPrefix
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	r, err := doRequest(cmd, path, http.MethodGet)
+	if err != nil {
+		cmd.Printf("Failed to get hotspot history: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
@@ -14,8 +14,11 @@
 package command
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/pkg/errors"
@@ -36,9 +39,82 @@ func NewOperatorCommand() *cobra.Command {
 	c.AddCommand(NewCheckOperatorCommand())
 	c.AddCommand(NewAddOperatorCommand())
 	c.AddCommand(NewRemoveOperatorCommand())
+	c.AddCommand(NewWaitingQueuesCommand())
+	c.AddCommand(NewOperatorHistoryCommand())
 	return c
 }
 
+// NewWaitingQueuesCommand returns a command to show the waiting queues.
+func NewWaitingQueuesCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "queue",
+		Short: "show the waiting queues of the schedulers",
+		Run:   showWaitingQueuesCommandFunc,
+	}
+	return c
+}
+
+func showWaitingQueuesCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	r, err := doRequest(cmd, operatorsPrefix+"/waiting-queues", http.MethodGet)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println(r)
+}
+
+// NewOperatorHistoryCommand returns a command to show the finished operators.
+func NewOperatorHistoryCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "history [--region=<region_id>] [--store=<store_id>] [--kind=<kind>] [--scheduler=<name>] [--start=<unix_time>] [--end=<unix_time>] [--limit=<limit>]",
+		Short: "show the finished operators, the latest first",
+		Run:   showOperatorHistoryCommandFunc,
+	}
+	c.Flags().String("region", "", "only the operators of the region")
+	c.Flags().String("store", "", "only the operators involving the store")
+	c.Flags().String("kind", "", "only the operators of the kind")
+	c.Flags().String("scheduler", "", "only the operators created by the scheduler")
+	c.Flags().String("start", "", "only the operators ending at or after the unix time")
+	c.Flags().String("end", "", "only the operators ending before the unix time")
+	c.Flags().String("limit", "", "the max number of the operators")
+	return c
+}
+
+func showOperatorHistoryCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	query := url.Values{}
+	for flag, param := range map[string]string{
+		"region":    "region_id",
+		"store":     "store_id",
+		"kind":      "kind",
+		"scheduler": "scheduler",
+		"start":     "start",
+		"end":       "end",
+		"limit":     "limit",
+	} {
+		if value, _ := cmd.Flags().GetString(flag); value != "" {
+			query.Set(param, value)
+		}
+	}
+	path := operatorsPrefix + "/history"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	r, err := doRequest(cmd, path, http.MethodGet)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println(r)
+}
+
 // NewCheckOperatorCommand returns a command to show status of the operator.
 func NewCheckOperatorCommand() *cobra.Command {
 	c := &cobra.Command{
@@ -361,16 +437,17 @@ func splitRegionCommandFunc(cmd *cobra.Command, args []string) {
 // NewScatterRegionCommand returns a command to scatter a region.
 func NewScatterRegionCommand() *cobra.Command {
 	c := &cobra.Command{
-		Use:   "scatter-region <region_id>",
+		Use:   "scatter-region <region_id> [<region_id>...] [--group=<group>]",
 		Short: "usually used for a batch of adjacent regions",
-		Long:  "usually used for a batch of adjacent regions, for example, scatter the regions for 1 to 100, need to use the following commands in order: \"scatter-region 1; scatter-region 2; ...; scatter-region 100;\"",
+		Long:  "usually used for a batch of adjacent regions, for example, scatter the regions for 1 to 100 with \"scatter-region 1 2 ... 100\". The peers and the leaders of the regions in the same group are balanced among the stores.",
 		Run:   scatterRegionCommandFunc,
 	}
+	c.Flags().String("group", "", "the name of the scatter group")
 	return c
 }
 
 func scatterRegionCommandFunc(cmd *cobra.Command, args []string) {
-	if len(args) != 1 {
+	if len(args) == 0 {
 		cmd.Println(cmd.UsageString())
 		return
 	}
@@ -380,11 +457,27 @@ func scatterRegionCommandFunc(cmd *cobra.Command, args []string) {
 		cmd.Println(err)
 		return
 	}
+	group, _ := cmd.Flags().GetString("group")
 
-	input := make(map[string]interface{})
-	input["name"] = cmd.Name()
-	input["region_id"] = ids[0]
-	postJSON(cmd, operatorsPrefix, input)
+	if len(ids) == 1 {
+		input := make(map[string]interface{})
+		input["name"] = cmd.Name()
+		input["region_id"] = ids[0]
+		input["group"] = group
+		postJSON(cmd, operatorsPrefix, input)
+		return
+	}
+	data, err := json.Marshal(map[string]interface{}{"region_ids": ids, "group": group})
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	r, err := doRequest(cmd, regionsPrefix+"/scatter", http.MethodPost, WithBody("application/json", bytes.NewBuffer(data)))
+	if err != nil {
+		cmd.Printf("Failed! %s\n", err)
+		return
+	}
+	cmd.Println(r)
 }
 
 // NewRemoveOperatorCommand returns a command to remove operators.
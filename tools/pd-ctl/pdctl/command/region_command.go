@@ -41,6 +41,7 @@ var (
 	regionsSizePrefix      = "pd/api/v1/regions/size"
 	regionsKeyPrefix       = "pd/api/v1/regions/key"
 	regionsSiblingPrefix   = "pd/api/v1/regions/sibling"
+	regionsMergeJobsPrefix = "pd/api/v1/regions/merge-jobs"
 	regionIDPrefix         = "pd/api/v1/region/id"
 	regionKeyPrefix        = "pd/api/v1/region/key"
 )
@@ -57,6 +58,7 @@ func NewRegionCommand() *cobra.Command {
 	r.AddCommand(NewRegionWithSiblingCommand())
 	r.AddCommand(NewRegionWithStoreCommand())
 	r.AddCommand(NewRegionsWithStartKeyCommand())
+	r.AddCommand(NewRegionMergeJobCommand())
 
 	topRead := &cobra.Command{
 		Use:   `topread <limit> [--jq="<query string>"]`,
@@ -531,3 +533,87 @@ func printWithJQFilter(data, filter string) {
 
 	fmt.Printf("%s\n", out)
 }
+
+// NewRegionMergeJobCommand returns a merge-job subcommand of regionCmd
+func NewRegionMergeJobCommand() *cobra.Command {
+	r := &cobra.Command{
+		Use:   "merge-job [show [<job_id>] | add | cancel <job_id>]",
+		Short: "merge the empty or small regions in a key range in bulk",
+	}
+	r.AddCommand(&cobra.Command{
+		Use:   "show [<job_id>]",
+		Short: "show the progress of all merge jobs or the specified one",
+		Run:   showMergeJobCommandFunc,
+	})
+	add := &cobra.Command{
+		Use:   "add [--start-key=<hex>] [--end-key=<hex>] [--concurrency=<n>]",
+		Short: "add a job to merge the regions in the key range",
+		Run:   addMergeJobCommandFunc,
+	}
+	add.Flags().String("start-key", "", "the start key of the range in hex format, the range starts from the beginning if it is empty")
+	add.Flags().String("end-key", "", "the end key of the range in hex format, the range ends at the end if it is empty")
+	add.Flags().Int("concurrency", 0, "the number of merges running at the same time, 8 if it is not set")
+	r.AddCommand(add)
+	r.AddCommand(&cobra.Command{
+		Use:   "cancel <job_id>",
+		Short: "cancel the merge job, the running merges are not canceled",
+		Run:   cancelMergeJobCommandFunc,
+	})
+	return r
+}
+
+func showMergeJobCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) > 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	prefix := regionsMergeJobsPrefix
+	if len(args) == 1 {
+		prefix += "/" + args[0]
+	}
+	r, err := doRequest(cmd, prefix, http.MethodGet)
+	if err != nil {
+		cmd.Printf("Failed to get merge jobs: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
+
+func addMergeJobCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	startKey, _ := cmd.Flags().GetString("start-key")
+	endKey, _ := cmd.Flags().GetString("end-key")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	input := map[string]interface{}{
+		"start_key":   startKey,
+		"end_key":     endKey,
+		"concurrency": concurrency,
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	r, err := doRequest(cmd, regionsMergeJobsPrefix, http.MethodPost, WithBody("application/json", bytes.NewBuffer(data)))
+	if err != nil {
+		cmd.Printf("Failed to add merge job: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
+
+func cancelMergeJobCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	_, err := doRequest(cmd, regionsMergeJobsPrefix+"/"+args[0], http.MethodDelete)
+	if err != nil {
+		cmd.Printf("Failed to cancel merge job: %s\n", err)
+		return
+	}
+	cmd.Println("Success!")
+}
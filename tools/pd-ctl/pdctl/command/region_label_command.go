@@ -0,0 +1,195 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	regionLabelRulesPrefix  = "pd/api/v1/config/region-label/rules"
+	regionLabelRulePrefix   = "pd/api/v1/config/region-label/rule"
+	regionLabelRegionPrefix = "pd/api/v1/config/region-label/region"
+	regionLabelKeyPrefix    = "pd/api/v1/config/region-label/key"
+)
+
+// NewRegionLabelCommand returns a region-label subcommand of rootCmd
+func NewRegionLabelCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "region-label <subcommand>",
+		Short: "region label rules",
+	}
+	c.AddCommand(NewShowRegionLabelRulesCommand())
+	c.AddCommand(NewShowRegionLabelRuleCommand())
+	c.AddCommand(NewSetRegionLabelRuleCommand())
+	c.AddCommand(NewDeleteRegionLabelRuleCommand())
+	c.AddCommand(NewShowRegionLabelsCommand())
+	c.AddCommand(NewShowKeyLabelsCommand())
+	return c
+}
+
+// NewShowRegionLabelRulesCommand returns a rules subcommand of region-label
+func NewShowRegionLabelRulesCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rules",
+		Short: "show all label rules",
+		Run:   showRegionLabelRulesCommandFunc,
+	}
+}
+
+// NewShowRegionLabelRuleCommand returns a show subcommand of region-label
+func NewShowRegionLabelRuleCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <id>",
+		Short: "show the label rule",
+		Run:   showRegionLabelRuleCommandFunc,
+	}
+}
+
+// NewSetRegionLabelRuleCommand returns a set subcommand of region-label
+func NewSetRegionLabelRuleCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "set <id> <key>=<value>... [--start-key=<hex>] [--end-key=<hex>] [--ttl=<duration>]",
+		Short: "create or update a label rule which assigns the labels to the regions in the key range",
+		Run:   setRegionLabelRuleCommandFunc,
+	}
+	c.Flags().String("start-key", "", "the start key of the range in hex format, the range starts from the beginning if it is empty")
+	c.Flags().String("end-key", "", "the end key of the range in hex format, the range ends at the end if it is empty")
+	c.Flags().String("ttl", "", "the duration for the rule to live, such as 1h, the rule lives forever if it is empty")
+	return c
+}
+
+// NewDeleteRegionLabelRuleCommand returns a delete subcommand of region-label
+func NewDeleteRegionLabelRuleCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <id>",
+		Short: "delete the label rule",
+		Run:   deleteRegionLabelRuleCommandFunc,
+	}
+}
+
+// NewShowRegionLabelsCommand returns a region subcommand of region-label
+func NewShowRegionLabelsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "region <region_id>",
+		Short: "show the labels of the region",
+		Run:   showRegionLabelsCommandFunc,
+	}
+}
+
+// NewShowKeyLabelsCommand returns a key subcommand of region-label
+func NewShowKeyLabelsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "key <key>",
+		Short: "show the labels of the key in hex format",
+		Run:   showKeyLabelsCommandFunc,
+	}
+}
+
+func showRegionLabelRulesCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	r, err := doRequest(cmd, regionLabelRulesPrefix, http.MethodGet)
+	if err != nil {
+		cmd.Printf("Failed to get label rules: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
+
+func showRegionLabelRuleCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	r, err := doRequest(cmd, path.Join(regionLabelRulePrefix, args[0]), http.MethodGet)
+	if err != nil {
+		cmd.Printf("Failed to get the label rule: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
+
+func setRegionLabelRuleCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) < 2 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	labels := make([]map[string]interface{}, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 {
+			cmd.Printf("Invalid label %s, the label should be in the format of key=value\n", arg)
+			return
+		}
+		labels = append(labels, map[string]interface{}{"key": kv[0], "value": kv[1]})
+	}
+	startKey, _ := cmd.Flags().GetString("start-key")
+	endKey, _ := cmd.Flags().GetString("end-key")
+	ttl, _ := cmd.Flags().GetString("ttl")
+	input := map[string]interface{}{
+		"id":        args[0],
+		"labels":    labels,
+		"start_key": startKey,
+		"end_key":   endKey,
+		"ttl":       ttl,
+	}
+	postJSON(cmd, regionLabelRulePrefix, input)
+}
+
+func deleteRegionLabelRuleCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	_, err := doRequest(cmd, path.Join(regionLabelRulePrefix, args[0]), http.MethodDelete)
+	if err != nil {
+		cmd.Printf("Failed to delete the label rule: %s\n", err)
+		return
+	}
+	cmd.Println("Success!")
+}
+
+func showRegionLabelsCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	r, err := doRequest(cmd, path.Join(regionLabelRegionPrefix, args[0]), http.MethodGet)
+	if err != nil {
+		cmd.Printf("Failed to get the labels of the region: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
+
+func showKeyLabelsCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	r, err := doRequest(cmd, path.Join(regionLabelKeyPrefix, args[0]), http.MethodGet)
+	if err != nil {
+		cmd.Printf("Failed to get the labels of the key: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
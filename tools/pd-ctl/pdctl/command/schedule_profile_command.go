@@ -0,0 +1,226 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	scheduleProfilesPrefix        = "pd/api/v1/config/schedule-profile/profiles"
+	scheduleProfilePrefix         = "pd/api/v1/config/schedule-profile/profile"
+	scheduleProfileCalendarPrefix = "pd/api/v1/config/schedule-profile/calendar"
+	scheduleProfileStatusPrefix   = "pd/api/v1/config/schedule-profile/status"
+)
+
+// NewScheduleProfileCommand returns a schedule-profile subcommand of rootCmd
+func NewScheduleProfileCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "schedule-profile <subcommand>",
+		Short: "schedule profiles activated by the weekly time windows",
+	}
+	c.AddCommand(NewShowScheduleProfilesCommand())
+	c.AddCommand(NewShowScheduleProfileCommand())
+	c.AddCommand(NewSetScheduleProfileCommand())
+	c.AddCommand(NewDeleteScheduleProfileCommand())
+	c.AddCommand(NewScheduleProfileCalendarCommand())
+	c.AddCommand(NewScheduleProfileStatusCommand())
+	return c
+}
+
+// NewShowScheduleProfilesCommand returns a list subcommand of schedule-profile
+func NewShowScheduleProfilesCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "show all schedule profiles",
+		Run:   showScheduleProfilesCommandFunc,
+	}
+}
+
+// NewShowScheduleProfileCommand returns a show subcommand of schedule-profile
+func NewShowScheduleProfileCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <name>",
+		Short: "show the schedule profile",
+		Run:   showScheduleProfileCommandFunc,
+	}
+}
+
+// NewSetScheduleProfileCommand returns a set subcommand of schedule-profile
+func NewSetScheduleProfileCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "set <name> [<config>=<value>...] [--paused-schedulers=<scheduler>,...]",
+		Short: "create or update a schedule profile which overrides the schedule config and pauses the schedulers while it is active",
+		Run:   setScheduleProfileCommandFunc,
+	}
+	c.Flags().StringSlice("paused-schedulers", nil, "the schedulers paused while the profile is active")
+	return c
+}
+
+// NewDeleteScheduleProfileCommand returns a delete subcommand of schedule-profile
+func NewDeleteScheduleProfileCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <name>",
+		Short: "delete the schedule profile",
+		Run:   deleteScheduleProfileCommandFunc,
+	}
+}
+
+// NewScheduleProfileCalendarCommand returns a calendar subcommand of schedule-profile
+func NewScheduleProfileCalendarCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "calendar [set --in=<file>]",
+		Short: "show or replace the weekly time windows of the schedule profiles",
+		Run:   showScheduleProfileCalendarCommandFunc,
+	}
+	set := &cobra.Command{
+		Use:   "set --in=<file>",
+		Short: "replace the calendar with the one in the file",
+		Run:   setScheduleProfileCalendarCommandFunc,
+	}
+	set.Flags().String("in", "calendar.json", "the file contains the calendar")
+	c.AddCommand(set)
+	return c
+}
+
+// NewScheduleProfileStatusCommand returns a status subcommand of schedule-profile
+func NewScheduleProfileStatusCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "show the active schedule profile and the schedule config in use",
+		Run:   showScheduleProfileStatusCommandFunc,
+	}
+}
+
+func showScheduleProfilesCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	r, err := doRequest(cmd, scheduleProfilesPrefix, http.MethodGet)
+	if err != nil {
+		cmd.Printf("Failed to get schedule profiles: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
+
+func showScheduleProfileCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	r, err := doRequest(cmd, path.Join(scheduleProfilePrefix, args[0]), http.MethodGet)
+	if err != nil {
+		cmd.Printf("Failed to get the schedule profile: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
+
+func setScheduleProfileCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) < 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	config := make(map[string]interface{}, len(args)-1)
+	for _, arg := range args[1:] {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 {
+			cmd.Printf("Invalid config %s, the config should be in the format of config=value\n", arg)
+			return
+		}
+		// The numbers and the booleans are sent as they are, such as
+		// region-schedule-limit=2, and the others are sent as strings, such
+		// as max-store-down-time=1h.
+		var value interface{}
+		if err := json.Unmarshal([]byte(kv[1]), &value); err != nil {
+			value = kv[1]
+		}
+		config[kv[0]] = value
+	}
+	pausedSchedulers, _ := cmd.Flags().GetStringSlice("paused-schedulers")
+	input := map[string]interface{}{
+		"name":              args[0],
+		"config":            config,
+		"paused-schedulers": pausedSchedulers,
+	}
+	postJSON(cmd, scheduleProfilePrefix, input)
+}
+
+func deleteScheduleProfileCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	_, err := doRequest(cmd, path.Join(scheduleProfilePrefix, args[0]), http.MethodDelete)
+	if err != nil {
+		cmd.Printf("Failed to delete the schedule profile: %s\n", err)
+		return
+	}
+	cmd.Println("Success!")
+}
+
+func showScheduleProfileCalendarCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	r, err := doRequest(cmd, scheduleProfileCalendarPrefix, http.MethodGet)
+	if err != nil {
+		cmd.Printf("Failed to get the calendar: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
+
+func setScheduleProfileCalendarCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	file, _ := cmd.Flags().GetString("in")
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	_, err = doRequest(cmd, scheduleProfileCalendarPrefix, http.MethodPost, WithBody("application/json", bytes.NewBuffer(content)))
+	if err != nil {
+		cmd.Printf("Failed to set the calendar: %s\n", err)
+		return
+	}
+	cmd.Println("Success!")
+}
+
+func showScheduleProfileStatusCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	r, err := doRequest(cmd, scheduleProfileStatusPrefix, http.MethodGet)
+	if err != nil {
+		cmd.Printf("Failed to get the schedule profile status: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
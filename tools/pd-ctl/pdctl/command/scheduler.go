@@ -16,12 +16,15 @@ package command
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/url"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/pingcap/pd/v4/server/schedule"
 	"github.com/spf13/cobra"
 )
 
@@ -45,28 +48,119 @@ func NewSchedulerCommand() *cobra.Command {
 	c.AddCommand(NewPauseSchedulerCommand())
 	c.AddCommand(NewResumeSchedulerCommand())
 	c.AddCommand(NewConfigSchedulerCommand())
+	c.AddCommand(NewSimulateSchedulerCommand())
+	c.AddCommand(NewExplainSchedulerCommand())
 	return c
 }
 
+// NewSimulateSchedulerCommand returns a command to simulate the schedulers.
+func NewSimulateSchedulerCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "simulate [<scheduler> [<args>...]] [--config=<json>] [--schedule-config=<json>] [--store-weights=<json>]",
+		Short: "show the operators which a scheduler or the running schedulers would create with the checkers, nothing is dispatched",
+		Run:   simulateSchedulerCommandFunc,
+	}
+	c.Flags().String("config", "", "the config of the scheduler in json, it takes precedence over the args")
+	c.Flags().String("schedule-config", "", `the schedule config items to change in json, e.g. {"leader-schedule-policy":"size"}`)
+	c.Flags().String("store-weights", "", `the store weights to change in json, e.g. {"1":{"leader-weight":2,"region-weight":1}}`)
+	return c
+}
+
+func simulateSchedulerCommandFunc(cmd *cobra.Command, args []string) {
+	input := make(map[string]interface{})
+	if len(args) > 0 {
+		input["scheduler"] = args[0]
+		input["args"] = args[1:]
+	}
+	for _, flag := range []string{"config", "schedule-config", "store-weights"} {
+		value, _ := cmd.Flags().GetString(flag)
+		if value == "" {
+			continue
+		}
+		if !json.Valid([]byte(value)) {
+			cmd.Printf("Failed! invalid %s: %s\n", flag, value)
+			return
+		}
+		input[flag] = json.RawMessage(value)
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	r, err := doRequest(cmd, schedulersPrefix+"/simulate", http.MethodPost, WithBody("application/json", bytes.NewBuffer(data)))
+	if err != nil {
+		cmd.Printf("Failed! %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
+
+// NewExplainSchedulerCommand returns a command to explain the decisions of a scheduler.
+func NewExplainSchedulerCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "explain <scheduler> [enable [<rounds>] | disable]",
+		Short: "show the last rounds recorded in the explain mode of a scheduler, or turn the explain mode on or off",
+		Run:   explainSchedulerCommandFunc,
+	}
+	return c
+}
+
+func explainSchedulerCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) < 1 || len(args) > 3 {
+		cmd.Usage()
+		return
+	}
+	path := schedulersPrefix + "/" + args[0] + "/explain"
+	if len(args) == 1 {
+		r, err := doRequest(cmd, path, http.MethodGet)
+		if err != nil {
+			cmd.Printf("Failed to get the explain of %s: %s\n", args[0], err)
+			return
+		}
+		cmd.Println(r)
+		return
+	}
+	input := make(map[string]interface{})
+	switch {
+	case args[1] == "enable":
+		input["enable"] = true
+		if len(args) == 3 {
+			rounds, err := strconv.Atoi(args[2])
+			if err != nil {
+				cmd.Usage()
+				return
+			}
+			input["rounds"] = rounds
+		}
+	case args[1] == "disable" && len(args) == 2:
+		input["enable"] = false
+	default:
+		cmd.Usage()
+		return
+	}
+	postJSON(cmd, path, input)
+}
+
 // NewPauseSchedulerCommand returns a command to pause a scheduler.
 func NewPauseSchedulerCommand() *cobra.Command {
 	c := &cobra.Command{
-		Use:   "pause <scheduler> <delay>",
-		Short: "pause a scheduler",
+		Use:   "pause <scheduler> <delay> [<reason>]",
+		Short: "pause a scheduler for delay seconds, the pause is kept after the leader changes",
 		Run:   pauseOrResumeSchedulerCommandFunc,
 	}
 	return c
 }
 
 func pauseOrResumeSchedulerCommandFunc(cmd *cobra.Command, args []string) {
-	if len(args) != 2 && len(args) != 1 {
+	if len(args) < 1 || len(args) > 3 || (cmd.Name() == "resume" && len(args) != 1) {
 		cmd.Usage()
 		return
 	}
 	path := schedulersPrefix + "/" + args[0]
 	input := make(map[string]interface{})
 	input["delay"] = 0
-	if len(args) == 2 {
+	if len(args) >= 2 {
 		dealy, err := strconv.Atoi(args[1])
 		if err != nil {
 			cmd.Usage()
@@ -74,6 +168,9 @@ func pauseOrResumeSchedulerCommandFunc(cmd *cobra.Command, args []string) {
 		}
 		input["delay"] = dealy
 	}
+	if len(args) == 3 {
+		input["reason"] = args[2]
+	}
 	postJSON(cmd, path, input)
 }
 
@@ -81,7 +178,7 @@ func pauseOrResumeSchedulerCommandFunc(cmd *cobra.Command, args []string) {
 func NewShowSchedulerCommand() *cobra.Command {
 	c := &cobra.Command{
 		Use:   "show",
-		Short: "show schedulers",
+		Short: "show schedulers and their pause state",
 		Run:   showSchedulerCommandFunc,
 	}
 	return c
@@ -103,7 +200,7 @@ func showSchedulerCommandFunc(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	r, err := doRequest(cmd, schedulersPrefix, http.MethodGet)
+	r, err := doRequest(cmd, schedulersPrefix+"?status=true", http.MethodGet)
 	if err != nil {
 		cmd.Println(err)
 		return
@@ -127,6 +224,7 @@ func NewAddSchedulerCommand() *cobra.Command {
 	c.AddCommand(NewBalanceRegionSchedulerCommand())
 	c.AddCommand(NewBalanceHotRegionSchedulerCommand())
 	c.AddCommand(NewRandomMergeSchedulerCommand())
+	c.AddCommand(NewSplitHotRegionSchedulerCommand())
 	c.AddCommand(NewBalanceAdjacentRegionSchedulerCommand())
 	c.AddCommand(NewLabelSchedulerCommand())
 	return c
@@ -291,6 +389,16 @@ func NewRandomMergeSchedulerCommand() *cobra.Command {
 	return c
 }
 
+// NewSplitHotRegionSchedulerCommand returns a command to add a split-hot-region-scheduler.
+func NewSplitHotRegionSchedulerCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "split-hot-region-scheduler",
+		Short: "add a scheduler to split the regions too hot to move",
+		Run:   addSchedulerCommandFunc,
+	}
+	return c
+}
+
 // NewLabelSchedulerCommand returns a command to add a label-scheduler.
 func NewLabelSchedulerCommand() *cobra.Command {
 	c := &cobra.Command{
@@ -424,8 +532,9 @@ func removeSchedulerCommandFunc(cmd *cobra.Command, args []string) {
 // NewConfigSchedulerCommand returns commands to config scheduler.
 func NewConfigSchedulerCommand() *cobra.Command {
 	c := &cobra.Command{
-		Use:   "config",
-		Short: "config a scheduler",
+		Use:   "config <scheduler> [schema | set <key> <value>]",
+		Short: "show, describe or set the config of a scheduler",
+		Run:   schedulerConfigCommandFunc,
 	}
 	c.AddCommand(
 		newConfigEvictLeaderCommand(),
@@ -436,6 +545,151 @@ func NewConfigSchedulerCommand() *cobra.Command {
 	return c
 }
 
+// schedulerConfigCommandFunc handles the schedulers which have no specific
+// config commands, the config items are described by the schema from pd.
+func schedulerConfigCommandFunc(cmd *cobra.Command, args []string) {
+	switch {
+	case len(args) == 1:
+		showSchedulerConfigCommandFunc(cmd, args[0])
+	case len(args) == 2 && args[1] == "schema":
+		showSchedulerConfigSchemaCommandFunc(cmd, args[0])
+	case len(args) == 4 && args[1] == "set":
+		setSchedulerConfigCommandFunc(cmd, args[0], args[2], args[3])
+	default:
+		cmd.Println(cmd.UsageString())
+	}
+}
+
+type schedulerConfig struct {
+	Config json.RawMessage        `json:"config"`
+	Schema *schedule.ConfigSchema `json:"schema"`
+}
+
+func getSchedulerConfig(cmd *cobra.Command, name string) (*schedulerConfig, error) {
+	r, err := doRequest(cmd, path.Join(schedulerConfigPrefix, name), http.MethodGet)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &schedulerConfig{}
+	if err := json.Unmarshal([]byte(r), cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func showSchedulerConfigCommandFunc(cmd *cobra.Command, name string) {
+	cfg, err := getSchedulerConfig(cmd, name)
+	if err != nil {
+		cmd.Printf("Failed to get the config of %s: %s\n", name, err)
+		return
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, cfg.Config, "", "  "); err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println(out.String())
+}
+
+func showSchedulerConfigSchemaCommandFunc(cmd *cobra.Command, name string) {
+	cfg, err := getSchedulerConfig(cmd, name)
+	if err != nil {
+		cmd.Printf("Failed to get the config of %s: %s\n", name, err)
+		return
+	}
+	if cfg.Schema == nil {
+		cmd.Printf("%s has no config schema\n", name)
+		return
+	}
+	keys := make([]string, 0, len(cfg.Schema.Properties))
+	for key := range cfg.Schema.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		item := cfg.Schema.Properties[key]
+		desc := item.Type
+		if item.Items != nil {
+			desc += " of " + item.Items.Type
+			if len(item.Items.Enum) > 0 {
+				desc += " in [" + strings.Join(item.Items.Enum, ",") + "]"
+			}
+		}
+		if len(item.Enum) > 0 {
+			desc += " in [" + strings.Join(item.Enum, ",") + "]"
+		}
+		if item.Minimum != nil {
+			desc += fmt.Sprintf(", min %v", *item.Minimum)
+		}
+		if item.Maximum != nil {
+			desc += fmt.Sprintf(", max %v", *item.Maximum)
+		}
+		if item.ReadOnly {
+			desc += ", read-only"
+		}
+		cmd.Printf("%s: %s\n", key, desc)
+	}
+}
+
+func setSchedulerConfigCommandFunc(cmd *cobra.Command, name, key, value string) {
+	cfg, err := getSchedulerConfig(cmd, name)
+	if err != nil {
+		cmd.Printf("Failed to get the config of %s: %s\n", name, err)
+		return
+	}
+	if cfg.Schema == nil {
+		cmd.Printf("%s has no config schema\n", name)
+		return
+	}
+	item, ok := cfg.Schema.Properties[key]
+	if !ok {
+		cmd.Printf("%s has no config item %s\n", name, key)
+		return
+	}
+	if item.ReadOnly {
+		cmd.Printf("%s is read-only\n", key)
+		return
+	}
+	val, err := parseSchedulerConfigValue(item, value)
+	if err != nil {
+		cmd.Printf("Failed to parse %s: %s\n", value, err)
+		return
+	}
+	postJSON(cmd, path.Join(schedulerConfigPrefix, name), map[string]interface{}{key: val})
+}
+
+// parseSchedulerConfigValue converts the value from the command line to the
+// type of the schema. The elements of an array are separated by commas.
+func parseSchedulerConfigValue(schema *schedule.ConfigSchema, value string) (interface{}, error) {
+	switch schema.Type {
+	case "integer":
+		return strconv.ParseInt(value, 10, 64)
+	case "number":
+		return strconv.ParseFloat(value, 64)
+	case "boolean":
+		return strconv.ParseBool(value)
+	case "string":
+		return value, nil
+	case "array":
+		vals := []interface{}{}
+		for _, v := range strings.Split(value, ",") {
+			if v == "" {
+				continue
+			}
+			val, err := parseSchedulerConfigValue(schema.Items, v)
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, val)
+		}
+		return vals, nil
+	default:
+		var val interface{}
+		err := json.Unmarshal([]byte(value), &val)
+		return val, err
+	}
+}
+
 func newConfigHotRegionCommand() *cobra.Command {
 	c := &cobra.Command{
 		Use:   "balance-hot-region-scheduler",
@@ -449,7 +703,13 @@ func newConfigHotRegionCommand() *cobra.Command {
 	c.AddCommand(&cobra.Command{
 		Use:   "set <key> <value>",
 		Short: "set the config item",
-		Run:   func(cmd *cobra.Command, args []string) { postSchedulerConfigCommandFunc(cmd, c.Name(), args) }})
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 2 {
+				cmd.Println(cmd.UsageString())
+				return
+			}
+			setSchedulerConfigCommandFunc(cmd, c.Name(), args[0], args[1])
+		}})
 	return c
 }
 
@@ -494,6 +754,9 @@ func newConfigShuffleRegionCommand() *cobra.Command {
 		Use:   "shuffle-region-scheduler",
 		Short: "shuffle-region-scheduler config",
 	}
+	c.Run = func(cmd *cobra.Command, args []string) {
+		schedulerConfigCommandFunc(cmd, append([]string{c.Name()}, args...))
+	}
 	c.AddCommand(&cobra.Command{
 		Use:   "show-roles",
 		Short: "show affected roles (leader,follower,learner)",
@@ -502,6 +765,12 @@ func newConfigShuffleRegionCommand() *cobra.Command {
 		Use:   "set-roles [leader,][follower,][learner]",
 		Short: "set affected roles",
 		Run:   setSuffleRegionSchedulerRolesCommandFunc,
+	}, &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "set the config item",
+		Run: func(cmd *cobra.Command, args []string) {
+			schedulerConfigCommandFunc(cmd, append([]string{c.Name(), "set"}, args...))
+		},
 	})
 	return c
 }
@@ -537,22 +806,6 @@ func listSchedulerConfigCommandFunc(cmd *cobra.Command, args []string) {
 	cmd.Println(r)
 }
 
-func postSchedulerConfigCommandFunc(cmd *cobra.Command, schedulerName string, args []string) {
-	if len(args) != 2 {
-		cmd.Println(cmd.UsageString())
-		return
-	}
-	var val interface{}
-	input := make(map[string]interface{})
-	key, value := args[0], args[1]
-	val, err := strconv.ParseFloat(value, 64)
-	if err != nil {
-		val = value
-	}
-	input[key] = val
-	postJSON(cmd, path.Join(schedulerConfigPrefix, schedulerName, "config"), input)
-}
-
 // convertReomveConfigToReomveScheduler make cmd can be used at removeCommandFunc
 func convertReomveConfigToReomveScheduler(cmd *cobra.Command) {
 	setCommandUse(cmd, "remove")
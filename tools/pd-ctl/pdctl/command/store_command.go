@@ -16,6 +16,7 @@ package command
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"path"
 	"strconv"
@@ -24,8 +25,9 @@ import (
 )
 
 var (
-	storesPrefix = "pd/api/v1/stores"
-	storePrefix  = "pd/api/v1/store/%v"
+	storesPrefix     = "pd/api/v1/stores"
+	storePrefix      = "pd/api/v1/store/%v"
+	storeWatchPrefix = "pd/api/v1/watch/stores?revision=%d"
 )
 
 // NewStoreCommand return a stores subcommand of rootCmd
@@ -41,10 +43,22 @@ func NewStoreCommand() *cobra.Command {
 	s.AddCommand(NewStoreLimitCommand())
 	s.AddCommand(NewRemoveTombStoneCommand())
 	s.AddCommand(NewStoreLimitSceneCommand())
+	s.AddCommand(NewWatchStoreCommand())
 	s.Flags().String("jq", "", "jq query")
 	return s
 }
 
+// NewWatchStoreCommand returns a watch subcommand of storeCmd.
+func NewWatchStoreCommand() *cobra.Command {
+	w := &cobra.Command{
+		Use:   "watch [--revision=<revision>]",
+		Short: "watch the changes of stores, one event per line",
+		Run:   watchStoreCommandFunc,
+	}
+	w.Flags().Int64("revision", 0, "the revision to watch from, the current stores are shown first if it is not positive")
+	return w
+}
+
 // NewDeleteStoreByAddrCommand returns a subcommand of delete
 func NewDeleteStoreByAddrCommand() *cobra.Command {
 	d := &cobra.Command{
@@ -89,8 +103,8 @@ func NewSetStoreWeightCommand() *cobra.Command {
 // NewStoreLimitCommand returns a limit subcommand of storeCmd.
 func NewStoreLimitCommand() *cobra.Command {
 	return &cobra.Command{
-		Use:   "limit [<store_id>|<all> <rate>]",
-		Short: "set a store's rate limit",
+		Use:   "limit [<store_id>|<all> <rate> [add-peer|remove-peer]]",
+		Short: "set a store's rate limit, both add-peer and remove-peer limits are set if the type is not specified",
 		Run:   storeLimitCommandFunc,
 	}
 }
@@ -358,7 +372,7 @@ func storeLimitCommandFunc(cmd *cobra.Command, args []string) {
 		showAllLimitCommandFunc(cmd, args)
 		return
 	}
-	if len(args) != 2 {
+	if len(args) != 2 && len(args) != 3 {
 		cmd.Usage()
 		return
 	}
@@ -367,18 +381,20 @@ func storeLimitCommandFunc(cmd *cobra.Command, args []string) {
 		cmd.Println("rate should be a number that >= 0.")
 		return
 	}
+	input := map[string]interface{}{
+		"rate": rate,
+	}
+	if len(args) == 3 {
+		input["type"] = args[2]
+	}
 	// if the storeid is "all", set limits for all stores
 	if args[0] == "all" {
 		prefix := path.Join(storesPrefix, "limit")
-		postJSON(cmd, prefix, map[string]interface{}{
-			"rate": rate,
-		})
+		postJSON(cmd, prefix, input)
 		return
 	}
 	prefix := fmt.Sprintf(path.Join(storePrefix, "limit"), args[0])
-	postJSON(cmd, prefix, map[string]interface{}{
-		"rate": rate,
-	})
+	postJSON(cmd, prefix, input)
 }
 
 func showStoresCommandFunc(cmd *cobra.Command, args []string) {
@@ -430,3 +446,30 @@ func setAllLimitCommandFunc(cmd *cobra.Command, args []string) {
 		"rate": rate,
 	})
 }
+
+func watchStoreCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Usage()
+		return
+	}
+	revision, err := cmd.Flags().GetInt64("revision")
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	prefix := fmt.Sprintf(storeWatchPrefix, revision)
+	err = doStreamRequest(cmd, prefix, func(decoder *json.Decoder) error {
+		for {
+			var events []json.RawMessage
+			if err := decoder.Decode(&events); err != nil {
+				return err
+			}
+			for _, e := range events {
+				cmd.Println(string(e))
+			}
+		}
+	})
+	if err != nil && err != io.EOF {
+		cmd.Printf("Failed to watch stores: %s\n", err)
+	}
+}
@@ -31,6 +31,7 @@ type CommandFlags struct {
 	CAPath   string
 	CertPath string
 	KeyPath  string
+	Token    string
 	Help     bool
 }
 
@@ -66,6 +67,7 @@ func getBasicCmd() *cobra.Command {
 	rootCmd.PersistentFlags().StringVar(&commandFlags.CAPath, "cacert", "", "Path of file that contains list of trusted SSL CAs.")
 	rootCmd.PersistentFlags().StringVar(&commandFlags.CertPath, "cert", "", "Path of file that contains X509 certificate in PEM format.")
 	rootCmd.PersistentFlags().StringVar(&commandFlags.KeyPath, "key", "", "Path of file that contains X509 key in PEM format.")
+	rootCmd.PersistentFlags().StringVar(&commandFlags.Token, "token", "", "Bearer token to authenticate with pd, the environment variable PD_CTL_TOKEN is used if it is not set.")
 	rootCmd.PersistentFlags().BoolVarP(&commandFlags.Help, "help", "h", false, "Help message.")
 
 	rootCmd.AddCommand(
@@ -87,6 +89,10 @@ func getBasicCmd() *cobra.Command {
 		command.NewPluginCommand(),
 		command.NewComponentCommand(),
 		command.NewCompletionCommand(),
+		command.NewServiceGCSafepointCommand(),
+		command.NewRegionLabelCommand(),
+		command.NewScheduleProfileCommand(),
+		command.NewAuditCommand(),
 	)
 
 	rootCmd.Flags().ParseErrorsWhitelist.UnknownFlags = true
@@ -127,6 +133,7 @@ func hiddenFlag(cmd *cobra.Command) {
 	cmd.LocalFlags().MarkHidden("cacert")
 	cmd.LocalFlags().MarkHidden("cert")
 	cmd.LocalFlags().MarkHidden("key")
+	cmd.LocalFlags().MarkHidden("token")
 }
 
 // MainStart start main command
@@ -0,0 +1,291 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/grpcutil"
+	"github.com/pingcap/pd/v4/server/replay"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+var (
+	pdAddr      = flag.String("pd", "http://127.0.0.1:2379", "pd address")
+	recordDir   = flag.String("record-dir", "", "the directory of the recording to replay")
+	replayedDir = flag.String("replayed-dir", "", "the heartbeat-record.dir of the pd to replay to, used to collect the replayed operators")
+	speed       = flag.Float64("speed", 0, "the replay speed relative to the recording, 0 means as fast as possible")
+	wait        = flag.Duration("wait", 5*time.Second, "how long to wait for the operators after the replay")
+	caPath      = flag.String("cacert", "", "path of file that contains list of trusted SSL CAs.")
+	certPath    = flag.String("cert", "", "path of file that contains X509 certificate in PEM format.")
+	keyPath     = flag.String("key", "", "path of file that contains X509 key in PEM format.")
+)
+
+func main() {
+	flag.Parse()
+	if *recordDir == "" || *replayedDir == "" {
+		fmt.Fprintln(os.Stderr, "both -record-dir and -replayed-dir are required")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	files, err := replay.ListFiles(*recordDir)
+	if err != nil {
+		log.Fatal("failed to list the recording files", zap.Error(err))
+	}
+	if len(files) == 0 {
+		log.Fatal("no recording file found", zap.String("dir", *recordDir))
+	}
+	tlsCfg, err := grpcutil.SecurityConfig{
+		CAPath:   *caPath,
+		CertPath: *certPath,
+		KeyPath:  *keyPath,
+	}.ToTLSConfig()
+	if err != nil {
+		log.Fatal("failed to load the tls config", zap.Error(err))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cc, err := grpcutil.GetClientConn(ctx, *pdAddr, tlsCfg)
+	if err != nil {
+		log.Fatal("failed to connect to pd", zap.Error(err))
+	}
+	r := &replayer{cli: pdpb.NewPDClient(cc), stores: make(map[uint64]*metapb.Store)}
+	if err := r.prepare(ctx, files); err != nil {
+		log.Fatal("failed to prepare the replay", zap.Error(err))
+	}
+	// The replayed operators are recorded by pd itself, the ones created
+	// before the replay are ignored.
+	replayStart := time.Now()
+	if err := r.replay(ctx, files); err != nil {
+		log.Fatal("failed to replay", zap.Error(err))
+	}
+	log.Info("replay finished, wait for the operators", zap.Int("region-heartbeats", r.regionCount),
+		zap.Int("store-heartbeats", r.storeCount), zap.Duration("wait", *wait))
+	time.Sleep(*wait)
+
+	replayed, err := readReplayedOperators(*replayedDir, replayStart)
+	if err != nil {
+		log.Fatal("failed to read the replayed operators", zap.Error(err))
+	}
+	diff := replay.DiffOperators(r.operators, replayed)
+	fmt.Printf("recorded: %d, replayed: %d, matched: %d\n", len(r.operators), len(replayed), diff.Matched)
+	fmt.Printf("only recorded: %d\n", len(diff.OnlyRecorded))
+	for _, op := range diff.OnlyRecorded {
+		fmt.Printf("  %s\n", op)
+	}
+	fmt.Printf("only replayed: %d\n", len(diff.OnlyReplayed))
+	for _, op := range diff.OnlyReplayed {
+		fmt.Printf("  %s\n", op)
+	}
+}
+
+type replayer struct {
+	cli       pdpb.PDClient
+	clusterID uint64
+	stream    pdpb.PD_RegionHeartbeatClient
+	// stores are the metas which have been put to pd.
+	stores    map[uint64]*metapb.Store
+	operators []*replay.OperatorRecord
+
+	regionCount int
+	storeCount  int
+}
+
+func (r *replayer) header() *pdpb.RequestHeader {
+	return &pdpb.RequestHeader{ClusterId: r.clusterID}
+}
+
+// prepare collects the recorded operators and bootstraps the cluster with the
+// first recorded region.
+func (r *replayer) prepare(ctx context.Context, files []string) error {
+	members, err := r.cli.GetMembers(ctx, &pdpb.GetMembersRequest{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	r.clusterID = members.GetHeader().GetClusterId()
+
+	var region *pdpb.RegionHeartbeatRequest
+	stores := make(map[uint64]*metapb.Store)
+	err = replay.ReadFiles(files, func(record *replay.Record) error {
+		switch record.Type {
+		case replay.RecordRegionHeartbeat:
+			if region == nil && record.Region.GetLeader() != nil {
+				region = record.Region
+			}
+		case replay.RecordStoreHeartbeat:
+			if _, ok := stores[record.Store.GetId()]; !ok {
+				stores[record.Store.GetId()] = record.Store
+			}
+		case replay.RecordOperator:
+			r.operators = append(r.operators, record.Operator)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if region == nil {
+		return errors.New("no region heartbeat is recorded")
+	}
+
+	resp, err := r.cli.IsBootstrapped(ctx, &pdpb.IsBootstrappedRequest{Header: r.header()})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if resp.GetBootstrapped() {
+		log.Warn("the cluster is already bootstrapped, the result may be inaccurate")
+		return nil
+	}
+	// The bootstrap region covers the whole key space, it is replaced by the
+	// recorded heartbeats.
+	leader := region.GetLeader()
+	store, ok := stores[leader.GetStoreId()]
+	if !ok {
+		store = &metapb.Store{Id: leader.GetStoreId(), Address: fmt.Sprintf("store%d", leader.GetStoreId())}
+	}
+	_, err = r.cli.Bootstrap(ctx, &pdpb.BootstrapRequest{
+		Header: r.header(),
+		Store:  store,
+		Region: &metapb.Region{
+			Id:          region.GetRegion().GetId(),
+			Peers:       []*metapb.Peer{leader},
+			RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+		},
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	r.stores[store.GetId()] = store
+	log.Info("bootstrapped", zap.Uint64("store-id", store.GetId()), zap.Uint64("region-id", region.GetRegion().GetId()))
+	return nil
+}
+
+// replay sends the recorded heartbeats to pd with the recorded intervals
+// scaled by the speed.
+func (r *replayer) replay(ctx context.Context, files []string) error {
+	stream, err := r.cli.RegionHeartbeat(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	r.stream = stream
+	// Drain the responses so that pd is not blocked on sending them.
+	go func() {
+		for {
+			if _, err := stream.Recv(); err != nil {
+				if err != io.EOF && ctx.Err() == nil {
+					log.Warn("region heartbeat stream is broken", zap.Error(err))
+				}
+				return
+			}
+		}
+	}()
+
+	var start, recordStart time.Time
+	return replay.ReadFiles(files, func(record *replay.Record) error {
+		if *speed > 0 {
+			if start.IsZero() {
+				start, recordStart = time.Now(), record.Time
+			}
+			due := start.Add(time.Duration(float64(record.Time.Sub(recordStart)) / *speed))
+			if d := time.Until(due); d > 0 {
+				time.Sleep(d)
+			}
+		}
+		switch record.Type {
+		case replay.RecordRegionHeartbeat:
+			return r.sendRegionHeartbeat(ctx, record.Region)
+		case replay.RecordStoreHeartbeat:
+			return r.sendStoreHeartbeat(ctx, record.Store, record.StoreStats)
+		}
+		return nil
+	})
+}
+
+func (r *replayer) sendRegionHeartbeat(ctx context.Context, req *pdpb.RegionHeartbeatRequest) error {
+	// pd rejects the heartbeat if a store is unknown, put a placeholder one
+	// for the store whose heartbeat is not replayed yet.
+	for _, peer := range req.GetRegion().GetPeers() {
+		if _, ok := r.stores[peer.GetStoreId()]; !ok {
+			if err := r.putStore(ctx, &metapb.Store{Id: peer.GetStoreId(), Address: fmt.Sprintf("store%d", peer.GetStoreId())}); err != nil {
+				return err
+			}
+		}
+	}
+	req.Header = r.header()
+	if err := r.stream.Send(req); err != nil {
+		return errors.WithStack(err)
+	}
+	r.regionCount++
+	return nil
+}
+
+func (r *replayer) sendStoreHeartbeat(ctx context.Context, store *metapb.Store, stats *pdpb.StoreStats) error {
+	// Put the store when it first shows up or its meta is changed, e.g. the
+	// labels are updated.
+	if old, ok := r.stores[store.GetId()]; !ok || !proto.Equal(old, store) {
+		if err := r.putStore(ctx, store); err != nil {
+			return err
+		}
+	}
+	resp, err := r.cli.StoreHeartbeat(ctx, &pdpb.StoreHeartbeatRequest{Header: r.header(), Stats: stats})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if e := resp.GetHeader().GetError(); e != nil {
+		log.Warn("failed to send store heartbeat", zap.Uint64("store-id", store.GetId()), zap.String("error", e.GetMessage()))
+	}
+	r.storeCount++
+	return nil
+}
+
+func (r *replayer) putStore(ctx context.Context, store *metapb.Store) error {
+	resp, err := r.cli.PutStore(ctx, &pdpb.PutStoreRequest{Header: r.header(), Store: store})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if e := resp.GetHeader().GetError(); e != nil {
+		log.Warn("failed to put store", zap.Uint64("store-id", store.GetId()), zap.String("error", e.GetMessage()))
+	}
+	r.stores[store.GetId()] = store
+	return nil
+}
+
+// readReplayedOperators reads the operators recorded by pd since the replay
+// starts.
+func readReplayedOperators(dir string, since time.Time) ([]*replay.OperatorRecord, error) {
+	files, err := replay.ListFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	var ops []*replay.OperatorRecord
+	err = replay.ReadFiles(files, func(record *replay.Record) error {
+		if record.Type == replay.RecordOperator && !record.Time.Before(since) {
+			ops = append(ops, record.Operator)
+		}
+		return nil
+	})
+	return ops, err
+}
@@ -32,6 +32,8 @@ import (
 var (
 	pdAddrs     = flag.String("pd", "127.0.0.1:2379", "pd address")
 	concurrency = flag.Int("C", 1000, "concurrency")
+	clientNum   = flag.Int("client", 1, "the number of pd clients, each client uses its own tso stream")
+	duration    = flag.Duration("duration", 0, "how long to run the benchmark, 0 means until interrupted")
 	interval    = flag.Duration("interval", time.Second, "interval to output the statistics")
 	caPath      = flag.String("cacert", "", "path of file that contains list of trusted SSL CAs.")
 	certPath    = flag.String("cert", "", "path of file that contains X509 certificate in PEM format..")
@@ -42,19 +44,26 @@ var (
 func main() {
 	flag.Parse()
 
-	pdCli, err := pd.NewClient([]string{*pdAddrs}, pd.SecurityOption{
-		CAPath:   *caPath,
-		CertPath: *certPath,
-		KeyPath:  *keyPath,
-	})
-	if err != nil {
-		log.Fatal(fmt.Sprintf("%v", err))
+	if *clientNum < 1 {
+		*clientNum = 1
+	}
+	pdClis := make([]pd.Client, *clientNum)
+	for i := range pdClis {
+		pdCli, err := pd.NewClient([]string{*pdAddrs}, pd.SecurityOption{
+			CAPath:   *caPath,
+			CertPath: *certPath,
+			KeyPath:  *keyPath,
+		})
+		if err != nil {
+			log.Fatal(fmt.Sprintf("%v", err))
+		}
+		pdClis[i] = pdCli
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	// To avoid the first time high latency.
 	for i := 0; i < *concurrency; i++ {
-		_, _, err = pdCli.GetTS(ctx)
+		_, _, err := pdClis[i%*clientNum].GetTS(ctx)
 		if err != nil {
 			log.Fatal("get tso failed", zap.Error(err))
 		}
@@ -64,7 +73,7 @@ func main() {
 
 	wg.Add(*concurrency)
 	for i := 0; i < *concurrency; i++ {
-		go reqWorker(ctx, pdCli, durCh)
+		go reqWorker(ctx, pdClis[i%*clientNum], durCh)
 	}
 
 	wg.Add(1)
@@ -81,10 +90,15 @@ func main() {
 		<-sc
 		cancel()
 	}()
+	if *duration > 0 {
+		time.AfterFunc(*duration, cancel)
+	}
 
 	wg.Wait()
 
-	pdCli.Close()
+	for _, pdCli := range pdClis {
+		pdCli.Close()
+	}
 }
 
 func showStats(ctx context.Context, durCh chan time.Duration) {
@@ -101,12 +115,15 @@ func showStats(ctx context.Context, durCh chan time.Duration) {
 	for {
 		select {
 		case <-ticker.C:
+			s.elapsed = time.Since(s.start)
 			println(s.String())
 			total.merge(s)
 			s = newStats()
 		case d := <-durCh:
 			s.update(d)
 		case <-statCtx.Done():
+			s.elapsed = time.Since(s.start)
+			total.merge(s)
 			println("\nTotal:")
 			println(total.String())
 			return
@@ -122,8 +139,11 @@ const (
 )
 
 type stats struct {
+	start        time.Time
+	elapsed      time.Duration
 	maxDur       time.Duration
 	minDur       time.Duration
+	totalDur     time.Duration
 	count        int
 	milliCnt     int
 	twoMilliCnt  int
@@ -134,6 +154,7 @@ type stats struct {
 
 func newStats() *stats {
 	return &stats{
+		start:  time.Now(),
 		minDur: time.Hour,
 		maxDur: 0,
 	}
@@ -141,6 +162,7 @@ func newStats() *stats {
 
 func (s *stats) update(dur time.Duration) {
 	s.count++
+	s.totalDur += dur
 
 	if dur > s.maxDur {
 		s.maxDur = dur
@@ -183,6 +205,8 @@ func (s *stats) merge(other *stats) {
 		s.minDur = other.minDur
 	}
 
+	s.elapsed += other.elapsed
+	s.totalDur += other.totalDur
 	s.count += other.count
 	s.milliCnt += other.milliCnt
 	s.twoMilliCnt += other.twoMilliCnt
@@ -192,8 +216,15 @@ func (s *stats) merge(other *stats) {
 }
 
 func (s *stats) String() string {
-	return fmt.Sprintf("count:%d, max:%d, min:%d, >1ms:%d, >2ms:%d, >5ms:%d, >10ms:%d, >30ms:%d",
-		s.count, s.maxDur.Nanoseconds()/int64(time.Millisecond), s.minDur.Nanoseconds()/int64(time.Millisecond),
+	var qps, avg float64
+	if s.elapsed > 0 {
+		qps = float64(s.count) / s.elapsed.Seconds()
+	}
+	if s.count > 0 {
+		avg = float64(s.totalDur) / float64(s.count) / float64(time.Millisecond)
+	}
+	return fmt.Sprintf("count:%d, qps:%.0f, avg:%.3fms, max:%d, min:%d, >1ms:%d, >2ms:%d, >5ms:%d, >10ms:%d, >30ms:%d",
+		s.count, qps, avg, s.maxDur.Nanoseconds()/int64(time.Millisecond), s.minDur.Nanoseconds()/int64(time.Millisecond),
 		s.milliCnt, s.twoMilliCnt, s.fiveMilliCnt, s.tenMSCnt, s.thirtyCnt)
 }
 